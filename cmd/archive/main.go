@@ -0,0 +1,102 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"auction-site-go/internal/persistence"
+)
+
+// archive moves the events of auctions that ended more than RETENTION_DAYS
+// ago from the store of the server to the archive file. Run it with the
+// store configuration of the server while the server is stopped, since it
+// rewrites the stored events.
+func main() {
+	log.Println("Reading configuration from environment variables")
+	eventsFile := os.Getenv("EVENTS_FILE")
+	if eventsFile == "" {
+		eventsFile = "tmp/events.jsonl"
+	}
+
+	commandsFile := os.Getenv("COMMANDS_FILE")
+	if commandsFile == "" {
+		commandsFile = "tmp/commands.jsonl"
+	}
+
+	snapshotsFile := os.Getenv("SNAPSHOTS_FILE")
+	if snapshotsFile == "" {
+		snapshotsFile = "tmp/snapshots.jsonl"
+	}
+
+	archiveFile := os.Getenv("ARCHIVE_FILE")
+	if archiveFile == "" {
+		archiveFile = "tmp/archive.jsonl"
+	}
+
+	retentionDays := 90
+	if s := os.Getenv("RETENTION_DAYS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid RETENTION_DAYS: %s", s)
+		}
+		retentionDays = n
+	}
+
+	// The store is read with the backend and the payload decorators of the
+	// server, names encrypted per user are kept as they are
+	backend := os.Getenv("STORE_BACKEND")
+	if backend == "" {
+		backend = "file"
+	}
+
+	var compressionThreshold int
+	if s := os.Getenv("STORE_COMPRESSION_THRESHOLD"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Fatalf("Invalid STORE_COMPRESSION_THRESHOLD: %v", err)
+		}
+		compressionThreshold = n
+	}
+
+	// Archiving one side of a mirror would make them diverge
+	if os.Getenv("MIRROR_EVENTS_FILE") != "" {
+		log.Fatalf("Archiving mirrored stores is not supported, unset MIRROR_EVENTS_FILE once the migration is done")
+	}
+
+	var base persistence.EventRewriter
+	switch backend {
+	case "file":
+		base = persistence.NewFileStore(commandsFile, eventsFile, snapshotsFile)
+	case "daily":
+		base = persistence.NewDailyFileStore(filepath.Dir(eventsFile))
+	case "memory":
+		// The memory store is saved to the events file, its snapshots aren't kept
+		base = persistence.NewFileStore(commandsFile, eventsFile, "")
+	default:
+		log.Fatalf("Unknown store backend: %s", backend)
+	}
+
+	// Archived events are encrypted and compressed like the stored ones
+	decorate := func(store persistence.EventRewriter) persistence.EventRewriter {
+		if keys := os.Getenv("STORE_ENCRYPTION_KEYS"); keys != "" {
+			secrets, err := persistence.ParseSecretKeys(keys)
+			if err != nil {
+				log.Fatalf("Failed to parse encryption keys: %v", err)
+			}
+			store = persistence.NewEncryptedStore(store, secrets)
+		}
+		// Events compressed while compression was enabled are read either way
+		return persistence.NewCompressingStore(store, compressionThreshold)
+	}
+	store := decorate(base)
+	archive := decorate(persistence.NewFileStore("", archiveFile, ""))
+
+	result, err := persistence.ArchiveEvents(store, archive, time.Duration(retentionDays)*24*time.Hour, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to archive events: %v", err)
+	}
+	log.Printf("Archived %d events of %d auctions to %s", result.Events, len(result.Auctions), archiveFile)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"auction-site-go/internal/domain"
+)
+
+// compat checks the shapes of the stored events and commands against the
+// frozen ones in SCHEMAS_FILE, failing when a frozen shape changed. With
+// COMPAT_FREEZE=true it first freezes the shapes that aren't frozen yet, such
+// as new types and new versions of events, leaving frozen shapes untouched.
+// The server embeds the file, so it must be rebuilt after freezing.
+func main() {
+	log.Println("Reading configuration from environment variables")
+	schemasFile := os.Getenv("SCHEMAS_FILE")
+	if schemasFile == "" {
+		schemasFile = "internal/domain/schemas.json"
+	}
+	freeze := os.Getenv("COMPAT_FREEZE") == "true"
+
+	frozen := map[string]string{}
+	data, err := os.ReadFile(schemasFile)
+	if err != nil {
+		log.Fatalf("Failed to read frozen schemas: %v", err)
+	}
+	if err := json.Unmarshal(data, &frozen); err != nil {
+		log.Fatalf("Failed to parse frozen schemas: %v", err)
+	}
+
+	if freeze {
+		added := 0
+		for key, hash := range domain.SchemaHashes() {
+			if _, ok := frozen[key]; !ok {
+				frozen[key] = hash
+				log.Printf("Freezing %s", key)
+				added++
+			}
+		}
+		// Maps are marshaled with sorted keys, keeping diffs small
+		data, err := json.MarshalIndent(frozen, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to marshal frozen schemas: %v", err)
+		}
+		if err := os.WriteFile(schemasFile, append(data, '\n'), 0644); err != nil {
+			log.Fatalf("Failed to write frozen schemas: %v", err)
+		}
+		log.Printf("Froze %d schemas in %s", added, schemasFile)
+	}
+
+	problems := domain.CheckSchemas(frozen)
+	for _, problem := range problems {
+		log.Println(problem)
+	}
+	if len(problems) > 0 {
+		log.Fatalf("%d schema problems", len(problems))
+	}
+	log.Printf("All %d schemas match the frozen ones", len(domain.SchemaHashes()))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+
+	"auction-site-go/internal/persistence"
+)
+
+// export writes an anonymized, sampled dataset of the bids in the events file
+// to stdout as JSON lines
+func main() {
+	eventsFile := os.Getenv("EVENTS_FILE")
+	if eventsFile == "" {
+		eventsFile = "tmp/events.jsonl"
+	}
+
+	options := persistence.AnonymizeOptions{
+		Salt:         os.Getenv("EXPORT_SALT"),
+		SampleRate:   1,
+		AmountBucket: 100,
+		K:            5,
+	}
+	if s := os.Getenv("EXPORT_SAMPLE_RATE"); s != "" {
+		rate, err := strconv.ParseFloat(s, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			log.Fatalf("Invalid EXPORT_SAMPLE_RATE: %s", s)
+		}
+		options.SampleRate = rate
+	}
+	if s := os.Getenv("EXPORT_AMOUNT_BUCKET"); s != "" {
+		bucket, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || bucket <= 0 {
+			log.Fatalf("Invalid EXPORT_AMOUNT_BUCKET: %s", s)
+		}
+		options.AmountBucket = bucket
+	}
+	if s := os.Getenv("EXPORT_K"); s != "" {
+		k, err := strconv.Atoi(s)
+		if err != nil || k < 1 {
+			log.Fatalf("Invalid EXPORT_K: %s", s)
+		}
+		options.K = k
+	}
+
+	// Without a salt, use a random one so pseudonyms can't be linked to other exports
+	if options.Salt == "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			log.Fatalf("Failed to generate salt: %v", err)
+		}
+		options.Salt = hex.EncodeToString(salt)
+	}
+
+	// Reading through a compressing store also decodes compressed events, and
+	// through a unique event store drops the IDs they're stored with
+	store := persistence.NewUniqueEventStore(persistence.NewCompressingStore(persistence.NewFileStore("", eventsFile, ""), 0))
+	events, err := store.ReadEvents()
+	if err != nil {
+		log.Fatalf("Failed to read events: %v", err)
+	}
+
+	dataset := persistence.AnonymizeEvents(events, options)
+	encoder := json.NewEncoder(os.Stdout)
+	for _, bid := range dataset.Bids {
+		if err := encoder.Encode(bid); err != nil {
+			log.Fatalf("Failed to write dataset: %v", err)
+		}
+	}
+	log.Printf("Exported %d bids, suppressed %d to keep %d-anonymity", len(dataset.Bids), dataset.Suppressed, options.K)
+}
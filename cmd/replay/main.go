@@ -0,0 +1,161 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// replay re-reads the events file, optionally filtered by auction, event type
+// and time range, and feeds the events to the projections in
+// REPLAY_PROJECTIONS: "validate" checks them as a dry run, "snapshot" rebuilds
+// the repository and writes it as a snapshot, which needs an unfiltered replay.
+// With REPLAY_RESET it instead resets the checkpoints of the listed
+// projections, so they're rebuilt from the first event on their next run.
+// With REPLAY_COMMANDS=true it instead replays the stored commands through
+// the current domain logic and reports where the events differ.
+func main() {
+	log.Println("Reading configuration from environment variables")
+	eventsFile := os.Getenv("EVENTS_FILE")
+	if eventsFile == "" {
+		eventsFile = "tmp/events.jsonl"
+	}
+
+	commandsFile := os.Getenv("COMMANDS_FILE")
+	if commandsFile == "" {
+		commandsFile = "tmp/commands.jsonl"
+	}
+
+	snapshotsFile := os.Getenv("SNAPSHOTS_FILE")
+	if snapshotsFile == "" {
+		snapshotsFile = "tmp/snapshots.jsonl"
+	}
+
+	checkpointsFile := os.Getenv("CHECKPOINTS_FILE")
+	if checkpointsFile == "" {
+		checkpointsFile = "tmp/checkpoints.json"
+	}
+
+	if s := os.Getenv("REPLAY_RESET"); s != "" {
+		checkpoints, err := persistence.OpenCheckpoints(checkpointsFile)
+		if err != nil {
+			log.Fatalf("Failed to read checkpoints: %v", err)
+		}
+		for _, name := range strings.Split(s, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if err := checkpoints.Reset(name); err != nil {
+				log.Fatalf("Failed to reset checkpoint of %s: %v", name, err)
+			}
+			log.Printf("Reset checkpoint of %s", name)
+		}
+		return
+	}
+
+	if os.Getenv("REPLAY_COMMANDS") == "true" {
+		store := persistence.NewUniqueEventStore(persistence.NewCompressingStore(persistence.NewFileStore(commandsFile, eventsFile, ""), 0))
+		report, err := persistence.CheckDeterminism(store)
+		if err != nil {
+			log.Fatalf("Failed to replay commands: %v", err)
+		}
+		for _, divergence := range report.Divergences {
+			log.Printf("Command %d, event %d: %s", divergence.Command, divergence.Event, divergence.Reason)
+		}
+		if !report.Deterministic() {
+			log.Fatalf("Found %d divergences replaying %d commands", len(report.Divergences), report.Commands)
+		}
+		log.Printf("Replaying %d commands reproduced all %d events", report.Commands, report.Events)
+		return
+	}
+
+	var filter persistence.ReplayFilter
+	if s := os.Getenv("REPLAY_AUCTION"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid REPLAY_AUCTION: %s", s)
+		}
+		auctionId := domain.AuctionId(id)
+		filter.AuctionId = &auctionId
+	}
+	if s := os.Getenv("REPLAY_TYPES"); s != "" {
+		for _, t := range strings.Split(s, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, t)
+			}
+		}
+	}
+	if s := os.Getenv("REPLAY_FROM"); s != "" {
+		from, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			log.Fatalf("Invalid REPLAY_FROM: %s", s)
+		}
+		filter.From = from
+	}
+	if s := os.Getenv("REPLAY_TO"); s != "" {
+		to, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			log.Fatalf("Invalid REPLAY_TO: %s", s)
+		}
+		filter.To = to
+	}
+
+	names := os.Getenv("REPLAY_PROJECTIONS")
+	if names == "" {
+		names = "validate"
+	}
+	var validation *persistence.ValidationProjection
+	var repository *persistence.RepositoryProjection
+	var projections []persistence.Projection
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "validate":
+			validation = persistence.NewValidationProjection()
+			projections = append(projections, validation)
+		case "snapshot":
+			// A snapshot of some of the events would be loaded as the full state
+			if !filter.IsZero() {
+				log.Fatalf("The snapshot projection needs an unfiltered replay")
+			}
+			repository = persistence.NewRepositoryProjection()
+			projections = append(projections, repository)
+		default:
+			log.Fatalf("Unknown projection in REPLAY_PROJECTIONS: %s", name)
+		}
+	}
+
+	// Reading through a compressing store also decodes compressed events, and
+	// through a unique event store drops the IDs they're stored with
+	store := persistence.NewUniqueEventStore(persistence.NewCompressingStore(persistence.NewFileStore("", eventsFile, snapshotsFile), 0))
+	result, err := persistence.Replay(store, filter, projections...)
+	if err != nil {
+		log.Fatalf("Failed to replay events: %v", err)
+	}
+	log.Printf("Replayed %d of %d events", result.Replayed, result.Read)
+
+	if repository != nil {
+		snapshot, err := repository.Snapshot()
+		if err != nil {
+			log.Fatalf("Failed to build snapshot: %v", err)
+		}
+		if err := store.WriteSnapshot(snapshot); err != nil {
+			log.Fatalf("Failed to write snapshot: %v", err)
+		}
+		log.Printf("Wrote snapshot of %d auctions at position %d", len(snapshot.Auctions), snapshot.Position)
+	}
+
+	if validation != nil {
+		for _, violation := range validation.Violations {
+			log.Printf("Event %d of auction %d: %s", violation.Index, violation.AuctionId, violation.Reason)
+		}
+		if len(validation.Violations) > 0 {
+			log.Fatalf("Found %d invalid events", len(validation.Violations))
+		}
+		log.Printf("All replayed events are valid")
+	}
+}
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
+	"math/rand"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"auction-site-go/internal/domain"
@@ -24,12 +31,193 @@ func main() {
 		commandsFile = "tmp/commands.jsonl"
 	}
 
+	snapshotsFile := os.Getenv("SNAPSHOTS_FILE")
+	if snapshotsFile == "" {
+		snapshotsFile = "tmp/snapshots.jsonl"
+	}
+
+	// Write a snapshot every N events, 0 disables snapshots
+	snapshotEvery := int64(1000)
+	if s := os.Getenv("SNAPSHOT_EVERY"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid SNAPSHOT_EVERY: %v", err)
+		}
+		snapshotEvery = n
+	}
+
+	// Commands that fail are kept in the dead letters file for retrying
+	deadLettersFile := os.Getenv("DEAD_LETTERS_FILE")
+	if deadLettersFile == "" {
+		deadLettersFile = "tmp/dead_letters.jsonl"
+	}
+
 	// Get server port from environment variables or use default
 	port := os.Getenv("SERVER_PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	// Storage backend, "file" by default, "daily" for one file per day next to
+	// the events file, or "memory"
+	backend := os.Getenv("STORE_BACKEND")
+	if backend == "" {
+		backend = "file"
+	}
+
+	// Tracing of store operations is opt-in
+	storeTracing := os.Getenv("STORE_TRACING") == "true"
+
+	// Logging of the commands on auctions is opt-in
+	commandLogging := os.Getenv("COMMAND_LOGGING") == "true"
+
+	// Fraction of the commands whose decisions are logged, such as 0.01,
+	// 0 disables the decision log
+	var decisionLogSample float64
+	if s := os.Getenv("DECISION_LOG_SAMPLE"); s != "" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f < 0 || f > 1 {
+			log.Fatalf("Invalid DECISION_LOG_SAMPLE: %s", s)
+		}
+		decisionLogSample = f
+	}
+
+	// Group commit of event appends is enabled by a window such as "5ms"
+	var groupCommitWindow time.Duration
+	if s := os.Getenv("STORE_GROUP_COMMIT_WINDOW"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid STORE_GROUP_COMMIT_WINDOW: %v", err)
+		}
+		groupCommitWindow = d
+	}
+
+	// Write-behind buffering of event appends is enabled by an interval such
+	// as "50ms". Events acknowledged within the last interval are lost if the
+	// process crashes, see persistence.BufferedStore.
+	var bufferInterval time.Duration
+	if s := os.Getenv("STORE_BUFFER_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid STORE_BUFFER_INTERVAL: %v", err)
+		}
+		bufferInterval = d
+	}
+	if bufferInterval > 0 && (groupCommitWindow > 0 || backend == "memory") {
+		log.Fatalf("STORE_BUFFER_INTERVAL can't be combined with STORE_GROUP_COMMIT_WINDOW or the memory backend")
+	}
+
+	// Listing moderation is enabled by comma-separated keyword lists
+	rejectKeywords := splitList(os.Getenv("MODERATION_REJECT_KEYWORDS"))
+	reviewKeywords := splitList(os.Getenv("MODERATION_REVIEW_KEYWORDS"))
+
+	// Screening is enabled by a comma-separated list of denied user IDs or
+	// names, applied to bids of at least SCREENING_BID_THRESHOLD
+	deniedParties := splitList(os.Getenv("SCREENING_DENIED_PARTIES"))
+	var screeningThreshold int64
+	if s := os.Getenv("SCREENING_BID_THRESHOLD"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid SCREENING_BID_THRESHOLD: %v", err)
+		}
+		screeningThreshold = n
+	}
+
+	// Compression of events larger than the threshold in bytes, 0 disables it
+	var compressionThreshold int
+	if s := os.Getenv("STORE_COMPRESSION_THRESHOLD"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Fatalf("Invalid STORE_COMPRESSION_THRESHOLD: %v", err)
+		}
+		compressionThreshold = n
+	}
+
+	// Bids per client and minute, 0 disables the limit
+	var bidRateLimit int
+	if s := os.Getenv("BID_RATE_LIMIT"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Fatalf("Invalid BID_RATE_LIMIT: %v", err)
+		}
+		bidRateLimit = n
+	}
+
+	// Workers processing the bids of clients sending "Prefer: respond-async",
+	// 0 keeps all bids synchronous, and the number of bids they may queue
+	var asyncBidWorkers int
+	if s := os.Getenv("ASYNC_BID_WORKERS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid ASYNC_BID_WORKERS: %s", s)
+		}
+		asyncBidWorkers = n
+	}
+	asyncBidQueue := 1000
+	if s := os.Getenv("ASYNC_BID_QUEUE"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid ASYNC_BID_QUEUE: %s", s)
+		}
+		asyncBidQueue = n
+	}
+
+	// Lifecycle SLA monitors, enabled by their limits: how long after expiry
+	// an auction may remain without a result, how long after an event its
+	// subscribers may handle it, and how many events the snapshotter may lag
+	var slaLimits persistence.SLALimits
+	if s := os.Getenv("SLA_CLOSING"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid SLA_CLOSING: %v", err)
+		}
+		slaLimits.Closing = d
+	}
+	if s := os.Getenv("SLA_DISPATCH"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid SLA_DISPATCH: %v", err)
+		}
+		slaLimits.Dispatch = d
+	}
+	if s := os.Getenv("SLA_PROJECTION_LAG"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid SLA_PROJECTION_LAG: %v", err)
+		}
+		slaLimits.ProjectionLag = n
+	}
+
+	// The canary probes the server through its API at an interval such as
+	// "1m", listing a private auction and bidding on it
+	var canaryInterval time.Duration
+	if s := os.Getenv("CANARY_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("Invalid CANARY_INTERVAL: %v", err)
+		}
+		canaryInterval = d
+	}
+
+	// Writes are mirrored to a second set of files while migrating between
+	// backends, MIRROR_VERIFY compares both sides at startup
+	mirrorEventsFile := os.Getenv("MIRROR_EVENTS_FILE")
+	mirrorCommandsFile := os.Getenv("MIRROR_COMMANDS_FILE")
+	mirrorSnapshotsFile := os.Getenv("MIRROR_SNAPSHOTS_FILE")
+	if mirrorCommandsFile == "" {
+		mirrorCommandsFile = filepath.Join(filepath.Dir(mirrorEventsFile), "commands.jsonl")
+	}
+	if mirrorSnapshotsFile == "" {
+		mirrorSnapshotsFile = filepath.Join(filepath.Dir(mirrorEventsFile), "snapshots.jsonl")
+	}
+	mirrorVerify := os.Getenv("MIRROR_VERIFY") == "true"
+
+	// Erasure of personal data is enabled by the file of the user keys
+	userKeysFile := os.Getenv("USER_KEYS_FILE")
+
+	// Encryption at rest is enabled by providing keys as "id:base64key,..."
+	encryptionKeys := os.Getenv("STORE_ENCRYPTION_KEYS")
+
 	// Ensure directory exists
 	log.Printf("Ensuring directory exists for events file: %s", eventsFile)
 	dir := filepath.Dir(eventsFile)
@@ -37,22 +225,126 @@ func main() {
 		log.Fatalf("Failed to create directory: %v", err)
 	}
 
-	// Read events
-	events, err := persistence.ReadEvents(eventsFile)
+	var store persistence.Store
+	switch backend {
+	case "file":
+		store = persistence.NewFileStore(commandsFile, eventsFile, snapshotsFile)
+	case "daily":
+		store = persistence.NewDailyFileStore(dir)
+	case "memory":
+		memoryStore, err := openMemoryStore(commandsFile, eventsFile)
+		if err != nil {
+			log.Fatalf("Failed to open memory store: %v", err)
+		}
+		store = memoryStore
+	default:
+		log.Fatalf("Unknown store backend: %s", backend)
+	}
+	if mirrorEventsFile != "" {
+		mirror := persistence.NewMirrorStore(store, persistence.NewFileStore(mirrorCommandsFile, mirrorEventsFile, mirrorSnapshotsFile))
+		if mirrorVerify {
+			verification, err := mirror.Verify()
+			if err != nil {
+				log.Fatalf("Failed to verify mirror: %v", err)
+			}
+			log.Printf("Mirror verification: %d commands, %d events, %d mismatches", verification.Commands, verification.Events, len(verification.Mismatches))
+			for _, m := range verification.Mismatches {
+				log.Printf("Mirror mismatch in %s at %d: %s", m.Stream, m.Position, m.Reason)
+			}
+		}
+		store = mirror
+	}
+	if groupCommitWindow > 0 {
+		store = persistence.NewGroupCommitStore(store, groupCommitWindow, 100)
+	}
+	if bufferInterval > 0 {
+		buffered := persistence.NewBufferedStore(store, bufferInterval, 500)
+		flushOnExit(buffered)
+		store = buffered
+	}
+	if encryptionKeys != "" {
+		secrets, err := persistence.ParseSecretKeys(encryptionKeys)
+		if err != nil {
+			log.Fatalf("Failed to parse encryption keys: %v", err)
+		}
+		store = persistence.NewEncryptedStore(store, secrets)
+	}
+	// Compress before encrypting, since ciphertext doesn't compress
+	if compressionThreshold > 0 {
+		store = persistence.NewCompressingStore(store, compressionThreshold)
+	}
+	// Names are encrypted per user above the decorators reshaping payloads
+	var userKeys *persistence.UserKeys
+	if userKeysFile != "" {
+		keys, err := persistence.OpenUserKeys(userKeysFile)
+		if err != nil {
+			log.Fatalf("Failed to open user keys: %v", err)
+		}
+		userKeys = keys
+		store = persistence.NewErasableStore(store, userKeys)
+	}
+	// Event IDs are stored with the events, over the payload decorators
+	uniqueEvents := persistence.NewUniqueEventStore(store)
+	store = uniqueEvents
+	store = persistence.NewValidatingStore(store)
+	store = persistence.NewIdempotentStore(store)
+	var tracer persistence.Tracer
+	if storeTracing {
+		tracer = persistence.LogTracer{}
+	}
+	store = persistence.Instrument(store, backend, tracer)
+
+	// Initialize the repository and the read models from the latest snapshot
+	// and the events after it
+	restored, err := persistence.LoadState(store)
 	if err != nil {
 		log.Fatalf("Failed to read events: %v", err)
 	}
+	repo, position := restored.Repository, restored.Position
 
-	// Initialize repository
-	repo := domain.EventsToAuctionStates(events)
+	// Components subscribe to the events once they are stored
+	eventBus := persistence.NewEventBus()
+	var slaMonitor *persistence.SLAMonitor
+	if slaLimits != (persistence.SLALimits{}) {
+		slaMonitor = persistence.NewSLAMonitor(slaLimits, repo, position, time.Now, func(violation persistence.SLAViolation) {
+			log.Printf("SLA violation: %s", violation)
+		})
+		eventBus.Subscribe(slaMonitor.Observe)
+	}
+	if snapshotEvery > 0 {
+		snapshotter := persistence.NewSnapshotter(store, snapshotEvery, repo, position)
+		snapshotter.KeepReadModels(restored.ReadModels, restored.Activity.Snapshot())
+		observe := func(event domain.Event) {
+			// The event is stored, a failed snapshot only delays the next one
+			if err := snapshotter.Observe([]domain.Event{event}); err != nil {
+				log.Printf("Failed to write snapshot: %v", err)
+			}
+		}
+		if slaMonitor != nil {
+			observe = slaMonitor.Dispatched("snapshotter", observe)
+			slaMonitor.Projection("snapshotter", snapshotter.Position)
+		}
+		eventBus.Subscribe(observe)
+	}
+	if slaMonitor != nil {
+		go func() {
+			for range time.Tick(10 * time.Second) {
+				slaMonitor.Check()
+			}
+		}()
+	}
 
 	onCommand := func(command domain.Command) error {
-		return persistence.WriteCommands(commandsFile, []domain.Command{command})
+		return store.WriteCommands([]domain.Command{command})
 	}
 
 	// Event handler
 	onEvent := func(event domain.Event) error {
-		return persistence.WriteEvents(eventsFile, []domain.Event{event})
+		if err := store.WriteEvents([]domain.Event{event}); err != nil {
+			return err
+		}
+		eventBus.Publish(event)
+		return nil
 	}
 
 	// Get current time
@@ -60,8 +352,134 @@ func main() {
 
 	// Create web application
 	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+	if len(rejectKeywords) > 0 || len(reviewKeywords) > 0 {
+		app.Moderator = domain.NewKeywordModerator(rejectKeywords, reviewKeywords)
+	}
+	if len(deniedParties) > 0 {
+		app.Screener = domain.NewDenyListScreener(deniedParties)
+		app.ScreeningThreshold = screeningThreshold
+	}
+	if commandLogging {
+		app.Commands.Use(domain.LogCommands(log.Printf))
+	}
+	if decisionLogSample > 0 {
+		app.Commands.Use(domain.LogDecisions(log.Printf, decisionLogSample, rand.Float64))
+	}
+	app.ReadEventsSince = store.ReadEventsSince
+	app.ReadEventIdsSince = uniqueEvents.ReadEventIdsSince
+	app.HealthCheck = func(ctx context.Context) error {
+		return persistence.Ping(ctx, store)
+	}
+	if bidRateLimit > 0 {
+		app.BidAdmission = web.NewAdmissionControl(bidRateLimit, time.Minute, getCurrentTime)
+	}
+	deadLetters, err := persistence.OpenDeadLetters(deadLettersFile)
+	if err != nil {
+		log.Fatalf("Failed to open dead letters: %v", err)
+	}
+	app.DeadLetters = deadLetters
+	if userKeys != nil {
+		app.ForgetUser = userKeys.Forget
+	}
+	if asyncBidWorkers > 0 {
+		app.AsyncBids = web.NewAsyncCommands(asyncBidWorkers, asyncBidQueue, getCurrentTime)
+	}
+
+	// Restore the moderation queue, rule sets, contact threads, reserve
+	// statuses, descriptions, started and archived auctions
+	app.State.SetReports(restored.ReadModels.Reports)
+	app.State.SetRuleSets(restored.ReadModels.RuleSets)
+	app.State.SetContacts(restored.ReadModels.Contacts)
+	app.State.SetReserves(restored.ReadModels.Reserves)
+	app.State.SetDescriptions(restored.ReadModels.Descriptions)
+	app.State.SetStartedAuctions(restored.ReadModels.Started)
+	app.State.SetArchivedAuctions(restored.ReadModels.Archived)
+
+	// The restored activity feed follows the new events
+	eventBus.Subscribe(restored.Activity.Observe)
+	app.Activity = restored.Activity
+
+	// Sealed bid auctions get their result recorded shortly after closing,
+	// auctions closing below their reserve price get it reported, and
+	// scheduled auctions get their start reported
+	go func() {
+		for range time.Tick(10 * time.Second) {
+			app.DetermineWinners(context.Background())
+			app.ReportReserves()
+			app.StartAuctions()
+		}
+	}()
+
+	if canaryInterval > 0 {
+		canary := web.NewCanary("http://localhost:"+port, &http.Client{Timeout: 10 * time.Second}, getCurrentTime)
+		go func() {
+			for range time.Tick(canaryInterval) {
+				if err := canary.Probe(); err != nil {
+					log.Printf("Canary failed: %v", err)
+				}
+			}
+		}()
+	}
 
 	// Start server
 	log.Printf("Starting server on port %s", port)
 	log.Fatal(app.Run(":" + port))
 }
+
+// openMemoryStore creates a memory store seeded from the JSONL files, which
+// are replaced by a snapshot of the store when the process is stopped
+func openMemoryStore(commandsFile, eventsFile string) (*persistence.MemoryStore, error) {
+	fileStore := persistence.NewFileStore(commandsFile, eventsFile, "")
+	commands, err := fileStore.ReadCommands()
+	if err != nil {
+		return nil, err
+	}
+	events, err := fileStore.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	memoryStore := persistence.NewMemoryStore()
+	memoryStore.WriteCommands(commands)
+	memoryStore.WriteEvents(events)
+
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		log.Printf("Writing memory store snapshot to %s and %s", commandsFile, eventsFile)
+		if err := memoryStore.SnapshotToFiles(commandsFile, eventsFile); err != nil {
+			log.Fatalf("Failed to write snapshot: %v", err)
+		}
+		os.Exit(0)
+	}()
+
+	return memoryStore, nil
+}
+
+// flushOnExit flushes the buffered events when the process is stopped
+func flushOnExit(store *persistence.BufferedStore) {
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		log.Printf("Flushing %d buffered events", store.Buffered())
+		if err := store.Flush(); err != nil {
+			log.Fatalf("Failed to flush buffered events: %v", err)
+		}
+		os.Exit(0)
+	}()
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
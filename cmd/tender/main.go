@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"auction-site-go/internal/domain"
+)
+
+// tender deals the key of a tender: it generates an RSA key, splits the
+// private key among the TENDER_CUSTODIANS so that TENDER_THRESHOLD of them
+// can reveal the bids, and prints the public key and the shares. The private
+// key itself is never written, so each share must be handed to its custodian
+// and not kept.
+func main() {
+	log.Println("Reading configuration from environment variables")
+	var custodians []domain.UserId
+	for _, id := range strings.Split(os.Getenv("TENDER_CUSTODIANS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			custodians = append(custodians, domain.UserId(id))
+		}
+	}
+	if len(custodians) == 0 {
+		log.Fatalf("TENDER_CUSTODIANS must list the custodians")
+	}
+
+	// A majority of the custodians by default
+	threshold := len(custodians)/2 + 1
+	if s := os.Getenv("TENDER_THRESHOLD"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > len(custodians) {
+			log.Fatalf("Invalid TENDER_THRESHOLD: %s", s)
+		}
+		threshold = n
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		log.Fatalf("Failed to encode public key: %v", err)
+	}
+	shares, err := domain.SplitSecret(x509.MarshalPKCS1PrivateKey(key), len(custodians), threshold, rand.Reader)
+	if err != nil {
+		log.Fatalf("Failed to split key: %v", err)
+	}
+
+	output := struct {
+		Tender domain.Tender            `json:"tender"`
+		Shares map[domain.UserId]string `json:"shares"`
+	}{
+		Tender: domain.Tender{
+			PublicKey:  base64.StdEncoding.EncodeToString(publicKey),
+			Threshold:  threshold,
+			Custodians: custodians,
+		},
+		Shares: make(map[domain.UserId]string, len(custodians)),
+	}
+	for i, custodian := range custodians {
+		output.Shares[custodian] = base64.StdEncoding.EncodeToString(shares[i])
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+	log.Printf("Dealt a key to %d custodians, %d of them can reveal the bids", len(custodians), threshold)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"auction-site-go/internal/persistence"
+)
+
+// verify replays all stored events twice and checks that both rebuilds give
+// the same auctions, so fold logic that isn't deterministic is caught before
+// an upgrade. With VERIFY_SNAPSHOT=true it also rebuilds from the latest
+// snapshot and the events after it, like the server does on startup.
+func main() {
+	log.Println("Reading configuration from environment variables")
+	eventsFile := os.Getenv("EVENTS_FILE")
+	if eventsFile == "" {
+		eventsFile = "tmp/events.jsonl"
+	}
+
+	snapshotsFile := os.Getenv("SNAPSHOTS_FILE")
+	if snapshotsFile == "" {
+		snapshotsFile = "tmp/snapshots.jsonl"
+	}
+	fromSnapshot := os.Getenv("VERIFY_SNAPSHOT") == "true"
+
+	// Events are read without the IDs they're stored with
+	store := persistence.NewUniqueEventStore(persistence.NewCompressingStore(persistence.NewFileStore("", eventsFile, snapshotsFile), 0))
+	report, err := persistence.VerifyRebuild(store, fromSnapshot)
+	if err != nil {
+		log.Fatalf("Failed to verify rebuilds: %v", err)
+	}
+	for _, mismatch := range report.Mismatches {
+		log.Printf("Auction %d differs in the %s:\n  expected %s\n  actual   %s", mismatch.AuctionId, mismatch.Rebuild, mismatch.Expected, mismatch.Actual)
+	}
+	if !report.Deterministic() {
+		log.Fatalf("Found %d mismatches rebuilding %d auctions from %d events", len(report.Mismatches), report.Auctions, report.Events)
+	}
+	if report.SnapshotPosition > 0 {
+		log.Printf("Rebuilding from the snapshot at %d gave the same auctions", report.SnapshotPosition)
+	}
+	log.Printf("Rebuilding %d auctions from %d events twice gave the same auctions", report.Auctions, report.Events)
+}
@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of activity of a user
+const (
+	ActivityListed    = "listed"
+	ActivityBidPlaced = "bidPlaced"
+	ActivityOutbid    = "outbid"
+	ActivityWon       = "won"
+	ActivitySold      = "sold"
+)
+
+// Activity is something that happened to a user in an auction
+type Activity struct {
+	// Seq orders the activities of all users, for paging through them
+	Seq       int64     `json:"seq"`
+	At        time.Time `json:"at"`
+	Kind      string    `json:"kind"`
+	AuctionId AuctionId `json:"auctionId"`
+	Title     string    `json:"title"`
+	// Amount is the bid, the outbidding bid or the final price, when known.
+	// The seller of a multi-unit auction sold for the total of the prices.
+	Amount int64 `json:"amount,omitempty"`
+	// Units are the units won or sold in a multi-unit auction
+	Units int `json:"units,omitempty"`
+}
+
+// ActivityFeed is a read model of the activity of each user across their
+// roles, as seller and bidder, folded from the events. Auctions that won or
+// sold are settled when the feed is read after their close, once their
+// result is known.
+type ActivityFeed struct {
+	mu      sync.Mutex
+	repo    Repository
+	byUser  map[UserId][]Activity
+	settled map[AuctionId]bool
+	seq     int64
+}
+
+// NewActivityFeed creates an empty activity feed
+func NewActivityFeed() *ActivityFeed {
+	return &ActivityFeed{
+		repo:    make(Repository),
+		byUser:  make(map[UserId][]Activity),
+		settled: make(map[AuctionId]bool),
+	}
+}
+
+// ActivitySnapshot is the state of an activity feed besides its auctions,
+// which are restored from the repository at the same position
+type ActivitySnapshot struct {
+	ByUser  map[UserId][]Activity `json:"byUser"`
+	Settled map[AuctionId]bool    `json:"settled"`
+	Seq     int64                 `json:"seq"`
+}
+
+// RestoreActivityFeed restores an activity feed from a snapshot of it and the
+// repository at the same position
+func RestoreActivityFeed(snapshot ActivitySnapshot, repo Repository) *ActivityFeed {
+	f := NewActivityFeed()
+	for id, entry := range repo {
+		f.repo[id] = entry
+	}
+	for userId, activities := range snapshot.ByUser {
+		f.byUser[userId] = append([]Activity{}, activities...)
+	}
+	for id, settled := range snapshot.Settled {
+		f.settled[id] = settled
+	}
+	f.seq = snapshot.Seq
+	return f
+}
+
+// Snapshot returns a copy of the state of the feed besides its auctions
+func (f *ActivityFeed) Snapshot() ActivitySnapshot {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	snapshot := ActivitySnapshot{
+		ByUser:  make(map[UserId][]Activity, len(f.byUser)),
+		Settled: make(map[AuctionId]bool, len(f.settled)),
+		Seq:     f.seq,
+	}
+	for userId, activities := range f.byUser {
+		snapshot.ByUser[userId] = append([]Activity{}, activities...)
+	}
+	for id, settled := range f.settled {
+		snapshot.Settled[id] = settled
+	}
+	return snapshot
+}
+
+// Observe folds an event into the feed
+func (f *ActivityFeed) Observe(event Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch e := event.(type) {
+	case AuctionAddedEvent:
+		f.add(e.Auction.Seller.ID, Activity{At: e.Time, Kind: ActivityListed, AuctionId: e.Auction.ID, Title: e.Auction.Title})
+	case BidAcceptedEvent:
+		f.addBid(e.Time, e.Bid)
+	case ProxyBidPlacedEvent:
+		f.addBid(e.Time, e.Bid)
+	}
+
+	// Fold only the auction of the event, ApplyEvents copies the repository
+	id, ok := EventAuctionId(event)
+	if !ok {
+		return
+	}
+	auction := Repository{}
+	if entry, exists := f.repo[id]; exists {
+		auction[id] = entry
+	}
+	if entry, exists := ApplyEvents(auction, []Event{event})[id]; exists {
+		f.repo[id] = entry
+	}
+}
+
+// addBid adds the activities of a bid, to its bidder and the bidder it outbids
+func (f *ActivityFeed) addBid(at time.Time, bid Bid) {
+	entry, ok := f.repo[bid.ForAuction]
+	if !ok {
+		return
+	}
+	// A Dutch bid pays the current price, whatever its amount
+	amount := bid.Amount
+	if dutch, ok := entry.State.(*DutchState); ok {
+		amount = dutch.CurrentPrice(bid.At)
+	}
+	f.add(bid.Bidder.ID, Activity{At: at, Kind: ActivityBidPlaced, AuctionId: bid.ForAuction, Title: entry.Auction.Title, Amount: amount})
+	// The highest bid of a timed ascending auction is the first one
+	if bids := entry.State.GetBids(); entry.Auction.Type.Type == TimedAscending && len(bids) > 0 && bids[0].Bidder.ID != bid.Bidder.ID {
+		f.add(bids[0].Bidder.ID, Activity{At: at, Kind: ActivityOutbid, AuctionId: bid.ForAuction, Title: entry.Auction.Title, Amount: bid.Amount})
+	}
+}
+
+// Page returns the activities of a user, newest first, up to limit of those
+// before the sequence number before, all when before is 0
+func (f *ActivityFeed) Page(userId UserId, now time.Time, before int64, limit int) []Activity {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.settle(now)
+
+	activities := f.byUser[userId]
+	page := []Activity{}
+	for i := len(activities) - 1; i >= 0 && len(page) < limit; i-- {
+		if before == 0 || activities[i].Seq < before {
+			page = append(page, activities[i])
+		}
+	}
+	return page
+}
+
+// settle adds the activities of the auctions that closed with a result
+func (f *ActivityFeed) settle(now time.Time) {
+	var ids []AuctionId
+	for id, entry := range f.repo {
+		if !f.settled[id] && !entry.Auction.AwaitingReveal() {
+			ids = append(ids, id)
+		}
+	}
+	// Auctions settling together get their sequence numbers in ID order
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		entry := f.repo[id]
+		state := entry.State.Increment(now)
+		if !state.HasEnded() {
+			continue
+		}
+		f.settled[id] = true
+		if entry.Auction.Type.Type == MultiUnit {
+			f.settleUnits(entry.Auction, state)
+			continue
+		}
+		amount, winner, ok := state.TryGetAmountAndWinner()
+		if !ok {
+			continue
+		}
+		at := CurrentExpiry(state)
+		f.add(winner, Activity{At: at, Kind: ActivityWon, AuctionId: id, Title: entry.Auction.Title, Amount: amount})
+		f.add(entry.Auction.Seller.ID, Activity{At: at, Kind: ActivitySold, AuctionId: id, Title: entry.Auction.Title, Amount: amount})
+	}
+}
+
+// settleUnits adds the activities of a closed multi-unit auction, a unit won
+// by each winner at the price they pay and the units sold by the seller
+func (f *ActivityFeed) settleUnits(auction Auction, state State) {
+	allocations := Allocations(state)
+	if len(allocations) == 0 {
+		return
+	}
+	at := CurrentExpiry(state)
+	var total int64
+	for _, allocation := range allocations {
+		f.add(allocation.Bidder, Activity{At: at, Kind: ActivityWon, AuctionId: auction.ID, Title: auction.Title, Amount: allocation.Price, Units: 1})
+		total += allocation.Price
+	}
+	f.add(auction.Seller.ID, Activity{At: at, Kind: ActivitySold, AuctionId: auction.ID, Title: auction.Title, Amount: total, Units: len(allocations)})
+}
+
+// add appends an activity of a user with the next sequence number
+func (f *ActivityFeed) add(userId UserId, activity Activity) {
+	f.seq++
+	activity.Seq = f.seq
+	f.byUser[userId] = append(f.byUser[userId], activity)
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxDescriptionLength limits the length of an auction description
+const MaxDescriptionLength = 5000
+
+// Descriptions holds the descriptions of auctions by auction, which are set
+// by amending the auctions
+type Descriptions map[AuctionId]string
+
+// AmendAuctionCommand represents a command to amend the title, description
+// or end time of an auction before bidding starts. Unset fields are kept.
+type AmendAuctionCommand struct {
+	Time        time.Time  `json:"at"`
+	AuctionId   AuctionId  `json:"auctionId"`
+	Seller      User       `json:"user"`
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Expiry      *time.Time `json:"expiry,omitempty"`
+}
+
+// GetTime returns the time of the command
+func (c AmendAuctionCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for AmendAuctionCommand
+func (c AmendAuctionCommand) MarshalJSON() ([]byte, error) {
+	type amendAuctionCommandJSON AmendAuctionCommand
+	return MarshalEnvelope("AmendAuction", amendAuctionCommandJSON(c))
+}
+
+// AuctionAmendedEvent represents an event indicating an auction was amended
+// before bidding started, with the fields that changed
+type AuctionAmendedEvent struct {
+	Time        time.Time  `json:"at"`
+	AuctionId   AuctionId  `json:"auctionId"`
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Expiry      *time.Time `json:"expiry,omitempty"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e AuctionAmendedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for AuctionAmendedEvent
+func (e AuctionAmendedEvent) MarshalJSON() ([]byte, error) {
+	type auctionAmendedEventJSON AuctionAmendedEvent
+	return MarshalEnvelope("AuctionAmended", auctionAmendedEventJSON(e))
+}
+
+// withAmendment returns a copy of the auction with an amendment applied. A
+// new title drops the translations of the previous one.
+func (a Auction) withAmendment(title *string, expiry *time.Time) Auction {
+	if title != nil {
+		a.Title = *title
+		a.Translations = nil
+	}
+	if expiry != nil {
+		a.Expiry = *expiry
+	}
+	return a
+}
+
+// Amended returns the auction as an amendment would leave it, such as to
+// moderate it before amending
+func (a Auction) Amended(c AmendAuctionCommand) Auction {
+	return a.withAmendment(c.Title, c.Expiry)
+}
+
+// handleAmendAuction amends an open or upcoming auction of the seller that
+// has no bids yet
+func handleAmendAuction(c AmendAuctionCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists || !entry.Auction.VisibleTo(&c.Seller) {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	if c.Seller.ID != entry.Auction.Seller.ID {
+		return nil, repo, NewCannotAmendAuctionError(c.AuctionId)
+	}
+
+	state := entry.State.Increment(c.Time)
+	if _, cancelled := state.(*CancelledState); cancelled {
+		return nil, repo, NewAuctionCancelledError(c.AuctionId)
+	}
+	if state.HasEnded() {
+		return nil, repo, NewAuctionHasEndedError(c.AuctionId)
+	}
+	if len(state.GetBids()) > 0 {
+		return nil, repo, NewAuctionHasBidsError(c.AuctionId)
+	}
+	if c.Expiry != nil && (!c.Expiry.After(c.Time) || !c.Expiry.After(entry.Auction.StartsAt)) {
+		return nil, repo, NewInvalidRevisionError("the end time must be after the start and in the future")
+	}
+
+	event := AuctionAmendedEvent{
+		Time:        c.Time,
+		AuctionId:   c.AuctionId,
+		Title:       c.Title,
+		Description: c.Description,
+		Expiry:      c.Expiry,
+	}
+	return event, ApplyEvents(repo, []Event{event}), nil
+}
+
+// amendAuction applies an amendment to an auction entry. Amendments only
+// apply before the first bid, so a new end time restarts from an empty state,
+// keeping the maximum bids of an open timed ascending auction.
+func amendAuction(auction Auction, state State, e AuctionAmendedEvent) (Auction, State) {
+	amended := auction.withAmendment(e.Title, e.Expiry)
+	if e.Expiry == nil {
+		return amended, state
+	}
+	if ongoing, ok := state.Increment(e.Time).(*OngoingState); ok {
+		next := *ongoing
+		next.nextExpiry = *e.Expiry
+		return amended, &next
+	}
+	return amended, amended.CreateEmptyState()
+}
+
+// validateAmendment checks an amendment changes something, to a title that
+// isn't blank and a description that isn't too long
+func validateAmendment(c AmendAuctionCommand) []FieldError {
+	var errors []FieldError
+	if c.Seller.ID == "" {
+		errors = append(errors, FieldError{Field: "user", Code: FieldRequired})
+	}
+	if c.Title == nil && c.Description == nil && c.Expiry == nil {
+		errors = append(errors, FieldError{Field: "title", Code: FieldRequired})
+	}
+	if c.Title != nil && strings.TrimSpace(*c.Title) == "" {
+		errors = append(errors, FieldError{Field: "title", Code: FieldRequired})
+	}
+	if c.Description != nil && len([]rune(*c.Description)) > MaxDescriptionLength {
+		errors = append(errors, FieldError{Field: "description", Code: FieldTooLong})
+	}
+	return errors
+}
+
+// EventsToDescriptions folds a list of events into the descriptions of the
+// auctions
+func EventsToDescriptions(events []Event) Descriptions {
+	return ApplyDescriptionEvents(make(Descriptions), events)
+}
+
+// ApplyDescriptionEvents folds a list of events onto a copy of the
+// descriptions. An empty description removes it. Events that don't amend
+// a description are ignored.
+func ApplyDescriptionEvents(descriptions Descriptions, events []Event) Descriptions {
+	newDescriptions := make(Descriptions, len(descriptions))
+	for k, v := range descriptions {
+		newDescriptions[k] = v
+	}
+
+	for _, event := range events {
+		amended, ok := event.(AuctionAmendedEvent)
+		if !ok || amended.Description == nil {
+			continue
+		}
+		if *amended.Description == "" {
+			delete(newDescriptions, amended.AuctionId)
+		} else {
+			newDescriptions[amended.AuctionId] = *amended.Description
+		}
+	}
+	return newDescriptions
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ArchivedAuctions holds the auctions whose events were moved to the archive
+type ArchivedAuctions map[AuctionId]bool
+
+// EventArchivedEvent takes the place of an event of an archived auction in
+// the store, so archival keeps the positions of the other events. It keeps
+// the ID of the event it replaces.
+type EventArchivedEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	EventId   string    `json:"eventId"`
+}
+
+// GetTime returns the time of the event
+func (e EventArchivedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for EventArchivedEvent
+func (e EventArchivedEvent) MarshalJSON() ([]byte, error) {
+	type eventArchivedEventJSON EventArchivedEvent
+	return MarshalEnvelope("EventArchived", eventArchivedEventJSON(e))
+}
+
+// EventsToArchivedAuctions folds a list of events into the archived auctions
+func EventsToArchivedAuctions(events []Event) ArchivedAuctions {
+	return ApplyArchivedEvents(make(ArchivedAuctions), events)
+}
+
+// ApplyArchivedEvents folds a list of events onto a copy of the archived
+// auctions. Events that don't replace an archived event are ignored.
+func ApplyArchivedEvents(archived ArchivedAuctions, events []Event) ArchivedAuctions {
+	newArchived := make(ArchivedAuctions, len(archived))
+	for k, v := range archived {
+		newArchived[k] = v
+	}
+
+	for _, event := range events {
+		if e, ok := event.(EventArchivedEvent); ok {
+			newArchived[e.AuctionId] = true
+		}
+	}
+	return newArchived
+}
+
+// RejectArchivedAuctions rejects adding an auction with the ID of an archived
+// one, which is no longer in the repository, as AuctionAlreadyExists
+func RejectArchivedAuctions(archived func() ArchivedAuctions) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			if c, ok := cmd.(AddAuctionCommand); ok && archived()[c.Auction.ID] {
+				return nil, repo, NewAuctionAlreadyExistsError(c.Auction.ID)
+			}
+			return next(ctx, cmd, repo)
+		}
+	}
+}
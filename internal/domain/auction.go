@@ -3,6 +3,7 @@ package domain
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,8 @@ type AuctionTypeEnum int
 const (
 	TimedAscending  AuctionTypeEnum = iota
 	SingleSealedBid                 = 1
+	Dutch                           = 2
+	MultiUnit                       = 3
 )
 
 // String returns the string representation of the auction type enum
@@ -21,6 +24,10 @@ func (t AuctionTypeEnum) String() string {
 		return "TimedAscending"
 	case SingleSealedBid:
 		return "SingleSealedBid"
+	case Dutch:
+		return "Dutch"
+	case MultiUnit:
+		return "MultiUnit"
 	default:
 		return "Unknown"
 	}
@@ -48,6 +55,22 @@ func NewSingleSealedBidType(options SealedBidOptions) AuctionType {
 	}
 }
 
+// NewDutchType creates a new Dutch auction type
+func NewDutchType(options DutchOptions) AuctionType {
+	return AuctionType{
+		Type:    Dutch,
+		Options: options.String(),
+	}
+}
+
+// NewMultiUnitType creates a new MultiUnit auction type
+func NewMultiUnitType(options MultiUnitOptions) AuctionType {
+	return AuctionType{
+		Type:    MultiUnit,
+		Options: options.String(),
+	}
+}
+
 // String returns a string representation of the auction type
 func (t AuctionType) String() string {
 	return t.Options
@@ -68,6 +91,20 @@ func (t *AuctionType) UnmarshalJSON(data []byte) error {
 		}
 		t.Type = TimedAscending
 		t.Options = options.String()
+	} else if strings.HasPrefix(s, "Dutch") {
+		options, err := ParseDutchOptions(s)
+		if err != nil {
+			return err
+		}
+		t.Type = Dutch
+		t.Options = options.String()
+	} else if strings.HasPrefix(s, "MultiUnit") {
+		options, err := ParseMultiUnitOptions(s)
+		if err != nil {
+			return err
+		}
+		t.Type = MultiUnit
+		t.Options = options.String()
 	} else if s == "Vickrey" || s == "Blind" {
 		t.Type = SingleSealedBid
 		t.Options = s
@@ -92,6 +129,19 @@ type Auction struct {
 	Seller   User        `json:"user"`
 	Type     AuctionType `json:"type"`
 	Currency Currency    `json:"currency"`
+
+	// Translations maps language tags to translated titles
+	Translations map[string]string `json:"translations,omitempty"`
+	// Visibility is public when empty
+	Visibility Visibility `json:"visibility,omitempty"`
+	// Invitees may see and bid on a private auction
+	Invitees []UserId `json:"invitees,omitempty"`
+	// Tags group listings for the seller, such as for bulk revisions
+	Tags []string `json:"tags,omitempty"`
+	// ClonedFrom is the auction this one was cloned from, if any
+	ClonedFrom *AuctionId `json:"clonedFrom,omitempty"`
+	// Tender encrypts the bids of a sealed bid auction, if set
+	Tender *Tender `json:"tender,omitempty"`
 }
 
 // NewAuction creates a new auction
@@ -107,6 +157,27 @@ func NewAuction(id AuctionId, startsAt time.Time, title string, expiry time.Time
 	}
 }
 
+// Clone returns a new auction with the settings and metadata of this one on
+// a fresh schedule, linked back to it through ClonedFrom
+func (a Auction) Clone(id AuctionId, startsAt, expiry time.Time) Auction {
+	clone := a
+	clone.ID = id
+	clone.StartsAt = startsAt
+	clone.Expiry = expiry
+	clone.ClonedFrom = &a.ID
+	// A tender's key and shares are not reused
+	clone.Tender = nil
+	clone.Tags = append([]string(nil), a.Tags...)
+	clone.Invitees = append([]UserId(nil), a.Invitees...)
+	if a.Translations != nil {
+		clone.Translations = make(map[string]string, len(a.Translations))
+		for tag, title := range a.Translations {
+			clone.Translations[tag] = title
+		}
+	}
+	return clone
+}
+
 // ValidateBid validates a bid for the auction
 func (a Auction) ValidateBid(bid Bid) error {
 	if bid.Bidder.ID == a.Seller.ID {
@@ -120,7 +191,9 @@ func (a Auction) ValidateBid(bid Bid) error {
 func (a Auction) CreateEmptyState() State {
 	if a.Type.Type == SingleSealedBid {
 		options := SealedBidOptions(a.Type.Options)
-		return NewSealedBidState(a.Expiry, options)
+		state := NewSealedBidState(a.Expiry, options)
+		state.encrypted = a.AwaitingReveal()
+		return state
 	} else if a.Type.Type == TimedAscending {
 		options, err := ParseTimedAscendingOptions(a.Type.Options)
 		if err != nil {
@@ -129,6 +202,20 @@ func (a Auction) CreateEmptyState() State {
 			return NewTimedAscendingState(a.StartsAt, a.Expiry, defaultOptions)
 		}
 		return NewTimedAscendingState(a.StartsAt, a.Expiry, *options)
+	} else if a.Type.Type == Dutch {
+		options, err := ParseDutchOptions(a.Type.Options)
+		if err != nil {
+			// Without a schedule the price stays at the floor of zero
+			return NewDutchState(a.StartsAt, a.Expiry, DutchOptions{})
+		}
+		return NewDutchState(a.StartsAt, a.Expiry, *options)
+	} else if a.Type.Type == MultiUnit {
+		options, err := ParseMultiUnitOptions(a.Type.Options)
+		if err != nil {
+			// Without units nobody can win
+			return NewMultiUnitState(a.StartsAt, a.Expiry, MultiUnitOptions{Settlement: PayAsBid})
+		}
+		return NewMultiUnitState(a.StartsAt, a.Expiry, *options)
 	}
 
 	// Default to a sealed bid auction if the type is unknown
@@ -10,6 +10,9 @@ type Bid struct {
 	Bidder     User      `json:"user"`
 	At         time.Time `json:"at"`
 	Amount     int64     `json:"amount"`
+	// Sealed is the encrypted amount of a bid on a tender, whose Amount is
+	// zero until the tender is revealed
+	Sealed string `json:"sealed,omitempty"`
 }
 
 // NewBid creates a new bid
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CommandHandler handles a command against a repository, returning the
+// resulting event and repository
+type CommandHandler func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error)
+
+// CommandMiddleware wraps a command handler with behaviour shared by all
+// commands, such as recording, authorization or logging
+type CommandMiddleware func(next CommandHandler) CommandHandler
+
+// CommandBus dispatches commands on auctions through a pipeline of
+// middleware to Handle. The first middleware is the outermost one.
+type CommandBus struct {
+	middleware []CommandMiddleware
+	pipeline   CommandHandler
+}
+
+// NewCommandBus creates a command bus with a middleware pipeline
+func NewCommandBus(middleware ...CommandMiddleware) *CommandBus {
+	bus := &CommandBus{}
+	bus.Use(middleware...)
+	return bus
+}
+
+// Use adds middleware to the end of the pipeline, closest to Handle. It must
+// not be called once commands are dispatched.
+func (b *CommandBus) Use(middleware ...CommandMiddleware) {
+	b.middleware = append(b.middleware, middleware...)
+
+	pipeline := CommandHandler(handleCommand)
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		pipeline = b.middleware[i](pipeline)
+	}
+	b.pipeline = pipeline
+}
+
+// Dispatch passes a command through the pipeline
+func (b *CommandBus) Dispatch(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+	return b.pipeline(ctx, cmd, repo)
+}
+
+// handleCommand adapts Handle to a CommandHandler
+func handleCommand(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+	return Handle(cmd, repo)
+}
+
+// RecordCommands records commands before handling them, so rejected commands
+// are kept too. A command that can't be recorded isn't handled.
+func RecordCommands(record func(ctx context.Context, cmd Command) error) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			if err := record(ctx, cmd); err != nil {
+				return nil, repo, err
+			}
+			return next(ctx, cmd, repo)
+		}
+	}
+}
+
+// DeadLetterCommands passes the commands that fail, with their error, to
+// record, so they can be inspected and retried instead of being lost
+func DeadLetterCommands(record func(ctx context.Context, cmd Command, err error)) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			event, newRepo, err := next(ctx, cmd, repo)
+			if err != nil {
+				record(ctx, cmd, err)
+			}
+			return event, newRepo, err
+		}
+	}
+}
+
+// AuthorizeCommands rejects the commands authorize returns an error for
+func AuthorizeCommands(authorize func(ctx context.Context, cmd Command, repo Repository) error) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			if err := authorize(ctx, cmd, repo); err != nil {
+				return nil, repo, err
+			}
+			return next(ctx, cmd, repo)
+		}
+	}
+}
+
+// LogCommands logs the type, auction, duration and outcome of commands
+func LogCommands(logf func(format string, args ...interface{})) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			start := time.Now()
+			event, newRepo, err := next(ctx, cmd, repo)
+			outcome := "ok"
+			if err != nil {
+				outcome = err.Error()
+			}
+			auctionId, _ := CommandAuctionId(cmd)
+			logf("command %s on auction %d took %v: %s", commandName(cmd), auctionId, time.Since(start), outcome)
+			return event, newRepo, err
+		}
+	}
+}
+
+// commandName returns the name of the type of a command
+func commandName(cmd Command) string {
+	name := fmt.Sprintf("%T", cmd)
+	return name[strings.LastIndex(name, ".")+1:]
+}
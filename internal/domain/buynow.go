@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"time"
+)
+
+// BuyNowPrice returns the price an auction can be bought at, if it can be
+// bought now: it has a buy now price, it's open, and no bid has exceeded
+// the buy now threshold
+func BuyNowPrice(state State, now time.Time) (int64, bool) {
+	ongoing, ok := state.Increment(now).(*OngoingState)
+	if !ok || ongoing.options.BuyNowPrice <= 0 {
+		return 0, false
+	}
+	if len(ongoing.bids) > 0 && ongoing.bids[0].Amount > ongoing.options.BuyNowThreshold {
+		return 0, false
+	}
+	return ongoing.options.BuyNowPrice, true
+}
+
+// BuyNowCommand represents a command to buy an auction at its buy now price
+type BuyNowCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	Buyer     User      `json:"buyer"`
+}
+
+// GetTime returns the time of the command
+func (c BuyNowCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for BuyNowCommand
+func (c BuyNowCommand) MarshalJSON() ([]byte, error) {
+	type buyNowCommandJSON BuyNowCommand
+	return MarshalEnvelope("BuyNow", buyNowCommandJSON(c))
+}
+
+// BoughtNowEvent represents an event indicating an auction was bought at its
+// buy now price, which ends it with the buyer as the winner
+type BoughtNowEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	Buyer     User      `json:"buyer"`
+	Price     int64     `json:"price"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e BoughtNowEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for BoughtNowEvent
+func (e BoughtNowEvent) MarshalJSON() ([]byte, error) {
+	type boughtNowEventJSON BoughtNowEvent
+	return MarshalEnvelope("BoughtNow", boughtNowEventJSON(e))
+}
+
+// handleBuyNow ends an auction with the buyer as the winner at the buy now
+// price
+func handleBuyNow(c BuyNowCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists || !entry.Auction.VisibleTo(&c.Buyer) {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	if c.Buyer.ID == entry.Auction.Seller.ID {
+		return nil, repo, NewSellerCannotPlaceBidsError(c.Buyer.ID, c.AuctionId)
+	}
+
+	state := entry.State.Increment(c.Time)
+	if state.HasEnded() {
+		return nil, repo, NewAuctionHasEndedError(c.AuctionId)
+	}
+	if _, ok := state.(*AwaitingStartState); ok {
+		return nil, repo, NewAuctionHasNotStartedError(c.AuctionId)
+	}
+	price, ok := BuyNowPrice(state, c.Time)
+	if !ok {
+		return nil, repo, NewBuyNowNotAvailableError(c.AuctionId)
+	}
+
+	event := BoughtNowEvent{Time: c.Time, AuctionId: c.AuctionId, Buyer: c.Buyer, Price: price}
+	return event, ApplyEvents(repo, []Event{event}), nil
+}
+
+// buyNow ends a timed ascending auction with the purchase as its winning bid
+func buyNow(state State, e BoughtNowEvent) State {
+	ongoing, ok := state.Increment(e.Time).(*OngoingState)
+	if !ok {
+		return state
+	}
+	bid := Bid{ForAuction: e.AuctionId, Bidder: e.Buyer, At: e.Time, Amount: e.Price}
+	return &EndedState{
+		bids:       append([]Bid{bid}, ongoing.bids...),
+		expiry:     e.Time,
+		options:    ongoing.options,
+		extensions: ongoing.extensions,
+	}
+}
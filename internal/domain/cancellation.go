@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"time"
+)
+
+// CancelAuctionCommand represents a command to cancel an auction. Sellers
+// may cancel their auctions before the first bid, and support may cancel any
+// open auction, overriding the bids it has.
+type CancelAuctionCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	By        User      `json:"user"`
+	// Override cancels an auction with bids, as support
+	Override bool   `json:"override,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// GetTime returns the time of the command
+func (c CancelAuctionCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for CancelAuctionCommand
+func (c CancelAuctionCommand) MarshalJSON() ([]byte, error) {
+	type cancelAuctionCommandJSON CancelAuctionCommand
+	return MarshalEnvelope("CancelAuction", cancelAuctionCommandJSON(c))
+}
+
+// AuctionCancelledEvent represents an event indicating an auction was
+// cancelled, which refuses any further bid and has no winner
+type AuctionCancelledEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	By        UserId    `json:"by"`
+	Override  bool      `json:"override,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	// Bids is the number of bids the cancellation overrode
+	Bids int `json:"bids"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e AuctionCancelledEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for AuctionCancelledEvent
+func (e AuctionCancelledEvent) MarshalJSON() ([]byte, error) {
+	type auctionCancelledEventJSON AuctionCancelledEvent
+	return MarshalEnvelope("AuctionCancelled", auctionCancelledEventJSON(e))
+}
+
+// CancelledState represents an auction that was cancelled. Its bids are
+// kept for the record, but none wins.
+type CancelledState struct {
+	bids []Bid
+	at   time.Time
+}
+
+// CancelledAt returns when an auction was cancelled, if it was
+func CancelledAt(state State) (time.Time, bool) {
+	cancelled, ok := state.(*CancelledState)
+	if !ok {
+		return time.Time{}, false
+	}
+	return cancelled.at, true
+}
+
+// Increment returns the state as it is, a cancelled auction doesn't change
+func (s *CancelledState) Increment(now time.Time) State {
+	return s
+}
+
+// AddBid refuses the bid
+func (s *CancelledState) AddBid(bid Bid) (State, error) {
+	return s, NewAuctionCancelledError(bid.ForAuction)
+}
+
+// GetBids returns the bids placed before the cancellation
+func (s *CancelledState) GetBids() []Bid {
+	return s.bids
+}
+
+// TryGetAmountAndWinner returns no winner
+func (s *CancelledState) TryGetAmountAndWinner() (int64, UserId, bool) {
+	return 0, "", false
+}
+
+// HasEnded returns true, a cancelled auction is over
+func (s *CancelledState) HasEnded() bool {
+	return true
+}
+
+// handleCancelAuction cancels an open or upcoming auction, by its seller
+// before the first bid or by support
+func handleCancelAuction(c CancelAuctionCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists || !entry.Auction.VisibleTo(&c.By) {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	support := c.By.Type == "Support"
+	if c.By.ID != entry.Auction.Seller.ID && !support || c.Override && !support {
+		return nil, repo, NewCannotCancelAuctionError(c.AuctionId)
+	}
+
+	state := entry.State.Increment(c.Time)
+	if _, cancelled := state.(*CancelledState); cancelled {
+		return nil, repo, NewAuctionCancelledError(c.AuctionId)
+	}
+	if state.HasEnded() {
+		return nil, repo, NewAuctionHasEndedError(c.AuctionId)
+	}
+	bids := len(state.GetBids())
+	if bids > 0 && !c.Override {
+		return nil, repo, NewAuctionHasBidsError(c.AuctionId)
+	}
+
+	event := AuctionCancelledEvent{
+		Time:      c.Time,
+		AuctionId: c.AuctionId,
+		By:        c.By.ID,
+		Override:  c.Override,
+		Reason:    c.Reason,
+		Bids:      bids,
+	}
+	return event, ApplyEvents(repo, []Event{event}), nil
+}
+
+// cancelAuction ends an auction as cancelled, keeping its bids
+func cancelAuction(state State, at time.Time) State {
+	return &CancelledState{bids: state.GetBids(), at: at}
+}
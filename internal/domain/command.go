@@ -15,6 +15,9 @@ type Command interface {
 type AddAuctionCommand struct {
 	Time    time.Time `json:"at"`
 	Auction Auction   `json:"auction"`
+
+	// IdempotencyKey identifies retries of the same client request
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 // GetTime returns the time of the command
@@ -26,6 +29,9 @@ func (c AddAuctionCommand) GetTime() time.Time {
 type PlaceBidCommand struct {
 	Time time.Time `json:"at"`
 	Bid  Bid       `json:"bid"`
+
+	// IdempotencyKey identifies retries of the same client request
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 // GetTime returns the time of the command
@@ -42,6 +48,8 @@ type Event interface {
 type AuctionAddedEvent struct {
 	Time    time.Time `json:"at"`
 	Auction Auction   `json:"auction"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
 }
 
 // GetTime returns the time of the event
@@ -53,6 +61,8 @@ func (e AuctionAddedEvent) GetTime() time.Time {
 type BidAcceptedEvent struct {
 	Time time.Time `json:"at"`
 	Bid  Bid       `json:"bid"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
 }
 
 // GetTime returns the time of the event
@@ -60,16 +70,120 @@ func (e BidAcceptedEvent) GetTime() time.Time {
 	return e.Time
 }
 
+// CommandAuctionId returns the ID of the auction a command targets
+func CommandAuctionId(cmd Command) (AuctionId, bool) {
+	switch c := cmd.(type) {
+	case AddAuctionCommand:
+		return c.Auction.ID, true
+	case PlaceBidCommand:
+		return c.Bid.ForAuction, true
+	case TranslateListingCommand:
+		return c.AuctionId, true
+	case ReviseListingCommand:
+		return c.AuctionId, true
+	case SubmitKeyShareCommand:
+		return c.AuctionId, true
+	case RevealTenderCommand:
+		return c.AuctionId, true
+	case SendContactMessageCommand:
+		return c.AuctionId, true
+	case DetermineWinnerCommand:
+		return c.AuctionId, true
+	case BuyNowCommand:
+		return c.AuctionId, true
+	case SetMaxBidCommand:
+		return c.AuctionId, true
+	case PlaceProxyBidCommand:
+		return c.AuctionId, true
+	case CancelAuctionCommand:
+		return c.AuctionId, true
+	case AmendAuctionCommand:
+		return c.AuctionId, true
+	}
+	return 0, false
+}
+
+// CommandIdempotencyKey returns the idempotency key of a command, empty if it has none
+func CommandIdempotencyKey(cmd Command) string {
+	switch c := cmd.(type) {
+	case AddAuctionCommand:
+		return c.IdempotencyKey
+	case PlaceBidCommand:
+		return c.IdempotencyKey
+	}
+	return ""
+}
+
+// EventAuctionId returns the ID of the auction an event belongs to
+func EventAuctionId(event Event) (AuctionId, bool) {
+	switch e := event.(type) {
+	case AuctionAddedEvent:
+		return e.Auction.ID, true
+	case BidAcceptedEvent:
+		return e.Bid.ForAuction, true
+	case ListingTranslatedEvent:
+		return e.AuctionId, true
+	case ListingRevisedEvent:
+		return e.AuctionId, true
+	case KeyShareSubmittedEvent:
+		return e.AuctionId, true
+	case TenderRevealedEvent:
+		return e.AuctionId, true
+	case ContactMessageSentEvent:
+		return e.AuctionId, true
+	case WinnerDeterminedEvent:
+		return e.AuctionId, true
+	case ReserveMetEvent:
+		return e.AuctionId, true
+	case ReserveNotMetEvent:
+		return e.AuctionId, true
+	case BoughtNowEvent:
+		return e.AuctionId, true
+	case MaxBidSetEvent:
+		return e.AuctionId, true
+	case ProxyBidPlacedEvent:
+		return e.Bid.ForAuction, true
+	case AuctionExtendedEvent:
+		return e.AuctionId, true
+	case UnitsAllocatedEvent:
+		return e.AuctionId, true
+	case AuctionCancelledEvent:
+		return e.AuctionId, true
+	case AuctionAmendedEvent:
+		return e.AuctionId, true
+	case AuctionStartedEvent:
+		return e.AuctionId, true
+	}
+	return 0, false
+}
+
+// commandDecoders and eventDecoders hold decoders for envelope types defined
+// outside the domain, such as the encrypted envelopes of the persistence layer
+var (
+	commandDecoders = map[string]func(data []byte) (Command, error){}
+	eventDecoders   = map[string]func(data []byte) (Event, error){}
+)
+
+// RegisterCommandType registers a decoder for an additional command $type.
+// It is meant to be called from init functions.
+func RegisterCommandType(typeName string, decode func(data []byte) (Command, error)) {
+	commandDecoders[typeName] = decode
+}
+
+// RegisterEventType registers a decoder for an additional event $type.
+// It is meant to be called from init functions.
+func RegisterEventType(typeName string, decode func(data []byte) (Event, error)) {
+	eventDecoders[typeName] = decode
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface for Command
 func UnmarshalCommand(data []byte) (Command, error) {
-	var typeCheck struct {
-		Type string `json:"$type"`
-	}
-	if err := json.Unmarshal(data, &typeCheck); err != nil {
+	typeName, err := EnvelopeType(data)
+	if err != nil {
 		return nil, err
 	}
 
-	switch typeCheck.Type {
+	switch typeName {
 	case "AddAuction":
 		var cmd AddAuctionCommand
 		if err := json.Unmarshal(data, &cmd); err != nil {
@@ -82,8 +196,89 @@ func UnmarshalCommand(data []byte) (Command, error) {
 			return nil, err
 		}
 		return cmd, nil
+	case "FileReport":
+		var cmd FileReportCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "ChangeReportStatus":
+		var cmd ChangeReportStatusCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "TranslateListing":
+		var cmd TranslateListingCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "ReviseListing":
+		var cmd ReviseListingCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "SubmitKeyShare":
+		var cmd SubmitKeyShareCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "RevealTender":
+		var cmd RevealTenderCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "SendContactMessage":
+		var cmd SendContactMessageCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "DetermineWinner":
+		var cmd DetermineWinnerCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "CancelAuction":
+		var cmd CancelAuctionCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "AmendAuction":
+		var cmd AmendAuctionCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "BuyNow":
+		var cmd BuyNowCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "SetMaxBid":
+		var cmd SetMaxBidCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
+	case "PlaceProxyBid":
+		var cmd PlaceProxyBidCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, err
+		}
+		return cmd, nil
 	default:
-		return nil, fmt.Errorf("unknown command type: %s", typeCheck.Type)
+		if decode, ok := commandDecoders[typeName]; ok {
+			return decode(data)
+		}
+		return nil, fmt.Errorf("unknown command type: %s", typeName)
 	}
 }
 
@@ -93,11 +288,13 @@ func (c AddAuctionCommand) MarshalJSON() ([]byte, error) {
 		Type    string   `json:"$type"`
 		Time    time.Time `json:"at"`
 		Auction Auction  `json:"auction"`
+		IdempotencyKey string `json:"idempotencyKey,omitempty"`
 	}
 	return json.Marshal(addAuctionCommandJSON{
 		Type:    "AddAuction",
 		Time:    c.Time,
 		Auction: c.Auction,
+		IdempotencyKey: c.IdempotencyKey,
 	})
 }
 
@@ -107,24 +304,30 @@ func (c PlaceBidCommand) MarshalJSON() ([]byte, error) {
 		Type string    `json:"$type"`
 		Time time.Time `json:"at"`
 		Bid  Bid       `json:"bid"`
+		IdempotencyKey string `json:"idempotencyKey,omitempty"`
 	}
 	return json.Marshal(placeBidCommandJSON{
 		Type: "PlaceBid",
 		Time: c.Time,
 		Bid:  c.Bid,
+		IdempotencyKey: c.IdempotencyKey,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface for Event
 func UnmarshalEvent(data []byte) (Event, error) {
-	var typeCheck struct {
-		Type string `json:"$type"`
+	typeName, err := EnvelopeType(data)
+	if err != nil {
+		return nil, err
 	}
-	if err := json.Unmarshal(data, &typeCheck); err != nil {
+
+	// Bring events stored in an older shape to the current one
+	data, err = upcastEvent(typeName, data)
+	if err != nil {
 		return nil, err
 	}
 
-	switch typeCheck.Type {
+	switch typeName {
 	case "AuctionAdded":
 		var evt AuctionAddedEvent
 		if err := json.Unmarshal(data, &evt); err != nil {
@@ -137,8 +340,143 @@ func UnmarshalEvent(data []byte) (Event, error) {
 			return nil, err
 		}
 		return evt, nil
+	case "ReportFiled":
+		var evt ReportFiledEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ReportStatusChanged":
+		var evt ReportStatusChangedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ListingModerated":
+		var evt ListingModeratedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "RuleSetPublished":
+		var evt RuleSetPublishedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "UserScreened":
+		var evt UserScreenedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ListingTranslated":
+		var evt ListingTranslatedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ListingRevised":
+		var evt ListingRevisedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "KeyShareSubmitted":
+		var evt KeyShareSubmittedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "TenderRevealed":
+		var evt TenderRevealedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ContactMessageSent":
+		var evt ContactMessageSentEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "WinnerDetermined":
+		var evt WinnerDeterminedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ReserveMet":
+		var evt ReserveMetEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ReserveNotMet":
+		var evt ReserveNotMetEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "AuctionStarted":
+		var evt AuctionStartedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "EventArchived":
+		var evt EventArchivedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "AuctionAmended":
+		var evt AuctionAmendedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "AuctionCancelled":
+		var evt AuctionCancelledEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "UnitsAllocated":
+		var evt UnitsAllocatedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "AuctionExtended":
+		var evt AuctionExtendedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "BoughtNow":
+		var evt BoughtNowEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "MaxBidSet":
+		var evt MaxBidSetEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
+	case "ProxyBidPlaced":
+		var evt ProxyBidPlacedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, err
+		}
+		return evt, nil
 	default:
-		return nil, fmt.Errorf("unknown event type: %s", typeCheck.Type)
+		if decode, ok := eventDecoders[typeName]; ok {
+			return decode(data)
+		}
+		return nil, fmt.Errorf("unknown event type: %s", typeName)
 	}
 }
 
@@ -148,25 +486,29 @@ func (e AuctionAddedEvent) MarshalJSON() ([]byte, error) {
 		Type    string   `json:"$type"`
 		Time    time.Time `json:"at"`
 		Auction Auction  `json:"auction"`
+		Metadata *EventMetadata `json:"$meta,omitempty"`
 	}
 	return json.Marshal(auctionAddedEventJSON{
 		Type:    "AuctionAdded",
 		Time:    e.Time,
 		Auction: e.Auction,
+		Metadata: e.Metadata,
 	})
 }
 
 // MarshalJSON implements json.Marshaler interface for BidAcceptedEvent
 func (e BidAcceptedEvent) MarshalJSON() ([]byte, error) {
 	type bidAcceptedEventJSON struct {
-		Type string    `json:"$type"`
-		Time time.Time `json:"at"`
-		Bid  Bid       `json:"bid"`
+		Type     string         `json:"$type"`
+		Time     time.Time      `json:"at"`
+		Bid      Bid            `json:"bid"`
+		Metadata *EventMetadata `json:"$meta,omitempty"`
 	}
 	return json.Marshal(bidAcceptedEventJSON{
-		Type: "BidAccepted",
-		Time: e.Time,
-		Bid:  e.Bid,
+		Type:     "BidAccepted",
+		Time:     e.Time,
+		Bid:      e.Bid,
+		Metadata: e.Metadata,
 	})
 }
 
@@ -178,7 +520,12 @@ type Repository map[AuctionId]struct {
 
 // EventsToAuctionStates folds a list of events into a repository
 func EventsToAuctionStates(events []Event) Repository {
-	repo := make(Repository)
+	return ApplyEvents(make(Repository), events)
+}
+
+// ApplyEvents folds a list of events onto a copy of a repository
+func ApplyEvents(repo Repository, events []Event) Repository {
+	repo = copyRepository(repo)
 	
 	for _, event := range events {
 		switch e := event.(type) {
@@ -204,6 +551,67 @@ func EventsToAuctionStates(events []Event) Repository {
 					State:   nextState,
 				}
 			}
+		case ListingTranslatedEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.Auction = entry.Auction.withTranslation(e.Translation.Language, e.Translation.Title)
+				repo[e.AuctionId] = entry
+			}
+		case ListingRevisedEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.Auction = entry.Auction.withRevision(e.Expiry, e.AddTags)
+				if e.Expiry != nil {
+					entry.State = entry.Auction.CreateEmptyState()
+				}
+				repo[e.AuctionId] = entry
+			}
+		case KeyShareSubmittedEvent:
+			if entry, ok := repo[e.AuctionId]; ok && entry.Auction.Tender != nil {
+				entry.Auction.Tender = entry.Auction.Tender.withShare(e.Custodian, e.Share)
+				repo[e.AuctionId] = entry
+			}
+		case TenderRevealedEvent:
+			if entry, ok := repo[e.AuctionId]; ok && entry.Auction.Tender != nil {
+				entry.State = revealBids(entry.Auction, entry.State, e.Amounts)
+				tender := *entry.Auction.Tender
+				tender.Revealed = true
+				entry.Auction.Tender = &tender
+				repo[e.AuctionId] = entry
+			}
+		case WinnerDeterminedEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.State = determineWinner(entry.State.Increment(e.Time))
+				repo[e.AuctionId] = entry
+			}
+		case UnitsAllocatedEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.State = allocateUnits(entry.State.Increment(e.Time))
+				repo[e.AuctionId] = entry
+			}
+		case AuctionAmendedEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.Auction, entry.State = amendAuction(entry.Auction, entry.State, e)
+				repo[e.AuctionId] = entry
+			}
+		case AuctionCancelledEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.State = cancelAuction(entry.State.Increment(e.Time), e.Time)
+				repo[e.AuctionId] = entry
+			}
+		case BoughtNowEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.State = buyNow(entry.State, e)
+				repo[e.AuctionId] = entry
+			}
+		case MaxBidSetEvent:
+			if entry, ok := repo[e.AuctionId]; ok {
+				entry.State = setMaxBid(entry.State, e)
+				repo[e.AuctionId] = entry
+			}
+		case ProxyBidPlacedEvent:
+			if entry, ok := repo[e.Bid.ForAuction]; ok {
+				entry.State, _ = entry.State.AddBid(e.Bid)
+				repo[e.Bid.ForAuction] = entry
+			}
 		}
 	}
 	
@@ -218,6 +626,9 @@ func Handle(cmd Command, repo Repository) (Event, Repository, error) {
 		if _, exists := repo[auction.ID]; exists {
 			return nil, repo, NewAuctionAlreadyExistsError(auction.ID)
 		}
+		if err := validateTender(auction); err != nil {
+			return nil, repo, err
+		}
 		
 		// Create new state
 		state := auction.CreateEmptyState()
@@ -246,10 +657,23 @@ func Handle(cmd Command, repo Repository) (Event, Repository, error) {
 			return nil, repo, NewAuctionNotFoundError(auctionId)
 		}
 		
+		// Private auctions don't exist for those not invited
+		if !entry.Auction.VisibleTo(&bid.Bidder) {
+			return nil, repo, NewAuctionNotFoundError(auctionId)
+		}
+
 		// Validate bid
 		if err := entry.Auction.ValidateBid(bid); err != nil {
 			return nil, repo, err
 		}
+		if err := validateTenderBid(entry.Auction, bid); err != nil {
+			return nil, repo, err
+		}
+
+		// Scheduled auctions of any type take no bids until they start
+		if !bid.At.After(entry.Auction.StartsAt) {
+			return nil, repo, NewAuctionHasNotStartedError(auctionId)
+		}
 		
 		// Add bid to state
 		nextState, err := entry.State.AddBid(bid)
@@ -271,6 +695,27 @@ func Handle(cmd Command, repo Repository) (Event, Repository, error) {
 			Time: c.Time,
 			Bid:  bid,
 		}, newRepo, nil
+
+	case TranslateListingCommand:
+		return handleTranslateListing(c, repo)
+	case ReviseListingCommand:
+		return handleReviseListing(c, repo)
+	case SubmitKeyShareCommand:
+		return handleSubmitKeyShare(c, repo)
+	case RevealTenderCommand:
+		return handleRevealTender(c, repo)
+	case DetermineWinnerCommand:
+		return handleDetermineWinner(c, repo)
+	case BuyNowCommand:
+		return handleBuyNow(c, repo)
+	case SetMaxBidCommand:
+		return handleSetMaxBid(c, repo)
+	case PlaceProxyBidCommand:
+		return handlePlaceProxyBid(c, repo)
+	case CancelAuctionCommand:
+		return handleCancelAuction(c, repo)
+	case AmendAuctionCommand:
+		return handleAmendAuction(c, repo)
 	}
 	
 	return nil, repo, fmt.Errorf("unknown command type")
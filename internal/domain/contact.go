@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+)
+
+// Roles of the parties of a contact thread, which is all they learn of each
+// other through it
+const (
+	ContactSeller = "seller"
+	ContactWinner = "winner"
+)
+
+// Limits of contact messages
+const (
+	MaxContactMessageLength  = 2000
+	MaxContactAttachments    = 3
+	MaxContactAttachmentSize = 5 << 20
+)
+
+// ContactRetention is how long after the close of an auction its contact
+// thread is kept, after which it's closed and its messages are no longer
+// served, and may be archived with the auction
+const ContactRetention = 90 * 24 * time.Hour
+
+// MaskedContactDetails replaces the email addresses and phone numbers in
+// contact messages, so the parties settle through the relay
+const MaskedContactDetails = "[contact details removed]"
+
+var (
+	contactEmail = regexp.MustCompile(`[[:alnum:]._%+-]+@[[:alnum:].-]+\.[[:alpha:]]{2,}`)
+	contactPhone = regexp.MustCompile(`\+?[0-9][0-9 ()./-]{6,}[0-9]`)
+)
+
+// MaskContactDetails removes the email addresses and phone numbers of a text
+func MaskContactDetails(text string) string {
+	text = contactEmail.ReplaceAllString(text, MaskedContactDetails)
+	return contactPhone.ReplaceAllString(text, MaskedContactDetails)
+}
+
+// ContactAttachment describes a file attached to a contact message, held by
+// the attachment storage under its name
+type ContactAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// ContactMessage is a message of a contact thread, numbered from 1
+type ContactMessage struct {
+	Seq         int                 `json:"seq"`
+	At          time.Time           `json:"at"`
+	Sender      UserId              `json:"sender"`
+	Role        string              `json:"role"`
+	Text        string              `json:"text"`
+	Attachments []ContactAttachment `json:"attachments"`
+}
+
+// ContactThread is the messaging thread between the winner and the seller
+// of a closed auction
+type ContactThread struct {
+	AuctionId AuctionId        `json:"auctionId"`
+	Messages  []ContactMessage `json:"messages"`
+}
+
+// ContactThreads holds the contact threads by auction
+type ContactThreads map[AuctionId]ContactThread
+
+// ContactParties returns the seller and the winner of an auction, and when
+// its contact thread closes, if the auction has closed with a winner
+func ContactParties(auction Auction, state State, now time.Time) (seller, winner UserId, closesAt time.Time, ok bool) {
+	state = state.Increment(now)
+	if !state.HasEnded() || auction.AwaitingReveal() {
+		return "", "", time.Time{}, false
+	}
+	_, winner, ok = state.TryGetAmountAndWinner()
+	if !ok {
+		return "", "", time.Time{}, false
+	}
+	return auction.Seller.ID, winner, CurrentExpiry(state).Add(ContactRetention), true
+}
+
+// ContactRole returns the role of a user in the contact thread of an
+// auction, checking the thread is open
+func ContactRole(repo Repository, auctionId AuctionId, userId UserId, now time.Time) (string, error) {
+	entry, ok := repo[auctionId]
+	if !ok {
+		return "", NewAuctionNotFoundError(auctionId)
+	}
+	seller, winner, closesAt, ok := ContactParties(entry.Auction, entry.State, now)
+	if !ok || now.After(closesAt) {
+		return "", NewContactThreadClosedError(auctionId)
+	}
+	switch userId {
+	case seller:
+		return ContactSeller, nil
+	case winner:
+		return ContactWinner, nil
+	}
+	return "", NewNotAContactPartyError(auctionId)
+}
+
+// SendContactMessageCommand represents a command of the winner or the seller
+// of a closed auction sending a message to the other
+type SendContactMessageCommand struct {
+	Time        time.Time           `json:"at"`
+	AuctionId   AuctionId           `json:"auctionId"`
+	Sender      UserId              `json:"sender"`
+	Text        string              `json:"text"`
+	Attachments []ContactAttachment `json:"attachments"`
+}
+
+// GetTime returns the time of the command
+func (c SendContactMessageCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for SendContactMessageCommand
+func (c SendContactMessageCommand) MarshalJSON() ([]byte, error) {
+	type sendContactMessageCommandJSON struct {
+		Type        string              `json:"$type"`
+		Time        time.Time           `json:"at"`
+		AuctionId   AuctionId           `json:"auctionId"`
+		Sender      UserId              `json:"sender"`
+		Text        string              `json:"text"`
+		Attachments []ContactAttachment `json:"attachments"`
+	}
+	return json.Marshal(sendContactMessageCommandJSON{
+		Type:        "SendContactMessage",
+		Time:        c.Time,
+		AuctionId:   c.AuctionId,
+		Sender:      c.Sender,
+		Text:        c.Text,
+		Attachments: c.Attachments,
+	})
+}
+
+// ContactMessageSentEvent represents an event indicating a message was sent
+// in the contact thread of an auction, with its contact details masked
+type ContactMessageSentEvent struct {
+	Time      time.Time      `json:"at"`
+	AuctionId AuctionId      `json:"auctionId"`
+	Message   ContactMessage `json:"message"`
+}
+
+// GetTime returns the time of the event
+func (e ContactMessageSentEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ContactMessageSentEvent
+func (e ContactMessageSentEvent) MarshalJSON() ([]byte, error) {
+	type contactMessageSentEventJSON struct {
+		Type      string         `json:"$type"`
+		Time      time.Time      `json:"at"`
+		AuctionId AuctionId      `json:"auctionId"`
+		Message   ContactMessage `json:"message"`
+	}
+	return json.Marshal(contactMessageSentEventJSON{
+		Type:      "ContactMessageSent",
+		Time:      e.Time,
+		AuctionId: e.AuctionId,
+		Message:   e.Message,
+	})
+}
+
+// HandleContact processes a contact command against the contact threads and
+// the auctions they are about
+func HandleContact(cmd SendContactMessageCommand, threads ContactThreads, repo Repository) (Event, ContactThreads, error) {
+	if err := ValidateCommand(cmd); err != nil {
+		return nil, threads, err
+	}
+	role, err := ContactRole(repo, cmd.AuctionId, cmd.Sender, cmd.Time)
+	if err != nil {
+		return nil, threads, err
+	}
+
+	attachments := cmd.Attachments
+	if attachments == nil {
+		attachments = []ContactAttachment{}
+	}
+	event := ContactMessageSentEvent{
+		Time:      cmd.Time,
+		AuctionId: cmd.AuctionId,
+		Message: ContactMessage{
+			Seq:         len(threads[cmd.AuctionId].Messages) + 1,
+			At:          cmd.Time,
+			Sender:      cmd.Sender,
+			Role:        role,
+			Text:        MaskContactDetails(cmd.Text),
+			Attachments: attachments,
+		},
+	}
+	return event, ApplyContactEvents(threads, []Event{event}), nil
+}
+
+// EventsToContactThreads folds a list of events into contact threads
+func EventsToContactThreads(events []Event) ContactThreads {
+	return ApplyContactEvents(make(ContactThreads), events)
+}
+
+// ApplyContactEvents folds a list of events onto a copy of the contact
+// threads. Events that are not about contact threads are ignored.
+func ApplyContactEvents(threads ContactThreads, events []Event) ContactThreads {
+	newThreads := make(ContactThreads, len(threads))
+	for k, v := range threads {
+		newThreads[k] = v
+	}
+
+	for _, event := range events {
+		if e, ok := event.(ContactMessageSentEvent); ok {
+			thread := newThreads[e.AuctionId]
+			thread.AuctionId = e.AuctionId
+			thread.Messages = append(thread.Messages[:len(thread.Messages):len(thread.Messages)], e.Message)
+			newThreads[e.AuctionId] = thread
+		}
+	}
+
+	return newThreads
+}
+
+// validateContactMessage checks the text and attachments of a message
+func validateContactMessage(c SendContactMessageCommand) []FieldError {
+	var errors []FieldError
+	if c.Sender == "" {
+		errors = append(errors, FieldError{Field: "sender", Code: FieldRequired})
+	}
+	if len(c.Text) == 0 && len(c.Attachments) == 0 {
+		errors = append(errors, FieldError{Field: "text", Code: FieldRequired})
+	}
+	if len([]rune(c.Text)) > MaxContactMessageLength {
+		errors = append(errors, FieldError{Field: "text", Code: FieldTooLong})
+	}
+	if len(c.Attachments) > MaxContactAttachments {
+		errors = append(errors, FieldError{Field: "attachments", Code: FieldTooMany})
+	}
+	for _, attachment := range c.Attachments {
+		if attachment.Name == "" {
+			errors = append(errors, FieldError{Field: "attachments.name", Code: FieldRequired})
+		}
+		if attachment.Size <= 0 {
+			errors = append(errors, FieldError{Field: "attachments.size", Code: FieldMustBePositive})
+		} else if attachment.Size > MaxContactAttachmentSize {
+			errors = append(errors, FieldError{Field: "attachments.size", Code: FieldTooLarge})
+		}
+	}
+	return errors
+}
@@ -89,6 +89,31 @@ const (
 	ErrorSellerCannotPlaceBids   ErrorType = "SellerCannotPlaceBids"
 	ErrorMustPlaceBidOverHighest ErrorType = "MustPlaceBidOverHighestBid"
 	ErrorAlreadyPlacedBid        ErrorType = "AlreadyPlacedBid"
+	ErrorDuplicateCommand        ErrorType = "DuplicateCommand"
+	ErrorInvalidReport           ErrorType = "InvalidReport"
+	ErrorReportNotFound          ErrorType = "ReportNotFound"
+	ErrorInvalidReportTransition ErrorType = "InvalidReportTransition"
+	ErrorListingRejected         ErrorType = "ListingRejected"
+	ErrorInvalidRuleSet          ErrorType = "InvalidRuleSet"
+	ErrorUserBlocked             ErrorType = "UserBlocked"
+	ErrorInvalidTranslation      ErrorType = "InvalidTranslation"
+	ErrorAuctionHasBids          ErrorType = "AuctionHasBids"
+	ErrorInvalidRevision         ErrorType = "InvalidRevision"
+	ErrorInvalidTender           ErrorType = "InvalidTender"
+	ErrorNotACustodian           ErrorType = "NotACustodian"
+	ErrorDuplicateEvent          ErrorType = "DuplicateEvent"
+	ErrorInvalidCommand          ErrorType = "InvalidCommand"
+	ErrorContactThreadClosed     ErrorType = "ContactThreadClosed"
+	ErrorNotAContactParty        ErrorType = "NotAContactParty"
+	ErrorContactMessageNotFound  ErrorType = "ContactMessageNotFound"
+	ErrorBidBelowCurrentPrice    ErrorType = "BidBelowCurrentPrice"
+	ErrorWinnerNotDeterminable   ErrorType = "WinnerNotDeterminable"
+	ErrorBuyNowNotAvailable      ErrorType = "BuyNowNotAvailable"
+	ErrorProxyBiddingUnsupported ErrorType = "ProxyBiddingUnsupported"
+	ErrorNoProxyBidDue           ErrorType = "NoProxyBidDue"
+	ErrorCannotCancelAuction     ErrorType = "CannotCancelAuction"
+	ErrorAuctionCancelled        ErrorType = "AuctionCancelled"
+	ErrorCannotAmendAuction      ErrorType = "CannotAmendAuction"
 )
 
 // DomainError carries a stable code (Type) and optional structured Data.
@@ -160,3 +185,224 @@ func NewAlreadyPlacedBidError() error {
 		Type: ErrorAlreadyPlacedBid,
 	}
 }
+
+// NewDuplicateCommandError creates a new DuplicateCommand error
+func NewDuplicateCommandError(idempotencyKey string) error {
+	return DomainError{
+		Type: ErrorDuplicateCommand,
+		Data: idempotencyKey,
+	}
+}
+
+// NewInvalidReportError creates a new InvalidReport error
+func NewInvalidReportError(reason string) error {
+	return DomainError{
+		Type: ErrorInvalidReport,
+		Data: reason,
+	}
+}
+
+// NewReportNotFoundError creates a new ReportNotFound error
+func NewReportNotFoundError(id ReportId) error {
+	return DomainError{
+		Type: ErrorReportNotFound,
+		Data: id,
+	}
+}
+
+// NewInvalidReportTransitionError creates a new InvalidReportTransition error
+func NewInvalidReportTransitionError(from, to ReportStatus) error {
+	return DomainError{
+		Type: ErrorInvalidReportTransition,
+		Data: map[string]interface{}{
+			"from": from,
+			"to":   to,
+		},
+	}
+}
+
+// NewListingRejectedError creates a new ListingRejected error
+func NewListingRejectedError(id AuctionId, reasons []string) error {
+	return DomainError{
+		Type: ErrorListingRejected,
+		Data: map[string]interface{}{
+			"auctionId": id,
+			"reasons":   reasons,
+		},
+	}
+}
+
+// NewInvalidRuleSetError creates a new InvalidRuleSet error
+func NewInvalidRuleSetError(reason string) error {
+	return DomainError{
+		Type: ErrorInvalidRuleSet,
+		Data: reason,
+	}
+}
+
+// NewUserBlockedError creates a new UserBlocked error
+func NewUserBlockedError(id UserId) error {
+	return DomainError{
+		Type: ErrorUserBlocked,
+		Data: id,
+	}
+}
+
+// NewInvalidTranslationError creates a new InvalidTranslation error
+func NewInvalidTranslationError(reason string) error {
+	return DomainError{
+		Type: ErrorInvalidTranslation,
+		Data: reason,
+	}
+}
+
+// NewAuctionHasBidsError creates a new AuctionHasBids error
+func NewAuctionHasBidsError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorAuctionHasBids,
+		Data: id,
+	}
+}
+
+// NewInvalidRevisionError creates a new InvalidRevision error
+func NewInvalidRevisionError(reason string) error {
+	return DomainError{
+		Type: ErrorInvalidRevision,
+		Data: reason,
+	}
+}
+
+// NewInvalidTenderError creates a new InvalidTender error
+func NewInvalidTenderError(reason string) error {
+	return DomainError{
+		Type: ErrorInvalidTender,
+		Data: reason,
+	}
+}
+
+// NewNotACustodianError creates a new NotACustodian error
+func NewNotACustodianError(auctionId AuctionId) error {
+	return DomainError{
+		Type: ErrorNotACustodian,
+		Data: auctionId,
+	}
+}
+
+// NewDuplicateEventError creates a new DuplicateEvent error
+func NewDuplicateEventError(eventId string) error {
+	return DomainError{
+		Type: ErrorDuplicateEvent,
+		Data: eventId,
+	}
+}
+
+// NewInvalidCommandError creates a new InvalidCommand error listing the
+// invalid fields of a command
+func NewInvalidCommandError(command string, errors []FieldError) error {
+	return DomainError{
+		Type: ErrorInvalidCommand,
+		Data: map[string]interface{}{"command": command, "errors": errors},
+	}
+}
+
+// NewContactThreadClosedError creates a new ContactThreadClosed error, for
+// an auction that hasn't closed with a winner or is past its retention
+func NewContactThreadClosedError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorContactThreadClosed,
+		Data: id,
+	}
+}
+
+// NewNotAContactPartyError creates a new NotAContactParty error
+func NewNotAContactPartyError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorNotAContactParty,
+		Data: id,
+	}
+}
+
+// NewContactMessageNotFoundError creates a new ContactMessageNotFound error
+func NewContactMessageNotFoundError(id AuctionId, seq int) error {
+	return DomainError{
+		Type: ErrorContactMessageNotFound,
+		Data: map[string]interface{}{
+			"auctionId": id,
+			"seq":       seq,
+		},
+	}
+}
+
+// NewBidBelowCurrentPriceError creates a new BidBelowCurrentPrice error, for
+// a bid under the price on the clock of a Dutch auction
+func NewBidBelowCurrentPriceError(price int64) error {
+	return DomainError{
+		Type: ErrorBidBelowCurrentPrice,
+		Data: price,
+	}
+}
+
+// NewWinnerNotDeterminableError creates a new WinnerNotDeterminable error,
+// for an auction that isn't a closed and revealed sealed bid auction, or
+// whose winner was already determined
+func NewWinnerNotDeterminableError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorWinnerNotDeterminable,
+		Data: id,
+	}
+}
+
+// NewBuyNowNotAvailableError creates a new error for buying an auction
+// without a buy now offer, or whose bids have exceeded its threshold
+func NewBuyNowNotAvailableError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorBuyNowNotAvailable,
+		Data: id,
+	}
+}
+
+// NewProxyBiddingUnsupportedError creates a new error for setting a maximum
+// bid on an auction that isn't a timed ascending auction
+func NewProxyBiddingUnsupportedError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorProxyBiddingUnsupported,
+		Data: id,
+	}
+}
+
+// NewNoProxyBidDueError creates a new error for placing a proxy bid on an
+// auction where the proxy bidding engine has none due
+func NewNoProxyBidDueError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorNoProxyBidDue,
+		Data: id,
+	}
+}
+
+// NewCannotCancelAuctionError creates a new error for cancelling an auction
+// as neither its seller nor support, or overriding its bids without being
+// support
+func NewCannotCancelAuctionError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorCannotCancelAuction,
+		Data: id,
+	}
+}
+
+// NewAuctionCancelledError creates a new error for bidding on or cancelling
+// an auction that was cancelled
+func NewAuctionCancelledError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorAuctionCancelled,
+		Data: id,
+	}
+}
+
+// NewCannotAmendAuctionError creates a new error for amending an auction
+// as someone other than its seller
+func NewCannotAmendAuctionError(id AuctionId) error {
+	return DomainError{
+		Type: ErrorCannotAmendAuction,
+		Data: id,
+	}
+}
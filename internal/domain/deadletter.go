@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter is a command that failed, kept with its error so it can be
+// inspected and retried
+type DeadLetter struct {
+	ID      int64     `json:"id"`
+	At      time.Time `json:"at"`
+	Command Command   `json:"-"`
+	Error   string    `json:"error"`
+	// Retries counts the retries of the command, Resolved tells whether one
+	// succeeded
+	Retries   int        `json:"retries,omitempty"`
+	RetriedAt *time.Time `json:"retriedAt,omitempty"`
+	Resolved  bool       `json:"resolved,omitempty"`
+}
+
+// deadLetterJSON is the serialized form of a dead letter
+type deadLetterJSON struct {
+	deadLetterFields
+	Command json.RawMessage `json:"command"`
+}
+
+type deadLetterFields DeadLetter
+
+// MarshalJSON implements json.Marshaler interface for DeadLetter
+func (d DeadLetter) MarshalJSON() ([]byte, error) {
+	command, err := MarshalCommand(d.Command)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(deadLetterJSON{deadLetterFields: deadLetterFields(d), Command: command})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface for DeadLetter
+func (d *DeadLetter) UnmarshalJSON(data []byte) error {
+	var raw deadLetterJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	command, err := UnmarshalCommand(raw.Command)
+	if err != nil {
+		return err
+	}
+	*d = DeadLetter(raw.deadLetterFields)
+	d.Command = command
+	return nil
+}
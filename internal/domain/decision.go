@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Decision records how a command was decided: what it asked for, the state
+// of its auction the rules were evaluated against, and the rejection or the
+// resulting event. It answers why a bid was rejected without reproducing the
+// state locally.
+type Decision struct {
+	At            time.Time              `json:"at"`
+	Command       string                 `json:"command"`
+	AuctionId     *AuctionId             `json:"auctionId,omitempty"`
+	CorrelationId string                 `json:"correlationId,omitempty"`
+	Inputs        map[string]interface{} `json:"inputs,omitempty"`
+	State         *DecisionState         `json:"state,omitempty"`
+	Outcome       string                 `json:"outcome"`
+	// Rejection is the error type and data of a rejected command
+	Rejection *DecisionRejection `json:"rejection,omitempty"`
+	// Event is the type of the event of an accepted command
+	Event string `json:"event,omitempty"`
+}
+
+// DecisionState summarizes the state of an auction at the time of a command
+type DecisionState struct {
+	Seller     UserId    `json:"seller"`
+	StartsAt   time.Time `json:"startsAt"`
+	Expiry     time.Time `json:"expiry"`
+	Ended      bool      `json:"ended"`
+	Bids       int       `json:"bids"`
+	HighestBid *int64    `json:"highestBid,omitempty"`
+}
+
+// DecisionRejection is the reason a command was rejected
+type DecisionRejection struct {
+	Type ErrorType   `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Outcomes of decisions
+const (
+	DecisionAccepted = "accepted"
+	DecisionRejected = "rejected"
+)
+
+// LogDecisions logs the decision on a sample of the commands as a JSON
+// line, the sample being the fraction of commands to log and random a source
+// of numbers in [0, 1)
+func LogDecisions(logf func(format string, args ...interface{}), sample float64, random func() float64) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			if random() >= sample {
+				return next(ctx, cmd, repo)
+			}
+			decision := newDecision(ctx, cmd, repo)
+			event, newRepo, err := next(ctx, cmd, repo)
+			decision.decided(event, err)
+
+			data, marshalErr := json.Marshal(decision)
+			if marshalErr != nil {
+				logf("decision of command %s not logged: %v", commandName(cmd), marshalErr)
+			} else {
+				logf("decision %s", data)
+			}
+			return event, newRepo, err
+		}
+	}
+}
+
+// newDecision records a command and the state of its auction before it's
+// handled
+func newDecision(ctx context.Context, cmd Command, repo Repository) *Decision {
+	decision := &Decision{At: cmd.GetTime(), Command: commandName(cmd), Inputs: decisionInputs(cmd)}
+	if metadata, ok := MetadataFromContext(ctx); ok {
+		decision.CorrelationId = metadata.CorrelationId
+	}
+
+	auctionId, ok := CommandAuctionId(cmd)
+	if !ok {
+		return decision
+	}
+	decision.AuctionId = &auctionId
+	entry, ok := repo[auctionId]
+	if !ok {
+		return decision
+	}
+
+	state := entry.State.Increment(cmd.GetTime())
+	bids := state.GetBids()
+	decision.State = &DecisionState{
+		Seller:   entry.Auction.Seller.ID,
+		StartsAt: entry.Auction.StartsAt,
+		Expiry:   entry.Auction.Expiry,
+		Ended:    state.HasEnded(),
+		Bids:     len(bids),
+	}
+	for _, bid := range bids {
+		if decision.State.HighestBid == nil || bid.Amount > *decision.State.HighestBid {
+			amount := bid.Amount
+			decision.State.HighestBid = &amount
+		}
+	}
+	return decision
+}
+
+// decided records the outcome of a command
+func (d *Decision) decided(event Event, err error) {
+	if err == nil {
+		d.Outcome = DecisionAccepted
+		if data, marshalErr := json.Marshal(event); marshalErr == nil {
+			d.Event, _ = EnvelopeType(data)
+		}
+		return
+	}
+	d.Outcome = DecisionRejected
+	if domainErr, ok := err.(DomainError); ok {
+		d.Rejection = &DecisionRejection{Type: domainErr.Type, Data: domainErr.Data}
+	} else {
+		d.Rejection = &DecisionRejection{Type: ErrorType(err.Error())}
+	}
+}
+
+// decisionInputs summarizes what a command asks for, leaving out listing
+// content
+func decisionInputs(cmd Command) map[string]interface{} {
+	switch c := cmd.(type) {
+	case AddAuctionCommand:
+		return map[string]interface{}{
+			"seller":   c.Auction.Seller.ID,
+			"type":     c.Auction.Type,
+			"startsAt": c.Auction.StartsAt,
+			"expiry":   c.Auction.Expiry,
+		}
+	case PlaceBidCommand:
+		inputs := map[string]interface{}{
+			"bidder": c.Bid.Bidder.ID,
+			"at":     c.Bid.At,
+			"amount": c.Bid.Amount,
+		}
+		if c.Bid.Sealed != "" {
+			inputs["sealed"] = true
+		}
+		return inputs
+	case ReviseListingCommand:
+		return map[string]interface{}{"expiry": c.Expiry, "addTags": c.AddTags}
+	case TranslateListingCommand:
+		return map[string]interface{}{"language": c.Translation.Language}
+	case SubmitKeyShareCommand:
+		return map[string]interface{}{"custodian": c.Custodian}
+	case BuyNowCommand:
+		return map[string]interface{}{"buyer": c.Buyer.ID}
+	case SetMaxBidCommand:
+		return map[string]interface{}{"bidder": c.Bidder.ID, "max": c.Max}
+	case AmendAuctionCommand:
+		return map[string]interface{}{"title": c.Title != nil, "description": c.Description != nil, "expiry": c.Expiry}
+	case CancelAuctionCommand:
+		return map[string]interface{}{"by": c.By.ID, "override": c.Override}
+	}
+	return nil
+}
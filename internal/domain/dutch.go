@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DutchOptions defines the options for a Dutch (descending price) auction
+type DutchOptions struct {
+	// The price the clock starts at
+	StartPrice int64 `json:"startPrice"`
+
+	// The amount the price drops by at the end of each interval
+	Decrement int64 `json:"decrement"`
+
+	// How long the price stays at each step
+	Interval time.Duration `json:"interval"`
+
+	// The price the clock stops dropping at. If nobody bids before the
+	// expiry, the item remains unsold
+	Floor int64 `json:"floor"`
+}
+
+// String returns a string representation of the options
+func (o DutchOptions) String() string {
+	seconds := int(o.Interval.Seconds())
+	return fmt.Sprintf("Dutch|%d|%d|%d|%d", o.StartPrice, o.Decrement, seconds, o.Floor)
+}
+
+// ParseDutchOptions parses a string into DutchOptions
+func ParseDutchOptions(s string) (*DutchOptions, error) {
+	parts := strings.Split(s, "|")
+	if len(parts) != 5 || parts[0] != "Dutch" {
+		return nil, fmt.Errorf("invalid dutch options format: %s", s)
+	}
+
+	var amounts [4]int64
+	for i, part := range parts[1:] {
+		amount, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dutch option format: %s", part)
+		}
+		amounts[i] = amount
+	}
+
+	return &DutchOptions{
+		StartPrice: amounts[0],
+		Decrement:  amounts[1],
+		Interval:   time.Duration(amounts[2]) * time.Second,
+		Floor:      amounts[3],
+	}, nil
+}
+
+// PriceAt returns the price on the clock of an auction started at start
+func (o DutchOptions) PriceAt(start, now time.Time) int64 {
+	if !now.After(start) || o.Interval <= 0 || o.Decrement <= 0 {
+		return o.StartPrice
+	}
+	steps := int64(now.Sub(start) / o.Interval)
+	// Compared before multiplying, so long auctions can't overflow
+	if steps >= (o.StartPrice-o.Floor)/o.Decrement+1 {
+		return o.Floor
+	}
+	if price := o.StartPrice - steps*o.Decrement; price > o.Floor {
+		return price
+	}
+	return o.Floor
+}
+
+// DutchState represents the state of a Dutch auction. The price drops from
+// the start price by a decrement every interval down to the floor, and the
+// first bid of at least the current price buys the item at that price.
+type DutchState struct {
+	start   time.Time
+	expiry  time.Time
+	options DutchOptions
+	// bid is the winning bid, at the price it bought at, once accepted
+	bid *Bid
+	// ended tells the auction expired without a bid
+	ended bool
+}
+
+// NewDutchState creates a new Dutch auction state
+func NewDutchState(start, expiry time.Time, options DutchOptions) *DutchState {
+	return &DutchState{
+		start:   start,
+		expiry:  expiry,
+		options: options,
+	}
+}
+
+// CurrentPrice returns the price on the clock, which stops at the winning
+// bid once there is one
+func (s *DutchState) CurrentPrice(now time.Time) int64 {
+	if s.bid != nil {
+		return s.bid.Amount
+	}
+	return s.options.PriceAt(s.start, now)
+}
+
+// Increment advances the state based on the current time, ending an
+// auction nobody bid on at its expiry
+func (s *DutchState) Increment(now time.Time) State {
+	if s.bid != nil || s.ended || now.Before(s.expiry) {
+		return s
+	}
+	return &DutchState{
+		start:   s.start,
+		expiry:  s.expiry,
+		options: s.options,
+		ended:   true,
+	}
+}
+
+// AddBid attempts to add a bid to the state
+func (s *DutchState) AddBid(bid Bid) (State, error) {
+	if s.HasEnded() || !bid.At.Before(s.expiry) {
+		return s, NewAuctionHasEndedError(bid.ForAuction)
+	}
+	if !bid.At.After(s.start) {
+		return s, NewAuctionHasNotStartedError(bid.ForAuction)
+	}
+
+	price := s.options.PriceAt(s.start, bid.At)
+	if bid.Amount < price {
+		return s, NewBidBelowCurrentPriceError(price)
+	}
+
+	won := bid
+	won.Amount = price
+	return &DutchState{
+		start:   s.start,
+		expiry:  s.expiry,
+		options: s.options,
+		bid:     &won,
+	}, nil
+}
+
+// GetBids returns the winning bid, if any
+func (s *DutchState) GetBids() []Bid {
+	if s.bid == nil {
+		return []Bid{}
+	}
+	return []Bid{*s.bid}
+}
+
+// TryGetAmountAndWinner returns the price and bidder of the winning bid
+func (s *DutchState) TryGetAmountAndWinner() (int64, UserId, bool) {
+	if s.bid == nil {
+		return 0, "", false
+	}
+	return s.bid.Amount, s.bid.Bidder.ID, true
+}
+
+// HasEnded returns true once a bid is accepted or the auction has expired
+func (s *DutchState) HasEnded() bool {
+	return s.bid != nil || s.ended
+}
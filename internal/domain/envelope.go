@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Commands and events are stored as JSON objects carrying their type in a
+// "$type" field, the envelope. These helpers are shared by all stores so that
+// none of them needs to handle the envelope by hand.
+
+// EnvelopeType returns the $type of an enveloped JSON object
+func EnvelopeType(data []byte) (string, error) {
+	var envelope struct {
+		Type string `json:"$type"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return "", err
+	}
+	if envelope.Type == "" {
+		return "", fmt.Errorf("missing $type")
+	}
+	return envelope.Type, nil
+}
+
+// MarshalEnvelope marshals a value to a JSON object and adds the $type field
+// to it. The value must marshal to an object, which may be empty.
+func MarshalEnvelope(typeName string, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%s envelope: %v", typeName, err)
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+	fields["$type"], _ = json.Marshal(typeName)
+	return json.Marshal(fields)
+}
+
+// MarshalCommand marshals a command to its envelope
+func MarshalCommand(cmd Command) ([]byte, error) {
+	return json.Marshal(cmd)
+}
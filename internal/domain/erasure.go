@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"reflect"
+)
+
+// ForgottenName replaces the name of a user whose personal data was erased
+const ForgottenName = "[forgotten]"
+
+var userType = reflect.TypeOf(User{})
+
+// MapUsers returns a copy of a value, such as an event, a command or
+// auction snapshots, with every User it contains replaced by the result of
+// f. The value itself is left unchanged.
+func MapUsers(v interface{}, f func(User) (User, error)) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	mapped, err := mapUsers(reflect.ValueOf(v), f)
+	if err != nil {
+		return nil, err
+	}
+	return mapped.Interface(), nil
+}
+
+// mapUsers copies a value down to its users, sharing what contains none
+func mapUsers(v reflect.Value, f func(User) (User, error)) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == userType {
+			user, err := f(v.Interface().(User))
+			return reflect.ValueOf(user), err
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !out.Field(i).CanSet() {
+				continue
+			}
+			field, err := mapUsers(v.Field(i), f)
+			if err != nil {
+				return v, err
+			}
+			out.Field(i).Set(field)
+		}
+		return out, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := mapUsers(v.Elem(), f)
+		if err != nil {
+			return v, err
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(elem)
+		return out, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := mapUsers(v.Elem(), f)
+		if err != nil {
+			return v, err
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(elem)
+		return out, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, nil
+		}
+		var out reflect.Value
+		if v.Kind() == reflect.Slice {
+			out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		} else {
+			out = reflect.New(v.Type()).Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			elem, err := mapUsers(v.Index(i), f)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := mapUsers(iter.Value(), f)
+			if err != nil {
+				return v, err
+			}
+			out.SetMapIndex(iter.Key(), elem)
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// ForgetUser returns the auctions of a repository the user took part in with
+// the user's name replaced by ForgottenName
+func ForgetUser(repo Repository, userId UserId) (Repository, error) {
+	forget := func(user User) (User, error) {
+		if user.ID == userId && user.Name != "" {
+			user.Name = ForgottenName
+		}
+		return user, nil
+	}
+
+	forgotten := make(Repository)
+	for id, entry := range repo {
+		if !tookPart(entry.Auction, entry.State, userId) {
+			continue
+		}
+		snapshot, err := SnapshotState(entry.State)
+		if err != nil {
+			return nil, err
+		}
+		mapped, err := MapUsers(AuctionSnapshot{Auction: entry.Auction, State: snapshot}, forget)
+		if err != nil {
+			return nil, err
+		}
+		restored, err := RestoreRepository([]AuctionSnapshot{mapped.(AuctionSnapshot)})
+		if err != nil {
+			return nil, err
+		}
+		forgotten[id] = restored[id]
+	}
+	return forgotten, nil
+}
+
+// tookPart tells whether a user is the seller or a bidder of an auction
+func tookPart(auction Auction, state State, userId UserId) bool {
+	if auction.Seller.ID == userId {
+		return true
+	}
+	for _, bid := range state.GetBids() {
+		if bid.Bidder.ID == userId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// eventIdNamespace is the UUID namespace of event IDs
+var eventIdNamespace = []byte{
+	0x6b, 0x2f, 0x8e, 0x61, 0x3c, 0x0d, 0x4f, 0x52,
+	0x9a, 0x17, 0x5e, 0x84, 0xd1, 0x26, 0xc3, 0x70,
+}
+
+// EventAggregate returns the aggregate an event belongs to, such as
+// "auction/1", which numbers its events
+func EventAggregate(event Event) string {
+	if id, ok := EventAuctionId(event); ok {
+		return "auction/" + strconv.FormatInt(int64(id), 10)
+	}
+	switch e := event.(type) {
+	case ListingModeratedEvent:
+		return "auction/" + strconv.FormatInt(int64(e.AuctionId), 10)
+	case EventArchivedEvent:
+		return "auction/" + strconv.FormatInt(int64(e.AuctionId), 10)
+	case ReportFiledEvent:
+		return "report/" + strconv.FormatInt(int64(e.Report.ID), 10)
+	case ReportStatusChangedEvent:
+		return "report/" + strconv.FormatInt(int64(e.ReportId), 10)
+	case RuleSetPublishedEvent:
+		return "ruleset/" + strconv.Itoa(e.RuleSet.Version)
+	case UserScreenedEvent:
+		return "user/" + string(e.UserId)
+	}
+	return fmt.Sprintf("%T", event)
+}
+
+// NewEventId returns the ID of the event at a 1-based version of an
+// aggregate, a name-based UUID (version 5), so the same event always gets the
+// same ID and consumers receiving it twice can tell
+func NewEventId(aggregate string, version int64) string {
+	h := sha1.New()
+	h.Write(eventIdNamespace)
+	h.Write([]byte(aggregate + "@" + strconv.FormatInt(version, 10)))
+	sum := h.Sum(nil)[:16]
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+
+	id := hex.EncodeToString(sum)
+	return id[0:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:32]
+}
+
+// EventIds numbers the events of each aggregate in log order, returning
+// the ID of each event
+func EventIds(events []Event) []string {
+	versions := make(map[string]int64)
+	ids := make([]string, len(events))
+	for i, event := range events {
+		aggregate := EventAggregate(event)
+		versions[aggregate]++
+		ids[i] = NewEventId(aggregate, versions[aggregate])
+	}
+	return ids
+}
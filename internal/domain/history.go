@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// PricePoint is a bid on the price trajectory of an auction
+type PricePoint struct {
+	At    time.Time `json:"at"`
+	Price int64     `json:"price"`
+	// ExtendedTo is the new end time when the bid extended the auction
+	ExtendedTo *time.Time `json:"extendedTo,omitempty"`
+}
+
+// PriceHistory is the price trajectory of a closed auction, for charting
+type PriceHistory struct {
+	StartsAt time.Time    `json:"startsAt"`
+	EndedAt  time.Time    `json:"endedAt"`
+	Points   []PricePoint `json:"points"`
+	// FinalPrice is what the winner pays, nil without a winner
+	FinalPrice *int64 `json:"finalPrice"`
+}
+
+// ComputePriceHistory replays the bids of an ended auction in time order.
+// For timed ascending auctions each bid raises the price and marks where it
+// extended the end time; sealed bids are plotted at their amounts, since
+// they only set the price at the close.
+func ComputePriceHistory(auction Auction, state State) PriceHistory {
+	bids := append([]Bid(nil), state.GetBids()...)
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].At.Before(bids[j].At)
+	})
+
+	history := PriceHistory{
+		StartsAt: auction.StartsAt,
+		EndedAt:  CurrentExpiry(state),
+		Points:   make([]PricePoint, 0, len(bids)),
+	}
+
+	var timeFrame time.Duration
+	if auction.Type.Type == TimedAscending {
+		if options, err := ParseTimedAscendingOptions(auction.Type.Options); err == nil {
+			timeFrame = options.TimeFrame
+		}
+	}
+	expiry := auction.Expiry
+	for _, bid := range bids {
+		point := PricePoint{At: bid.At, Price: bid.Amount}
+		if timeFrame > 0 && bid.At.Add(timeFrame).After(expiry) {
+			expiry = bid.At.Add(timeFrame)
+			extendedTo := expiry
+			point.ExtendedTo = &extendedTo
+		}
+		history.Points = append(history.Points, point)
+	}
+
+	if amount, _, ok := state.TryGetAmountAndWinner(); ok {
+		history.FinalPrice = &amount
+	}
+	return history
+}
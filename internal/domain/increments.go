@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxIncrementBands limits the size of a bid increment table
+const MaxIncrementBands = 20
+
+// IncrementBand is the least a bid must raise the highest bid by, from an
+// amount of the highest bid up to the next band
+type IncrementBand struct {
+	From      int64 `json:"from"`
+	Increment int64 `json:"increment"`
+}
+
+// IncrementTable holds the bands of bid increments of a timed ascending
+// auction, ordered by amount from zero
+type IncrementTable []IncrementBand
+
+// DefaultIncrementTable is the table of the auctions listed without one:
+// +1 under 100, +5 under 1000 and +10 from there
+var DefaultIncrementTable = IncrementTable{
+	{From: 0, Increment: 1},
+	{From: 100, Increment: 5},
+	{From: 1000, Increment: 10},
+}
+
+// Increment returns the increment of the band of an amount, zero without
+// bands
+func (t IncrementTable) Increment(amount int64) int64 {
+	var increment int64
+	for _, band := range t {
+		if amount < band.From {
+			break
+		}
+		increment = band.Increment
+	}
+	return increment
+}
+
+// String returns the bands as from:increment pairs separated by commas,
+// such as "0:1,100:5"
+func (t IncrementTable) String() string {
+	bands := make([]string, len(t))
+	for i, band := range t {
+		bands[i] = fmt.Sprintf("%d:%d", band.From, band.Increment)
+	}
+	return strings.Join(bands, ",")
+}
+
+// ParseIncrementTable parses the bands of a table from its string
+func ParseIncrementTable(s string) (IncrementTable, error) {
+	var table IncrementTable
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid increment band format: %s", pair)
+		}
+		from, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid increment band format: %s", pair)
+		}
+		increment, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid increment band format: %s", pair)
+		}
+		table = append(table, IncrementBand{From: from, Increment: increment})
+	}
+	return table, nil
+}
+
+// validateIncrementTable checks a table starts at zero, with bands of
+// positive increments in increasing order
+func validateIncrementTable(t IncrementTable) []FieldError {
+	if len(t) > MaxIncrementBands {
+		return []FieldError{{Field: "auction.type", Code: FieldTooMany}}
+	}
+	for i, band := range t {
+		if band.Increment <= 0 {
+			return []FieldError{{Field: "auction.type", Code: FieldMustBePositive}}
+		}
+		if i == 0 && band.From != 0 || i > 0 && band.From <= t[i-1].From {
+			return []FieldError{{Field: "auction.type", Code: FieldMalformed}}
+		}
+	}
+	return nil
+}
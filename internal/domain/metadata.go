@@ -0,0 +1,130 @@
+package domain
+
+import "context"
+
+// EventMetadata describes where an event comes from, for auditing and for
+// tracing disputes back to the requests that caused them. It's stored with
+// the event in a "$meta" field.
+type EventMetadata struct {
+	// CorrelationId identifies the request, shared by all its events
+	CorrelationId string `json:"correlationId,omitempty"`
+	// CausationId identifies the dispatch of the command producing the event
+	CausationId string `json:"causationId,omitempty"`
+	UserId      UserId `json:"userId,omitempty"`
+	SourceIP    string `json:"sourceIp,omitempty"`
+}
+
+// metadataKey is the context key of the metadata of a request
+type metadataKey struct{}
+
+// ContextWithMetadata returns a context carrying the metadata of a request
+func ContextWithMetadata(ctx context.Context, metadata EventMetadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns the metadata a context carries
+func MetadataFromContext(ctx context.Context) (EventMetadata, bool) {
+	metadata, ok := ctx.Value(metadataKey{}).(EventMetadata)
+	return metadata, ok
+}
+
+// GetEventMetadata returns the metadata of an event, nil when it has none or
+// its type doesn't carry metadata
+func GetEventMetadata(event Event) *EventMetadata {
+	switch e := event.(type) {
+	case AuctionAddedEvent:
+		return e.Metadata
+	case BidAcceptedEvent:
+		return e.Metadata
+	case ListingTranslatedEvent:
+		return e.Metadata
+	case ListingRevisedEvent:
+		return e.Metadata
+	case KeyShareSubmittedEvent:
+		return e.Metadata
+	case TenderRevealedEvent:
+		return e.Metadata
+	case WinnerDeterminedEvent:
+		return e.Metadata
+	case BoughtNowEvent:
+		return e.Metadata
+	case MaxBidSetEvent:
+		return e.Metadata
+	case ProxyBidPlacedEvent:
+		return e.Metadata
+	case UnitsAllocatedEvent:
+		return e.Metadata
+	case AuctionCancelledEvent:
+		return e.Metadata
+	case AuctionAmendedEvent:
+		return e.Metadata
+	}
+	return nil
+}
+
+// WithEventMetadata returns a copy of an event with its metadata replaced.
+// Only the events produced by Handle carry metadata, others are returned as
+// they are.
+func WithEventMetadata(event Event, metadata *EventMetadata) Event {
+	switch e := event.(type) {
+	case AuctionAddedEvent:
+		e.Metadata = metadata
+		return e
+	case BidAcceptedEvent:
+		e.Metadata = metadata
+		return e
+	case ListingTranslatedEvent:
+		e.Metadata = metadata
+		return e
+	case ListingRevisedEvent:
+		e.Metadata = metadata
+		return e
+	case KeyShareSubmittedEvent:
+		e.Metadata = metadata
+		return e
+	case TenderRevealedEvent:
+		e.Metadata = metadata
+		return e
+	case WinnerDeterminedEvent:
+		e.Metadata = metadata
+		return e
+	case BoughtNowEvent:
+		e.Metadata = metadata
+		return e
+	case MaxBidSetEvent:
+		e.Metadata = metadata
+		return e
+	case ProxyBidPlacedEvent:
+		e.Metadata = metadata
+		return e
+	case UnitsAllocatedEvent:
+		e.Metadata = metadata
+		return e
+	case AuctionCancelledEvent:
+		e.Metadata = metadata
+		return e
+	case AuctionAmendedEvent:
+		e.Metadata = metadata
+		return e
+	}
+	return event
+}
+
+// StampEvents sets the metadata of the context on the events of handled
+// commands, with a causation ID from newId for each dispatch
+func StampEvents(newId func() string) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			event, newRepo, err := next(ctx, cmd, repo)
+			if err != nil {
+				return event, newRepo, err
+			}
+			metadata, ok := MetadataFromContext(ctx)
+			if !ok {
+				return event, newRepo, nil
+			}
+			metadata.CausationId = newId()
+			return WithEventMetadata(event, &metadata), newRepo, nil
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ModerationVerdict is the outcome of moderating a listing
+type ModerationVerdict string
+
+const (
+	ModerationApproved ModerationVerdict = "Approved"
+	ModerationRejected ModerationVerdict = "Rejected"
+	ModerationReview   ModerationVerdict = "Review"
+)
+
+// ModerationDecision is the verdict of a moderation provider on a listing
+type ModerationDecision struct {
+	Verdict  ModerationVerdict `json:"verdict"`
+	Reasons  []string          `json:"reasons"`
+	Provider string            `json:"provider"`
+}
+
+// ModerationProvider checks listings before they are published
+type ModerationProvider interface {
+	ModerateListing(auction Auction) (ModerationDecision, error)
+}
+
+// KeywordModerator is a ModerationProvider matching the title of listings
+// against lists of keywords, case insensitively
+type KeywordModerator struct {
+	Rejected []string
+	Review   []string
+}
+
+// NewKeywordModerator creates a moderator rejecting listings containing one of
+// the rejected keywords and queueing those containing a review keyword
+func NewKeywordModerator(rejected, review []string) *KeywordModerator {
+	return &KeywordModerator{Rejected: rejected, Review: review}
+}
+
+// ModerateListing matches the title of a listing against the keywords
+func (m *KeywordModerator) ModerateListing(auction Auction) (ModerationDecision, error) {
+	title := strings.ToLower(auction.Title)
+	match := func(keywords []string) []string {
+		reasons := []string{}
+		for _, keyword := range keywords {
+			if keyword != "" && strings.Contains(title, strings.ToLower(keyword)) {
+				reasons = append(reasons, "keyword: "+keyword)
+			}
+		}
+		return reasons
+	}
+
+	if reasons := match(m.Rejected); len(reasons) > 0 {
+		return ModerationDecision{Verdict: ModerationRejected, Reasons: reasons, Provider: "keywords"}, nil
+	}
+	if reasons := match(m.Review); len(reasons) > 0 {
+		return ModerationDecision{Verdict: ModerationReview, Reasons: reasons, Provider: "keywords"}, nil
+	}
+	return ModerationDecision{Verdict: ModerationApproved, Reasons: []string{}, Provider: "keywords"}, nil
+}
+
+// ListingModeratedEvent represents an event recording the moderation decision
+// on a listing. It is recorded for rejected listings too, which never get an
+// auction stream, so it isn't part of one.
+type ListingModeratedEvent struct {
+	Time      time.Time          `json:"at"`
+	AuctionId AuctionId          `json:"auctionId"`
+	Decision  ModerationDecision `json:"decision"`
+}
+
+// GetTime returns the time of the event
+func (e ListingModeratedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ListingModeratedEvent
+func (e ListingModeratedEvent) MarshalJSON() ([]byte, error) {
+	type listingModeratedEventJSON struct {
+		Type      string             `json:"$type"`
+		Time      time.Time          `json:"at"`
+		AuctionId AuctionId          `json:"auctionId"`
+		Decision  ModerationDecision `json:"decision"`
+	}
+	return json.Marshal(listingModeratedEventJSON{
+		Type:      "ListingModerated",
+		Time:      e.Time,
+		AuctionId: e.AuctionId,
+		Decision:  e.Decision,
+	})
+}
@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MultiUnitSettlement is how the winners of a multi-unit auction pay
+type MultiUnitSettlement string
+
+const (
+	// UniformPrice has every winner pay the lowest winning bid
+	UniformPrice MultiUnitSettlement = "uniform"
+
+	// PayAsBid has every winner pay their own bid
+	PayAsBid MultiUnitSettlement = "payAsBid"
+)
+
+// MultiUnitOptions defines the options for an auction selling identical
+// units, one to each of the highest bidders
+type MultiUnitOptions struct {
+	// The number of units for sale
+	Units int `json:"units"`
+
+	// How the winners pay
+	Settlement MultiUnitSettlement `json:"settlement"`
+}
+
+// String returns a string representation of the options
+func (o MultiUnitOptions) String() string {
+	return fmt.Sprintf("MultiUnit|%d|%s", o.Units, o.Settlement)
+}
+
+// ParseMultiUnitOptions parses a string into MultiUnitOptions
+func ParseMultiUnitOptions(s string) (*MultiUnitOptions, error) {
+	parts := strings.Split(s, "|")
+	if len(parts) != 3 || parts[0] != "MultiUnit" {
+		return nil, fmt.Errorf("invalid multi-unit options format: %s", s)
+	}
+
+	units, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid units format: %s", parts[1])
+	}
+	settlement := MultiUnitSettlement(parts[2])
+	if settlement != UniformPrice && settlement != PayAsBid {
+		return nil, fmt.Errorf("invalid settlement: %s", parts[2])
+	}
+
+	return &MultiUnitOptions{Units: units, Settlement: settlement}, nil
+}
+
+// Allocation is a unit of a multi-unit auction won by a bidder
+type Allocation struct {
+	Bidder UserId `json:"bidder"`
+	// Amount is the winning bid, and Price what the bidder pays
+	Amount int64 `json:"amount"`
+	Price  int64 `json:"price"`
+}
+
+// MultiUnitState represents the state of a multi-unit auction. Bids are open
+// and each bidder's latest bid stands, the highest standing bids winning a
+// unit each, the earliest among equal ones.
+type MultiUnitState struct {
+	start   time.Time
+	expiry  time.Time
+	options MultiUnitOptions
+	// bids are the accepted bids, newest first
+	bids []Bid
+	// ended tells the auction has expired
+	ended bool
+	// settled tells the allocations were recorded after the close
+	settled bool
+}
+
+// NewMultiUnitState creates a new multi-unit auction state
+func NewMultiUnitState(start, expiry time.Time, options MultiUnitOptions) *MultiUnitState {
+	return &MultiUnitState{
+		start:   start,
+		expiry:  expiry,
+		options: options,
+		bids:    []Bid{},
+	}
+}
+
+// standingBids returns the latest bid of each bidder, highest first
+func (s *MultiUnitState) standingBids() []Bid {
+	seen := make(map[UserId]bool, len(s.bids))
+	standing := make([]Bid, 0, len(s.bids))
+	for _, bid := range s.bids {
+		if !seen[bid.Bidder.ID] {
+			seen[bid.Bidder.ID] = true
+			standing = append(standing, bid)
+		}
+	}
+	sort.SliceStable(standing, func(i, j int) bool {
+		if standing[i].Amount != standing[j].Amount {
+			return standing[i].Amount > standing[j].Amount
+		}
+		return standing[i].At.Before(standing[j].At)
+	})
+	return standing
+}
+
+// winningBids returns the standing bids that win a unit
+func (s *MultiUnitState) winningBids() []Bid {
+	standing := s.standingBids()
+	if len(standing) > s.options.Units {
+		standing = standing[:s.options.Units]
+	}
+	return standing
+}
+
+// Allocations returns the units of a multi-unit auction by winner, highest
+// bid first, and what each winner pays. While it's open, these are the
+// units its standing bids would win.
+func Allocations(state State) []Allocation {
+	s, ok := state.(*MultiUnitState)
+	if !ok {
+		return nil
+	}
+	winning := s.winningBids()
+	allocations := make([]Allocation, len(winning))
+	for i, bid := range winning {
+		price := bid.Amount
+		if s.options.Settlement == UniformPrice {
+			price = winning[len(winning)-1].Amount
+		}
+		allocations[i] = Allocation{Bidder: bid.Bidder.ID, Amount: bid.Amount, Price: price}
+	}
+	return allocations
+}
+
+// Increment advances the state based on the current time, ending the
+// auction at its expiry
+func (s *MultiUnitState) Increment(now time.Time) State {
+	if s.ended || now.Before(s.expiry) {
+		return s
+	}
+	ended := *s
+	ended.ended = true
+	return &ended
+}
+
+// AddBid attempts to add a bid to the state. A bid must raise the bidder's
+// standing bid, and beat the lowest winning bid when all units are taken.
+func (s *MultiUnitState) AddBid(bid Bid) (State, error) {
+	if s.HasEnded() || !bid.At.Before(s.expiry) {
+		return s.Increment(bid.At), NewAuctionHasEndedError(bid.ForAuction)
+	}
+	if !bid.At.After(s.start) {
+		return s, NewAuctionHasNotStartedError(bid.ForAuction)
+	}
+
+	winning := s.winningBids()
+	for _, standing := range s.standingBids() {
+		if standing.Bidder.ID == bid.Bidder.ID && bid.Amount <= standing.Amount {
+			return s, NewMustPlaceBidOverHighestError(standing.Amount)
+		}
+	}
+	if len(winning) == s.options.Units {
+		lowest := winning[len(winning)-1]
+		if lowest.Bidder.ID != bid.Bidder.ID && bid.Amount <= lowest.Amount {
+			return s, NewMustPlaceBidOverHighestError(lowest.Amount)
+		}
+	}
+
+	next := *s
+	next.bids = append([]Bid{bid}, s.bids...)
+	return &next, nil
+}
+
+// GetBids returns all bids, newest first
+func (s *MultiUnitState) GetBids() []Bid {
+	return s.bids
+}
+
+// TryGetAmountAndWinner returns the price and bidder of the highest winning
+// bid once the auction has ended. Allocations has every winner.
+func (s *MultiUnitState) TryGetAmountAndWinner() (int64, UserId, bool) {
+	if !s.ended {
+		return 0, "", false
+	}
+	allocations := Allocations(s)
+	if len(allocations) == 0 {
+		return 0, "", false
+	}
+	return allocations[0].Price, allocations[0].Bidder, true
+}
+
+// HasEnded returns true once the auction has expired
+func (s *MultiUnitState) HasEnded() bool {
+	return s.ended
+}
+
+// UnitsAllocatedEvent represents an event recording the result of a
+// multi-unit auction at its close, the units each winner won and pays
+type UnitsAllocatedEvent struct {
+	Time        time.Time           `json:"at"`
+	AuctionId   AuctionId           `json:"auctionId"`
+	Settlement  MultiUnitSettlement `json:"settlement"`
+	Allocations []Allocation        `json:"allocations"`
+	Bids        int                 `json:"bids"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e UnitsAllocatedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for UnitsAllocatedEvent
+func (e UnitsAllocatedEvent) MarshalJSON() ([]byte, error) {
+	type unitsAllocatedEventJSON UnitsAllocatedEvent
+	return MarshalEnvelope("UnitsAllocated", unitsAllocatedEventJSON(e))
+}
+
+// allocateUnits marks the allocations of a multi-unit auction as recorded
+func allocateUnits(state State) State {
+	s, ok := state.(*MultiUnitState)
+	if !ok {
+		return state
+	}
+	settled := *s
+	settled.settled = true
+	return &settled
+}
@@ -0,0 +1,241 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// ProxyBid is the maximum a bidder is willing to pay in a timed ascending
+// auction, which the proxy bidding engine bids up to on their behalf. The
+// maximum is kept from other users, only the bids it places are shown.
+type ProxyBid struct {
+	Bidder User      `json:"user"`
+	Max    int64     `json:"max"`
+	At     time.Time `json:"at"`
+}
+
+// SetMaxBidCommand represents a command to set the maximum bid of a bidder
+type SetMaxBidCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	Bidder    User      `json:"user"`
+	Max       int64     `json:"max"`
+}
+
+// GetTime returns the time of the command
+func (c SetMaxBidCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for SetMaxBidCommand
+func (c SetMaxBidCommand) MarshalJSON() ([]byte, error) {
+	type setMaxBidCommandJSON SetMaxBidCommand
+	return MarshalEnvelope("SetMaxBid", setMaxBidCommandJSON(c))
+}
+
+// MaxBidSetEvent represents an event indicating a bidder set their maximum
+// bid, replacing the previous one
+type MaxBidSetEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	Bidder    User      `json:"user"`
+	Max       int64     `json:"max"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e MaxBidSetEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for MaxBidSetEvent
+func (e MaxBidSetEvent) MarshalJSON() ([]byte, error) {
+	type maxBidSetEventJSON MaxBidSetEvent
+	return MarshalEnvelope("MaxBidSet", maxBidSetEventJSON(e))
+}
+
+// PlaceProxyBidCommand represents a command to place the bid the proxy
+// bidding engine has due in an auction, as told by NextProxyBid
+type PlaceProxyBidCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+}
+
+// GetTime returns the time of the command
+func (c PlaceProxyBidCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for PlaceProxyBidCommand
+func (c PlaceProxyBidCommand) MarshalJSON() ([]byte, error) {
+	type placeProxyBidCommandJSON PlaceProxyBidCommand
+	return MarshalEnvelope("PlaceProxyBid", placeProxyBidCommandJSON(c))
+}
+
+// ProxyBidPlacedEvent represents an event indicating the proxy bidding engine
+// placed a bid on behalf of a bidder, up to their maximum. It counts as a bid
+// like BidAcceptedEvent, and tells the log apart the bids nobody placed.
+type ProxyBidPlacedEvent struct {
+	Time time.Time `json:"at"`
+	Bid  Bid       `json:"bid"`
+	// Max is the maximum the bid was placed under
+	Max int64 `json:"max"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e ProxyBidPlacedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ProxyBidPlacedEvent
+func (e ProxyBidPlacedEvent) MarshalJSON() ([]byte, error) {
+	type proxyBidPlacedEventJSON ProxyBidPlacedEvent
+	return MarshalEnvelope("ProxyBidPlaced", proxyBidPlacedEventJSON(e))
+}
+
+// proxyIncrement is the least a proxy bid raises a highest bid by
+func proxyIncrement(options TimedAscendingOptions, highest int64) int64 {
+	if raise := options.MinimumRaise(highest); raise > 1 {
+		return raise
+	}
+	return 1
+}
+
+// requiredBid returns the least amount a bid must be of to be accepted by
+// the proxy bidding engine
+func (s *OngoingState) requiredBid() int64 {
+	if len(s.bids) == 0 {
+		return proxyIncrement(s.options, 0)
+	}
+	return s.bids[0].Amount + proxyIncrement(s.options, s.bids[0].Amount)
+}
+
+// MaxBid returns the maximum bid of a bidder on an auction, if they set one
+// and the auction is open
+func MaxBid(state State, userId UserId, now time.Time) (int64, bool) {
+	ongoing, ok := state.Increment(now).(*OngoingState)
+	if !ok {
+		return 0, false
+	}
+	for _, proxy := range ongoing.proxies {
+		if proxy.Bidder.ID == userId {
+			return proxy.Max, true
+		}
+	}
+	return 0, false
+}
+
+// NextProxyBid returns the bid the proxy bidding engine has due in an
+// auction, if any. The strongest maximum, the earliest among equal ones,
+// leads at the least amount beating every other maximum that can still bid,
+// in a single bid. A bidder whose maximum can't beat it gets no bid, the
+// leader is raised past their maximum instead.
+func NextProxyBid(id AuctionId, state State, now time.Time) (ProxyBidPlacedEvent, bool) {
+	ongoing, ok := state.Increment(now).(*OngoingState)
+	if !ok || len(ongoing.proxies) == 0 {
+		return ProxyBidPlacedEvent{}, false
+	}
+
+	ranked := append([]ProxyBid(nil), ongoing.proxies...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Max != ranked[j].Max {
+			return ranked[i].Max > ranked[j].Max
+		}
+		return ranked[i].At.Before(ranked[j].At)
+	})
+	top := ranked[0]
+	required := ongoing.requiredBid()
+	if top.Max < required {
+		return ProxyBidPlacedEvent{}, false
+	}
+
+	// A leader is only raised when challenged
+	var amount int64
+	if len(ongoing.bids) == 0 || ongoing.bids[0].Bidder.ID != top.Bidder.ID {
+		amount = required
+	}
+	if len(ranked) > 1 && ranked[1].Max >= required {
+		if beat := ranked[1].Max + proxyIncrement(ongoing.options, ranked[1].Max); beat > amount {
+			amount = beat
+		}
+	}
+	if amount > top.Max {
+		amount = top.Max
+	}
+	if amount < required {
+		return ProxyBidPlacedEvent{}, false
+	}
+
+	return ProxyBidPlacedEvent{
+		Time: now,
+		Bid:  Bid{ForAuction: id, Bidder: top.Bidder, At: now, Amount: amount},
+		Max:  top.Max,
+	}, true
+}
+
+// handleSetMaxBid sets the maximum bid of a bidder, which must be enough for
+// a bid to be accepted, or to keep their lead
+func handleSetMaxBid(c SetMaxBidCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists || !entry.Auction.VisibleTo(&c.Bidder) {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	if err := entry.Auction.ValidateBid(Bid{ForAuction: c.AuctionId, Bidder: c.Bidder}); err != nil {
+		return nil, repo, err
+	}
+	if entry.Auction.Type.Type != TimedAscending {
+		return nil, repo, NewProxyBiddingUnsupportedError(c.AuctionId)
+	}
+
+	state := entry.State.Increment(c.Time)
+	if state.HasEnded() {
+		return nil, repo, NewAuctionHasEndedError(c.AuctionId)
+	}
+	ongoing, ok := state.(*OngoingState)
+	if !ok {
+		return nil, repo, NewAuctionHasNotStartedError(c.AuctionId)
+	}
+	if len(ongoing.bids) > 0 {
+		highest := ongoing.bids[0]
+		if highest.Bidder.ID == c.Bidder.ID && c.Max < highest.Amount ||
+			highest.Bidder.ID != c.Bidder.ID && c.Max < ongoing.requiredBid() {
+			return nil, repo, NewMustPlaceBidOverHighestError(highest.Amount)
+		}
+	}
+
+	event := MaxBidSetEvent{Time: c.Time, AuctionId: c.AuctionId, Bidder: c.Bidder, Max: c.Max}
+	return event, ApplyEvents(repo, []Event{event}), nil
+}
+
+// handlePlaceProxyBid places the bid the proxy bidding engine has due
+func handlePlaceProxyBid(c PlaceProxyBidCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	event, ok := NextProxyBid(c.AuctionId, entry.State, c.Time)
+	if !ok {
+		return nil, repo, NewNoProxyBidDueError(c.AuctionId)
+	}
+	return event, ApplyEvents(repo, []Event{event}), nil
+}
+
+// setMaxBid replaces the maximum bid of a bidder in an open auction
+func setMaxBid(state State, e MaxBidSetEvent) State {
+	ongoing, ok := state.Increment(e.Time).(*OngoingState)
+	if !ok {
+		return state
+	}
+	proxies := make([]ProxyBid, 0, len(ongoing.proxies)+1)
+	for _, proxy := range ongoing.proxies {
+		if proxy.Bidder.ID != e.Bidder.ID {
+			proxies = append(proxies, proxy)
+		}
+	}
+	next := *ongoing
+	next.proxies = append(proxies, ProxyBid{Bidder: e.Bidder, Max: e.Max, At: e.Time})
+	return &next
+}
@@ -0,0 +1,32 @@
+package domain
+
+// ReadModels are the read models folded from the events besides the
+// auctions and the activity feed, kept in snapshots so restoring them only
+// folds the events after the snapshot
+type ReadModels struct {
+	Reports      Reports          `json:"reports"`
+	RuleSets     []RuleSet        `json:"ruleSets"`
+	Contacts     ContactThreads   `json:"contacts"`
+	Reserves     Reserves         `json:"reserves"`
+	Descriptions Descriptions     `json:"descriptions"`
+	Started      StartedAuctions  `json:"started"`
+	Archived     ArchivedAuctions `json:"archived"`
+}
+
+// EventsToReadModels folds a list of events into the read models
+func EventsToReadModels(events []Event) ReadModels {
+	return ReadModels{}.Apply(events)
+}
+
+// Apply folds a list of events onto a copy of the read models
+func (m ReadModels) Apply(events []Event) ReadModels {
+	return ReadModels{
+		Reports:      ApplyReportEvents(m.Reports, events),
+		RuleSets:     ApplyRuleSetEvents(m.RuleSets, events),
+		Contacts:     ApplyContactEvents(m.Contacts, events),
+		Reserves:     ApplyReserveEvents(m.Reserves, events),
+		Descriptions: ApplyDescriptionEvents(m.Descriptions, events),
+		Started:      ApplyStartedEvents(m.Started, events),
+		Archived:     ApplyArchivedEvents(m.Archived, events),
+	}
+}
@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ReportId is a unique identifier for an abuse report
+type ReportId int64
+
+// ReportReason is the reason taxonomy of abuse reports
+type ReportReason string
+
+const (
+	ReportProhibitedItem ReportReason = "ProhibitedItem"
+	ReportCounterfeit    ReportReason = "Counterfeit"
+	ReportFraud          ReportReason = "Fraud"
+	ReportShillBidding   ReportReason = "ShillBidding"
+	ReportHarassment     ReportReason = "Harassment"
+	ReportOther          ReportReason = "Other"
+)
+
+// ValidReportReason returns true if the reason is part of the taxonomy
+func ValidReportReason(reason ReportReason) bool {
+	switch reason {
+	case ReportProhibitedItem, ReportCounterfeit, ReportFraud, ReportShillBidding, ReportHarassment, ReportOther:
+		return true
+	}
+	return false
+}
+
+// ReportStatus is the state of a report in the moderation queue
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "Open"
+	ReportTriaged   ReportStatus = "Triaged"
+	ReportActioned  ReportStatus = "Actioned"
+	ReportDismissed ReportStatus = "Dismissed"
+)
+
+// reportTransitions lists the statuses a report can move to from each status
+var reportTransitions = map[ReportStatus][]ReportStatus{
+	ReportOpen:    {ReportTriaged, ReportDismissed},
+	ReportTriaged: {ReportActioned, ReportDismissed},
+}
+
+// CanTransition returns true if a report can move from one status to another
+func (s ReportStatus) CanTransition(to ReportStatus) bool {
+	for _, allowed := range reportTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportSLA is how long a report may stay unresolved
+const ReportSLA = 48 * time.Hour
+
+// ReportTarget is the listing or user a report is about. Exactly one of
+// AuctionId and UserId is set.
+type ReportTarget struct {
+	AuctionId *AuctionId `json:"auctionId,omitempty"`
+	UserId    *UserId    `json:"userId,omitempty"`
+}
+
+// Report is an abuse report about a listing or a user
+type Report struct {
+	ID       ReportId     `json:"id"`
+	Reporter UserId       `json:"reporter"`
+	Target   ReportTarget `json:"target"`
+	Reason   ReportReason `json:"reason"`
+	Text     string       `json:"text"`
+	Evidence []string     `json:"evidence"`
+	Status   ReportStatus `json:"status"`
+	FiledAt  time.Time    `json:"filedAt"`
+	DueBy    time.Time    `json:"dueBy"`
+}
+
+// IsOverdue returns true if the report is unresolved past its SLA
+func (r Report) IsOverdue(now time.Time) bool {
+	return (r.Status == ReportOpen || r.Status == ReportTriaged) && now.After(r.DueBy)
+}
+
+// Reports represents the moderation queue
+type Reports map[ReportId]Report
+
+// FileReportCommand represents a command to file an abuse report. The ID,
+// status and due date of the report are assigned when it is handled.
+type FileReportCommand struct {
+	Time   time.Time `json:"at"`
+	Report Report    `json:"report"`
+}
+
+// GetTime returns the time of the command
+func (c FileReportCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for FileReportCommand
+func (c FileReportCommand) MarshalJSON() ([]byte, error) {
+	type fileReportCommandJSON struct {
+		Type   string    `json:"$type"`
+		Time   time.Time `json:"at"`
+		Report Report    `json:"report"`
+	}
+	return json.Marshal(fileReportCommandJSON{
+		Type:   "FileReport",
+		Time:   c.Time,
+		Report: c.Report,
+	})
+}
+
+// ChangeReportStatusCommand represents a command of a moderator moving a
+// report through the moderation queue
+type ChangeReportStatusCommand struct {
+	Time     time.Time    `json:"at"`
+	ReportId ReportId     `json:"reportId"`
+	Status   ReportStatus `json:"status"`
+	By       UserId       `json:"by"`
+}
+
+// GetTime returns the time of the command
+func (c ChangeReportStatusCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ChangeReportStatusCommand
+func (c ChangeReportStatusCommand) MarshalJSON() ([]byte, error) {
+	type changeReportStatusCommandJSON struct {
+		Type     string       `json:"$type"`
+		Time     time.Time    `json:"at"`
+		ReportId ReportId     `json:"reportId"`
+		Status   ReportStatus `json:"status"`
+		By       UserId       `json:"by"`
+	}
+	return json.Marshal(changeReportStatusCommandJSON{
+		Type:     "ChangeReportStatus",
+		Time:     c.Time,
+		ReportId: c.ReportId,
+		Status:   c.Status,
+		By:       c.By,
+	})
+}
+
+// ReportFiledEvent represents an event indicating an abuse report was filed
+type ReportFiledEvent struct {
+	Time   time.Time `json:"at"`
+	Report Report    `json:"report"`
+}
+
+// GetTime returns the time of the event
+func (e ReportFiledEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ReportFiledEvent
+func (e ReportFiledEvent) MarshalJSON() ([]byte, error) {
+	type reportFiledEventJSON struct {
+		Type   string    `json:"$type"`
+		Time   time.Time `json:"at"`
+		Report Report    `json:"report"`
+	}
+	return json.Marshal(reportFiledEventJSON{
+		Type:   "ReportFiled",
+		Time:   e.Time,
+		Report: e.Report,
+	})
+}
+
+// ReportStatusChangedEvent represents an event indicating a report moved
+// through the moderation queue
+type ReportStatusChangedEvent struct {
+	Time     time.Time    `json:"at"`
+	ReportId ReportId     `json:"reportId"`
+	Status   ReportStatus `json:"status"`
+	By       UserId       `json:"by"`
+}
+
+// GetTime returns the time of the event
+func (e ReportStatusChangedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ReportStatusChangedEvent
+func (e ReportStatusChangedEvent) MarshalJSON() ([]byte, error) {
+	type reportStatusChangedEventJSON struct {
+		Type     string       `json:"$type"`
+		Time     time.Time    `json:"at"`
+		ReportId ReportId     `json:"reportId"`
+		Status   ReportStatus `json:"status"`
+		By       UserId       `json:"by"`
+	}
+	return json.Marshal(reportStatusChangedEventJSON{
+		Type:     "ReportStatusChanged",
+		Time:     e.Time,
+		ReportId: e.ReportId,
+		Status:   e.Status,
+		By:       e.By,
+	})
+}
+
+// HandleReport processes a report command against the moderation queue
+func HandleReport(cmd Command, reports Reports) (Event, Reports, error) {
+	switch c := cmd.(type) {
+	case FileReportCommand:
+		report := c.Report
+		if !ValidReportReason(report.Reason) {
+			return nil, reports, NewInvalidReportError("unknown reason")
+		}
+		if (report.Target.AuctionId == nil) == (report.Target.UserId == nil) {
+			return nil, reports, NewInvalidReportError("a report targets either a listing or a user")
+		}
+
+		// Reports are numbered in the order they are filed
+		report.ID = ReportId(len(reports) + 1)
+		report.Status = ReportOpen
+		report.FiledAt = c.Time
+		report.DueBy = c.Time.Add(ReportSLA)
+		if report.Evidence == nil {
+			report.Evidence = []string{}
+		}
+
+		event := ReportFiledEvent{Time: c.Time, Report: report}
+		return event, ApplyReportEvents(reports, []Event{event}), nil
+
+	case ChangeReportStatusCommand:
+		report, ok := reports[c.ReportId]
+		if !ok {
+			return nil, reports, NewReportNotFoundError(c.ReportId)
+		}
+		if !report.Status.CanTransition(c.Status) {
+			return nil, reports, NewInvalidReportTransitionError(report.Status, c.Status)
+		}
+
+		event := ReportStatusChangedEvent{Time: c.Time, ReportId: c.ReportId, Status: c.Status, By: c.By}
+		return event, ApplyReportEvents(reports, []Event{event}), nil
+	}
+
+	return nil, reports, fmt.Errorf("unknown command type")
+}
+
+// EventsToReports folds a list of events into a moderation queue
+func EventsToReports(events []Event) Reports {
+	return ApplyReportEvents(make(Reports), events)
+}
+
+// ApplyReportEvents folds a list of events onto a copy of the moderation
+// queue. Events that are not about reports are ignored.
+func ApplyReportEvents(reports Reports, events []Event) Reports {
+	newReports := make(Reports, len(reports))
+	for k, v := range reports {
+		newReports[k] = v
+	}
+
+	for _, event := range events {
+		switch e := event.(type) {
+		case ReportFiledEvent:
+			newReports[e.Report.ID] = e.Report
+		case ReportStatusChangedEvent:
+			if report, ok := newReports[e.ReportId]; ok {
+				report.Status = e.Status
+				newReports[e.ReportId] = report
+			}
+		}
+	}
+
+	return newReports
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"time"
+)
+
+// Statuses of the reserve price of an auction, which is all that is told of
+// it, never its amount
+const (
+	ReserveMet    = "met"
+	ReserveNotMet = "notMet"
+)
+
+// Reserves holds the reserve statuses reported so far by auction
+type Reserves map[AuctionId]string
+
+// ReservePrice returns the hidden reserve price of an auction, if it has one
+func ReservePrice(auction Auction) (int64, bool) {
+	if auction.Type.Type != TimedAscending {
+		return 0, false
+	}
+	options, err := ParseTimedAscendingOptions(auction.Type.Options)
+	if err != nil || options.ReservePrice <= 0 {
+		return 0, false
+	}
+	return options.ReservePrice, true
+}
+
+// ReserveStatus tells whether the highest bid on an auction has met its
+// reserve price by exceeding it, as the winner's bid must, empty when the
+// auction has no reserve or was cancelled
+func ReserveStatus(auction Auction, state State) string {
+	reserve, ok := ReservePrice(auction)
+	if _, cancelled := CancelledAt(state); !ok || cancelled {
+		return ""
+	}
+	if bids := state.GetBids(); len(bids) > 0 && bids[0].Amount > reserve {
+		return ReserveMet
+	}
+	return ReserveNotMet
+}
+
+// ReserveEvent returns the event reporting a change of the reserve status of
+// an auction, or nil when there is nothing new to report. The reserve is
+// reported met as soon as a bid exceeds it, and not met once the auction has
+// closed at or below it.
+func ReserveEvent(auction Auction, state State, reported Reserves, now time.Time) Event {
+	state = state.Increment(now)
+	switch ReserveStatus(auction, state) {
+	case ReserveMet:
+		if reported[auction.ID] != ReserveMet {
+			return ReserveMetEvent{Time: now, AuctionId: auction.ID}
+		}
+	case ReserveNotMet:
+		if state.HasEnded() && reported[auction.ID] == "" {
+			return ReserveNotMetEvent{Time: now, AuctionId: auction.ID}
+		}
+	}
+	return nil
+}
+
+// ReserveMetEvent represents an event indicating a bid has met the reserve
+// price of an auction
+type ReserveMetEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+}
+
+// GetTime returns the time of the event
+func (e ReserveMetEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ReserveMetEvent
+func (e ReserveMetEvent) MarshalJSON() ([]byte, error) {
+	type reserveMetEventJSON ReserveMetEvent
+	return MarshalEnvelope("ReserveMet", reserveMetEventJSON(e))
+}
+
+// ReserveNotMetEvent represents an event indicating an auction has closed
+// below its reserve price, so the item remains unsold
+type ReserveNotMetEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+}
+
+// GetTime returns the time of the event
+func (e ReserveNotMetEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ReserveNotMetEvent
+func (e ReserveNotMetEvent) MarshalJSON() ([]byte, error) {
+	type reserveNotMetEventJSON ReserveNotMetEvent
+	return MarshalEnvelope("ReserveNotMet", reserveNotMetEventJSON(e))
+}
+
+// EventsToReserves folds a list of events into the reported reserve statuses
+func EventsToReserves(events []Event) Reserves {
+	return ApplyReserveEvents(make(Reserves), events)
+}
+
+// ApplyReserveEvents folds a list of events onto a copy of the reported
+// reserve statuses. Events that are not about reserves are ignored.
+func ApplyReserveEvents(reserves Reserves, events []Event) Reserves {
+	newReserves := make(Reserves, len(reserves))
+	for k, v := range reserves {
+		newReserves[k] = v
+	}
+
+	for _, event := range events {
+		switch e := event.(type) {
+		case ReserveMetEvent:
+			newReserves[e.AuctionId] = ReserveMet
+		case ReserveNotMetEvent:
+			newReserves[e.AuctionId] = ReserveNotMet
+		}
+	}
+
+	return newReserves
+}
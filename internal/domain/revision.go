@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// maxTagLength bounds the length of listing tags
+const maxTagLength = 50
+
+// ValidTag tells whether a listing tag is non-empty, at most 50 characters
+// and free of whitespace
+func ValidTag(tag string) bool {
+	return tag != "" && len(tag) <= maxTagLength && !strings.ContainsAny(tag, " \t\r\n")
+}
+
+// HasTag tells whether the auction carries a tag
+func (a Auction) HasTag(tag string) bool {
+	for _, t := range a.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// withRevision returns a copy of the auction with a revision applied
+func (a Auction) withRevision(expiry *time.Time, addTags []string) Auction {
+	if expiry != nil {
+		a.Expiry = *expiry
+	}
+	tags := append([]string(nil), a.Tags...)
+	for _, tag := range addTags {
+		if !a.HasTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	a.Tags = tags
+	return a
+}
+
+// ReviseListingCommand represents a command to revise a listing that has no
+// bids yet, extending its end time and adding tags
+type ReviseListingCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	// Expiry is the new end time, which must be later than the current one
+	Expiry  *time.Time `json:"expiry,omitempty"`
+	AddTags []string   `json:"addTags,omitempty"`
+}
+
+// GetTime returns the time of the command
+func (c ReviseListingCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ReviseListingCommand
+func (c ReviseListingCommand) MarshalJSON() ([]byte, error) {
+	type reviseListingCommandJSON ReviseListingCommand
+	return MarshalEnvelope("ReviseListing", reviseListingCommandJSON(c))
+}
+
+// ListingRevisedEvent represents an event indicating a listing was revised
+type ListingRevisedEvent struct {
+	Time      time.Time  `json:"at"`
+	AuctionId AuctionId  `json:"auctionId"`
+	Expiry    *time.Time `json:"expiry,omitempty"`
+	AddTags   []string   `json:"addTags,omitempty"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e ListingRevisedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ListingRevisedEvent
+func (e ListingRevisedEvent) MarshalJSON() ([]byte, error) {
+	type listingRevisedEventJSON ListingRevisedEvent
+	return MarshalEnvelope("ListingRevised", listingRevisedEventJSON(e))
+}
+
+// handleReviseListing revises an open or upcoming auction without bids
+func handleReviseListing(c ReviseListingCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	if c.Expiry == nil && len(c.AddTags) == 0 {
+		return nil, repo, NewInvalidRevisionError("nothing to revise")
+	}
+	state := entry.State.Increment(c.Time)
+	if state.HasEnded() {
+		return nil, repo, NewAuctionHasEndedError(c.AuctionId)
+	}
+	if len(state.GetBids()) > 0 {
+		return nil, repo, NewAuctionHasBidsError(c.AuctionId)
+	}
+	if c.Expiry != nil && !c.Expiry.After(entry.Auction.Expiry) {
+		return nil, repo, NewInvalidRevisionError("the end time can only be extended")
+	}
+	for _, tag := range c.AddTags {
+		if !ValidTag(tag) {
+			return nil, repo, NewInvalidRevisionError("invalid tag: " + tag)
+		}
+	}
+
+	event := ListingRevisedEvent{
+		Time:      c.Time,
+		AuctionId: c.AuctionId,
+		Expiry:    c.Expiry,
+		AddTags:   c.AddTags,
+	}
+	newRepo := copyRepository(repo)
+	entry.Auction = entry.Auction.withRevision(c.Expiry, c.AddTags)
+	if c.Expiry != nil {
+		// Revisions only apply before the first bid, so the new end time
+		// restarts from an empty state
+		entry.State = entry.Auction.CreateEmptyState()
+	}
+	newRepo[c.AuctionId] = entry
+	return event, newRepo, nil
+}
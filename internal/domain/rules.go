@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProhibitedItemRule rejects or queues for review the listings whose title
+// contains a keyword
+type ProhibitedItemRule struct {
+	Keyword string            `json:"keyword"`
+	Verdict ModerationVerdict `json:"verdict"`
+}
+
+// RuleSet is a published version of the prohibited item rules
+type RuleSet struct {
+	Version     int                  `json:"version"`
+	Rules       []ProhibitedItemRule `json:"rules"`
+	PublishedAt time.Time            `json:"publishedAt"`
+	PublishedBy UserId               `json:"publishedBy"`
+}
+
+// ValidateRules returns an InvalidRuleSet error if a rule is malformed
+func ValidateRules(rules []ProhibitedItemRule) error {
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Keyword) == "" {
+			return NewInvalidRuleSetError(fmt.Sprintf("rule %d has no keyword", i))
+		}
+		if rule.Verdict != ModerationRejected && rule.Verdict != ModerationReview {
+			return NewInvalidRuleSetError(fmt.Sprintf("rule %d must reject or review", i))
+		}
+	}
+	return nil
+}
+
+// ModerateListing evaluates the rules against a listing. Rejecting rules take
+// precedence over review rules.
+func (r RuleSet) ModerateListing(auction Auction) (ModerationDecision, error) {
+	title := strings.ToLower(auction.Title)
+	decision := ModerationDecision{
+		Verdict:  ModerationApproved,
+		Reasons:  []string{},
+		Provider: fmt.Sprintf("rules:v%d", r.Version),
+	}
+	for _, rule := range r.Rules {
+		if !strings.Contains(title, strings.ToLower(rule.Keyword)) {
+			continue
+		}
+		decision.Reasons = append(decision.Reasons, "keyword: "+rule.Keyword)
+		decision.Verdict = MostSevereVerdict(decision.Verdict, rule.Verdict)
+	}
+	return decision, nil
+}
+
+// MostSevereVerdict returns the more restrictive of two verdicts
+func MostSevereVerdict(a, b ModerationVerdict) ModerationVerdict {
+	severity := map[ModerationVerdict]int{ModerationApproved: 0, ModerationReview: 1, ModerationRejected: 2}
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// RuleSetPublishedEvent represents an event indicating a new version of the
+// prohibited item rules was published
+type RuleSetPublishedEvent struct {
+	Time    time.Time `json:"at"`
+	RuleSet RuleSet   `json:"ruleSet"`
+}
+
+// GetTime returns the time of the event
+func (e RuleSetPublishedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for RuleSetPublishedEvent
+func (e RuleSetPublishedEvent) MarshalJSON() ([]byte, error) {
+	type ruleSetPublishedEventJSON struct {
+		Type    string    `json:"$type"`
+		Time    time.Time `json:"at"`
+		RuleSet RuleSet   `json:"ruleSet"`
+	}
+	return json.Marshal(ruleSetPublishedEventJSON{
+		Type:    "RuleSetPublished",
+		Time:    e.Time,
+		RuleSet: e.RuleSet,
+	})
+}
+
+// PublishRuleSet creates the next version of the rules, given the published versions
+func PublishRuleSet(ruleSets []RuleSet, rules []ProhibitedItemRule, by UserId, now time.Time) (RuleSetPublishedEvent, error) {
+	if err := ValidateRules(rules); err != nil {
+		return RuleSetPublishedEvent{}, err
+	}
+	return RuleSetPublishedEvent{
+		Time: now,
+		RuleSet: RuleSet{
+			Version:     len(ruleSets) + 1,
+			Rules:       rules,
+			PublishedAt: now,
+			PublishedBy: by,
+		},
+	}, nil
+}
+
+// EventsToRuleSets folds a list of events into the published rule set versions
+func EventsToRuleSets(events []Event) []RuleSet {
+	return ApplyRuleSetEvents([]RuleSet{}, events)
+}
+
+// ApplyRuleSetEvents folds a list of events onto a copy of the published rule
+// set versions. Events that don't publish a rule set are ignored.
+func ApplyRuleSetEvents(ruleSets []RuleSet, events []Event) []RuleSet {
+	newRuleSets := append([]RuleSet{}, ruleSets...)
+	for _, event := range events {
+		if e, ok := event.(RuleSetPublishedEvent); ok {
+			newRuleSets = append(newRuleSets, e.RuleSet)
+		}
+	}
+	return newRuleSets
+}
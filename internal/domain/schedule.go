@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+)
+
+// StartedAuctions holds the auctions whose start has been reported
+type StartedAuctions map[AuctionId]bool
+
+// StartEvent returns the event reporting an auction has started, or nil when
+// it hasn't yet, was already reported, or is already over. The event is
+// dated at the start time rather than when it's noticed, so reads as of a
+// time see the auction start when it did.
+func StartEvent(auction Auction, state State, started StartedAuctions, now time.Time) Event {
+	if started[auction.ID] || !now.After(auction.StartsAt) || state.Increment(now).HasEnded() {
+		return nil
+	}
+	return AuctionStartedEvent{Time: auction.StartsAt, AuctionId: auction.ID, StartsAt: auction.StartsAt}
+}
+
+// AuctionStartedEvent represents an event indicating a scheduled auction has
+// reached its start time and takes bids
+type AuctionStartedEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	StartsAt  time.Time `json:"startsAt"`
+}
+
+// GetTime returns the time of the event
+func (e AuctionStartedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for AuctionStartedEvent
+func (e AuctionStartedEvent) MarshalJSON() ([]byte, error) {
+	type auctionStartedEventJSON AuctionStartedEvent
+	return MarshalEnvelope("AuctionStarted", auctionStartedEventJSON(e))
+}
+
+// EventsToStartedAuctions folds a list of events into the started auctions
+func EventsToStartedAuctions(events []Event) StartedAuctions {
+	return ApplyStartedEvents(make(StartedAuctions), events)
+}
+
+// ApplyStartedEvents folds a list of events onto a copy of the started
+// auctions. Events that are not about starts are ignored.
+func ApplyStartedEvents(started StartedAuctions, events []Event) StartedAuctions {
+	newStarted := make(StartedAuctions, len(started))
+	for k, v := range started {
+		newStarted[k] = v
+	}
+
+	for _, event := range events {
+		if e, ok := event.(AuctionStartedEvent); ok {
+			newStarted[e.AuctionId] = true
+		}
+	}
+	return newStarted
+}
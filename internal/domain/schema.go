@@ -0,0 +1,241 @@
+package domain
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Stored events and commands must stay readable for as long as they are
+// kept, so their shapes are frozen: schemas.json records a hash of the shape
+// of each type at each version, and CheckSchemas fails when a frozen shape
+// changes. A change to an event supersedes its shape with a new version, by
+// registering an upcaster from the frozen version and freezing the new one
+// with cmd/compat. Commands aren't versioned, so a change to a command takes
+// a new command type.
+
+//go:embed schemas.json
+var frozenSchemasJSON []byte
+
+// FrozenSchemas returns the frozen schema hashes by type and version, such
+// as "AuctionAdded@v1"
+func FrozenSchemas() (map[string]string, error) {
+	frozen := map[string]string{}
+	if err := json.Unmarshal(frozenSchemasJSON, &frozen); err != nil {
+		return nil, fmt.Errorf("error unmarshaling frozen schemas: %v", err)
+	}
+	return frozen, nil
+}
+
+// storedEventTypes returns a value of each stored event type by $type
+func storedEventTypes() map[string]interface{} {
+	return map[string]interface{}{
+		"AuctionAdded":        AuctionAddedEvent{},
+		"BidAccepted":         BidAcceptedEvent{},
+		"ReportFiled":         ReportFiledEvent{},
+		"ReportStatusChanged": ReportStatusChangedEvent{},
+		"ListingModerated":    ListingModeratedEvent{},
+		"RuleSetPublished":    RuleSetPublishedEvent{},
+		"UserScreened":        UserScreenedEvent{},
+		"ListingTranslated":   ListingTranslatedEvent{},
+		"ListingRevised":      ListingRevisedEvent{},
+		"KeyShareSubmitted":   KeyShareSubmittedEvent{},
+		"TenderRevealed":      TenderRevealedEvent{},
+		"ContactMessageSent":  ContactMessageSentEvent{},
+		"WinnerDetermined":    WinnerDeterminedEvent{},
+		"ReserveMet":          ReserveMetEvent{},
+		"ReserveNotMet":       ReserveNotMetEvent{},
+		"BoughtNow":           BoughtNowEvent{},
+		"MaxBidSet":           MaxBidSetEvent{},
+		"ProxyBidPlaced":      ProxyBidPlacedEvent{},
+		"AuctionExtended":     AuctionExtendedEvent{},
+		"UnitsAllocated":      UnitsAllocatedEvent{},
+		"AuctionCancelled":    AuctionCancelledEvent{},
+		"AuctionAmended":      AuctionAmendedEvent{},
+		"AuctionStarted":      AuctionStartedEvent{},
+		"EventArchived":       EventArchivedEvent{},
+	}
+}
+
+// storedCommandTypes returns a value of each stored command type by $type
+func storedCommandTypes() map[string]interface{} {
+	return map[string]interface{}{
+		"AddAuction":         AddAuctionCommand{},
+		"PlaceBid":           PlaceBidCommand{},
+		"FileReport":         FileReportCommand{},
+		"ChangeReportStatus": ChangeReportStatusCommand{},
+		"TranslateListing":   TranslateListingCommand{},
+		"ReviseListing":      ReviseListingCommand{},
+		"SubmitKeyShare":     SubmitKeyShareCommand{},
+		"RevealTender":       RevealTenderCommand{},
+		"SendContactMessage": SendContactMessageCommand{},
+		"BuyNow":             BuyNowCommand{},
+		"SetMaxBid":          SetMaxBidCommand{},
+		"PlaceProxyBid":      PlaceProxyBidCommand{},
+		"DetermineWinner":    DetermineWinnerCommand{},
+		"CancelAuction":      CancelAuctionCommand{},
+		"AmendAuction":       AmendAuctionCommand{},
+	}
+}
+
+// SchemaHashes returns the hashes of the current shapes of the stored types
+// by type and version
+func SchemaHashes() map[string]string {
+	hashes := map[string]string{}
+	for typeName, v := range storedEventTypes() {
+		hashes[schemaKey(typeName, EventVersion(typeName))] = SchemaHash(v)
+	}
+	for typeName, v := range storedCommandTypes() {
+		hashes[schemaKey(typeName, 1)] = SchemaHash(v)
+	}
+	return hashes
+}
+
+// CheckSchemas compares the current shapes of the stored types to the
+// frozen ones, returning a problem for each frozen shape that changed or
+// disappeared and each current shape that isn't frozen yet
+func CheckSchemas(frozen map[string]string) []string {
+	current := SchemaHashes()
+	var problems []string
+	for key, hash := range current {
+		frozenHash, ok := frozen[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not frozen, freeze it with cmd/compat", key))
+		} else if frozenHash != hash {
+			problems = append(problems, fmt.Sprintf("%s is frozen but its shape changed, supersede it with a new version instead", key))
+		}
+	}
+	for key := range frozen {
+		typeName, version, err := parseSchemaKey(key)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		_, isEvent := storedEventTypes()[typeName]
+		_, isCommand := storedCommandTypes()[typeName]
+		switch {
+		case !isEvent && !isCommand:
+			problems = append(problems, fmt.Sprintf("%s is frozen but the type no longer exists, stored data can't be read", key))
+		case isEvent && version > EventVersion(typeName), isCommand && version > 1:
+			problems = append(problems, fmt.Sprintf("%s is frozen but the type is at an earlier version, an upcaster is missing", key))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// schemaKey returns the key of a type at a version
+func schemaKey(typeName string, version int) string {
+	return fmt.Sprintf("%s@v%d", typeName, version)
+}
+
+// parseSchemaKey parses the key of a type at a version
+func parseSchemaKey(key string) (string, int, error) {
+	i := strings.LastIndex(key, "@v")
+	if i < 0 {
+		return "", 0, fmt.Errorf("invalid schema key %q", key)
+	}
+	var version int
+	if _, err := fmt.Sscanf(key[i+2:], "%d", &version); err != nil || version < 1 {
+		return "", 0, fmt.Errorf("invalid schema key %q", key)
+	}
+	return key[:i], version, nil
+}
+
+// SchemaHash returns the hex SHA-256 of the shape of a value
+func SchemaHash(v interface{}) string {
+	sum := sha256.Sum256([]byte(Schema(v)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Schema describes the JSON shape of the type of a value: the JSON names
+// of its fields in order and their kinds, regardless of Go type names
+func Schema(v interface{}) string {
+	var b strings.Builder
+	writeSchema(&b, reflect.TypeOf(v), map[reflect.Type]bool{})
+	return b.String()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// writeSchema writes the shape of a type, seen holding the structs being
+// described to cut recursion
+func writeSchema(b *strings.Builder, t reflect.Type, seen map[reflect.Type]bool) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		b.WriteString("?")
+		writeSchema(b, t.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[]")
+		writeSchema(b, t.Elem(), seen)
+	case reflect.Map:
+		b.WriteString("map[")
+		writeSchema(b, t.Key(), seen)
+		b.WriteString("]")
+		writeSchema(b, t.Elem(), seen)
+	case reflect.Interface:
+		b.WriteString("any")
+	case reflect.Struct:
+		if t == timeType {
+			b.WriteString("time")
+			return
+		}
+		if seen[t] {
+			b.WriteString("recursive")
+			return
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		fields := map[string]reflect.Type{}
+		collectFields(t, fields)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		b.WriteString("{")
+		for i, name := range names {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(name)
+			b.WriteString(":")
+			writeSchema(b, fields[name], seen)
+		}
+		b.WriteString("}")
+	default:
+		b.WriteString(t.Kind().String())
+	}
+}
+
+// collectFields collects the JSON fields of a struct, inlining embedded
+// structs without a name as encoding/json does
+func collectFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, fields)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+}
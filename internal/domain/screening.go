@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ScreeningResult is the outcome of screening a user against denied-party lists
+type ScreeningResult struct {
+	Match    bool     `json:"match"`
+	Lists    []string `json:"lists"`
+	Provider string   `json:"provider"`
+}
+
+// ScreeningProvider screens users against sanctions and denied-party lists
+type ScreeningProvider interface {
+	Screen(user User) (ScreeningResult, error)
+}
+
+// DenyListScreener is a ScreeningProvider over a fixed list of user IDs and
+// names, matched case insensitively
+type DenyListScreener struct {
+	denied map[string]bool
+}
+
+// NewDenyListScreener creates a screener denying the given user IDs and names
+func NewDenyListScreener(entries []string) *DenyListScreener {
+	denied := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		denied[strings.ToLower(entry)] = true
+	}
+	return &DenyListScreener{denied: denied}
+}
+
+// Screen matches the ID and name of a user against the list
+func (s *DenyListScreener) Screen(user User) (ScreeningResult, error) {
+	result := ScreeningResult{Lists: []string{}, Provider: "denylist"}
+	if s.denied[strings.ToLower(string(user.ID))] || (user.Name != "" && s.denied[strings.ToLower(user.Name)]) {
+		result.Match = true
+		result.Lists = append(result.Lists, "denylist")
+	}
+	return result, nil
+}
+
+// Screening contexts
+const (
+	ScreeningListing = "Listing"
+	ScreeningBid     = "Bid"
+)
+
+// UserScreenedEvent represents an event recording the screening of a user
+type UserScreenedEvent struct {
+	Time    time.Time       `json:"at"`
+	UserId  UserId          `json:"userId"`
+	Context string          `json:"context"`
+	Result  ScreeningResult `json:"result"`
+}
+
+// GetTime returns the time of the event
+func (e UserScreenedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for UserScreenedEvent
+func (e UserScreenedEvent) MarshalJSON() ([]byte, error) {
+	type userScreenedEventJSON struct {
+		Type    string          `json:"$type"`
+		Time    time.Time       `json:"at"`
+		UserId  UserId          `json:"userId"`
+		Context string          `json:"context"`
+		Result  ScreeningResult `json:"result"`
+	}
+	return json.Marshal(userScreenedEventJSON{
+		Type:    "UserScreened",
+		Time:    e.Time,
+		UserId:  e.UserId,
+		Context: e.Context,
+		Result:  e.Result,
+	})
+}
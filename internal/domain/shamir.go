@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Shamir's secret sharing over GF(256): each byte of the secret is the
+// constant term of a random polynomial of degree threshold-1, and a share
+// holds the values of the polynomials at one x coordinate. Any threshold
+// shares recover the secret by Lagrange interpolation at 0, fewer reveal
+// nothing about it. A share is encoded as its x coordinate followed by the
+// values.
+
+// gfMul multiplies in GF(256) with the AES polynomial x^8+x^4+x^3+x+1
+func gfMul(a, b byte) byte {
+	var p byte
+	for b > 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns the multiplicative inverse of a non-zero element, a^254
+func gfInv(a byte) byte {
+	result := byte(1)
+	for i := 0; i < 254; i++ {
+		result = gfMul(result, a)
+	}
+	return result
+}
+
+// SplitSecret splits a secret into n shares of which any threshold recover
+// it, drawing the polynomial coefficients from random
+func SplitSecret(secret []byte, n, threshold int, random io.Reader) ([][]byte, error) {
+	if threshold < 1 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("invalid threshold %d of %d shares", threshold, n)
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("empty secret")
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, threshold)
+	for j, b := range secret {
+		coefficients[0] = b
+		if _, err := io.ReadFull(random, coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			// Horner's rule, from the highest coefficient down
+			x := share[0]
+			var y byte
+			for k := threshold - 1; k >= 0; k-- {
+				y = gfMul(y, x) ^ coefficients[k]
+			}
+			share[j+1] = y
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares recovers a secret from shares. Given fewer shares than the
+// threshold, or shares of different secrets, it returns garbage, which
+// callers must detect.
+func CombineShares(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares")
+	}
+	length := len(shares[0])
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if len(share) != length || length < 2 {
+			return nil, errors.New("shares have different lengths")
+		}
+		if share[0] == 0 || seen[share[0]] {
+			return nil, errors.New("shares have invalid or duplicate coordinates")
+		}
+		seen[share[0]] = true
+	}
+
+	secret := make([]byte, length-1)
+	for i, share := range shares {
+		// Lagrange basis polynomial of the share evaluated at 0
+		basis := byte(1)
+		for j, other := range shares {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfMul(other[0], gfInv(other[0]^share[0])))
+		}
+		for k := range secret {
+			secret[k] ^= gfMul(basis, share[k+1])
+		}
+	}
+	return secret, nil
+}
@@ -24,6 +24,10 @@ type SealedBidState struct {
 	disclosing bool
 	expiry     time.Time
 	options    SealedBidOptions
+	// encrypted tells the amounts of a tender's bids are not revealed yet
+	encrypted bool
+	// determined tells the result was recorded after the close
+	determined bool
 }
 
 // NewSealedBidState creates a new sealed bid auction state
@@ -64,6 +68,7 @@ func (s *SealedBidState) Increment(now time.Time) State {
 			disclosing: true,
 			expiry:     s.expiry,
 			options:    s.options,
+			encrypted:  s.encrypted,
 		}
 	}
 
@@ -112,6 +117,7 @@ func (s *SealedBidState) AddBid(bid Bid) (State, error) {
 		disclosing: sealedState.disclosing,
 		expiry:     sealedState.expiry,
 		options:    sealedState.options,
+		encrypted:  sealedState.encrypted,
 	}, nil
 }
 
@@ -130,7 +136,7 @@ func (s *SealedBidState) GetBids() []Bid {
 
 // TryGetAmountAndWinner attempts to get the winning amount and bidder
 func (s *SealedBidState) TryGetAmountAndWinner() (int64, UserId, bool) {
-	if !s.disclosing || len(s.bidsList) == 0 {
+	if !s.disclosing || s.encrypted || len(s.bidsList) == 0 {
 		return 0, "", false
 	}
 
@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StateSnapshot is a serializable representation of an auction state
+type StateSnapshot struct {
+	// Kind is one of "AwaitingStart", "Ongoing", "Ended", "SealedBid", "Dutch",
+	// "DutchEnded", "MultiUnit", "MultiUnitEnded" or "Cancelled"
+	Kind       string    `json:"kind"`
+	Bids       []Bid     `json:"bids"`
+	Start      time.Time `json:"start"`
+	Expiry     time.Time `json:"expiry"`
+	Disclosing bool      `json:"disclosing"`
+	Options    string    `json:"options"`
+	Encrypted  bool      `json:"encrypted,omitempty"`
+	Determined bool      `json:"determined,omitempty"`
+	// Proxies are the maximum bids of an ongoing timed ascending auction
+	Proxies []ProxyBid `json:"proxies,omitempty"`
+	// Extensions counts the soft close extensions of a timed ascending auction
+	Extensions int `json:"extensions,omitempty"`
+}
+
+// AuctionSnapshot is a serializable auction together with its state
+type AuctionSnapshot struct {
+	Auction Auction       `json:"auction"`
+	State   StateSnapshot `json:"state"`
+}
+
+// SnapshotState captures an auction state
+func SnapshotState(state State) (StateSnapshot, error) {
+	switch s := state.(type) {
+	case *AwaitingStartState:
+		return StateSnapshot{
+			Kind:    "AwaitingStart",
+			Bids:    []Bid{},
+			Start:   s.start,
+			Expiry:  s.startingExpiry,
+			Options: s.options.String(),
+		}, nil
+	case *OngoingState:
+		return StateSnapshot{
+			Kind:       "Ongoing",
+			Bids:       s.bids,
+			Expiry:     s.nextExpiry,
+			Options:    s.options.String(),
+			Proxies:    s.proxies,
+			Extensions: s.extensions,
+		}, nil
+	case *EndedState:
+		return StateSnapshot{
+			Kind:       "Ended",
+			Bids:       s.bids,
+			Expiry:     s.expiry,
+			Options:    s.options.String(),
+			Extensions: s.extensions,
+		}, nil
+	case *SealedBidState:
+		bids := s.bidsList
+		if !s.disclosing {
+			// The map has no order, keep the snapshot deterministic
+			bids = make([]Bid, 0, len(s.bids))
+			for _, bid := range s.bids {
+				bids = append(bids, bid)
+			}
+			sort.Slice(bids, func(i, j int) bool {
+				return bids[i].At.Before(bids[j].At)
+			})
+		}
+		return StateSnapshot{
+			Kind:       "SealedBid",
+			Bids:       bids,
+			Expiry:     s.expiry,
+			Disclosing: s.disclosing,
+			Options:    string(s.options),
+			Encrypted:  s.encrypted,
+			Determined: s.determined,
+		}, nil
+	case *DutchState:
+		kind := "Dutch"
+		if s.ended {
+			kind = "DutchEnded"
+		}
+		return StateSnapshot{
+			Kind:    kind,
+			Bids:    s.GetBids(),
+			Start:   s.start,
+			Expiry:  s.expiry,
+			Options: s.options.String(),
+		}, nil
+	case *MultiUnitState:
+		kind := "MultiUnit"
+		if s.ended {
+			kind = "MultiUnitEnded"
+		}
+		return StateSnapshot{
+			Kind:       kind,
+			Bids:       s.bids,
+			Start:      s.start,
+			Expiry:     s.expiry,
+			Options:    s.options.String(),
+			Determined: s.settled,
+		}, nil
+	case *CancelledState:
+		return StateSnapshot{
+			Kind:   "Cancelled",
+			Bids:   s.bids,
+			Expiry: s.at,
+		}, nil
+	default:
+		return StateSnapshot{}, fmt.Errorf("unknown state type: %T", state)
+	}
+}
+
+// RestoreState recreates an auction state from a snapshot
+func RestoreState(snapshot StateSnapshot) (State, error) {
+	bids := snapshot.Bids
+	if bids == nil {
+		bids = []Bid{}
+	}
+
+	switch snapshot.Kind {
+	case "AwaitingStart", "Ongoing", "Ended":
+		options, err := ParseTimedAscendingOptions(snapshot.Options)
+		if err != nil {
+			return nil, err
+		}
+		switch snapshot.Kind {
+		case "AwaitingStart":
+			return &AwaitingStartState{start: snapshot.Start, startingExpiry: snapshot.Expiry, options: *options}, nil
+		case "Ongoing":
+			return &OngoingState{bids: bids, nextExpiry: snapshot.Expiry, options: *options, proxies: snapshot.Proxies, extensions: snapshot.Extensions}, nil
+		default:
+			return &EndedState{bids: bids, expiry: snapshot.Expiry, options: *options, extensions: snapshot.Extensions}, nil
+		}
+	case "SealedBid":
+		bidsByUser := make(map[UserId]Bid, len(bids))
+		for _, bid := range bids {
+			bidsByUser[bid.Bidder.ID] = bid
+		}
+		return &SealedBidState{
+			bids:       bidsByUser,
+			bidsList:   bids,
+			disclosing: snapshot.Disclosing,
+			expiry:     snapshot.Expiry,
+			options:    SealedBidOptions(snapshot.Options),
+			encrypted:  snapshot.Encrypted,
+			determined: snapshot.Determined,
+		}, nil
+	case "Dutch", "DutchEnded":
+		options, err := ParseDutchOptions(snapshot.Options)
+		if err != nil {
+			return nil, err
+		}
+		state := NewDutchState(snapshot.Start, snapshot.Expiry, *options)
+		state.ended = snapshot.Kind == "DutchEnded"
+		if len(bids) > 0 {
+			bid := bids[0]
+			state.bid = &bid
+		}
+		return state, nil
+	case "MultiUnit", "MultiUnitEnded":
+		options, err := ParseMultiUnitOptions(snapshot.Options)
+		if err != nil {
+			return nil, err
+		}
+		state := NewMultiUnitState(snapshot.Start, snapshot.Expiry, *options)
+		state.bids = bids
+		state.ended = snapshot.Kind == "MultiUnitEnded"
+		state.settled = snapshot.Determined
+		return state, nil
+	case "Cancelled":
+		return &CancelledState{bids: bids, at: snapshot.Expiry}, nil
+	default:
+		return nil, fmt.Errorf("unknown state kind: %s", snapshot.Kind)
+	}
+}
+
+// SnapshotRepository captures all auctions of a repository, ordered by ID
+func SnapshotRepository(repo Repository) ([]AuctionSnapshot, error) {
+	snapshots := make([]AuctionSnapshot, 0, len(repo))
+	for _, entry := range repo {
+		state, err := SnapshotState(entry.State)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, AuctionSnapshot{Auction: entry.Auction, State: state})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Auction.ID < snapshots[j].Auction.ID
+	})
+	return snapshots, nil
+}
+
+// RestoreRepository recreates a repository from auction snapshots
+func RestoreRepository(snapshots []AuctionSnapshot) (Repository, error) {
+	repo := make(Repository, len(snapshots))
+	for _, snapshot := range snapshots {
+		state, err := RestoreState(snapshot.State)
+		if err != nil {
+			return nil, err
+		}
+		repo[snapshot.Auction.ID] = struct {
+			Auction Auction
+			State   State
+		}{
+			Auction: snapshot.Auction,
+			State:   state,
+		}
+	}
+	return repo, nil
+}
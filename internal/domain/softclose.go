@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SoftClose extends a timed ascending auction when a bid arrives in its last
+// moments, so bidders can't snipe it
+type SoftClose struct {
+	// Window is how long before the close a bid extends the auction
+	Window time.Duration `json:"window"`
+	// Extension is how much each late bid pushes the close back by
+	Extension time.Duration `json:"extension"`
+	// MaxExtensions is how many times the auction can be extended
+	MaxExtensions int `json:"maxExtensions"`
+}
+
+// String returns the soft close as window:extension:maxExtensions, with
+// durations in seconds
+func (s SoftClose) String() string {
+	return fmt.Sprintf("%d:%d:%d", int(s.Window.Seconds()), int(s.Extension.Seconds()), s.MaxExtensions)
+}
+
+// ParseSoftClose parses a soft close from its string
+func ParseSoftClose(s string) (SoftClose, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return SoftClose{}, fmt.Errorf("invalid soft close format: %s", s)
+	}
+	var values [3]int
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return SoftClose{}, fmt.Errorf("invalid soft close format: %s", s)
+		}
+		values[i] = value
+	}
+	return SoftClose{
+		Window:        time.Duration(values[0]) * time.Second,
+		Extension:     time.Duration(values[1]) * time.Second,
+		MaxExtensions: values[2],
+	}, nil
+}
+
+// extend returns the close of an auction after a bid at a time, and whether
+// the bid extended it
+func (s SoftClose) extend(expiry time.Time, extensions int, at time.Time) (time.Time, bool) {
+	if s.Window <= 0 || s.Extension <= 0 || extensions >= s.MaxExtensions {
+		return expiry, false
+	}
+	if expiry.Sub(at) > s.Window {
+		return expiry, false
+	}
+	return expiry.Add(s.Extension), true
+}
+
+// validateSoftClose checks a soft close either is off or has a window, an
+// extension and a number of extensions
+func validateSoftClose(s SoftClose) []FieldError {
+	if s.Window < 0 || s.Extension < 0 || s.MaxExtensions < 0 {
+		return []FieldError{{Field: "auction.type", Code: FieldMustNotBeNegative}}
+	}
+	if s != (SoftClose{}) && (s.Window == 0 || s.Extension == 0 || s.MaxExtensions == 0) {
+		return []FieldError{{Field: "auction.type", Code: FieldMalformed}}
+	}
+	return nil
+}
+
+// Extensions returns how many times late bids have extended a timed
+// ascending auction
+func Extensions(state State) int {
+	switch s := state.(type) {
+	case *OngoingState:
+		return s.extensions
+	case *EndedState:
+		return s.extensions
+	}
+	return 0
+}
+
+// ExtensionEvent returns the event reporting late bids extended an auction
+// from its state before them, or nil when they didn't
+func ExtensionEvent(id AuctionId, before, after State, now time.Time) Event {
+	extensions := Extensions(after)
+	if extensions <= Extensions(before) {
+		return nil
+	}
+	return AuctionExtendedEvent{Time: now, AuctionId: id, EndsAt: CurrentExpiry(after), Extensions: extensions}
+}
+
+// AuctionExtendedEvent represents an event indicating late bids extended an
+// auction under its soft close
+type AuctionExtendedEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	// EndsAt is the new close of the auction
+	EndsAt time.Time `json:"endsAt"`
+	// Extensions is how many times the auction has been extended
+	Extensions int `json:"extensions"`
+}
+
+// GetTime returns the time of the event
+func (e AuctionExtendedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for AuctionExtendedEvent
+func (e AuctionExtendedEvent) MarshalJSON() ([]byte, error) {
+	type auctionExtendedEventJSON AuctionExtendedEvent
+	return MarshalEnvelope("AuctionExtended", auctionExtendedEventJSON(e))
+}
@@ -23,3 +23,29 @@ type State interface {
 	// HasEnded returns true if the auction has ended
 	HasEnded() bool
 }
+
+// CurrentExpiry returns when an auction state closes, including the
+// extensions of a timed ascending auction by late bids and the early close
+// of a Dutch auction by its winning bid
+func CurrentExpiry(state State) time.Time {
+	switch s := state.(type) {
+	case *AwaitingStartState:
+		return s.startingExpiry
+	case *OngoingState:
+		return s.nextExpiry
+	case *EndedState:
+		return s.expiry
+	case *SealedBidState:
+		return s.expiry
+	case *DutchState:
+		if s.bid != nil {
+			return s.bid.At
+		}
+		return s.expiry
+	case *MultiUnitState:
+		return s.expiry
+	case *CancelledState:
+		return s.at
+	}
+	return time.Time{}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// AsOf is the point of a temporal query: a time, or a position in the event
+// log when Position is set
+type AsOf struct {
+	Time     time.Time
+	Position int64
+}
+
+// AuctionAsOf reconstructs an auction and its state as they were at a point,
+// from all the events in log order, along with the time the state is as of:
+// the time queried, or the time of the auction's last event up to a
+// position. It returns false if the auction didn't exist yet.
+func AuctionAsOf(events []Event, id AuctionId, asOf AsOf) (Auction, State, time.Time, bool) {
+	var selected []Event
+	var at time.Time
+	for i, event := range events {
+		if asOf.Position > 0 && int64(i)+1 > asOf.Position {
+			break
+		}
+		if asOf.Position == 0 && event.GetTime().After(asOf.Time) {
+			continue
+		}
+		if eventId, ok := EventAuctionId(event); !ok || eventId != id {
+			continue
+		}
+		selected = append(selected, event)
+		if event.GetTime().After(at) {
+			at = event.GetTime()
+		}
+	}
+	if asOf.Position == 0 {
+		at = asOf.Time
+	}
+
+	entry, ok := ApplyEvents(Repository{}, selected)[id]
+	if !ok {
+		return Auction{}, nil, time.Time{}, false
+	}
+	return entry.Auction, entry.State.Increment(at), at, true
+}
@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Tender makes a sealed bid auction end-to-end encrypted: bidders encrypt
+// their amounts to the public key with RSA-OAEP and SHA-256, and the private
+// key is split among custodians so that the bids can only be revealed after
+// the close, once Threshold of them submit their key shares
+type Tender struct {
+	// PublicKey is the base64 PKIX encoding of an RSA public key
+	PublicKey  string   `json:"publicKey"`
+	Threshold  int      `json:"threshold"`
+	Custodians []UserId `json:"custodians"`
+
+	// Shares are the key shares custodians submitted, base64 encoded
+	Shares map[UserId]string `json:"shares,omitempty"`
+	// Revealed tells whether the bids have been decrypted
+	Revealed bool `json:"revealed,omitempty"`
+}
+
+// IsCustodian tells whether a user holds a key share of the tender
+func (t Tender) IsCustodian(userId UserId) bool {
+	for _, custodian := range t.Custodians {
+		if custodian == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// withShare returns a copy of the tender with a custodian's share replaced
+func (t Tender) withShare(custodian UserId, share string) *Tender {
+	shares := make(map[UserId]string, len(t.Shares)+1)
+	for id, s := range t.Shares {
+		shares[id] = s
+	}
+	shares[custodian] = share
+	t.Shares = shares
+	return &t
+}
+
+// AwaitingReveal tells whether the auction is a tender whose bids are still
+// encrypted, so they have no amounts yet
+func (a Auction) AwaitingReveal() bool {
+	return a.Tender != nil && !a.Tender.Revealed
+}
+
+// parseTenderKey decodes the public key of a tender
+func parseTenderKey(publicKey string) (*rsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, NewInvalidTenderError("the public key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// validateTender checks the tender of a new auction, if it has one
+func validateTender(auction Auction) error {
+	t := auction.Tender
+	if t == nil {
+		return nil
+	}
+	if auction.Type.Type != SingleSealedBid {
+		return NewInvalidTenderError("only sealed bid auctions can be tenders")
+	}
+	if _, err := parseTenderKey(t.PublicKey); err != nil {
+		return NewInvalidTenderError("invalid public key")
+	}
+	seen := make(map[UserId]bool, len(t.Custodians))
+	for _, custodian := range t.Custodians {
+		if custodian == "" || seen[custodian] {
+			return NewInvalidTenderError("custodians must be distinct users")
+		}
+		seen[custodian] = true
+	}
+	if t.Threshold < 1 || t.Threshold > len(t.Custodians) || len(t.Custodians) > 255 {
+		return NewInvalidTenderError("the threshold must be between 1 and the number of custodians")
+	}
+	if len(t.Shares) > 0 || t.Revealed {
+		return NewInvalidTenderError("a new tender has no shares")
+	}
+	return nil
+}
+
+// validateTenderBid checks that bids on tenders are encrypted, and only those
+func validateTenderBid(auction Auction, bid Bid) error {
+	if auction.Tender == nil {
+		if bid.Sealed != "" {
+			return NewInvalidTenderError("the auction doesn't accept encrypted bids")
+		}
+		return nil
+	}
+	if bid.Sealed == "" || bid.Amount != 0 {
+		return NewInvalidTenderError("bids must be encrypted to the public key")
+	}
+	if _, err := base64.StdEncoding.DecodeString(bid.Sealed); err != nil {
+		return NewInvalidTenderError("the encrypted bid is not base64")
+	}
+	return nil
+}
+
+// SubmitKeyShareCommand represents a command to submit a custodian's key
+// share of a closed tender
+type SubmitKeyShareCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	Custodian UserId    `json:"custodian"`
+	Share     string    `json:"share"`
+}
+
+// GetTime returns the time of the command
+func (c SubmitKeyShareCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for SubmitKeyShareCommand
+func (c SubmitKeyShareCommand) MarshalJSON() ([]byte, error) {
+	type submitKeyShareCommandJSON SubmitKeyShareCommand
+	return MarshalEnvelope("SubmitKeyShare", submitKeyShareCommandJSON(c))
+}
+
+// KeyShareSubmittedEvent represents an event indicating a custodian
+// submitted their key share. A later share of the same custodian replaces
+// theirs, to recover from a mistyped share.
+type KeyShareSubmittedEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	Custodian UserId    `json:"custodian"`
+	Share     string    `json:"share"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e KeyShareSubmittedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for KeyShareSubmittedEvent
+func (e KeyShareSubmittedEvent) MarshalJSON() ([]byte, error) {
+	type keyShareSubmittedEventJSON KeyShareSubmittedEvent
+	return MarshalEnvelope("KeyShareSubmitted", keyShareSubmittedEventJSON(e))
+}
+
+// RevealTenderCommand represents a command to decrypt the bids of a tender
+// once a quorum of custodians submitted their shares
+type RevealTenderCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+}
+
+// GetTime returns the time of the command
+func (c RevealTenderCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for RevealTenderCommand
+func (c RevealTenderCommand) MarshalJSON() ([]byte, error) {
+	type revealTenderCommandJSON RevealTenderCommand
+	return MarshalEnvelope("RevealTender", revealTenderCommandJSON(c))
+}
+
+// TenderRevealedEvent represents an event carrying the decrypted amounts of
+// the bids of a tender, so the reveal doesn't need decrypting again
+type TenderRevealedEvent struct {
+	Time      time.Time        `json:"at"`
+	AuctionId AuctionId        `json:"auctionId"`
+	Amounts   map[UserId]int64 `json:"amounts"`
+	// Invalid are the bidders whose bids couldn't be decrypted to an amount
+	Invalid []UserId `json:"invalid,omitempty"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e TenderRevealedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for TenderRevealedEvent
+func (e TenderRevealedEvent) MarshalJSON() ([]byte, error) {
+	type tenderRevealedEventJSON TenderRevealedEvent
+	return MarshalEnvelope("TenderRevealed", tenderRevealedEventJSON(e))
+}
+
+// closedTender returns the entry of a tender that closed and isn't revealed
+func closedTender(repo Repository, id AuctionId, now time.Time) (Auction, State, error) {
+	entry, exists := repo[id]
+	if !exists || entry.Auction.Tender == nil {
+		return Auction{}, nil, NewAuctionNotFoundError(id)
+	}
+	state := entry.State.Increment(now)
+	if !state.HasEnded() {
+		return Auction{}, nil, NewInvalidTenderError("the tender has not closed")
+	}
+	if entry.Auction.Tender.Revealed {
+		return Auction{}, nil, NewInvalidTenderError("the tender has been revealed")
+	}
+	return entry.Auction, state, nil
+}
+
+// handleSubmitKeyShare records a custodian's share of a closed tender
+func handleSubmitKeyShare(c SubmitKeyShareCommand, repo Repository) (Event, Repository, error) {
+	auction, state, err := closedTender(repo, c.AuctionId, c.Time)
+	if err != nil {
+		return nil, repo, err
+	}
+	if !auction.Tender.IsCustodian(c.Custodian) {
+		return nil, repo, NewNotACustodianError(c.AuctionId)
+	}
+	share, err := base64.StdEncoding.DecodeString(c.Share)
+	if err != nil || len(share) < 2 || share[0] == 0 {
+		return nil, repo, NewInvalidTenderError("invalid key share")
+	}
+
+	newRepo := copyRepository(repo)
+	auction.Tender = auction.Tender.withShare(c.Custodian, c.Share)
+	newRepo[c.AuctionId] = struct {
+		Auction Auction
+		State   State
+	}{Auction: auction, State: state}
+	return KeyShareSubmittedEvent{
+		Time:      c.Time,
+		AuctionId: c.AuctionId,
+		Custodian: c.Custodian,
+		Share:     c.Share,
+	}, newRepo, nil
+}
+
+// handleRevealTender combines the submitted shares into the private key and
+// decrypts the bids of a tender
+func handleRevealTender(c RevealTenderCommand, repo Repository) (Event, Repository, error) {
+	auction, state, err := closedTender(repo, c.AuctionId, c.Time)
+	if err != nil {
+		return nil, repo, err
+	}
+	tender := auction.Tender
+	if len(tender.Shares) < tender.Threshold {
+		return nil, repo, NewInvalidTenderError("not enough key shares")
+	}
+
+	key, err := combineTenderKey(*tender)
+	if err != nil {
+		return nil, repo, err
+	}
+
+	event := TenderRevealedEvent{Time: c.Time, AuctionId: c.AuctionId, Amounts: make(map[UserId]int64)}
+	for _, bid := range state.GetBids() {
+		amount, ok := decryptTenderBid(key, bid.Sealed)
+		if !ok {
+			event.Invalid = append(event.Invalid, bid.Bidder.ID)
+			continue
+		}
+		event.Amounts[bid.Bidder.ID] = amount
+	}
+	sort.Slice(event.Invalid, func(i, j int) bool {
+		return event.Invalid[i] < event.Invalid[j]
+	})
+
+	newRepo := ApplyEvents(copyRepository(repo), []Event{event})
+	return event, newRepo, nil
+}
+
+// combineTenderKey recovers the private key of a tender from its shares,
+// checking it matches the public key
+func combineTenderKey(t Tender) (*rsa.PrivateKey, error) {
+	custodians := make([]UserId, 0, len(t.Shares))
+	for custodian := range t.Shares {
+		custodians = append(custodians, custodian)
+	}
+	sort.Slice(custodians, func(i, j int) bool {
+		return custodians[i] < custodians[j]
+	})
+
+	shares := make([][]byte, 0, len(custodians))
+	for _, custodian := range custodians {
+		share, err := base64.StdEncoding.DecodeString(t.Shares[custodian])
+		if err != nil {
+			return nil, NewInvalidTenderError("invalid key share")
+		}
+		shares = append(shares, share)
+	}
+
+	invalid := NewInvalidTenderError("the key shares don't recover the private key, custodians may resubmit theirs")
+	der, err := CombineShares(shares)
+	if err != nil {
+		return nil, invalid
+	}
+	key, err := x509.ParsePKCS1PrivateKey(der)
+	if err != nil {
+		return nil, invalid
+	}
+	publicKey, err := parseTenderKey(t.PublicKey)
+	if err != nil || publicKey.N.Cmp(key.N) != 0 || publicKey.E != key.E {
+		return nil, invalid
+	}
+	return key, nil
+}
+
+// decryptTenderBid decrypts the amount of an encrypted bid
+func decryptTenderBid(key *rsa.PrivateKey, sealed string) (int64, bool) {
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return 0, false
+	}
+	plaintext, err := rsa.DecryptOAEP(sha256.New(), nil, key, ciphertext, nil)
+	if err != nil {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(string(plaintext), 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+	return amount, true
+}
+
+// revealBids returns the state of a tender with the revealed amounts of its
+// bids, leaving out the invalid ones
+func revealBids(auction Auction, state State, amounts map[UserId]int64) State {
+	revealed := NewSealedBidState(auction.Expiry, SealedBidOptions(auction.Type.Options))
+	var next State = revealed
+	bids := append([]Bid(nil), state.GetBids()...)
+	sort.Slice(bids, func(i, j int) bool {
+		return bids[i].At.Before(bids[j].At)
+	})
+	for _, bid := range bids {
+		amount, ok := amounts[bid.Bidder.ID]
+		if !ok {
+			continue
+		}
+		bid.Amount = amount
+		next, _ = next.AddBid(bid)
+	}
+	return next.Increment(auction.Expiry)
+}
@@ -19,11 +19,45 @@ type TimedAscendingOptions struct {
 	// If no competing bidder challenges the standing bid within a given time frame,
 	// the standing bid becomes the winner
 	TimeFrame time.Duration `json:"timeFrame"`
+
+	// A bidder may end the auction at once by paying the buy now price, zero
+	// when the auction can't be bought now
+	BuyNowPrice int64 `json:"buyNowPrice"`
+
+	// The buy now price is withdrawn once a bid exceeds this threshold, so
+	// zero withdraws it at the first bid
+	BuyNowThreshold int64 `json:"buyNowThreshold"`
+
+	// The bands of the minimum raise by the amount of the highest bid, on
+	// top of MinRaise
+	Increments IncrementTable `json:"increments,omitempty"`
+
+	// Bids close to the end extend the auction, when set
+	SoftClose SoftClose `json:"softClose"`
+}
+
+// MinimumRaise returns the least amount a bid must raise the highest bid by
+func (o TimedAscendingOptions) MinimumRaise(highest int64) int64 {
+	if raise := o.Increments.Increment(highest); raise > o.MinRaise {
+		return raise
+	}
+	return o.MinRaise
 }
 
-// String returns a string representation of the options
+// String returns a string representation of the options, with the buy now
+// price and threshold only when there is a buy now price, an increment table
+// or a soft close, which comes last
 func (o TimedAscendingOptions) String() string {
 	seconds := int(o.TimeFrame.Seconds())
+	if o.SoftClose != (SoftClose{}) {
+		return fmt.Sprintf("English|%d|%d|%d|%d|%d|%s|%s", o.ReservePrice, o.MinRaise, seconds, o.BuyNowPrice, o.BuyNowThreshold, o.Increments, o.SoftClose)
+	}
+	if len(o.Increments) > 0 {
+		return fmt.Sprintf("English|%d|%d|%d|%d|%d|%s", o.ReservePrice, o.MinRaise, seconds, o.BuyNowPrice, o.BuyNowThreshold, o.Increments)
+	}
+	if o.BuyNowPrice != 0 {
+		return fmt.Sprintf("English|%d|%d|%d|%d|%d", o.ReservePrice, o.MinRaise, seconds, o.BuyNowPrice, o.BuyNowThreshold)
+	}
 	return fmt.Sprintf("English|%d|%d|%d", o.ReservePrice, o.MinRaise, seconds)
 }
 
@@ -31,7 +65,7 @@ func (o TimedAscendingOptions) String() string {
 func ParseTimedAscendingOptions(s string) (*TimedAscendingOptions, error) {
 	// Split the string by '|'
 	parts := strings.Split(s, "|")
-	if len(parts) != 4 || parts[0] != "English" {
+	if (len(parts) != 4 && len(parts) != 6 && len(parts) != 7 && len(parts) != 8) || parts[0] != "English" {
 		return nil, fmt.Errorf("invalid timed ascending options format: %s", s)
 	}
 
@@ -53,11 +87,33 @@ func ParseTimedAscendingOptions(s string) (*TimedAscendingOptions, error) {
 		return nil, fmt.Errorf("invalid time frame format: %s", parts[3])
 	}
 
-	return &TimedAscendingOptions{
+	options := &TimedAscendingOptions{
 		ReservePrice: reserveAmount,
 		MinRaise:     minRaiseAmount,
 		TimeFrame:    time.Duration(seconds) * time.Second,
-	}, nil
+	}
+	if len(parts) >= 6 {
+		// Parse buy now price and threshold
+		if options.BuyNowPrice, err = strconv.ParseInt(parts[4], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid buy now price format: %s", parts[4])
+		}
+		if options.BuyNowThreshold, err = strconv.ParseInt(parts[5], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid buy now threshold format: %s", parts[5])
+		}
+	}
+	if len(parts) >= 7 && (len(parts) == 7 || parts[6] != "") {
+		// Parse increment table, which may be left empty before a soft close
+		if options.Increments, err = ParseIncrementTable(parts[6]); err != nil {
+			return nil, err
+		}
+	}
+	if len(parts) == 8 {
+		// Parse soft close
+		if options.SoftClose, err = ParseSoftClose(parts[7]); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
 }
 
 // DefaultTimedAscendingOptions creates default options
@@ -89,15 +145,20 @@ type OngoingState struct {
 	bids       []Bid
 	nextExpiry time.Time
 	options    TimedAscendingOptions
+	// proxies are the maximum bids the proxy bidding engine bids up to
+	proxies []ProxyBid
+	// extensions counts the extensions by late bids under the soft close
+	extensions int
 }
 
 func (s *OngoingState) isTimedAscendingState() {}
 
 // EndedState represents a timed ascending auction that has ended
 type EndedState struct {
-	bids    []Bid
-	expiry  time.Time
-	options TimedAscendingOptions
+	bids       []Bid
+	expiry     time.Time
+	options    TimedAscendingOptions
+	extensions int
 }
 
 func (s *EndedState) isTimedAscendingState() {}
@@ -162,9 +223,10 @@ func (s *OngoingState) Increment(now time.Time) State {
 	if now.After(s.nextExpiry) || now.Equal(s.nextExpiry) {
 		// Transition to EndedState
 		return &EndedState{
-			bids:    s.bids,
-			expiry:  s.nextExpiry,
-			options: s.options,
+			bids:       s.bids,
+			expiry:     s.nextExpiry,
+			options:    s.options,
+			extensions: s.extensions,
 		}
 	}
 	// Stay in OngoingState
@@ -186,6 +248,13 @@ func (s *OngoingState) AddBid(bid Bid) (State, error) {
 	if now.Add(s.options.TimeFrame).After(newExpiry) {
 		newExpiry = now.Add(s.options.TimeFrame)
 	}
+	extensions := s.extensions
+	if extended, ok := s.options.SoftClose.extend(s.nextExpiry, extensions, now); ok {
+		if extended.After(newExpiry) {
+			newExpiry = extended
+		}
+		extensions++
+	}
 
 	if len(s.bids) == 0 {
 		// First bid is always accepted
@@ -193,13 +262,15 @@ func (s *OngoingState) AddBid(bid Bid) (State, error) {
 			bids:       append([]Bid{bid}, s.bids...),
 			nextExpiry: newExpiry,
 			options:    s.options,
+			proxies:    s.proxies,
+			extensions: extensions,
 		}, nil
 	}
 
 	// Check if bid is higher than the current highest bid + minimum raise
 	highestBid := s.bids[0]
 	highestAmount := highestBid.Amount
-	minRaiseAmount := s.options.MinRaise
+	minRaiseAmount := s.options.MinimumRaise(highestAmount)
 
 	// Calculate minimum acceptable bid
 	minAcceptableBid := highestAmount + minRaiseAmount
@@ -211,6 +282,8 @@ func (s *OngoingState) AddBid(bid Bid) (State, error) {
 			bids:       append([]Bid{bid}, s.bids...),
 			nextExpiry: newExpiry,
 			options:    s.options,
+			proxies:    s.proxies,
+			extensions: extensions,
 		}, nil
 	}
 
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// TranslationSeller is the source of translations supplied by the seller
+const TranslationSeller = "seller"
+
+// Translation is the content of a listing in a language
+type Translation struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	// Source is TranslationSeller or the name of a translation provider
+	Source string `json:"source"`
+}
+
+// TranslationProvider translates listings into other languages
+type TranslationProvider interface {
+	TranslateListing(auction Auction, language string) (Translation, error)
+}
+
+// ValidLanguageTag tells whether a language tag is well-formed, such as "sv"
+// or "en-GB": a primary language of 2 to 3 letters followed by subtags of
+// 1 to 8 letters or digits
+func ValidLanguageTag(tag string) bool {
+	parts := strings.Split(tag, "-")
+	for i, part := range parts {
+		if len(part) < 1 || len(part) > 8 {
+			return false
+		}
+		if i == 0 && (len(part) < 2 || len(part) > 3) {
+			return false
+		}
+		for _, c := range part {
+			isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+			isDigit := c >= '0' && c <= '9'
+			if !isLetter && !(isDigit && i > 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateTranslation returns an InvalidTranslation error for malformed translations
+func validateTranslation(language, title string) error {
+	if !ValidLanguageTag(language) {
+		return NewInvalidTranslationError("invalid language tag: " + language)
+	}
+	if strings.TrimSpace(title) == "" {
+		return NewInvalidTranslationError("title is required")
+	}
+	return nil
+}
+
+// ValidateTranslations checks the translations supplied with a new listing
+func ValidateTranslations(translations map[string]string) error {
+	for language, title := range translations {
+		if err := validateTranslation(language, title); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LocalizedTitle returns the title of the auction in the first of the
+// preferred languages it's available in, matching "sv-SE" to "sv" too, along
+// with the chosen language. It returns the original title and an empty
+// language when no translation matches.
+func (a Auction) LocalizedTitle(preferred []string) (string, string) {
+	for _, language := range preferred {
+		for _, candidate := range []string{language, strings.SplitN(language, "-", 2)[0]} {
+			for tag, title := range a.Translations {
+				if strings.EqualFold(tag, candidate) {
+					return title, tag
+				}
+			}
+		}
+	}
+	return a.Title, ""
+}
+
+// withTranslation returns a copy of the auction with a translation added
+func (a Auction) withTranslation(language, title string) Auction {
+	translations := make(map[string]string, len(a.Translations)+1)
+	for tag, t := range a.Translations {
+		translations[tag] = t
+	}
+	translations[language] = title
+	a.Translations = translations
+	return a
+}
+
+// TranslateListingCommand represents a command to add or replace the
+// translation of a listing
+type TranslateListingCommand struct {
+	Time        time.Time   `json:"at"`
+	AuctionId   AuctionId   `json:"auctionId"`
+	Translation Translation `json:"translation"`
+}
+
+// GetTime returns the time of the command
+func (c TranslateListingCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for TranslateListingCommand
+func (c TranslateListingCommand) MarshalJSON() ([]byte, error) {
+	type translateListingCommandJSON TranslateListingCommand
+	return MarshalEnvelope("TranslateListing", translateListingCommandJSON(c))
+}
+
+// ListingTranslatedEvent represents an event indicating a listing was translated
+type ListingTranslatedEvent struct {
+	Time        time.Time   `json:"at"`
+	AuctionId   AuctionId   `json:"auctionId"`
+	Translation Translation `json:"translation"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e ListingTranslatedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for ListingTranslatedEvent
+func (e ListingTranslatedEvent) MarshalJSON() ([]byte, error) {
+	type listingTranslatedEventJSON ListingTranslatedEvent
+	return MarshalEnvelope("ListingTranslated", listingTranslatedEventJSON(e))
+}
+
+// handleTranslateListing adds a translation to an auction of the repository
+func handleTranslateListing(c TranslateListingCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	if err := validateTranslation(c.Translation.Language, c.Translation.Title); err != nil {
+		return nil, repo, err
+	}
+
+	newRepo := copyRepository(repo)
+	entry.Auction = entry.Auction.withTranslation(c.Translation.Language, c.Translation.Title)
+	newRepo[c.AuctionId] = entry
+	return ListingTranslatedEvent{
+		Time:        c.Time,
+		AuctionId:   c.AuctionId,
+		Translation: c.Translation,
+	}, newRepo, nil
+}
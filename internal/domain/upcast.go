@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EventUpcaster transforms the JSON fields of an event from one version of
+// its shape to the next
+type EventUpcaster func(fields map[string]json.RawMessage) (map[string]json.RawMessage, error)
+
+// eventUpcasters holds the upcasters of each event $type, where the upcaster
+// at index i transforms version i+1 to version i+2
+var eventUpcasters = map[string][]EventUpcaster{}
+
+// RegisterEventUpcaster registers the transform of an event $type from a
+// version to the next. Upcasters of a type must be registered in version
+// order, starting from version 1. It is meant to be called from init
+// functions.
+func RegisterEventUpcaster(typeName string, fromVersion int, upcast EventUpcaster) {
+	if fromVersion != len(eventUpcasters[typeName])+1 {
+		panic(fmt.Sprintf("upcaster of %s from version %d registered out of order", typeName, fromVersion))
+	}
+	eventUpcasters[typeName] = append(eventUpcasters[typeName], upcast)
+}
+
+// EventVersion returns the current version of the shape of an event $type.
+// Events without upcasters are at version 1.
+func EventVersion(typeName string) int {
+	return len(eventUpcasters[typeName]) + 1
+}
+
+// MarshalEvent marshals an event, stamping it with the current version of its
+// shape when the type has been versioned
+func MarshalEvent(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	typeName, err := EnvelopeType(data)
+	if err != nil {
+		return nil, err
+	}
+	if EventVersion(typeName) == 1 {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["$version"], _ = json.Marshal(EventVersion(typeName))
+	return json.Marshal(fields)
+}
+
+// upcastEvent transforms stored event JSON to the current shape of its type.
+// Events without a $version are at version 1.
+func upcastEvent(typeName string, data []byte) ([]byte, error) {
+	upcasters := eventUpcasters[typeName]
+	if len(upcasters) == 0 {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	version := 1
+	if raw, ok := fields["$version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("invalid version of %s event: %v", typeName, err)
+		}
+	}
+	if version > len(upcasters)+1 {
+		return nil, fmt.Errorf("%s event has unknown version %d", typeName, version)
+	}
+	if version == len(upcasters)+1 {
+		return data, nil
+	}
+
+	for v := version; v <= len(upcasters); v++ {
+		var err error
+		if fields, err = upcasters[v-1](fields); err != nil {
+			return nil, fmt.Errorf("error upcasting %s event from version %d: %v", typeName, v, err)
+		}
+	}
+	fields["$version"], _ = json.Marshal(len(upcasters) + 1)
+	return json.Marshal(fields)
+}
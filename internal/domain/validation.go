@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// Codes of the field errors of invalid commands
+const (
+	FieldRequired          = "required"
+	FieldMustBePositive    = "mustBePositive"
+	FieldMustNotBeNegative = "mustNotBeNegative"
+	FieldMustBeAfter       = "mustBeAfter"
+	FieldUnknown           = "unknown"
+	FieldMalformed         = "malformed"
+	FieldTooLong           = "tooLong"
+	FieldTooMany           = "tooMany"
+	FieldTooLarge          = "tooLarge"
+)
+
+// FieldError is a problem with a field of a command, identified by its JSON
+// path in the command, such as "auction.title". Like DomainError it holds a
+// code rather than a message, with the related field for FieldMustBeAfter.
+type FieldError struct {
+	Field string `json:"field"`
+	Code  string `json:"code"`
+	Ref   string `json:"ref,omitempty"`
+}
+
+// knownCurrencies are the currencies auctions may be listed in
+var knownCurrencies = map[Currency]bool{VAC: true, SEK: true, DKK: true}
+
+// ValidateCommand checks the fields of a command on their own, before it's
+// handled against the auctions, returning an InvalidCommand error listing
+// every invalid field
+func ValidateCommand(cmd Command) error {
+	var errors []FieldError
+	switch c := cmd.(type) {
+	case AddAuctionCommand:
+		errors = validateAuction(c.Auction)
+	case PlaceBidCommand:
+		errors = validateBid(c.Bid)
+	case SendContactMessageCommand:
+		errors = validateContactMessage(c)
+	case SetMaxBidCommand:
+		errors = validateMaxBid(c)
+	case AmendAuctionCommand:
+		errors = validateAmendment(c)
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	return NewInvalidCommandError(commandName(cmd), errors)
+}
+
+// ValidateCommands rejects the commands ValidateCommand finds invalid
+func ValidateCommands() CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, cmd Command, repo Repository) (Event, Repository, error) {
+			if err := ValidateCommand(cmd); err != nil {
+				return nil, repo, err
+			}
+			return next(ctx, cmd, repo)
+		}
+	}
+}
+
+// validateAuction checks the fields of a new auction
+func validateAuction(auction Auction) []FieldError {
+	var errors []FieldError
+	if strings.TrimSpace(auction.Title) == "" {
+		errors = append(errors, FieldError{Field: "auction.title", Code: FieldRequired})
+	}
+	if auction.Seller.ID == "" {
+		errors = append(errors, FieldError{Field: "auction.user", Code: FieldRequired})
+	}
+	if !auction.Expiry.After(auction.StartsAt) {
+		errors = append(errors, FieldError{Field: "auction.expiry", Code: FieldMustBeAfter, Ref: "auction.startsAt"})
+	}
+	if !knownCurrencies[auction.Currency] {
+		errors = append(errors, FieldError{Field: "auction.currency", Code: FieldUnknown})
+	}
+	if auction.Type.Type == TimedAscending {
+		options, err := ParseTimedAscendingOptions(auction.Type.Options)
+		if err != nil {
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMalformed})
+		} else if options.ReservePrice < 0 || options.MinRaise < 0 || options.TimeFrame < 0 || options.BuyNowPrice < 0 || options.BuyNowThreshold < 0 {
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMustNotBeNegative})
+		} else if options.BuyNowPrice > 0 && (options.BuyNowThreshold >= options.BuyNowPrice || options.BuyNowPrice <= options.ReservePrice) {
+			// Buying now must outbid the threshold and exceed the reserve
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMalformed})
+		} else {
+			errors = append(errors, validateIncrementTable(options.Increments)...)
+			errors = append(errors, validateSoftClose(options.SoftClose)...)
+		}
+	}
+	if auction.Type.Type == MultiUnit {
+		options, err := ParseMultiUnitOptions(auction.Type.Options)
+		if err != nil {
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMalformed})
+		} else if options.Units <= 0 {
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMustBePositive})
+		}
+	}
+	if auction.Type.Type == Dutch {
+		options, err := ParseDutchOptions(auction.Type.Options)
+		if err != nil || options.Floor > options.StartPrice {
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMalformed})
+		} else if options.StartPrice <= 0 || options.Decrement <= 0 || options.Interval <= 0 {
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMustBePositive})
+		} else if options.Floor < 0 {
+			errors = append(errors, FieldError{Field: "auction.type", Code: FieldMustNotBeNegative})
+		}
+	}
+	return errors
+}
+
+// validateMaxBid checks the fields of a maximum bid
+func validateMaxBid(c SetMaxBidCommand) []FieldError {
+	var errors []FieldError
+	if c.Bidder.ID == "" {
+		errors = append(errors, FieldError{Field: "user", Code: FieldRequired})
+	}
+	if c.Max <= 0 {
+		errors = append(errors, FieldError{Field: "max", Code: FieldMustBePositive})
+	}
+	return errors
+}
+
+// validateBid checks the fields of a bid. The amount of an encrypted bid on
+// a tender is only known once revealed.
+func validateBid(bid Bid) []FieldError {
+	var errors []FieldError
+	if bid.Bidder.ID == "" {
+		errors = append(errors, FieldError{Field: "bid.user", Code: FieldRequired})
+	}
+	if bid.Sealed == "" && bid.Amount <= 0 {
+		errors = append(errors, FieldError{Field: "bid.amount", Code: FieldMustBePositive})
+	}
+	return errors
+}
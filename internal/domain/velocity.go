@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+)
+
+// BidVelocityBucket counts the bids placed during one interval
+type BidVelocityBucket struct {
+	Start         time.Time `json:"start"`
+	Bids          int       `json:"bids"`
+	UniqueBidders int       `json:"uniqueBidders"`
+}
+
+// BidVelocity is a time series of the bidding activity of an auction
+type BidVelocity struct {
+	Interval      time.Duration       `json:"-"`
+	Buckets       []BidVelocityBucket `json:"buckets"`
+	TotalBids     int                 `json:"totalBids"`
+	UniqueBidders int                 `json:"uniqueBidders"`
+}
+
+// ComputeBidVelocity buckets bids into intervals from `from` up to `to`.
+// Bids outside the range are only counted in the totals.
+func ComputeBidVelocity(bids []Bid, from, to time.Time, interval time.Duration) BidVelocity {
+	velocity := BidVelocity{
+		Interval:  interval,
+		Buckets:   []BidVelocityBucket{},
+		TotalBids: len(bids),
+	}
+
+	bucketBidders := []map[UserId]bool{}
+	for start := from; start.Before(to); start = start.Add(interval) {
+		velocity.Buckets = append(velocity.Buckets, BidVelocityBucket{Start: start})
+		bucketBidders = append(bucketBidders, make(map[UserId]bool))
+	}
+
+	bidders := make(map[UserId]bool)
+	for _, bid := range bids {
+		bidders[bid.Bidder.ID] = true
+		if bid.At.Before(from) || !bid.At.Before(to) {
+			continue
+		}
+		i := int(bid.At.Sub(from) / interval)
+		velocity.Buckets[i].Bids++
+		if !bucketBidders[i][bid.Bidder.ID] {
+			bucketBidders[i][bid.Bidder.ID] = true
+			velocity.Buckets[i].UniqueBidders++
+		}
+	}
+	velocity.UniqueBidders = len(bidders)
+
+	return velocity
+}
+
+// IsHot returns true if at least threshold bids were placed in the latest interval
+func (v BidVelocity) IsHot(threshold int) bool {
+	if len(v.Buckets) == 0 || threshold <= 0 {
+		return false
+	}
+	return v.Buckets[len(v.Buckets)-1].Bids >= threshold
+}
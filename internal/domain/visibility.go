@@ -0,0 +1,49 @@
+package domain
+
+// Visibility controls who can find and see an auction
+type Visibility string
+
+const (
+	// VisibilityPublic auctions are listed for everyone
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted auctions are left out of listings but can be seen
+	// by anyone with a link
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityPrivate auctions can only be seen and bid on by their
+	// invitees, besides the seller and support
+	VisibilityPrivate Visibility = "private"
+)
+
+// ValidVisibility tells whether a visibility is known, the empty visibility
+// of auctions listed before visibilities standing for public
+func ValidVisibility(v Visibility) bool {
+	switch v {
+	case "", VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// Listed tells whether the auction shows up in listings and searches
+func (a Auction) Listed() bool {
+	return a.Visibility == "" || a.Visibility == VisibilityPublic
+}
+
+// VisibleTo tells whether a user, nil when anonymous, may see the auction
+func (a Auction) VisibleTo(user *User) bool {
+	if a.Visibility != VisibilityPrivate {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	if user.ID == a.Seller.ID || user.Type == "Support" {
+		return true
+	}
+	for _, invitee := range a.Invitees {
+		if invitee == user.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"time"
+)
+
+// DetermineWinnerCommand represents a command to record the result of a
+// sealed bid or multi-unit auction once it has closed
+type DetermineWinnerCommand struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+}
+
+// GetTime returns the time of the command
+func (c DetermineWinnerCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for DetermineWinnerCommand
+func (c DetermineWinnerCommand) MarshalJSON() ([]byte, error) {
+	type determineWinnerCommandJSON DetermineWinnerCommand
+	return MarshalEnvelope("DetermineWinner", determineWinnerCommandJSON(c))
+}
+
+// WinnerDeterminedEvent represents an event recording the result of a sealed
+// bid auction at its close, when its bids are disclosed. Winner is empty
+// when nobody bid.
+type WinnerDeterminedEvent struct {
+	Time      time.Time `json:"at"`
+	AuctionId AuctionId `json:"auctionId"`
+	Winner    UserId    `json:"winner,omitempty"`
+	// Price is what the winner pays, the second highest bid of a Vickrey
+	// auction
+	Price int64 `json:"price,omitempty"`
+	Bids  int   `json:"bids"`
+
+	Metadata *EventMetadata `json:"$meta,omitempty"`
+}
+
+// GetTime returns the time of the event
+func (e WinnerDeterminedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for WinnerDeterminedEvent
+func (e WinnerDeterminedEvent) MarshalJSON() ([]byte, error) {
+	type winnerDeterminedEventJSON WinnerDeterminedEvent
+	return MarshalEnvelope("WinnerDetermined", winnerDeterminedEventJSON(e))
+}
+
+// AwaitingWinner tells whether a sealed bid auction has closed, with its
+// bids revealed, or a multi-unit auction has closed, and its result is not
+// recorded yet
+func AwaitingWinner(auction Auction, state State, now time.Time) bool {
+	switch s := state.Increment(now).(type) {
+	case *SealedBidState:
+		return s.HasEnded() && !s.determined && !auction.AwaitingReveal()
+	case *MultiUnitState:
+		return s.HasEnded() && !s.settled
+	}
+	return false
+}
+
+// handleDetermineWinner records the result of a closed sealed bid auction,
+// or the allocations of a closed multi-unit auction
+func handleDetermineWinner(c DetermineWinnerCommand, repo Repository) (Event, Repository, error) {
+	entry, exists := repo[c.AuctionId]
+	if !exists {
+		return nil, repo, NewAuctionNotFoundError(c.AuctionId)
+	}
+	if !AwaitingWinner(entry.Auction, entry.State, c.Time) {
+		return nil, repo, NewWinnerNotDeterminableError(c.AuctionId)
+	}
+
+	state := entry.State.Increment(c.Time)
+	if multiUnit, ok := state.(*MultiUnitState); ok {
+		event := UnitsAllocatedEvent{
+			Time:        c.Time,
+			AuctionId:   c.AuctionId,
+			Settlement:  multiUnit.options.Settlement,
+			Allocations: Allocations(multiUnit),
+			Bids:        len(multiUnit.bids),
+		}
+		return event, ApplyEvents(repo, []Event{event}), nil
+	}
+	event := WinnerDeterminedEvent{Time: c.Time, AuctionId: c.AuctionId, Bids: len(state.GetBids())}
+	if price, winner, ok := state.TryGetAmountAndWinner(); ok {
+		event.Winner = winner
+		event.Price = price
+	}
+	return event, ApplyEvents(repo, []Event{event}), nil
+}
+
+// determineWinner marks the winner of a sealed bid auction as determined
+func determineWinner(state State) State {
+	sealed, ok := state.(*SealedBidState)
+	if !ok {
+		return state
+	}
+	determined := *sealed
+	determined.determined = true
+	return &determined
+}
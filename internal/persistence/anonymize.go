@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+
+	"auction-site-go/internal/domain"
+)
+
+// AnonymizeOptions configures the export of an anonymized dataset
+type AnonymizeOptions struct {
+	// Salt keys the pseudonyms, it must be kept secret for them to be unlinkable
+	Salt string
+
+	// SampleRate is the fraction of auctions exported, between 0 and 1
+	SampleRate float64
+
+	// AmountBucket is the width amounts are rounded down to
+	AmountBucket int64
+
+	// K is the minimum number of bids sharing the same auction type, currency
+	// and amount bucket, smaller groups are suppressed
+	K int
+}
+
+// AnonymizedBid is a bid in a research-friendly form: IDs are pseudonymized,
+// times are relative to the start of the auction and amounts are bucketed
+type AnonymizedBid struct {
+	Auction         string `json:"auction"`
+	AuctionType     string `json:"auctionType"`
+	Currency        string `json:"currency"`
+	DurationSeconds int64  `json:"durationSeconds"`
+	OffsetSeconds   int64  `json:"offsetSeconds"`
+	Bidder          string `json:"bidder"`
+	AmountBucket    int64  `json:"amountBucket"`
+}
+
+// AnonymizedDataset is the result of anonymizing events
+type AnonymizedDataset struct {
+	Bids []AnonymizedBid
+
+	// Suppressed is the number of bids left out to keep k-anonymity
+	Suppressed int
+}
+
+// AnonymizeEvents produces an anonymized, sampled dataset of the bids in the
+// events. Auctions are sampled by a hash of their ID, so the same salt and
+// rate always select the same auctions.
+func AnonymizeEvents(events []domain.Event, options AnonymizeOptions) AnonymizedDataset {
+	auctions := make(map[domain.AuctionId]domain.Auction)
+	var bids []AnonymizedBid
+	for _, event := range events {
+		switch e := event.(type) {
+		case domain.AuctionAddedEvent:
+			auctions[e.Auction.ID] = e.Auction
+		case domain.BidAcceptedEvent:
+			if bid, ok := anonymizeBid(e.Bid, auctions, options); ok {
+				bids = append(bids, bid)
+			}
+		case domain.ProxyBidPlacedEvent:
+			if bid, ok := anonymizeBid(e.Bid, auctions, options); ok {
+				bids = append(bids, bid)
+			}
+		}
+	}
+
+	// Suppress the bids whose quasi-identifiers are shared by fewer than K bids
+	type group struct {
+		auctionType, currency string
+		amountBucket          int64
+	}
+	sizes := make(map[group]int)
+	for _, bid := range bids {
+		sizes[group{bid.AuctionType, bid.Currency, bid.AmountBucket}]++
+	}
+	dataset := AnonymizedDataset{Bids: []AnonymizedBid{}}
+	for _, bid := range bids {
+		if sizes[group{bid.AuctionType, bid.Currency, bid.AmountBucket}] < options.K {
+			dataset.Suppressed++
+			continue
+		}
+		dataset.Bids = append(dataset.Bids, bid)
+	}
+
+	// Keep the order of the events from leaking through the dataset
+	sort.SliceStable(dataset.Bids, func(i, j int) bool {
+		if dataset.Bids[i].Auction != dataset.Bids[j].Auction {
+			return dataset.Bids[i].Auction < dataset.Bids[j].Auction
+		}
+		return dataset.Bids[i].OffsetSeconds < dataset.Bids[j].OffsetSeconds
+	})
+	return dataset
+}
+
+// anonymizeBid anonymizes a bid on a sampled auction
+func anonymizeBid(bid domain.Bid, auctions map[domain.AuctionId]domain.Auction, options AnonymizeOptions) (AnonymizedBid, bool) {
+	auction, ok := auctions[bid.ForAuction]
+	if !ok || !sampled(options, auction.ID) {
+		return AnonymizedBid{}, false
+	}
+	bucket := bid.Amount
+	if options.AmountBucket > 0 {
+		bucket = bid.Amount / options.AmountBucket * options.AmountBucket
+	}
+	return AnonymizedBid{
+		Auction:         pseudonym(options.Salt, "auction", auctionKey(auction.ID)),
+		AuctionType:     auction.Type.Type.String(),
+		Currency:        string(auction.Currency),
+		DurationSeconds: int64(auction.Expiry.Sub(auction.StartsAt).Seconds()),
+		OffsetSeconds:   int64(bid.At.Sub(auction.StartsAt).Seconds()),
+		Bidder:          pseudonym(options.Salt, "user", string(bid.Bidder.ID)),
+		AmountBucket:    bucket,
+	}, true
+}
+
+// sampled returns true if the auction is part of the sample
+func sampled(options AnonymizeOptions, id domain.AuctionId) bool {
+	if options.SampleRate >= 1 {
+		return true
+	}
+	sum := sha256.Sum256([]byte(options.Salt + "sample" + auctionKey(id)))
+	return float64(binary.BigEndian.Uint64(sum[:8]))/float64(^uint64(0)) < options.SampleRate
+}
+
+// pseudonym derives a stable pseudonym for an ID from the salt
+func pseudonym(salt, kind, id string) string {
+	sum := sha256.Sum256([]byte(salt + "\x00" + kind + "\x00" + id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// auctionKey encodes an auction ID for hashing
+func auctionKey(id domain.AuctionId) string {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(id))
+	return string(buf[:])
+}
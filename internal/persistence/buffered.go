@@ -0,0 +1,164 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// BufferedStore is a Store decorator buffering event appends in memory and
+// writing them to the underlying store in batches, when the interval since
+// the first buffered event elapses, when maxSize events are buffered, or on
+// an explicit Flush. Reads and snapshots flush first, so they always see the
+// buffered events.
+//
+// Unlike GroupCommitStore, WriteEvents returns as soon as the events are
+// buffered. Events acknowledged but not yet flushed are lost if the process
+// crashes; the commands leading to them are written through and remain, so
+// the loss can be detected, but not undone. Use it only where bursts matter
+// more than the durability of the last interval of events, and call Flush
+// before the process exits.
+//
+// When a flush fails, its events stay buffered and new appends are refused
+// until a flush succeeds, so an acknowledged event is never dropped by the
+// store itself.
+type BufferedStore struct {
+	store    Store
+	interval time.Duration
+	maxSize  int
+
+	// flushMu serializes flushes, so batches reach the store in order
+	flushMu sync.Mutex
+
+	mu      sync.Mutex
+	buffer  []domain.Event
+	timer   *time.Timer
+	lastErr error
+}
+
+// NewBufferedStore wraps a store with buffering of event appends
+func NewBufferedStore(store Store, interval time.Duration, maxSize int) *BufferedStore {
+	return &BufferedStore{
+		store:    store,
+		interval: interval,
+		maxSize:  maxSize,
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *BufferedStore) ReadCommands() ([]domain.Command, error) {
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store right away
+func (s *BufferedStore) WriteCommands(commands []domain.Command) error {
+	return s.store.WriteCommands(commands)
+}
+
+// ReadEvents flushes the buffer and reads events from the underlying store
+func (s *BufferedStore) ReadEvents() ([]domain.Event, error) {
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	return s.store.ReadEvents()
+}
+
+// ReadEventsSince flushes the buffer and reads events after a position from
+// the underlying store
+func (s *BufferedStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	return s.store.ReadEventsSince(position)
+}
+
+// WriteEvents buffers events, flushing right away when the buffer is full
+func (s *BufferedStore) WriteEvents(events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	failed := s.lastErr != nil
+	s.mu.Unlock()
+	if failed {
+		// Only accept more events once the store takes the buffered ones
+		if err := s.Flush(); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	if len(s.buffer) == 0 && s.timer == nil {
+		s.timer = time.AfterFunc(s.interval, func() { s.Flush() })
+	}
+	s.buffer = append(s.buffer, events...)
+	full := s.maxSize > 0 && len(s.buffer) >= s.maxSize
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *BufferedStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot flushes the buffer, so the snapshot doesn't get ahead of the
+// stored events, and writes the snapshot to the underlying store
+func (s *BufferedStore) WriteSnapshot(snapshot Snapshot) error {
+	if err := s.Flush(); err != nil {
+		return err
+	}
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *BufferedStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// Buffered returns the number of events waiting to be flushed
+func (s *BufferedStore) Buffered() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buffer)
+}
+
+// Flush writes the buffered events to the underlying store
+func (s *BufferedStore) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	batch := s.buffer
+	s.buffer = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := s.store.WriteEvents(batch)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+	if err != nil {
+		// Keep the batch ahead of the events buffered meanwhile, and retry later
+		s.buffer = append(batch, s.buffer...)
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.interval, func() { s.Flush() })
+		}
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// ErrStoreOverloaded is returned when an operation can't get a slot, either
+// because its queue is full or because it waited longer than the queue timeout
+var ErrStoreOverloaded = errors.New("store overloaded")
+
+// BulkheadOptions configures the limits of a BulkheadStore. Reads and writes
+// are limited independently, so a burst of one class can't starve the other.
+type BulkheadOptions struct {
+	// Maximum number of operations of the class running at the same time
+	MaxConcurrentReads  int
+	MaxConcurrentWrites int
+
+	// Maximum number of operations of the class waiting for a slot
+	MaxQueuedReads  int
+	MaxQueuedWrites int
+
+	// How long an operation may wait for a slot, zero meaning indefinitely
+	QueueTimeout time.Duration
+}
+
+// bulkhead limits the concurrency of one class of operations
+type bulkhead struct {
+	slots     chan struct{}
+	queued    int64
+	maxQueued int64
+	timeout   time.Duration
+}
+
+func newBulkhead(maxConcurrent, maxQueued int, timeout time.Duration) *bulkhead {
+	return &bulkhead{
+		slots:     make(chan struct{}, maxConcurrent),
+		maxQueued: int64(maxQueued),
+		timeout:   timeout,
+	}
+}
+
+// acquire takes a slot, queueing if none is free
+func (b *bulkhead) acquire() error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if atomic.AddInt64(&b.queued, 1) > b.maxQueued {
+		atomic.AddInt64(&b.queued, -1)
+		return ErrStoreOverloaded
+	}
+	defer atomic.AddInt64(&b.queued, -1)
+
+	if b.timeout <= 0 {
+		b.slots <- struct{}{}
+		return nil
+	}
+
+	timer := time.NewTimer(b.timeout)
+	defer timer.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrStoreOverloaded
+	}
+}
+
+// release frees a slot taken by acquire
+func (b *bulkhead) release() {
+	<-b.slots
+}
+
+// BulkheadStore is a Store decorator applying concurrency limits and bounded
+// queueing to reads and writes, protecting the backend from bursts such as a
+// full replay or an export
+type BulkheadStore struct {
+	store  Store
+	reads  *bulkhead
+	writes *bulkhead
+}
+
+// NewBulkheadStore wraps a store with the given limits
+func NewBulkheadStore(store Store, options BulkheadOptions) *BulkheadStore {
+	return &BulkheadStore{
+		store:  store,
+		reads:  newBulkhead(options.MaxConcurrentReads, options.MaxQueuedReads, options.QueueTimeout),
+		writes: newBulkhead(options.MaxConcurrentWrites, options.MaxQueuedWrites, options.QueueTimeout),
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *BulkheadStore) ReadCommands() ([]domain.Command, error) {
+	if err := s.reads.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.reads.release()
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *BulkheadStore) WriteCommands(commands []domain.Command) error {
+	if err := s.writes.acquire(); err != nil {
+		return err
+	}
+	defer s.writes.release()
+	return s.store.WriteCommands(commands)
+}
+
+// ReadEvents reads events from the underlying store
+func (s *BulkheadStore) ReadEvents() ([]domain.Event, error) {
+	if err := s.reads.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.reads.release()
+	return s.store.ReadEvents()
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *BulkheadStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	if err := s.reads.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.reads.release()
+	return s.store.ReadEventsSince(position)
+}
+
+// WriteEvents writes events to the underlying store
+func (s *BulkheadStore) WriteEvents(events []domain.Event) error {
+	if err := s.writes.acquire(); err != nil {
+		return err
+	}
+	defer s.writes.release()
+	return s.store.WriteEvents(events)
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *BulkheadStore) ReadLatestSnapshot() (*Snapshot, error) {
+	if err := s.reads.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.reads.release()
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *BulkheadStore) WriteSnapshot(snapshot Snapshot) error {
+	if err := s.writes.acquire(); err != nil {
+		return err
+	}
+	defer s.writes.release()
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *BulkheadStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
@@ -0,0 +1,149 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// cachedStream is the cached event stream of a single auction
+type cachedStream struct {
+	events  []domain.Event
+	expires time.Time
+}
+
+// CachingStore is a read-through Store decorator that caches per-auction
+// event streams as well as lookups of unknown auction IDs. Negative entries
+// keep repeated lookups of guessed IDs from reaching the underlying store.
+type CachingStore struct {
+	store          Store
+	ttl            time.Duration
+	negativeTTL    time.Duration
+	getCurrentTime func() time.Time
+
+	mu      sync.Mutex
+	streams map[domain.AuctionId]cachedStream
+	missing map[domain.AuctionId]time.Time
+	// generations count the invalidations of each auction, so a read racing
+	// one doesn't cache what it read before
+	generations map[domain.AuctionId]uint64
+}
+
+// NewCachingStore wraps a store with a read-through cache. Cached streams live
+// for ttl and negative lookups for negativeTTL.
+func NewCachingStore(store Store, ttl, negativeTTL time.Duration, getCurrentTime func() time.Time) *CachingStore {
+	return &CachingStore{
+		store:          store,
+		ttl:            ttl,
+		negativeTTL:    negativeTTL,
+		getCurrentTime: getCurrentTime,
+		streams:        make(map[domain.AuctionId]cachedStream),
+		missing:        make(map[domain.AuctionId]time.Time),
+		generations:    make(map[domain.AuctionId]uint64),
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *CachingStore) ReadCommands() ([]domain.Command, error) {
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *CachingStore) WriteCommands(commands []domain.Command) error {
+	return s.store.WriteCommands(commands)
+}
+
+// ReadEvents reads events from the underlying store
+func (s *CachingStore) ReadEvents() ([]domain.Event, error) {
+	return s.store.ReadEvents()
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *CachingStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	return s.store.ReadEventsSince(position)
+}
+
+// WriteEvents writes events to the underlying store and invalidates the
+// cache entries of every auction touched by the batch
+func (s *CachingStore) WriteEvents(events []domain.Event) error {
+	err := s.store.WriteEvents(events)
+
+	// Invalidate even on error, since a failed batch may be partially written
+	for _, event := range events {
+		if id, ok := domain.EventAuctionId(event); ok {
+			s.Invalidate(id)
+		}
+	}
+
+	return err
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *CachingStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *CachingStore) WriteSnapshot(snapshot Snapshot) error {
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *CachingStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// ReadAuctionEvents returns the events of a single auction, serving them from
+// the cache when possible. Unknown auctions yield an empty slice. What was
+// read is only cached if the auction wasn't invalidated in the meantime.
+func (s *CachingStore) ReadAuctionEvents(id domain.AuctionId) ([]domain.Event, error) {
+	now := s.getCurrentTime()
+
+	s.mu.Lock()
+	if stream, ok := s.streams[id]; ok && now.Before(stream.expires) {
+		s.mu.Unlock()
+		return copyEvents(stream.events), nil
+	}
+	if expires, ok := s.missing[id]; ok && now.Before(expires) {
+		s.mu.Unlock()
+		return []domain.Event{}, nil
+	}
+	generation := s.generations[id]
+	s.mu.Unlock()
+
+	events, err := ReadAuctionEvents(s.store, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generations[id] != generation {
+		return copyEvents(events), nil
+	}
+	if len(events) == 0 {
+		s.missing[id] = now.Add(s.negativeTTL)
+	} else {
+		s.streams[id] = cachedStream{events: events, expires: now.Add(s.ttl)}
+	}
+
+	return copyEvents(events), nil
+}
+
+// Invalidate drops any cached stream or negative lookup for an auction
+func (s *CachingStore) Invalidate(id domain.AuctionId) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, id)
+	delete(s.missing, id)
+	s.generations[id]++
+}
+
+// copyEvents returns a copy of the events slice so callers can't mutate the cache
+func copyEvents(events []domain.Event) []domain.Event {
+	result := make([]domain.Event, len(events))
+	copy(result, events)
+	return result
+}
@@ -0,0 +1,148 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Checkpoints records the position of the last event each projection has
+// processed, in a JSON file, so projections resume after restarts instead of
+// reprocessing the whole store
+type Checkpoints struct {
+	path string
+
+	mu        sync.Mutex
+	positions map[string]int64
+}
+
+// OpenCheckpoints reads the checkpoints of a JSON file, which may not exist yet
+func OpenCheckpoints(path string) (*Checkpoints, error) {
+	c := &Checkpoints{path: path, positions: make(map[string]int64)}
+	exists, err := fileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &c.positions); err != nil {
+			return nil, fmt.Errorf("error unmarshaling checkpoints: %v", err)
+		}
+	}
+	return c, nil
+}
+
+// Position returns the checkpoint of a projection, 0 when it has none
+func (c *Checkpoints) Position(name string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.positions[name]
+}
+
+// Positions returns the checkpoints of all projections
+func (c *Checkpoints) Positions() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	positions := make(map[string]int64, len(c.positions))
+	for name, position := range c.positions {
+		positions[name] = position
+	}
+	return positions
+}
+
+// Save records the checkpoint of a projection
+func (c *Checkpoints) Save(name string, position int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.positions[name] = position
+	return c.write()
+}
+
+// Reset removes the checkpoint of a projection, so it's rebuilt from the
+// first event
+func (c *Checkpoints) Reset(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.positions, name)
+	return c.write()
+}
+
+// write rewrites the file through a temporary file, the lock being held
+func (c *Checkpoints) write() error {
+	data, err := json.Marshal(c.positions)
+	if err != nil {
+		return fmt.Errorf("error marshaling checkpoints: %v", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// CatchUp feeds each projection the events after its checkpoint, then saves
+// the position of the last event it processed. A projection keeping its
+// state in memory must restore it before catching up. On a projection error
+// the checkpoints of the events processed so far are still saved.
+func CatchUp(store Store, checkpoints *Checkpoints, projections ...Projection) (ReplayResult, error) {
+	var result ReplayResult
+	if len(projections) == 0 {
+		return result, nil
+	}
+
+	positions := make([]int64, len(projections))
+	from := checkpoints.Position(projections[0].Name())
+	for i, projection := range projections {
+		positions[i] = checkpoints.Position(projection.Name())
+		if positions[i] < from {
+			from = positions[i]
+		}
+	}
+
+	events, err := store.ReadEventsSince(from)
+	if err != nil {
+		return result, err
+	}
+
+	var applyErr error
+	for i, event := range events {
+		position := from + int64(i+1)
+		result.Read++
+		replayed := false
+		for j, projection := range projections {
+			if positions[j] >= position {
+				continue
+			}
+			if err := projection.Apply(position, event); err != nil {
+				applyErr = fmt.Errorf("projection %s failed at position %d: %v", projection.Name(), position, err)
+				break
+			}
+			positions[j] = position
+			replayed = true
+		}
+		if replayed {
+			result.Replayed++
+		}
+		if applyErr != nil {
+			break
+		}
+	}
+
+	for i, projection := range projections {
+		if positions[i] == checkpoints.Position(projection.Name()) {
+			continue
+		}
+		if err := checkpoints.Save(projection.Name(), positions[i]); err != nil {
+			return result, err
+		}
+	}
+	return result, applyErr
+}
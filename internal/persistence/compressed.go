@@ -0,0 +1,169 @@
+package persistence
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// CompressedEvent is an event compressed by CompressingStore. Only the time is
+// kept in the clear, since every event must expose it.
+type CompressedEvent struct {
+	Time time.Time `json:"at"`
+	Data []byte    `json:"data"`
+}
+
+// GetTime returns the time of the event
+func (e CompressedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for CompressedEvent
+func (e CompressedEvent) MarshalJSON() ([]byte, error) {
+	type compressedEventJSON CompressedEvent
+	return domain.MarshalEnvelope("Compressed", compressedEventJSON(e))
+}
+
+func init() {
+	domain.RegisterEventType("Compressed", func(data []byte) (domain.Event, error) {
+		var evt CompressedEvent
+		err := json.Unmarshal(data, &evt)
+		return evt, err
+	})
+}
+
+// CompressingStore is a Store decorator gzipping events whose JSON is larger
+// than a threshold. Smaller events, and events written before compression was
+// enabled, are stored and read as they are. A threshold of 0 only
+// decompresses.
+type CompressingStore struct {
+	store     Store
+	threshold int
+}
+
+// NewCompressingStore wraps a store with compression of events larger than
+// threshold bytes
+func NewCompressingStore(store Store, threshold int) *CompressingStore {
+	return &CompressingStore{
+		store:     store,
+		threshold: threshold,
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *CompressingStore) ReadCommands() ([]domain.Command, error) {
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *CompressingStore) WriteCommands(commands []domain.Command) error {
+	return s.store.WriteCommands(commands)
+}
+
+// ReadEvents reads and decompresses events from the underlying store
+func (s *CompressingStore) ReadEvents() ([]domain.Event, error) {
+	stored, err := s.store.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+	return decompressEvents(stored)
+}
+
+// ReadEventsSince reads and decompresses the events after a position from the underlying store
+func (s *CompressingStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	stored, err := s.store.ReadEventsSince(position)
+	if err != nil {
+		return nil, err
+	}
+	return decompressEvents(stored)
+}
+
+// WriteEvents compresses large events and writes them to the underlying store
+func (s *CompressingStore) WriteEvents(events []domain.Event) error {
+	stored, err := s.compressEvents(events)
+	if err != nil {
+		return err
+	}
+	return s.store.WriteEvents(stored)
+}
+
+// ReplaceEvents compresses large events and replaces the events of the
+// underlying store with them
+func (s *CompressingStore) ReplaceEvents(events []domain.Event) error {
+	stored, err := s.compressEvents(events)
+	if err != nil {
+		return err
+	}
+	return replaceEvents(s.store, stored)
+}
+
+// compressEvents compresses the events larger than the threshold
+func (s *CompressingStore) compressEvents(events []domain.Event) ([]domain.Event, error) {
+	stored := make([]domain.Event, len(events))
+	for i, event := range events {
+		data, err := domain.MarshalEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		if s.threshold <= 0 || len(data) <= s.threshold {
+			stored[i] = event
+			continue
+		}
+
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		stored[i] = CompressedEvent{Time: event.GetTime(), Data: buf.Bytes()}
+	}
+	return stored, nil
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *CompressingStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *CompressingStore) WriteSnapshot(snapshot Snapshot) error {
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *CompressingStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// decompressEvents replaces compressed events by the events they hold
+func decompressEvents(stored []domain.Event) ([]domain.Event, error) {
+	events := make([]domain.Event, len(stored))
+	for i, event := range stored {
+		compressed, ok := event.(CompressedEvent)
+		if !ok {
+			events[i] = event
+			continue
+		}
+
+		reader, err := gzip.NewReader(bytes.NewReader(compressed.Data))
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		if events[i], err = domain.UnmarshalEvent(data); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,205 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// dailyFileLayout is the date format in the names of the files of a DailyFileStore
+const dailyFileLayout = "2006-01-02"
+
+// DailyFileStore is a Store keeping commands and events in append-only
+// newline-delimited JSON files, one per UTC day, such as
+// events-2020-01-01.jsonl. Writes only append to the file of the current day,
+// and reads stream the files in order, so old days never need rewriting and
+// can be archived as whole files.
+//
+// An entry goes to the file of the day of its timestamp, unless a later file
+// already exists, in which case it's appended to the latest file. This keeps
+// the concatenation of the files in write order, which positions rely on.
+type DailyFileStore struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewDailyFileStore creates a daily file store in a directory
+func NewDailyFileStore(dir string) *DailyFileStore {
+	return &DailyFileStore{Dir: dir}
+}
+
+// ReadCommands reads the commands of all days
+func (s *DailyFileStore) ReadCommands() ([]domain.Command, error) {
+	paths, err := s.dayFiles("commands")
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]domain.Command, 0)
+	for _, path := range paths {
+		dayCommands, err := ReadCommands(path)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, dayCommands...)
+	}
+	return commands, nil
+}
+
+// WriteCommands appends commands to the files of their days
+func (s *DailyFileStore) WriteCommands(commands []domain.Command) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := s.dayFiles("commands")
+	if err != nil {
+		return err
+	}
+
+	for len(commands) > 0 {
+		path := s.targetFile("commands", paths, commands[0].GetTime())
+		n := 1
+		for n < len(commands) && s.targetFile("commands", paths, commands[n].GetTime()) == path {
+			n++
+		}
+		if err := WriteCommands(path, commands[:n]); err != nil {
+			return err
+		}
+		paths = appendPath(paths, path)
+		commands = commands[n:]
+	}
+	return nil
+}
+
+// ReadEvents reads the events of all days
+func (s *DailyFileStore) ReadEvents() ([]domain.Event, error) {
+	return s.ReadEventsSince(0)
+}
+
+// ReadEventsSince streams the files of all days, decoding only the events
+// after the position
+func (s *DailyFileStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	paths, err := s.dayFiles("events")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.Event, 0)
+	for _, path := range paths {
+		count, err := readEventLines(path, position, func(event domain.Event) {
+			events = append(events, event)
+		})
+		if err != nil {
+			return nil, err
+		}
+		position -= count
+		if position < 0 {
+			position = 0
+		}
+	}
+	return events, nil
+}
+
+// WriteEvents appends events to the files of their days
+func (s *DailyFileStore) WriteEvents(events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := s.dayFiles("events")
+	if err != nil {
+		return err
+	}
+
+	for len(events) > 0 {
+		path := s.targetFile("events", paths, events[0].GetTime())
+		n := 1
+		for n < len(events) && s.targetFile("events", paths, events[n].GetTime()) == path {
+			n++
+		}
+		if err := WriteEvents(path, events[:n]); err != nil {
+			return err
+		}
+		paths = appendPath(paths, path)
+		events = events[n:]
+	}
+	return nil
+}
+
+// ReplaceEvents replaces the events of all days, rewriting each day file
+// that keeps events and removing the others. Each file is replaced
+// atomically, but a failure can leave some days rewritten.
+func (s *DailyFileStore) ReplaceEvents(events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.dayFiles("events")
+	if err != nil {
+		return err
+	}
+
+	// Events go to the files WriteEvents would have appended them to
+	var paths []string
+	byPath := make(map[string][]domain.Event)
+	for _, event := range events {
+		path := s.targetFile("events", paths, event.GetTime())
+		paths = appendPath(paths, path)
+		byPath[path] = append(byPath[path], event)
+	}
+
+	for _, path := range paths {
+		if err := ReplaceEvents(path, byPath[path]); err != nil {
+			return err
+		}
+	}
+	for _, path := range old {
+		if _, kept := byPath[path]; !kept {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReadLatestSnapshot reads the last snapshot of the snapshots file
+func (s *DailyFileStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return ReadLatestSnapshot(filepath.Join(s.Dir, "snapshots.jsonl"))
+}
+
+// WriteSnapshot appends a snapshot to the snapshots file
+func (s *DailyFileStore) WriteSnapshot(snapshot Snapshot) error {
+	return WriteSnapshot(filepath.Join(s.Dir, "snapshots.jsonl"), snapshot)
+}
+
+// dayFiles returns the existing files of a kind, oldest first
+func (s *DailyFileStore) dayFiles(kind string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, kind+"-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	// The date layout sorts chronologically
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// targetFile returns the file an entry written at the given time goes to
+func (s *DailyFileStore) targetFile(kind string, paths []string, at time.Time) string {
+	path := filepath.Join(s.Dir, kind+"-"+at.UTC().Format(dailyFileLayout)+".jsonl")
+	if len(paths) > 0 && paths[len(paths)-1] > path {
+		return paths[len(paths)-1]
+	}
+	return path
+}
+
+// appendPath adds a path to the sorted files if it's new
+func appendPath(paths []string, path string) []string {
+	if len(paths) > 0 && paths[len(paths)-1] == path {
+		return paths
+	}
+	return append(paths, path)
+}
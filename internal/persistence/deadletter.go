@@ -0,0 +1,139 @@
+package persistence
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// DeadLetters keeps the commands that failed validation or processing in a
+// JSONL file, each change of a dead letter appending its new version. An
+// empty path keeps them in memory only.
+type DeadLetters struct {
+	path string
+
+	mu      sync.Mutex
+	letters map[int64]domain.DeadLetter
+	lastId  int64
+}
+
+// OpenDeadLetters reads the dead letters of a JSONL file, which may not
+// exist yet
+func OpenDeadLetters(path string) (*DeadLetters, error) {
+	d := &DeadLetters{path: path, letters: make(map[int64]domain.DeadLetter)}
+	if path == "" {
+		return d, nil
+	}
+	exists, err := fileExists(path)
+	if err != nil || !exists {
+		return d, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var letter domain.DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			return nil, fmt.Errorf("error unmarshaling dead letter: %v", err)
+		}
+		d.letters[letter.ID] = letter
+		if letter.ID > d.lastId {
+			d.lastId = letter.ID
+		}
+	}
+	return d, scanner.Err()
+}
+
+// Add records a failed command
+func (d *DeadLetters) Add(at time.Time, cmd domain.Command, cause error) (domain.DeadLetter, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letter := domain.DeadLetter{ID: d.lastId + 1, At: at, Command: cmd, Error: cause.Error()}
+	if err := d.write(letter); err != nil {
+		return domain.DeadLetter{}, err
+	}
+	d.lastId = letter.ID
+	return letter, nil
+}
+
+// Get returns a dead letter
+func (d *DeadLetters) Get(id int64) (domain.DeadLetter, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letter, ok := d.letters[id]
+	return letter, ok
+}
+
+// List returns the dead letters in the order they were added, leaving out
+// the resolved ones unless all is set
+func (d *DeadLetters) List(all bool) []domain.DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letters := make([]domain.DeadLetter, 0, len(d.letters))
+	for _, letter := range d.letters {
+		if all || !letter.Resolved {
+			letters = append(letters, letter)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].ID < letters[j].ID
+	})
+	return letters
+}
+
+// Retried records the outcome of a retry of a dead letter, resolving it
+// when cause is nil
+func (d *DeadLetters) Retried(id int64, at time.Time, cause error) (domain.DeadLetter, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letter, ok := d.letters[id]
+	if !ok {
+		return domain.DeadLetter{}, fmt.Errorf("unknown dead letter %d", id)
+	}
+	letter.Retries++
+	letter.RetriedAt = &at
+	if cause != nil {
+		letter.Error = cause.Error()
+	} else {
+		letter.Resolved = true
+	}
+	return letter, d.write(letter)
+}
+
+// write appends a version of a dead letter, the lock being held
+func (d *DeadLetters) write(letter domain.DeadLetter) error {
+	if d.path != "" {
+		data, err := json.Marshal(letter)
+		if err != nil {
+			return fmt.Errorf("error marshaling dead letter: %v", err)
+		}
+		file, err := os.OpenFile(d.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	d.letters[letter.ID] = letter
+	return nil
+}
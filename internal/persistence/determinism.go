@@ -0,0 +1,166 @@
+package persistence
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"auction-site-go/internal/domain"
+)
+
+// Divergence is a difference between the events the current domain logic
+// produces from the stored commands and the stored events
+type Divergence struct {
+	// Command is the 1-based position of the command, 0 for a stored event
+	// no command produced
+	Command int64
+	// Event is the 1-based position of the stored event, 0 when it's missing
+	Event  int64
+	Reason string
+}
+
+// DeterminismReport is the result of replaying the stored commands
+type DeterminismReport struct {
+	Commands    int
+	Events      int
+	Divergences []Divergence
+}
+
+// Deterministic tells whether the replay reproduced the stored events
+func (r DeterminismReport) Deterministic() bool {
+	return len(r.Divergences) == 0
+}
+
+// storedEvent is a stored event with its position
+type storedEvent struct {
+	position int64
+	event    domain.Event
+}
+
+// CheckDeterminism replays the stored commands on auctions through Handle,
+// from an empty repository, and diffs the events it produces against the
+// stored ones, auction by auction so a single divergence doesn't misalign
+// the rest. Commands rejected by Handle produce no event, and listings
+// rejected by moderation are left out like they were when first handled.
+//
+// Only events produced by auction commands are compared: translations from
+// providers, moderation, screening and reports are written outside of Handle.
+func CheckDeterminism(store Store) (DeterminismReport, error) {
+	var report DeterminismReport
+
+	commands, err := store.ReadCommands()
+	if err != nil {
+		return report, err
+	}
+	events, err := store.ReadEvents()
+	if err != nil {
+		return report, err
+	}
+
+	expected := make(map[domain.AuctionId][]storedEvent)
+	rejected := make(map[domain.AuctionId]int)
+	for i, event := range events {
+		if moderated, ok := event.(domain.ListingModeratedEvent); ok && moderated.Decision.Verdict == domain.ModerationRejected {
+			rejected[moderated.AuctionId]++
+		}
+		if isCommandEvent(event) {
+			id, _ := domain.EventAuctionId(event)
+			expected[id] = append(expected[id], storedEvent{position: int64(i + 1), event: event})
+		}
+	}
+
+	repo := make(domain.Repository)
+	for i, cmd := range commands {
+		if _, ok := domain.CommandAuctionId(cmd); !ok {
+			continue
+		}
+		report.Commands++
+		event, newRepo, err := domain.Handle(cmd, repo)
+		if err != nil {
+			continue
+		}
+
+		id, _ := domain.EventAuctionId(event)
+		if _, ok := event.(domain.AuctionAddedEvent); ok && rejected[id] > 0 {
+			rejected[id]--
+			continue
+		}
+		repo = newRepo
+		report.Events++
+
+		position := int64(i + 1)
+		queue := expected[id]
+		if len(queue) == 0 {
+			report.Divergences = append(report.Divergences, Divergence{
+				Command: position,
+				Reason:  fmt.Sprintf("produced %s, which is not stored", eventTypeName(event)),
+			})
+			continue
+		}
+		stored := queue[0]
+		expected[id] = queue[1:]
+		if reason := compareEvents(stored.event, event); reason != "" {
+			report.Divergences = append(report.Divergences, Divergence{Command: position, Event: stored.position, Reason: reason})
+		}
+	}
+
+	var unproduced []Divergence
+	for _, queue := range expected {
+		for _, stored := range queue {
+			unproduced = append(unproduced, Divergence{
+				Event:  stored.position,
+				Reason: fmt.Sprintf("stored %s, which no command produced", eventTypeName(stored.event)),
+			})
+		}
+	}
+	sort.Slice(unproduced, func(i, j int) bool {
+		return unproduced[i].Event < unproduced[j].Event
+	})
+	report.Divergences = append(report.Divergences, unproduced...)
+	return report, nil
+}
+
+// compareEvents returns how a replayed event differs from the stored one, or
+// an empty string
+func compareEvents(stored, replayed domain.Event) string {
+	// Metadata describes the original request, which a replay doesn't have
+	s, err := domain.MarshalEvent(domain.WithEventMetadata(stored, nil))
+	if err != nil {
+		return fmt.Sprintf("stored event can't be encoded: %v", err)
+	}
+	r, err := domain.MarshalEvent(replayed)
+	if err != nil {
+		return fmt.Sprintf("replayed event can't be encoded: %v", err)
+	}
+	if !bytes.Equal(s, r) {
+		return fmt.Sprintf("replayed %s, stored %s", r, s)
+	}
+	return ""
+}
+
+// isCommandEvent tells whether an event is produced by Handle
+func isCommandEvent(event domain.Event) bool {
+	switch e := event.(type) {
+	case domain.AuctionAddedEvent, domain.BidAcceptedEvent, domain.ListingRevisedEvent,
+		domain.KeyShareSubmittedEvent, domain.TenderRevealedEvent, domain.WinnerDeterminedEvent,
+		domain.BoughtNowEvent, domain.MaxBidSetEvent, domain.ProxyBidPlacedEvent,
+		domain.UnitsAllocatedEvent, domain.AuctionCancelledEvent, domain.AuctionAmendedEvent:
+		return true
+	case domain.ListingTranslatedEvent:
+		return e.Translation.Source == domain.TranslationSeller
+	}
+	return false
+}
+
+// eventTypeName returns the envelope type of an event
+func eventTypeName(event domain.Event) string {
+	data, err := domain.MarshalEvent(event)
+	if err != nil {
+		return fmt.Sprintf("%T", event)
+	}
+	name, err := domain.EnvelopeType(data)
+	if err != nil {
+		return fmt.Sprintf("%T", event)
+	}
+	return name
+}
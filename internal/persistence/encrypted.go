@@ -0,0 +1,379 @@
+package persistence
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// SecretProvider supplies the keys used to encrypt stored payloads
+type SecretProvider interface {
+	// CurrentKey returns the ID and value of the key new payloads are sealed with
+	CurrentKey() (string, []byte, error)
+
+	// Key returns the key with the given ID, so payloads sealed with a
+	// rotated-out key can still be opened
+	Key(keyId string) ([]byte, error)
+}
+
+// StaticSecretProvider is a SecretProvider over a fixed set of keys
+type StaticSecretProvider struct {
+	Current string
+	Keys    map[string][]byte
+}
+
+// CurrentKey returns the current key
+func (p *StaticSecretProvider) CurrentKey() (string, []byte, error) {
+	key, err := p.Key(p.Current)
+	return p.Current, key, err
+}
+
+// Key returns the key with the given ID
+func (p *StaticSecretProvider) Key(keyId string) ([]byte, error) {
+	key, ok := p.Keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key: %s", keyId)
+	}
+	return key, nil
+}
+
+// ParseSecretKeys parses a comma-separated list of "id:base64key" pairs into a
+// StaticSecretProvider. The first key is the current one.
+func ParseSecretKeys(s string) (*StaticSecretProvider, error) {
+	provider := &StaticSecretProvider{Keys: make(map[string][]byte)}
+	for i, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid encryption key format: %s", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %s: %v", parts[0], err)
+		}
+		if i == 0 {
+			provider.Current = parts[0]
+		}
+		provider.Keys[parts[0]] = key
+	}
+	return provider, nil
+}
+
+// SealedPayload is a payload encrypted by EncryptedStore
+type SealedPayload struct {
+	KeyId      string `json:"keyId"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"data"`
+}
+
+// SealedCommand is a command encrypted by EncryptedStore. Only the time is
+// kept in the clear, since every command must expose it.
+type SealedCommand struct {
+	Time       time.Time `json:"at"`
+	KeyId      string    `json:"keyId"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"data"`
+}
+
+// GetTime returns the time of the command
+func (c SealedCommand) GetTime() time.Time {
+	return c.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for SealedCommand
+func (c SealedCommand) MarshalJSON() ([]byte, error) {
+	type sealedCommandJSON SealedCommand
+	return domain.MarshalEnvelope("Sealed", sealedCommandJSON(c))
+}
+
+// SealedEvent is an event encrypted by EncryptedStore. Only the time is kept
+// in the clear, since every event must expose it.
+type SealedEvent struct {
+	Time       time.Time `json:"at"`
+	KeyId      string    `json:"keyId"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"data"`
+}
+
+// GetTime returns the time of the event
+func (e SealedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+// MarshalJSON implements json.Marshaler interface for SealedEvent
+func (e SealedEvent) MarshalJSON() ([]byte, error) {
+	type sealedEventJSON SealedEvent
+	return domain.MarshalEnvelope("Sealed", sealedEventJSON(e))
+}
+
+func init() {
+	domain.RegisterCommandType("Sealed", func(data []byte) (domain.Command, error) {
+		var cmd SealedCommand
+		err := json.Unmarshal(data, &cmd)
+		return cmd, err
+	})
+	domain.RegisterEventType("Sealed", func(data []byte) (domain.Event, error) {
+		var evt SealedEvent
+		err := json.Unmarshal(data, &evt)
+		return evt, err
+	})
+}
+
+// EncryptedStore is a Store decorator that encrypts whole commands and events
+// with AES-GCM before they reach the underlying store. Each payload is tagged
+// with the ID of its key so keys can be rotated. Place it closest to the
+// backend, since decorators below it only see sealed payloads.
+type EncryptedStore struct {
+	store   Store
+	secrets SecretProvider
+}
+
+// NewEncryptedStore wraps a store with payload encryption
+func NewEncryptedStore(store Store, secrets SecretProvider) *EncryptedStore {
+	return &EncryptedStore{
+		store:   store,
+		secrets: secrets,
+	}
+}
+
+// ReadCommands reads and decrypts commands from the underlying store
+func (s *EncryptedStore) ReadCommands() ([]domain.Command, error) {
+	stored, err := s.store.ReadCommands()
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]domain.Command, len(stored))
+	for i, cmd := range stored {
+		sealed, ok := cmd.(SealedCommand)
+		if !ok {
+			return nil, fmt.Errorf("command %d is not encrypted", i)
+		}
+		plaintext, err := s.open(sealed.KeyId, sealed.Nonce, sealed.Ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		if commands[i], err = domain.UnmarshalCommand(plaintext); err != nil {
+			return nil, err
+		}
+	}
+	return commands, nil
+}
+
+// WriteCommands encrypts commands and writes them to the underlying store
+func (s *EncryptedStore) WriteCommands(commands []domain.Command) error {
+	sealed := make([]domain.Command, len(commands))
+	for i, cmd := range commands {
+		data, err := domain.MarshalCommand(cmd)
+		if err != nil {
+			return err
+		}
+		keyId, nonce, ciphertext, err := s.seal(json.RawMessage(data))
+		if err != nil {
+			return err
+		}
+		sealed[i] = SealedCommand{Time: cmd.GetTime(), KeyId: keyId, Nonce: nonce, Ciphertext: ciphertext}
+	}
+	return s.store.WriteCommands(sealed)
+}
+
+// ReadEvents reads and decrypts events from the underlying store
+func (s *EncryptedStore) ReadEvents() ([]domain.Event, error) {
+	stored, err := s.store.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+	return s.openEvents(stored)
+}
+
+// ReadEventsSince reads and decrypts the events after a position from the underlying store
+func (s *EncryptedStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	stored, err := s.store.ReadEventsSince(position)
+	if err != nil {
+		return nil, err
+	}
+	return s.openEvents(stored)
+}
+
+// openEvents decrypts sealed events
+func (s *EncryptedStore) openEvents(stored []domain.Event) ([]domain.Event, error) {
+	events := make([]domain.Event, len(stored))
+	for i, event := range stored {
+		sealed, ok := event.(SealedEvent)
+		if !ok {
+			return nil, fmt.Errorf("event %d is not encrypted", i)
+		}
+		plaintext, err := s.open(sealed.KeyId, sealed.Nonce, sealed.Ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		if events[i], err = domain.UnmarshalEvent(plaintext); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// WriteEvents encrypts events and writes them to the underlying store
+func (s *EncryptedStore) WriteEvents(events []domain.Event) error {
+	sealed, err := s.sealEvents(events)
+	if err != nil {
+		return err
+	}
+	return s.store.WriteEvents(sealed)
+}
+
+// ReplaceEvents encrypts events and replaces the events of the underlying
+// store with them
+func (s *EncryptedStore) ReplaceEvents(events []domain.Event) error {
+	sealed, err := s.sealEvents(events)
+	if err != nil {
+		return err
+	}
+	return replaceEvents(s.store, sealed)
+}
+
+// sealEvents encrypts events with the current key
+func (s *EncryptedStore) sealEvents(events []domain.Event) ([]domain.Event, error) {
+	sealed := make([]domain.Event, len(events))
+	for i, event := range events {
+		data, err := domain.MarshalEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		keyId, nonce, ciphertext, err := s.seal(json.RawMessage(data))
+		if err != nil {
+			return nil, err
+		}
+		sealed[i] = SealedEvent{Time: event.GetTime(), KeyId: keyId, Nonce: nonce, Ciphertext: ciphertext}
+	}
+	return sealed, nil
+}
+
+// ReadLatestSnapshot reads and decrypts the latest snapshot from the underlying store
+func (s *EncryptedStore) ReadLatestSnapshot() (*Snapshot, error) {
+	stored, err := s.store.ReadLatestSnapshot()
+	if err != nil || stored == nil {
+		return stored, err
+	}
+	if stored.Sealed == nil {
+		return nil, fmt.Errorf("snapshot at position %d is not encrypted", stored.Position)
+	}
+
+	plaintext, err := s.open(stored.Sealed.KeyId, stored.Sealed.Nonce, stored.Sealed.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := Snapshot{Position: stored.Position}
+	if err := json.Unmarshal(plaintext, &snapshot.Auctions); err != nil {
+		return nil, err
+	}
+
+	if sealed := stored.SealedReadModels; sealed != nil {
+		plaintext, err := s.open(sealed.KeyId, sealed.Nonce, sealed.Ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		var models snapshotReadModels
+		if err := json.Unmarshal(plaintext, &models); err != nil {
+			return nil, err
+		}
+		snapshot.ReadModels = models.ReadModels
+		snapshot.Activity = models.Activity
+	}
+	return &snapshot, nil
+}
+
+// WriteSnapshot encrypts the auctions and the read models of a snapshot and
+// writes it to the underlying store
+func (s *EncryptedStore) WriteSnapshot(snapshot Snapshot) error {
+	keyId, nonce, ciphertext, err := s.seal(snapshot.Auctions)
+	if err != nil {
+		return err
+	}
+	stored := Snapshot{
+		Position: snapshot.Position,
+		Sealed:   &SealedPayload{KeyId: keyId, Nonce: nonce, Ciphertext: ciphertext},
+	}
+
+	if snapshot.ReadModels != nil || snapshot.Activity != nil {
+		keyId, nonce, ciphertext, err := s.seal(snapshotReadModels{ReadModels: snapshot.ReadModels, Activity: snapshot.Activity})
+		if err != nil {
+			return err
+		}
+		stored.SealedReadModels = &SealedPayload{KeyId: keyId, Nonce: nonce, Ciphertext: ciphertext}
+	}
+	return s.store.WriteSnapshot(stored)
+}
+
+// snapshotReadModels are the read models of a snapshot, sealed apart from its
+// auctions so snapshots written without them stay readable
+type snapshotReadModels struct {
+	ReadModels *domain.ReadModels       `json:"readModels"`
+	Activity   *domain.ActivitySnapshot `json:"activity"`
+}
+
+// Ping checks that a key is available for writing and the underlying store is healthy
+func (s *EncryptedStore) Ping(ctx context.Context) error {
+	if _, _, err := s.secrets.CurrentKey(); err != nil {
+		return err
+	}
+	return Ping(ctx, s.store)
+}
+
+// seal marshals a payload and encrypts it with the current key
+func (s *EncryptedStore) seal(payload interface{}) (string, []byte, []byte, error) {
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	keyId, key, err := s.secrets.CurrentKey()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, nil, err
+	}
+	// The key ID is authenticated so a payload can't be relabeled
+	return keyId, nonce, aead.Seal(nil, nonce, plaintext, []byte(keyId)), nil
+}
+
+// open decrypts a payload sealed with the given key
+func (s *EncryptedStore) open(keyId string, nonce, ciphertext []byte) ([]byte, error) {
+	key, err := s.secrets.Key(keyId)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyId))
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting payload with key %s: %v", keyId, err)
+	}
+	return plaintext, nil
+}
+
+// newAEAD creates an AES-GCM cipher for a 16, 24 or 32 byte key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,293 @@
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"auction-site-go/internal/domain"
+)
+
+// erasedPrefix marks a name encrypted with the key of its user
+const erasedPrefix = "pii:"
+
+// UserKeys holds the keys encrypting the personal data of each user, in a
+// JSON file rewritten on every change. Deleting a key erases the data it
+// encrypted, wherever it's stored.
+type UserKeys struct {
+	path string
+
+	mu   sync.Mutex
+	keys map[domain.UserId][]byte
+}
+
+// OpenUserKeys reads the keys of a JSON file, which may not exist yet. An
+// empty path keeps the keys in memory.
+func OpenUserKeys(path string) (*UserKeys, error) {
+	k := &UserKeys{path: path, keys: make(map[domain.UserId][]byte)}
+	if path == "" {
+		return k, nil
+	}
+	exists, err := fileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return k, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &k.keys); err != nil {
+			return nil, fmt.Errorf("error unmarshaling user keys: %v", err)
+		}
+	}
+	return k, nil
+}
+
+// Key returns the key of a user, false when the user has none or was forgotten
+func (k *UserKeys) Key(userId domain.UserId) ([]byte, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key, ok := k.keys[userId]
+	return key, ok
+}
+
+// KeyFor returns the key of a user, creating it on first use
+func (k *UserKeys) KeyFor(userId domain.UserId) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if key, ok := k.keys[userId]; ok {
+		return key, nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	k.keys[userId] = key
+	if err := k.write(); err != nil {
+		delete(k.keys, userId)
+		return nil, err
+	}
+	return key, nil
+}
+
+// Forget deletes the key of a user, so their personal data can no longer be
+// read. Data written for the user afterwards gets a new key.
+func (k *UserKeys) Forget(userId domain.UserId) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key, ok := k.keys[userId]
+	if !ok {
+		return nil
+	}
+	delete(k.keys, userId)
+	if err := k.write(); err != nil {
+		k.keys[userId] = key
+		return err
+	}
+	return nil
+}
+
+// write rewrites the file through a temporary file, the lock being held
+func (k *UserKeys) write() error {
+	if k.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(k.keys)
+	if err != nil {
+		return fmt.Errorf("error marshaling user keys: %v", err)
+	}
+	tmp := k.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, k.path)
+}
+
+// ErasableStore is a Store decorator encrypting the names of users in
+// commands, events and snapshots with the key of each user, so forgetting a
+// user erases their names from the log without rewriting it. Names of a
+// forgotten user read as domain.ForgottenName, and names written before the
+// store was used are read as they are. Place it above the decorators that
+// change the shape of payloads, such as EncryptedStore.
+type ErasableStore struct {
+	store Store
+	keys  *UserKeys
+}
+
+// NewErasableStore wraps a store with the encryption of user names
+func NewErasableStore(store Store, keys *UserKeys) *ErasableStore {
+	return &ErasableStore{
+		store: store,
+		keys:  keys,
+	}
+}
+
+// ReadCommands reads commands from the underlying store, decrypting names
+func (s *ErasableStore) ReadCommands() ([]domain.Command, error) {
+	stored, err := s.store.ReadCommands()
+	if err != nil {
+		return nil, err
+	}
+	commands := make([]domain.Command, len(stored))
+	for i, cmd := range stored {
+		opened, err := domain.MapUsers(cmd, s.openName)
+		if err != nil {
+			return nil, err
+		}
+		commands[i] = opened.(domain.Command)
+	}
+	return commands, nil
+}
+
+// WriteCommands encrypts names and writes commands to the underlying store
+func (s *ErasableStore) WriteCommands(commands []domain.Command) error {
+	sealed := make([]domain.Command, len(commands))
+	for i, cmd := range commands {
+		mapped, err := domain.MapUsers(cmd, s.sealName)
+		if err != nil {
+			return err
+		}
+		sealed[i] = mapped.(domain.Command)
+	}
+	return s.store.WriteCommands(sealed)
+}
+
+// ReadEvents reads events from the underlying store, decrypting names
+func (s *ErasableStore) ReadEvents() ([]domain.Event, error) {
+	stored, err := s.store.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+	return s.openEvents(stored)
+}
+
+// ReadEventsSince reads the events after a position from the underlying
+// store, decrypting names
+func (s *ErasableStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	stored, err := s.store.ReadEventsSince(position)
+	if err != nil {
+		return nil, err
+	}
+	return s.openEvents(stored)
+}
+
+// openEvents decrypts the names of events
+func (s *ErasableStore) openEvents(stored []domain.Event) ([]domain.Event, error) {
+	events := make([]domain.Event, len(stored))
+	for i, event := range stored {
+		opened, err := domain.MapUsers(event, s.openName)
+		if err != nil {
+			return nil, err
+		}
+		events[i] = opened.(domain.Event)
+	}
+	return events, nil
+}
+
+// WriteEvents encrypts names and writes events to the underlying store
+func (s *ErasableStore) WriteEvents(events []domain.Event) error {
+	sealed := make([]domain.Event, len(events))
+	for i, event := range events {
+		mapped, err := domain.MapUsers(event, s.sealName)
+		if err != nil {
+			return err
+		}
+		sealed[i] = mapped.(domain.Event)
+	}
+	return s.store.WriteEvents(sealed)
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store,
+// decrypting names
+func (s *ErasableStore) ReadLatestSnapshot() (*Snapshot, error) {
+	stored, err := s.store.ReadLatestSnapshot()
+	if err != nil || stored == nil {
+		return stored, err
+	}
+	opened, err := domain.MapUsers(stored.Auctions, s.openName)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := *stored
+	snapshot.Auctions = opened.([]domain.AuctionSnapshot)
+	return &snapshot, nil
+}
+
+// WriteSnapshot encrypts names and writes a snapshot to the underlying store
+func (s *ErasableStore) WriteSnapshot(snapshot Snapshot) error {
+	sealed, err := domain.MapUsers(snapshot.Auctions, s.sealName)
+	if err != nil {
+		return err
+	}
+	snapshot.Auctions = sealed.([]domain.AuctionSnapshot)
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the underlying store
+func (s *ErasableStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// sealName encrypts the name of a user with the user's key
+func (s *ErasableStore) sealName(user domain.User) (domain.User, error) {
+	if user.Name == "" || strings.HasPrefix(user.Name, erasedPrefix) {
+		return user, nil
+	}
+	key, err := s.keys.KeyFor(user.ID)
+	if err != nil {
+		return user, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return user, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return user, err
+	}
+	// The user ID is authenticated so a name can't be moved to another user
+	sealed := aead.Seal(nonce, nonce, []byte(user.Name), []byte(user.ID))
+	user.Name = erasedPrefix + base64.RawURLEncoding.EncodeToString(sealed)
+	return user, nil
+}
+
+// openName decrypts the name of a user, which reads as forgotten once the
+// user's key is deleted
+func (s *ErasableStore) openName(user domain.User) (domain.User, error) {
+	if !strings.HasPrefix(user.Name, erasedPrefix) {
+		return user, nil
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(user.Name, erasedPrefix))
+	if err != nil {
+		return user, fmt.Errorf("invalid encrypted name of user %s: %v", user.ID, err)
+	}
+	user.Name = domain.ForgottenName
+	key, ok := s.keys.Key(user.ID)
+	if !ok {
+		return user, nil
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return user, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return user, nil
+	}
+	// A name sealed with an earlier key of the user stays forgotten
+	name, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(user.ID))
+	if err != nil {
+		return user, nil
+	}
+	user.Name = string(name)
+	return user, nil
+}
@@ -0,0 +1,127 @@
+package persistence
+
+import (
+	"log"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"auction-site-go/internal/domain"
+)
+
+// asyncQueueSize is the number of events an async subscriber may lag behind
+// before Publish blocks on it
+const asyncQueueSize = 1000
+
+// EventHandler receives published events
+type EventHandler func(event domain.Event)
+
+// EventBus delivers events to the components subscribed to their types, such
+// as notifications and projections, once the events are stored. Sync
+// subscribers run in Publish, in subscription order; async subscribers each
+// run on their own goroutine, receiving events in publication order. A
+// panicking subscriber is logged and counted without affecting the others.
+type EventBus struct {
+	mu            sync.RWMutex
+	subscriptions []*subscription
+	wg            sync.WaitGroup
+
+	panics int64
+}
+
+// subscription is a handler for some types of events, all when types is empty
+type subscription struct {
+	types   map[reflect.Type]bool
+	handler EventHandler
+	queue   chan domain.Event
+}
+
+// NewEventBus creates an event bus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers a handler run in Publish for events of the types of
+// the given sample values, such as domain.BidAcceptedEvent{}, or for all events
+func (b *EventBus) Subscribe(handler EventHandler, types ...domain.Event) {
+	b.subscribe(&subscription{types: eventTypes(types), handler: handler})
+}
+
+// SubscribeAsync registers a handler run on its own goroutine for events of
+// the types of the given sample values, or for all events
+func (b *EventBus) SubscribeAsync(handler EventHandler, types ...domain.Event) {
+	s := &subscription{types: eventTypes(types), handler: handler, queue: make(chan domain.Event, asyncQueueSize)}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for event := range s.queue {
+			b.deliver(s, event)
+		}
+	}()
+	b.subscribe(s)
+}
+
+func (b *EventBus) subscribe(s *subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, s)
+}
+
+// Publish delivers an event to its subscribers. Call it only once the event
+// is stored, so subscribers never act on events that may be lost.
+func (b *EventBus) Publish(event domain.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	eventType := reflect.TypeOf(event)
+	for _, s := range b.subscriptions {
+		if len(s.types) > 0 && !s.types[eventType] {
+			continue
+		}
+		if s.queue != nil {
+			s.queue <- event
+		} else {
+			b.deliver(s, event)
+		}
+	}
+}
+
+// Close stops accepting events and waits for the async subscribers to handle
+// the events published so far
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	for _, s := range b.subscriptions {
+		if s.queue != nil {
+			close(s.queue)
+		}
+	}
+	b.subscriptions = nil
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+// Panics returns the number of deliveries that panicked
+func (b *EventBus) Panics() int64 {
+	return atomic.LoadInt64(&b.panics)
+}
+
+// deliver runs a handler, recovering from its panics
+func (b *EventBus) deliver(s *subscription, event domain.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			atomic.AddInt64(&b.panics, 1)
+			log.Printf("event subscriber panicked on %T: %v", event, r)
+		}
+	}()
+	s.handler(event)
+}
+
+// eventTypes returns the set of the types of sample events
+func eventTypes(samples []domain.Event) map[reflect.Type]bool {
+	types := make(map[reflect.Type]bool, len(samples))
+	for _, sample := range samples {
+		types[reflect.TypeOf(sample)] = true
+	}
+	return types
+}
@@ -0,0 +1,252 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// IdentifiedEvent is an event stored with its ID by UniqueEventStore
+type IdentifiedEvent struct {
+	ID    string       `json:"id"`
+	Event domain.Event `json:"event"`
+}
+
+// GetTime returns the time of the event
+func (e IdentifiedEvent) GetTime() time.Time {
+	return e.Event.GetTime()
+}
+
+// MarshalJSON implements json.Marshaler interface for IdentifiedEvent
+func (e IdentifiedEvent) MarshalJSON() ([]byte, error) {
+	type identifiedEventJSON IdentifiedEvent
+	return domain.MarshalEnvelope("Identified", identifiedEventJSON(e))
+}
+
+func init() {
+	domain.RegisterEventType("Identified", func(data []byte) (domain.Event, error) {
+		var stored struct {
+			ID    string          `json:"id"`
+			Event json.RawMessage `json:"event"`
+		}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, err
+		}
+		event, err := domain.UnmarshalEvent(stored.Event)
+		if err != nil {
+			return nil, err
+		}
+		return IdentifiedEvent{ID: stored.ID, Event: event}, nil
+	})
+}
+
+// UniqueEventStore is a Store decorator enforcing that event IDs are unique.
+// An event's ID is derived from its aggregate and its version there, see
+// domain.EventIds, so an append of an event identical to the latest of its
+// aggregate, which can only be a retry of that append, would take that
+// event's ID again and is refused with a DuplicateEvent error. Versions are
+// loaded from the underlying store on the first write.
+//
+// Events are stored with their ID, as IdentifiedEvent, and read without it.
+// It must be above the decorators transforming payloads, which would make
+// retries look different, and below those expecting plain events, such as
+// ValidatingStore.
+type UniqueEventStore struct {
+	store Store
+
+	mu       sync.Mutex
+	versions map[string]int64
+	latest   map[string][]byte
+}
+
+// NewUniqueEventStore wraps a store with event ID checks
+func NewUniqueEventStore(store Store) *UniqueEventStore {
+	return &UniqueEventStore{store: store}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *UniqueEventStore) ReadCommands() ([]domain.Command, error) {
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *UniqueEventStore) WriteCommands(commands []domain.Command) error {
+	return s.store.WriteCommands(commands)
+}
+
+// ReadEvents reads events from the underlying store
+func (s *UniqueEventStore) ReadEvents() ([]domain.Event, error) {
+	stored, err := s.store.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+	return unwrapEvents(stored), nil
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *UniqueEventStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	stored, err := s.store.ReadEventsSince(position)
+	if err != nil {
+		return nil, err
+	}
+	return unwrapEvents(stored), nil
+}
+
+// ReadEventIdsSince returns the IDs of the events after a position. Events
+// written before their IDs were stored get them by numbering the whole log.
+func (s *UniqueEventStore) ReadEventIdsSince(position int64) ([]string, error) {
+	stored, err := s.store.ReadEventsSince(position)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(stored))
+	for i, event := range stored {
+		if ids[i] = storedEventId(event); ids[i] == "" {
+			return s.numberEventIdsSince(position)
+		}
+	}
+	return ids, nil
+}
+
+// numberEventIdsSince returns the IDs of the events after a position by
+// numbering the events of each aggregate from the start of the log
+func (s *UniqueEventStore) numberEventIdsSince(position int64) ([]string, error) {
+	stored, err := s.store.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+	ids := eventIds(stored)
+	if position > int64(len(ids)) {
+		return nil, nil
+	}
+	return ids[position:], nil
+}
+
+// WriteEvents writes events to the underlying store, unless one of them
+// duplicates the latest event of its aggregate, in which case nothing is
+// written
+func (s *UniqueEventStore) WriteEvents(events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	versions := make(map[string]int64)
+	latest := make(map[string][]byte)
+	identified := make([]domain.Event, len(events))
+	for i, event := range events {
+		aggregate := domain.EventAggregate(event)
+		data, err := domain.MarshalEvent(event)
+		if err != nil {
+			return err
+		}
+		previous, ok := latest[aggregate]
+		if !ok {
+			previous = s.latest[aggregate]
+		}
+		version, ok := versions[aggregate]
+		if !ok {
+			version = s.versions[aggregate]
+		}
+		if previous != nil && bytes.Equal(data, previous) {
+			return domain.NewDuplicateEventError(domain.NewEventId(aggregate, version))
+		}
+		versions[aggregate] = version + 1
+		latest[aggregate] = data
+		identified[i] = IdentifiedEvent{ID: domain.NewEventId(aggregate, version+1), Event: event}
+	}
+
+	if err := s.store.WriteEvents(identified); err != nil {
+		// The events may have been written anyway, so versions are reloaded
+		s.versions = nil
+		return err
+	}
+	for aggregate, version := range versions {
+		s.versions[aggregate] = version
+		s.latest[aggregate] = latest[aggregate]
+	}
+	return nil
+}
+
+// load reads the versions and latest events of the aggregates, once
+func (s *UniqueEventStore) load() error {
+	if s.versions != nil {
+		return nil
+	}
+
+	events, err := s.ReadEvents()
+	if err != nil {
+		return err
+	}
+	versions := make(map[string]int64)
+	latest := make(map[string][]byte)
+	for _, event := range events {
+		aggregate := domain.EventAggregate(event)
+		data, err := domain.MarshalEvent(event)
+		if err != nil {
+			return err
+		}
+		versions[aggregate]++
+		latest[aggregate] = data
+	}
+	s.versions = versions
+	s.latest = latest
+	return nil
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *UniqueEventStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *UniqueEventStore) WriteSnapshot(snapshot Snapshot) error {
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *UniqueEventStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// unwrapEvents returns the events without the IDs they're stored with
+func unwrapEvents(stored []domain.Event) []domain.Event {
+	events := make([]domain.Event, len(stored))
+	for i, event := range stored {
+		if identified, ok := event.(IdentifiedEvent); ok {
+			event = identified.Event
+		}
+		events[i] = event
+	}
+	return events
+}
+
+// storedEventId returns the ID an event is stored with, or the ID of the
+// event an EventArchived event replaces, and "" if it has none
+func storedEventId(event domain.Event) string {
+	switch e := event.(type) {
+	case IdentifiedEvent:
+		return e.ID
+	case domain.EventArchivedEvent:
+		return e.EventId
+	}
+	return ""
+}
+
+// eventIds returns the IDs of all stored events, numbering those stored
+// without one
+func eventIds(stored []domain.Event) []string {
+	ids := domain.EventIds(unwrapEvents(stored))
+	for i, event := range stored {
+		if id := storedEventId(event); id != "" {
+			ids[i] = id
+		}
+	}
+	return ids
+}
@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// pendingWrite is an event append waiting for its group to be committed
+type pendingWrite struct {
+	events []domain.Event
+	done   chan error
+}
+
+// GroupCommitStore is a Store decorator batching event appends from
+// concurrent callers into a single write to the underlying store. A group is
+// committed when the window since its first append elapses or when it holds
+// maxBatch events, whichever comes first. WriteEvents only returns once its
+// group is committed, so an acknowledged append is as durable as without
+// batching.
+type GroupCommitStore struct {
+	store    Store
+	window   time.Duration
+	maxBatch int
+
+	// flushMu serializes commits, so groups reach the store in order
+	flushMu sync.Mutex
+
+	mu      sync.Mutex
+	pending []pendingWrite
+	size    int
+}
+
+// NewGroupCommitStore wraps a store with group commit of event appends
+func NewGroupCommitStore(store Store, window time.Duration, maxBatch int) *GroupCommitStore {
+	return &GroupCommitStore{
+		store:    store,
+		window:   window,
+		maxBatch: maxBatch,
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *GroupCommitStore) ReadCommands() ([]domain.Command, error) {
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *GroupCommitStore) WriteCommands(commands []domain.Command) error {
+	return s.store.WriteCommands(commands)
+}
+
+// ReadEvents reads events from the underlying store
+func (s *GroupCommitStore) ReadEvents() ([]domain.Event, error) {
+	return s.store.ReadEvents()
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *GroupCommitStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	return s.store.ReadEventsSince(position)
+}
+
+// WriteEvents adds events to the current group and waits for it to be committed
+func (s *GroupCommitStore) WriteEvents(events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	s.mu.Lock()
+	if len(s.pending) == 0 {
+		time.AfterFunc(s.window, s.flush)
+	}
+	s.pending = append(s.pending, pendingWrite{events: events, done: done})
+	s.size += len(events)
+	full := s.maxBatch > 0 && s.size >= s.maxBatch
+	s.mu.Unlock()
+
+	if full {
+		s.flush()
+	}
+	return <-done
+}
+
+// flush commits the current group, if any, and reports the result to its writers
+func (s *GroupCommitStore) flush() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	group := s.pending
+	s.pending = nil
+	s.size = 0
+	s.mu.Unlock()
+
+	if len(group) == 0 {
+		return
+	}
+
+	var events []domain.Event
+	for _, write := range group {
+		events = append(events, write.events...)
+	}
+	err := s.store.WriteEvents(events)
+	for _, write := range group {
+		write.done <- err
+	}
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *GroupCommitStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *GroupCommitStore) WriteSnapshot(snapshot Snapshot) error {
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *GroupCommitStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
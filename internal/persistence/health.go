@@ -0,0 +1,65 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// Healther is implemented by stores that can check whether they are able to
+// serve requests, for readiness probes
+type Healther interface {
+	// Ping returns an error if the store can't currently read or write
+	Ping(ctx context.Context) error
+}
+
+// Ping checks the health of a store, which is assumed healthy if it doesn't
+// implement Healther
+func Ping(ctx context.Context, store Store) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if healther, ok := store.(Healther); ok {
+		return healther.Ping(ctx)
+	}
+	return nil
+}
+
+// Ping checks that the directories of the files are writable
+func (s *FileStore) Ping(ctx context.Context) error {
+	for _, path := range []string{s.CommandsPath, s.EventsPath, s.SnapshotsPath} {
+		if path == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := checkWritable(filepath.Dir(path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping checks that the directory of the files is writable
+func (s *DailyFileStore) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return checkWritable(s.Dir)
+}
+
+// Ping always succeeds, the memory store has nothing to check
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// checkWritable creates and removes a file in a directory
+func checkWritable(dir string) error {
+	file, err := os.CreateTemp(dir, ".healthcheck-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,130 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"auction-site-go/internal/domain"
+)
+
+// IdempotentStore is a Store decorator enforcing that idempotency keys of
+// commands are unique, so a retried request doesn't write its command twice.
+// Keys are persisted with the commands, and loaded from the underlying store
+// on the first write.
+type IdempotentStore struct {
+	store Store
+
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// NewIdempotentStore wraps a store with idempotency key checks
+func NewIdempotentStore(store Store) *IdempotentStore {
+	return &IdempotentStore{store: store}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *IdempotentStore) ReadCommands() ([]domain.Command, error) {
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store, unless one of them
+// has an idempotency key that was already written, in which case a
+// DuplicateCommand error is returned and nothing is written
+func (s *IdempotentStore) WriteCommands(commands []domain.Command) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadKeys(); err != nil {
+		return err
+	}
+
+	batch := make(map[string]bool)
+	for _, cmd := range commands {
+		key := domain.CommandIdempotencyKey(cmd)
+		if key == "" {
+			continue
+		}
+		if s.keys[key] || batch[key] {
+			return domain.NewDuplicateCommandError(key)
+		}
+		batch[key] = true
+	}
+
+	if err := s.store.WriteCommands(commands); err != nil {
+		return err
+	}
+	for key := range batch {
+		s.keys[key] = true
+	}
+	return nil
+}
+
+// WriteCommandIdempotent writes a command under an idempotency key. It
+// returns false without writing when the key was already used.
+func (s *IdempotentStore) WriteCommandIdempotent(key string, cmd domain.Command) (bool, error) {
+	switch c := cmd.(type) {
+	case domain.AddAuctionCommand:
+		c.IdempotencyKey = key
+		cmd = c
+	case domain.PlaceBidCommand:
+		c.IdempotencyKey = key
+		cmd = c
+	}
+
+	err := s.WriteCommands([]domain.Command{cmd})
+	if domainErr, ok := err.(domain.DomainError); ok && domainErr.Type == domain.ErrorDuplicateCommand {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// loadKeys reads the idempotency keys of the stored commands, once
+func (s *IdempotentStore) loadKeys() error {
+	if s.keys != nil {
+		return nil
+	}
+
+	commands, err := s.store.ReadCommands()
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]bool)
+	for _, cmd := range commands {
+		if key := domain.CommandIdempotencyKey(cmd); key != "" {
+			keys[key] = true
+		}
+	}
+	s.keys = keys
+	return nil
+}
+
+// ReadEvents reads events from the underlying store
+func (s *IdempotentStore) ReadEvents() ([]domain.Event, error) {
+	return s.store.ReadEvents()
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *IdempotentStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	return s.store.ReadEventsSince(position)
+}
+
+// WriteEvents writes events to the underlying store
+func (s *IdempotentStore) WriteEvents(events []domain.Event) error {
+	return s.store.WriteEvents(events)
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *IdempotentStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *IdempotentStore) WriteSnapshot(snapshot Snapshot) error {
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *IdempotentStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
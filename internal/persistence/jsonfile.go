@@ -1,8 +1,10 @@
 package persistence
 
 import (
-	"encoding/json"
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -47,91 +49,113 @@ func ReadCommands(path string) ([]domain.Command, error) {
 
 // WriteCommands writes commands to a JSON file
 func WriteCommands(path string, commands []domain.Command) error {
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+	lines := make([][]byte, 0, len(commands))
+	for _, cmd := range commands {
+		data, err := domain.MarshalCommand(cmd)
+		if err != nil {
+			return fmt.Errorf("error marshaling command: %v", err)
+		}
+		lines = append(lines, data)
 	}
 
-	// Check if file exists
-	exists, err := fileExists(path)
+	return appendLines(path, lines)
+}
+
+// ReadEvents reads events from a JSON file
+func ReadEvents(path string) ([]domain.Event, error) {
+	return ReadEventsSince(path, 0)
+}
+
+// ReadEventsSince reads the events after the given position from a JSON file.
+// The position of an event is its 1-based line number, ignoring blank lines,
+// so only the lines after the position are decoded.
+func ReadEventsSince(path string, position int64) ([]domain.Event, error) {
+	events := make([]domain.Event, 0)
+	_, err := readEventLines(path, position, func(event domain.Event) {
+		events = append(events, event)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return events, nil
+}
 
-	var file *os.File
-	if !exists {
-		file, err = os.Create(path)
-	} else {
-		file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+// readEventLines streams the events of a JSON file, decoding only the lines
+// after the given position and passing them to fn. It returns the number of
+// events in the file.
+func readEventLines(path string, position int64, fn func(event domain.Event)) (int64, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
 	}
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer file.Close()
 
-	// Write commands
-	for _, cmd := range commands {
-		data, err := json.Marshal(cmd)
-		if err != nil {
-			return fmt.Errorf("error marshaling command: %v", err)
+	var current int64
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return current, readErr
 		}
 
-		if exists {
-			_, err = file.WriteString("\n")
-			if err != nil {
-				return err
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			current++
+			if current > position {
+				event, err := domain.UnmarshalEvent(line)
+				if err != nil {
+					return current, fmt.Errorf("error unmarshaling event: %v", err)
+				}
+				fn(event)
 			}
 		}
 
-		_, err = file.Write(data)
-		if err != nil {
-			return err
+		if readErr == io.EOF {
+			return current, nil
 		}
+	}
+}
 
-		exists = true
+// WriteEvents writes events to a JSON file
+func WriteEvents(path string, events []domain.Event) error {
+	lines := make([][]byte, 0, len(events))
+	for _, event := range events {
+		data, err := domain.MarshalEvent(event)
+		if err != nil {
+			return fmt.Errorf("error marshaling event: %v", err)
+		}
+		lines = append(lines, data)
 	}
 
-	return nil
+	return appendLines(path, lines)
 }
 
-// ReadEvents reads events from a JSON file
-func ReadEvents(path string) ([]domain.Event, error) {
-	exists, err := fileExists(path)
-	if err != nil {
-		return nil, err
-	}
-	if !exists {
-		return []domain.Event{}, nil
+// ReplaceEvents replaces the content of a JSON file with the given events.
+// The events are written to a temporary file first, so a failure keeps the
+// old content.
+func ReplaceEvents(path string, events []domain.Event) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
 	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+	if err := WriteEvents(tmp, events); err != nil {
+		return err
 	}
-
-	lines := strings.Split(string(data), "\n")
-	events := make([]domain.Event, 0, len(lines))
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		event, err := domain.UnmarshalEvent([]byte(line))
-		if err != nil {
-			return nil, fmt.Errorf("error unmarshaling event: %v", err)
+	if len(events) == 0 {
+		// Nothing was written, but the old content must still go
+		if err := os.WriteFile(tmp, nil, 0644); err != nil {
+			return err
 		}
-
-		events = append(events, event)
 	}
-
-	return events, nil
+	return os.Rename(tmp, path)
 }
 
-// WriteEvents writes events to a JSON file
-func WriteEvents(path string, events []domain.Event) error {
+// appendLines appends lines to a file, creating the file and its directory
+// if needed. Lines are separated by newlines, without a trailing one.
+func appendLines(path string, lines [][]byte) error {
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -155,13 +179,8 @@ func WriteEvents(path string, events []domain.Event) error {
 	}
 	defer file.Close()
 
-	// Write events
-	for _, event := range events {
-		data, err := json.Marshal(event)
-		if err != nil {
-			return fmt.Errorf("error marshaling event: %v", err)
-		}
-
+	// Write lines
+	for _, data := range lines {
 		if exists {
 			_, err = file.WriteString("\n")
 			if err != nil {
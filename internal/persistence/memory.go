@@ -0,0 +1,129 @@
+package persistence
+
+import (
+	"os"
+	"sync"
+
+	"auction-site-go/internal/domain"
+)
+
+// MemoryStore is a concurrency-safe Store keeping everything in memory, meant
+// for tests and demos
+type MemoryStore struct {
+	mu       sync.RWMutex
+	commands []domain.Command
+	events   []domain.Event
+	snapshot *Snapshot
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		commands: []domain.Command{},
+		events:   []domain.Event{},
+	}
+}
+
+// ReadCommands returns a copy of the stored commands
+func (s *MemoryStore) ReadCommands() ([]domain.Command, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	commands := make([]domain.Command, len(s.commands))
+	copy(commands, s.commands)
+	return commands, nil
+}
+
+// WriteCommands appends commands to the store
+func (s *MemoryStore) WriteCommands(commands []domain.Command) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands = append(s.commands, commands...)
+	return nil
+}
+
+// ReadEvents returns a copy of the stored events
+func (s *MemoryStore) ReadEvents() ([]domain.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyEvents(s.events), nil
+}
+
+// ReadEventsSince returns a copy of the events after the given position
+func (s *MemoryStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if position >= int64(len(s.events)) {
+		return []domain.Event{}, nil
+	}
+	if position < 0 {
+		position = 0
+	}
+	return copyEvents(s.events[position:]), nil
+}
+
+// WriteEvents appends events to the store
+func (s *MemoryStore) WriteEvents(events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// ReplaceEvents replaces the stored events
+func (s *MemoryStore) ReplaceEvents(events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = copyEvents(events)
+	return nil
+}
+
+// ReadLatestSnapshot returns the last written snapshot
+func (s *MemoryStore) ReadLatestSnapshot() (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot, nil
+}
+
+// WriteSnapshot replaces the kept snapshot
+func (s *MemoryStore) WriteSnapshot(snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot = &snapshot
+	return nil
+}
+
+// SnapshotToFiles writes the content of the store to JSONL files in the
+// FileStore format, replacing any existing files
+func (s *MemoryStore) SnapshotToFiles(commandsPath, eventsPath string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Write to temporary files first so a failed snapshot keeps the old one
+	commandsTmp := commandsPath + ".tmp"
+	eventsTmp := eventsPath + ".tmp"
+	for _, path := range []string{commandsTmp, eventsTmp} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := WriteCommands(commandsTmp, s.commands); err != nil {
+		return err
+	}
+	if err := WriteEvents(eventsTmp, s.events); err != nil {
+		return err
+	}
+
+	if err := os.Rename(commandsTmp, commandsPath); err != nil {
+		return err
+	}
+	return os.Rename(eventsTmp, eventsPath)
+}
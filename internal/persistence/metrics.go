@@ -0,0 +1,132 @@
+package persistence
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// storeMetrics holds one map of counters per backend label, published as
+// "store" through expvar
+var (
+	storeMetrics   = expvar.NewMap("store")
+	storeMetricsMu sync.Mutex
+)
+
+// backendMetrics returns the counters for a backend, creating them on first use
+func backendMetrics(backend string) *expvar.Map {
+	storeMetricsMu.Lock()
+	defer storeMetricsMu.Unlock()
+
+	if m, ok := storeMetrics.Get(backend).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map).Init()
+	storeMetrics.Set(backend, m)
+	return m
+}
+
+// MetricsStore is a Store decorator recording operation counts, latencies,
+// batch sizes and errors. For every operation it maintains the counters
+// "<op>.calls", "<op>.errors", "<op>.items" and "<op>.latencyMicros" under
+// the backend label.
+type MetricsStore struct {
+	store   Store
+	metrics *expvar.Map
+}
+
+// NewMetricsStore wraps a store, recording its metrics under the backend label
+func NewMetricsStore(store Store, backend string) *MetricsStore {
+	return &MetricsStore{
+		store:   store,
+		metrics: backendMetrics(backend),
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *MetricsStore) ReadCommands() ([]domain.Command, error) {
+	start := time.Now()
+	commands, err := s.store.ReadCommands()
+	s.record("ReadCommands", start, len(commands), err)
+	return commands, err
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *MetricsStore) WriteCommands(commands []domain.Command) error {
+	start := time.Now()
+	err := s.store.WriteCommands(commands)
+	s.record("WriteCommands", start, len(commands), err)
+	return err
+}
+
+// ReadEvents reads events from the underlying store
+func (s *MetricsStore) ReadEvents() ([]domain.Event, error) {
+	start := time.Now()
+	events, err := s.store.ReadEvents()
+	s.record("ReadEvents", start, len(events), err)
+	return events, err
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *MetricsStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	start := time.Now()
+	events, err := s.store.ReadEventsSince(position)
+	s.record("ReadEventsSince", start, len(events), err)
+	return events, err
+}
+
+// WriteEvents writes events to the underlying store
+func (s *MetricsStore) WriteEvents(events []domain.Event) error {
+	start := time.Now()
+	err := s.store.WriteEvents(events)
+	s.record("WriteEvents", start, len(events), err)
+	return err
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *MetricsStore) ReadLatestSnapshot() (*Snapshot, error) {
+	start := time.Now()
+	snapshot, err := s.store.ReadLatestSnapshot()
+	items := 0
+	if snapshot != nil {
+		items = len(snapshot.Auctions)
+	}
+	s.record("ReadLatestSnapshot", start, items, err)
+	return snapshot, err
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *MetricsStore) WriteSnapshot(snapshot Snapshot) error {
+	start := time.Now()
+	err := s.store.WriteSnapshot(snapshot)
+	s.record("WriteSnapshot", start, len(snapshot.Auctions), err)
+	return err
+}
+
+// Ping checks the health of the underlying store
+func (s *MetricsStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// record updates the counters of a single operation
+func (s *MetricsStore) record(op string, start time.Time, items int, err error) {
+	s.metrics.Add(op+".calls", 1)
+	s.metrics.Add(op+".items", int64(items))
+	s.metrics.Add(op+".latencyMicros", time.Since(start).Microseconds())
+	if err != nil {
+		s.metrics.Add(op+".errors", 1)
+	}
+}
+
+// Instrument wraps a store with metrics and, when a tracer is given, tracing,
+// so every backend is observed the same way without changes of its own
+func Instrument(store Store, backend string, tracer Tracer) Store {
+	store = NewMetricsStore(store, backend)
+	if tracer != nil {
+		store = NewTracingStore(store, backend, tracer)
+	}
+	return store
+}
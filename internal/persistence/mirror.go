@@ -0,0 +1,193 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"auction-site-go/internal/domain"
+)
+
+// MirrorStore is a Store decorator writing to a primary and a secondary store
+// while reading from the primary only, to migrate between backends without
+// downtime. The primary is authoritative: a failed secondary write is logged
+// and counted but doesn't fail the operation, and Verify reports where the
+// two sides diverged.
+type MirrorStore struct {
+	primary   Store
+	secondary Store
+
+	secondaryErrors int64
+}
+
+// NewMirrorStore creates a store mirroring writes of the primary to the secondary
+func NewMirrorStore(primary, secondary Store) *MirrorStore {
+	return &MirrorStore{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+// ReadCommands reads commands from the primary store
+func (s *MirrorStore) ReadCommands() ([]domain.Command, error) {
+	return s.primary.ReadCommands()
+}
+
+// WriteCommands writes commands to the primary, then to the secondary store
+func (s *MirrorStore) WriteCommands(commands []domain.Command) error {
+	if err := s.primary.WriteCommands(commands); err != nil {
+		return err
+	}
+	s.mirror("WriteCommands", s.secondary.WriteCommands(commands))
+	return nil
+}
+
+// ReadEvents reads events from the primary store
+func (s *MirrorStore) ReadEvents() ([]domain.Event, error) {
+	return s.primary.ReadEvents()
+}
+
+// ReadEventsSince reads events after a position from the primary store
+func (s *MirrorStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	return s.primary.ReadEventsSince(position)
+}
+
+// WriteEvents writes events to the primary, then to the secondary store
+func (s *MirrorStore) WriteEvents(events []domain.Event) error {
+	if err := s.primary.WriteEvents(events); err != nil {
+		return err
+	}
+	s.mirror("WriteEvents", s.secondary.WriteEvents(events))
+	return nil
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the primary store
+func (s *MirrorStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.primary.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the primary, then to the secondary store
+func (s *MirrorStore) WriteSnapshot(snapshot Snapshot) error {
+	if err := s.primary.WriteSnapshot(snapshot); err != nil {
+		return err
+	}
+	s.mirror("WriteSnapshot", s.secondary.WriteSnapshot(snapshot))
+	return nil
+}
+
+// Ping checks the health of the primary store. The secondary store doesn't
+// affect readiness, since its failures don't fail writes either.
+func (s *MirrorStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.primary)
+}
+
+// SecondaryErrors returns the number of writes that failed on the secondary store
+func (s *MirrorStore) SecondaryErrors() int64 {
+	return atomic.LoadInt64(&s.secondaryErrors)
+}
+
+// mirror records the outcome of a write to the secondary store
+func (s *MirrorStore) mirror(op string, err error) {
+	if err != nil {
+		atomic.AddInt64(&s.secondaryErrors, 1)
+		log.Printf("mirror %s to secondary store failed: %v", op, err)
+	}
+}
+
+// MirrorMismatch describes an entry that differs between the two sides
+type MirrorMismatch struct {
+	// Stream is "commands" or "events"
+	Stream string
+	// Position is the 1-based position of the entry in the stream
+	Position int64
+	Reason   string
+}
+
+// MirrorVerification is the result of comparing both sides of a MirrorStore
+type MirrorVerification struct {
+	Commands   int
+	Events     int
+	Mismatches []MirrorMismatch
+}
+
+// InSync tells whether both sides hold the same commands and events
+func (v MirrorVerification) InSync() bool {
+	return len(v.Mismatches) == 0
+}
+
+// Verify reads the commands and events of both stores and compares them entry
+// by entry through their JSON encoding
+func (s *MirrorStore) Verify() (MirrorVerification, error) {
+	var result MirrorVerification
+
+	primaryCommands, err := s.primary.ReadCommands()
+	if err != nil {
+		return result, err
+	}
+	secondaryCommands, err := s.secondary.ReadCommands()
+	if err != nil {
+		return result, err
+	}
+	result.Commands = len(primaryCommands)
+	for i := 0; i < len(primaryCommands) || i < len(secondaryCommands); i++ {
+		var p, q interface{}
+		if i < len(primaryCommands) {
+			p = primaryCommands[i]
+		}
+		if i < len(secondaryCommands) {
+			q = secondaryCommands[i]
+		}
+		if reason := compareEntries(p, q); reason != "" {
+			result.Mismatches = append(result.Mismatches, MirrorMismatch{Stream: "commands", Position: int64(i + 1), Reason: reason})
+		}
+	}
+
+	primaryEvents, err := s.primary.ReadEvents()
+	if err != nil {
+		return result, err
+	}
+	secondaryEvents, err := s.secondary.ReadEvents()
+	if err != nil {
+		return result, err
+	}
+	result.Events = len(primaryEvents)
+	for i := 0; i < len(primaryEvents) || i < len(secondaryEvents); i++ {
+		var p, q interface{}
+		if i < len(primaryEvents) {
+			p = primaryEvents[i]
+		}
+		if i < len(secondaryEvents) {
+			q = secondaryEvents[i]
+		}
+		if reason := compareEntries(p, q); reason != "" {
+			result.Mismatches = append(result.Mismatches, MirrorMismatch{Stream: "events", Position: int64(i + 1), Reason: reason})
+		}
+	}
+
+	return result, nil
+}
+
+// compareEntries returns why two entries differ, or an empty string
+func compareEntries(primary, secondary interface{}) string {
+	if secondary == nil {
+		return "missing on secondary"
+	}
+	if primary == nil {
+		return "missing on primary"
+	}
+	p, err := json.Marshal(primary)
+	if err != nil {
+		return fmt.Sprintf("primary entry can't be encoded: %v", err)
+	}
+	q, err := json.Marshal(secondary)
+	if err != nil {
+		return fmt.Sprintf("secondary entry can't be encoded: %v", err)
+	}
+	if !bytes.Equal(p, q) {
+		return "content differs"
+	}
+	return ""
+}
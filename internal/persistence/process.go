@@ -0,0 +1,199 @@
+package persistence
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// Process is a multi-step workflow reacting to events with follow-up
+// commands, such as invoicing the winner of an auction and then notifying
+// them. It must decide from the event alone, since its commands are the only
+// state the ProcessManager keeps.
+type Process interface {
+	// Name identifies the process in logs
+	Name() string
+	// Handle returns the commands to issue in response to an event
+	Handle(event domain.Event) []domain.Command
+}
+
+// ProcessManager runs processes on the events it observes, typically as an
+// async EventBus subscriber. The commands they issue are kept in an outbox
+// file until dispatched, so they survive restarts, and are retried with a
+// growing delay while dispatching fails. Domain errors are final rejections
+// and aren't retried.
+type ProcessManager struct {
+	outbox      string
+	dispatch    func(domain.Command) error
+	maxAttempts int
+	backoff     time.Duration
+
+	mu        sync.Mutex
+	processes []Process
+	pending   []outboxEntry
+	nextId    int64
+}
+
+// outboxEntry is a command waiting to be dispatched
+type outboxEntry struct {
+	id  int64
+	cmd domain.Command
+}
+
+// NewProcessManager creates a process manager dispatching commands with up
+// to maxAttempts attempts each, waiting backoff, then twice as long, between
+// attempts
+func NewProcessManager(outbox string, dispatch func(domain.Command) error, maxAttempts int, backoff time.Duration) *ProcessManager {
+	return &ProcessManager{
+		outbox:      outbox,
+		dispatch:    dispatch,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Register adds a process
+func (m *ProcessManager) Register(process Process) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processes = append(m.processes, process)
+}
+
+// Resume dispatches the commands left in the outbox by a previous run. Call
+// it at startup, before observing events.
+func (m *ProcessManager) Resume() error {
+	commands, err := ReadCommands(m.outbox)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.pending = nil
+	entries := m.add(commands)
+	m.mu.Unlock()
+
+	for _, entry := range entries {
+		m.run("outbox", entry)
+	}
+	return nil
+}
+
+// Observe runs the processes on an event and dispatches their commands
+func (m *ProcessManager) Observe(event domain.Event) {
+	m.mu.Lock()
+	processes := m.processes
+	m.mu.Unlock()
+
+	for _, process := range processes {
+		commands := process.Handle(event)
+		if len(commands) == 0 {
+			continue
+		}
+		entries, err := m.enqueue(commands)
+		if err != nil {
+			log.Printf("process %s failed to record its commands: %v", process.Name(), err)
+			continue
+		}
+		for _, entry := range entries {
+			m.run(process.Name(), entry)
+		}
+	}
+}
+
+// Pending returns the commands in the outbox
+func (m *ProcessManager) Pending() []domain.Command {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pendingCommands()
+}
+
+// run dispatches a command with retries, then removes it from the outbox
+func (m *ProcessManager) run(name string, entry outboxEntry) {
+	cmd := entry.cmd
+	delay := m.backoff
+	for attempt := 1; ; attempt++ {
+		err := m.dispatch(cmd)
+		if err == nil {
+			break
+		}
+		if _, ok := err.(domain.DomainError); ok {
+			log.Printf("process %s command %T rejected: %v", name, cmd, err)
+			break
+		}
+		if attempt >= m.maxAttempts {
+			// Kept in the outbox for the next Resume
+			log.Printf("process %s command %T failed after %d attempts: %v", name, cmd, attempt, err)
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	if err := m.dequeue(entry.id); err != nil {
+		log.Printf("process %s failed to update its outbox: %v", name, err)
+	}
+}
+
+// enqueue adds commands to the outbox
+func (m *ProcessManager) enqueue(commands []domain.Command) ([]outboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := WriteCommands(m.outbox, commands); err != nil {
+		return nil, err
+	}
+	return m.add(commands), nil
+}
+
+// add adds commands to the pending ones, the lock being held
+func (m *ProcessManager) add(commands []domain.Command) []outboxEntry {
+	entries := make([]outboxEntry, len(commands))
+	for i, cmd := range commands {
+		m.nextId++
+		entries[i] = outboxEntry{id: m.nextId, cmd: cmd}
+	}
+	m.pending = append(m.pending, entries...)
+	return entries
+}
+
+// pendingCommands returns the pending commands, the lock being held
+func (m *ProcessManager) pendingCommands() []domain.Command {
+	commands := make([]domain.Command, len(m.pending))
+	for i, entry := range m.pending {
+		commands[i] = entry.cmd
+	}
+	return commands
+}
+
+// dequeue removes a dispatched command from the outbox
+func (m *ProcessManager) dequeue(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, entry := range m.pending {
+		if entry.id == id {
+			m.pending = append(m.pending[:i:i], m.pending[i+1:]...)
+			break
+		}
+	}
+	return m.replaceOutbox()
+}
+
+// replaceOutbox rewrites the outbox with the pending commands, through a
+// temporary file so a failure keeps the old content
+func (m *ProcessManager) replaceOutbox() error {
+	tmp := m.outbox + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := WriteCommands(tmp, m.pendingCommands()); err != nil {
+		return err
+	}
+	if len(m.pending) == 0 {
+		if err := os.WriteFile(tmp, nil, 0644); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, m.outbox)
+}
@@ -0,0 +1,109 @@
+package persistence
+
+import (
+	"fmt"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// EventRewriter is a Store whose events can be replaced as a whole, which
+// archival needs to remove events from it
+type EventRewriter interface {
+	Store
+
+	// ReplaceEvents replaces all stored events
+	ReplaceEvents(events []domain.Event) error
+}
+
+// replaceEvents replaces the events of a store, if it can rewrite them
+func replaceEvents(store Store, events []domain.Event) error {
+	rewriter, ok := store.(EventRewriter)
+	if !ok {
+		return fmt.Errorf("%T can't replace events", store)
+	}
+	return rewriter.ReplaceEvents(events)
+}
+
+// ArchiveResult describes what ArchiveEvents moved
+type ArchiveResult struct {
+	Auctions []domain.AuctionId
+	Events   int
+}
+
+// ArchiveEvents moves the events of auctions that have ended, and whose last
+// event is older than maxAge, from the store to the archive. Archived
+// auctions are no longer part of the repository restored from the store.
+//
+// Each archived event is replaced by an EventArchived event keeping its ID,
+// so the other events keep their positions and projections resume where
+// they left off. Archival rewrites the store, so it must not run while the
+// store is in use. If the store has snapshots, a snapshot of the remaining
+// auctions is written so restoring stays consistent.
+func ArchiveEvents(store EventRewriter, archive Store, maxAge time.Duration, now time.Time) (ArchiveResult, error) {
+	// Events are moved as they are stored, with their IDs
+	stored, err := store.ReadEvents()
+	if err != nil {
+		return ArchiveResult{}, err
+	}
+	events := unwrapEvents(stored)
+
+	// Find the auctions that ended long enough ago
+	cutoff := now.Add(-maxAge)
+	lastEvent := make(map[domain.AuctionId]time.Time)
+	for _, event := range events {
+		if id, ok := domain.EventAuctionId(event); ok && event.GetTime().After(lastEvent[id]) {
+			lastEvent[id] = event.GetTime()
+		}
+	}
+	expired := make(map[domain.AuctionId]bool)
+	var result ArchiveResult
+	for id, entry := range domain.EventsToAuctionStates(events) {
+		if entry.State.Increment(now).HasEnded() && lastEvent[id].Before(cutoff) {
+			expired[id] = true
+			result.Auctions = append(result.Auctions, id)
+		}
+	}
+	if len(expired) == 0 {
+		return result, nil
+	}
+
+	ids := eventIds(stored)
+	var archived []domain.Event
+	kept := make([]domain.Event, len(events))
+	for i, event := range events {
+		if id, ok := domain.EventAuctionId(event); ok && expired[id] {
+			archived = append(archived, stored[i])
+			kept[i] = domain.EventArchivedEvent{Time: event.GetTime(), AuctionId: id, EventId: ids[i]}
+		} else {
+			kept[i] = stored[i]
+		}
+	}
+
+	// Archive first, so a failure can at worst leave events in both stores
+	if err := archive.WriteEvents(archived); err != nil {
+		return ArchiveResult{}, err
+	}
+	if err := store.ReplaceEvents(kept); err != nil {
+		return ArchiveResult{}, err
+	}
+	result.Events = len(archived)
+
+	snapshot, err := store.ReadLatestSnapshot()
+	if err != nil || snapshot == nil {
+		return result, err
+	}
+	remaining := unwrapEvents(kept)
+	auctions, err := domain.SnapshotRepository(domain.EventsToAuctionStates(remaining))
+	if err != nil {
+		return result, err
+	}
+	replaced := Snapshot{Position: int64(len(kept)), Auctions: auctions}
+	if snapshot.ReadModels != nil {
+		models, activity := foldReadModels(remaining)
+		activitySnapshot := activity.Snapshot()
+		replaced.ReadModels = &models
+		replaced.Activity = &activitySnapshot
+	}
+	return result, store.WriteSnapshot(replaced)
+}
@@ -0,0 +1,158 @@
+package persistence
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+
+	"auction-site-go/internal/domain"
+)
+
+// ShardedStore is a Store routing commands and events to one of several
+// shards by a hash of their auction ID. Reads are federated over all shards
+// and merged by time. Records without an auction ID go to the first shard.
+//
+// A batch spanning several shards is written shard by shard, so it is only
+// atomic when the underlying stores are and the batch touches a single shard.
+type ShardedStore struct {
+	shards []Store
+}
+
+// NewShardedStore creates a store over the given shards. The order of the
+// shards must stay the same between restarts.
+func NewShardedStore(shards ...Store) *ShardedStore {
+	return &ShardedStore{shards: shards}
+}
+
+// ShardFor returns the shard holding the given auction
+func (s *ShardedStore) ShardFor(id domain.AuctionId) Store {
+	return s.shards[s.shardIndex(id)]
+}
+
+// shardIndex hashes an auction ID to the index of its shard
+func (s *ShardedStore) shardIndex(id domain.AuctionId) int {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(id))
+	h := fnv.New32a()
+	h.Write(buf[:])
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// ReadCommands reads the commands of all shards, ordered by time
+func (s *ShardedStore) ReadCommands() ([]domain.Command, error) {
+	var commands []domain.Command
+	for _, shard := range s.shards {
+		shardCommands, err := shard.ReadCommands()
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, shardCommands...)
+	}
+
+	// Stable, so the order within a shard is kept for equal times
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].GetTime().Before(commands[j].GetTime())
+	})
+	return commands, nil
+}
+
+// WriteCommands writes each command to the shard of its auction
+func (s *ShardedStore) WriteCommands(commands []domain.Command) error {
+	batches := make([][]domain.Command, len(s.shards))
+	for _, cmd := range commands {
+		index := 0
+		if id, ok := domain.CommandAuctionId(cmd); ok {
+			index = s.shardIndex(id)
+		}
+		batches[index] = append(batches[index], cmd)
+	}
+
+	for i, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		if err := s.shards[i].WriteCommands(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadEvents reads the events of all shards, ordered by time
+func (s *ShardedStore) ReadEvents() ([]domain.Event, error) {
+	var events []domain.Event
+	for _, shard := range s.shards {
+		shardEvents, err := shard.ReadEvents()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, shardEvents...)
+	}
+
+	// Stable, so the order within a shard is kept for equal times
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].GetTime().Before(events[j].GetTime())
+	})
+	return events, nil
+}
+
+// ReadEventsSince reads the events after a position of the merged,
+// time-ordered stream of all shards. Positions are only stable as long as
+// every shard is appended to in time order.
+func (s *ShardedStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	events, err := s.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+	if position >= int64(len(events)) {
+		return []domain.Event{}, nil
+	}
+	if position < 0 {
+		position = 0
+	}
+	return events[position:], nil
+}
+
+// WriteEvents writes each event to the shard of its auction
+func (s *ShardedStore) WriteEvents(events []domain.Event) error {
+	batches := make([][]domain.Event, len(s.shards))
+	for _, event := range events {
+		index := 0
+		if id, ok := domain.EventAuctionId(event); ok {
+			index = s.shardIndex(id)
+		}
+		batches[index] = append(batches[index], event)
+	}
+
+	for i, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		if err := s.shards[i].WriteEvents(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadLatestSnapshot reads the latest snapshot, which is kept on the first shard
+func (s *ShardedStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.shards[0].ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the first shard. Snapshots cover all
+// shards, since their position is one of the merged stream.
+func (s *ShardedStore) WriteSnapshot(snapshot Snapshot) error {
+	return s.shards[0].WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of every shard
+func (s *ShardedStore) Ping(ctx context.Context) error {
+	for _, shard := range s.shards {
+		if err := Ping(ctx, shard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package persistence
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// Names of the lifecycle monitors, also used as the prefix of their metrics
+const (
+	SLAClosing    = "closing"
+	SLADispatch   = "dispatch"
+	SLAProjection = "projection"
+)
+
+// slaMetrics counts the checks and violations of each monitor, published as
+// the "sla" expvar
+var slaMetrics = expvar.NewMap("sla")
+
+// SLALimits are the bounds asserted on the auction lifecycle, zero disables
+// the corresponding monitor
+type SLALimits struct {
+	// Closing is how long after its expiry an auction may remain without a
+	// result, such as a tender still awaiting the reveal of its bids
+	Closing time.Duration
+	// Dispatch is how long after an event a subscriber may handle it
+	Dispatch time.Duration
+	// ProjectionLag is how many events a projection may lag behind the head
+	ProjectionLag int64
+}
+
+// SLAViolation is the alert raised when a limit is exceeded
+type SLAViolation struct {
+	Time    time.Time `json:"at"`
+	Monitor string    `json:"monitor"`
+	Subject string    `json:"subject"`
+	Value   string    `json:"value"`
+	Limit   string    `json:"limit"`
+}
+
+// GetTime returns when the violation was detected
+func (v SLAViolation) GetTime() time.Time {
+	return v.Time
+}
+
+func (v SLAViolation) String() string {
+	return fmt.Sprintf("%s of %s is %s, over %s", v.Monitor, v.Subject, v.Value, v.Limit)
+}
+
+// SLAMonitor asserts the timeliness of the auction lifecycle: auctions get
+// their result within a limit of their expiry, subscribers handle events
+// within a limit of their time, and projections stay within a number of
+// events of the head. Violations are counted in the "sla" expvar and passed
+// to the alert handler, once per subject until it recovers.
+type SLAMonitor struct {
+	limits         SLALimits
+	getCurrentTime func() time.Time
+	onAlert        func(SLAViolation)
+
+	mu          sync.Mutex
+	repo        domain.Repository
+	head        int64
+	projections map[string]func() int64
+	alerted     map[string]bool
+}
+
+// NewSLAMonitor creates a monitor starting from a repository at the given
+// event position
+func NewSLAMonitor(limits SLALimits, repo domain.Repository, position int64, getCurrentTime func() time.Time, onAlert func(SLAViolation)) *SLAMonitor {
+	return &SLAMonitor{
+		limits:         limits,
+		getCurrentTime: getCurrentTime,
+		onAlert:        onAlert,
+		repo:           repo,
+		head:           position,
+		projections:    make(map[string]func() int64),
+		alerted:        make(map[string]bool),
+	}
+}
+
+// Observe folds a stored event, advancing the head. Subscribe it to the
+// event bus before the subscribers it monitors.
+func (m *SLAMonitor) Observe(event domain.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.repo = domain.ApplyEvents(m.repo, []domain.Event{event})
+	m.head++
+}
+
+// Dispatched wraps a subscriber, asserting it handles events within the
+// dispatch limit of their time
+func (m *SLAMonitor) Dispatched(name string, handler EventHandler) EventHandler {
+	return func(event domain.Event) {
+		handler(event)
+		if m.limits.Dispatch <= 0 {
+			return
+		}
+		now := m.getCurrentTime()
+		delay := now.Sub(event.GetTime())
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.assert(SLADispatch, name, delay > m.limits.Dispatch, now, delay.String(), m.limits.Dispatch.String())
+	}
+}
+
+// Projection registers a projection by the function returning the position
+// of the last event it has handled
+func (m *SLAMonitor) Projection(name string, position func() int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.projections[name] = position
+}
+
+// Check asserts the closing of the auctions and the lag of the projections
+// at the current time
+func (m *SLAMonitor) Check() {
+	now := m.getCurrentTime()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.limits.Closing > 0 {
+		for id, entry := range m.repo {
+			expiry := domain.CurrentExpiry(entry.State.Increment(now))
+			if expiry.IsZero() || now.Before(expiry) {
+				continue
+			}
+			delay := now.Sub(expiry)
+			late := entry.Auction.AwaitingReveal() && delay > m.limits.Closing
+			m.assert(SLAClosing, fmt.Sprintf("auction/%d", id), late, now, delay.String(), m.limits.Closing.String())
+		}
+	}
+
+	if m.limits.ProjectionLag > 0 {
+		for name, position := range m.projections {
+			lag := m.head - position()
+			m.assert(SLAProjection, name, lag > m.limits.ProjectionLag, now, fmt.Sprintf("%d events", lag), fmt.Sprintf("%d events", m.limits.ProjectionLag))
+		}
+	}
+}
+
+// assert counts a check and alerts on a violation, unless the subject is
+// already in violation
+func (m *SLAMonitor) assert(monitor, subject string, violated bool, now time.Time, value, limit string) {
+	slaMetrics.Add(monitor+".checks", 1)
+	key := monitor + " " + subject
+	if !violated {
+		delete(m.alerted, key)
+		return
+	}
+	slaMetrics.Add(monitor+".violations", 1)
+	if m.alerted[key] {
+		return
+	}
+	m.alerted[key] = true
+	if m.onAlert != nil {
+		m.onAlert(SLAViolation{Time: now, Monitor: monitor, Subject: subject, Value: value, Limit: limit})
+	}
+}
@@ -0,0 +1,244 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"auction-site-go/internal/domain"
+)
+
+// Snapshot is the state of all auctions after the event at Position
+type Snapshot struct {
+	Position int64                    `json:"position"`
+	Auctions []domain.AuctionSnapshot `json:"auctions,omitempty"`
+
+	// ReadModels and Activity are the other read models after the event at
+	// Position, missing from snapshots written without them
+	ReadModels *domain.ReadModels       `json:"readModels,omitempty"`
+	Activity   *domain.ActivitySnapshot `json:"activity,omitempty"`
+
+	// Sealed holds the encrypted auctions of a snapshot written through an
+	// EncryptedStore, SealedReadModels its encrypted read models
+	Sealed           *SealedPayload `json:"sealed,omitempty"`
+	SealedReadModels *SealedPayload `json:"sealedReadModels,omitempty"`
+}
+
+// ReadLatestSnapshot reads the last snapshot from a JSON file, returning nil
+// when there is none
+func ReadLatestSnapshot(path string) (*Snapshot, error) {
+	exists, err := fileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+
+		var snapshot Snapshot
+		if err := json.Unmarshal([]byte(line), &snapshot); err != nil {
+			return nil, fmt.Errorf("error unmarshaling snapshot: %v", err)
+		}
+		return &snapshot, nil
+	}
+
+	return nil, nil
+}
+
+// WriteSnapshot appends a snapshot to a JSON file
+func WriteSnapshot(path string, snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error marshaling snapshot: %v", err)
+	}
+	return appendLines(path, [][]byte{data})
+}
+
+// LoadRepository restores the repository from the latest snapshot and the
+// events written after it. It returns the repository and the position of
+// the last event.
+func LoadRepository(store Store) (domain.Repository, int64, error) {
+	repo := make(domain.Repository)
+	var position int64
+
+	snapshot, err := store.ReadLatestSnapshot()
+	if err != nil {
+		return nil, 0, err
+	}
+	if snapshot != nil {
+		if repo, err = domain.RestoreRepository(snapshot.Auctions); err != nil {
+			return nil, 0, err
+		}
+		position = snapshot.Position
+	}
+
+	events, err := store.ReadEventsSince(position)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return domain.ApplyEvents(repo, events), position + int64(len(events)), nil
+}
+
+// RestoredState is the state restored from a store after the event at Position
+type RestoredState struct {
+	Repository domain.Repository
+	ReadModels domain.ReadModels
+	Activity   *domain.ActivityFeed
+	Position   int64
+}
+
+// LoadState restores the repository and the read models from the latest
+// snapshot and the events written after it. Read models missing from the
+// snapshot are folded from all events.
+func LoadState(store Store) (RestoredState, error) {
+	repo := make(domain.Repository)
+	var position int64
+
+	snapshot, err := store.ReadLatestSnapshot()
+	if err != nil {
+		return RestoredState{}, err
+	}
+	if snapshot != nil {
+		if repo, err = domain.RestoreRepository(snapshot.Auctions); err != nil {
+			return RestoredState{}, err
+		}
+		position = snapshot.Position
+	}
+
+	if snapshot == nil || snapshot.ReadModels == nil || snapshot.Activity == nil {
+		events, err := store.ReadEvents()
+		if err != nil {
+			return RestoredState{}, err
+		}
+		if int64(len(events)) < position {
+			return RestoredState{}, fmt.Errorf("snapshot at position %d is past the %d events", position, len(events))
+		}
+		models, activity := foldReadModels(events)
+		return RestoredState{
+			Repository: domain.ApplyEvents(repo, events[position:]),
+			ReadModels: models,
+			Activity:   activity,
+			Position:   int64(len(events)),
+		}, nil
+	}
+
+	events, err := store.ReadEventsSince(position)
+	if err != nil {
+		return RestoredState{}, err
+	}
+	activity := domain.RestoreActivityFeed(*snapshot.Activity, repo)
+	for _, event := range events {
+		activity.Observe(event)
+	}
+	return RestoredState{
+		Repository: domain.ApplyEvents(repo, events),
+		ReadModels: snapshot.ReadModels.Apply(events),
+		Activity:   activity,
+		Position:   position + int64(len(events)),
+	}, nil
+}
+
+// foldReadModels folds the read models and the activity feed from all events
+func foldReadModels(events []domain.Event) (domain.ReadModels, *domain.ActivityFeed) {
+	activity := domain.NewActivityFeed()
+	for _, event := range events {
+		activity.Observe(event)
+	}
+	return domain.EventsToReadModels(events), activity
+}
+
+// Snapshotter keeps its own repository up to date with the written events and
+// writes a snapshot of it every N events
+type Snapshotter struct {
+	store Store
+	every int64
+
+	mu           sync.Mutex
+	repo         domain.Repository
+	models       *domain.ReadModels
+	activity     *domain.ActivityFeed
+	position     int64
+	lastSnapshot int64
+}
+
+// NewSnapshotter creates a snapshotter starting from a repository at the
+// given event position. A snapshot is written every `every` events.
+func NewSnapshotter(store Store, every int64, repo domain.Repository, position int64) *Snapshotter {
+	return &Snapshotter{
+		store:        store,
+		every:        every,
+		repo:         repo,
+		position:     position,
+		lastSnapshot: position,
+	}
+}
+
+// KeepReadModels makes the snapshotter fold the read models and the activity
+// feed too, starting from the ones at its position, and write them in its
+// snapshots
+func (s *Snapshotter) KeepReadModels(models domain.ReadModels, activity domain.ActivitySnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.models = &models
+	s.activity = domain.RestoreActivityFeed(activity, s.repo)
+}
+
+// Observe folds events that have been written to the store, and writes a
+// snapshot once enough events have been written since the last one
+func (s *Snapshotter) Observe(events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.repo = domain.ApplyEvents(s.repo, events)
+	if s.models != nil {
+		models := s.models.Apply(events)
+		s.models = &models
+		for _, event := range events {
+			s.activity.Observe(event)
+		}
+	}
+	s.position += int64(len(events))
+
+	if s.position-s.lastSnapshot < s.every {
+		return nil
+	}
+
+	auctions, err := domain.SnapshotRepository(s.repo)
+	if err != nil {
+		return err
+	}
+	snapshot := Snapshot{Position: s.position, Auctions: auctions}
+	if s.models != nil {
+		activity := s.activity.Snapshot()
+		snapshot.ReadModels = s.models
+		snapshot.Activity = &activity
+	}
+	if err := s.store.WriteSnapshot(snapshot); err != nil {
+		return err
+	}
+	s.lastSnapshot = s.position
+	return nil
+}
+
+// Position returns the position of the last event the snapshotter has folded
+func (s *Snapshotter) Position() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.position
+}
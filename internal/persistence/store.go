@@ -0,0 +1,103 @@
+package persistence
+
+import (
+	"auction-site-go/internal/domain"
+)
+
+// Store persists the commands and events of the application
+type Store interface {
+	// ReadCommands returns all stored commands in the order they were written
+	ReadCommands() ([]domain.Command, error)
+
+	// WriteCommands appends commands to the store
+	WriteCommands(commands []domain.Command) error
+
+	// ReadEvents returns all stored events in the order they were written
+	ReadEvents() ([]domain.Event, error)
+
+	// ReadEventsSince returns the events after the given position, where the
+	// position of an event is its 1-based sequence number in the store.
+	// ReadEventsSince(0) is the same as ReadEvents.
+	ReadEventsSince(position int64) ([]domain.Event, error)
+
+	// WriteEvents appends events to the store
+	WriteEvents(events []domain.Event) error
+
+	// ReadLatestSnapshot returns the most recent snapshot, or nil if there is none
+	ReadLatestSnapshot() (*Snapshot, error)
+
+	// WriteSnapshot stores a snapshot
+	WriteSnapshot(snapshot Snapshot) error
+}
+
+// FileStore is a Store backed by newline-delimited JSON files
+type FileStore struct {
+	CommandsPath  string
+	EventsPath    string
+	SnapshotsPath string
+}
+
+// NewFileStore creates a new file store
+func NewFileStore(commandsPath, eventsPath, snapshotsPath string) *FileStore {
+	return &FileStore{
+		CommandsPath:  commandsPath,
+		EventsPath:    eventsPath,
+		SnapshotsPath: snapshotsPath,
+	}
+}
+
+// ReadCommands reads commands from the commands file
+func (s *FileStore) ReadCommands() ([]domain.Command, error) {
+	return ReadCommands(s.CommandsPath)
+}
+
+// WriteCommands appends commands to the commands file
+func (s *FileStore) WriteCommands(commands []domain.Command) error {
+	return WriteCommands(s.CommandsPath, commands)
+}
+
+// ReadEvents reads events from the events file
+func (s *FileStore) ReadEvents() ([]domain.Event, error) {
+	return ReadEvents(s.EventsPath)
+}
+
+// ReadEventsSince reads the events after the given line of the events file
+func (s *FileStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	return ReadEventsSince(s.EventsPath, position)
+}
+
+// WriteEvents appends events to the events file
+func (s *FileStore) WriteEvents(events []domain.Event) error {
+	return WriteEvents(s.EventsPath, events)
+}
+
+// ReadLatestSnapshot reads the last snapshot of the snapshots file
+func (s *FileStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return ReadLatestSnapshot(s.SnapshotsPath)
+}
+
+// WriteSnapshot appends a snapshot to the snapshots file
+func (s *FileStore) WriteSnapshot(snapshot Snapshot) error {
+	return WriteSnapshot(s.SnapshotsPath, snapshot)
+}
+
+// ReplaceEvents replaces the content of the events file
+func (s *FileStore) ReplaceEvents(events []domain.Event) error {
+	return ReplaceEvents(s.EventsPath, events)
+}
+
+// ReadAuctionEvents reads the events belonging to a single auction from a store
+func ReadAuctionEvents(store Store, id domain.AuctionId) ([]domain.Event, error) {
+	events, err := store.ReadEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	auctionEvents := make([]domain.Event, 0)
+	for _, event := range events {
+		if eventId, ok := domain.EventAuctionId(event); ok && eventId == id {
+			auctionEvents = append(auctionEvents, event)
+		}
+	}
+	return auctionEvents, nil
+}
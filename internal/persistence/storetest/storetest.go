@@ -0,0 +1,327 @@
+// Package storetest is a conformance test suite for Store implementations,
+// checking the guarantees the rest of the application relies on
+package storetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// Options tells which optional guarantees a store provides
+type Options struct {
+	// SkipSnapshots skips the snapshot tests, for stores without a snapshot file
+	SkipSnapshots bool
+	// SkipConcurrency skips the concurrency tests, for stores that must not
+	// be shared between goroutines
+	SkipConcurrency bool
+}
+
+// Run runs the suite, calling newStore for a new empty store in each test
+func Run(t *testing.T, newStore func(t *testing.T) persistence.Store, options Options) {
+	t.Run("StartsEmpty", func(t *testing.T) { testStartsEmpty(t, newStore(t)) })
+	t.Run("KeepsWriteOrder", func(t *testing.T) { testKeepsWriteOrder(t, newStore(t)) })
+	t.Run("ReadsEventsSincePosition", func(t *testing.T) { testReadsEventsSince(t, newStore(t)) })
+	t.Run("RoundTripsCommands", func(t *testing.T) { testRoundTripsCommands(t, newStore(t)) })
+	t.Run("RoundTripsEvents", func(t *testing.T) { testRoundTripsEvents(t, newStore(t)) })
+	if !options.SkipSnapshots {
+		t.Run("ReturnsLatestSnapshot", func(t *testing.T) { testLatestSnapshot(t, newStore(t)) })
+	}
+	if !options.SkipConcurrency {
+		t.Run("KeepsConcurrentBatchesWhole", func(t *testing.T) { testConcurrentBatches(t, newStore(t)) })
+	}
+}
+
+// now is the time of the sample commands and events
+var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// sampleAuction returns an auction using every optional field
+func sampleAuction(id domain.AuctionId) domain.Auction {
+	auction := domain.NewAuction(id, now, fmt.Sprintf("auction %d", id), now.Add(time.Hour),
+		domain.NewBuyerOrSeller("seller", "Seller"),
+		domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	auction.Translations = map[string]string{"sv": "auktion"}
+	auction.Visibility = domain.VisibilityPrivate
+	auction.Invitees = []domain.UserId{"buyer"}
+	auction.Tags = []string{"spring"}
+	return auction
+}
+
+// sampleAdded returns the AuctionAdded event of a sample auction
+func sampleAdded(id domain.AuctionId) domain.Event {
+	return domain.AuctionAddedEvent{Time: now, Auction: sampleAuction(id)}
+}
+
+// sampleCommands returns a command of every type
+func sampleCommands() []domain.Command {
+	auctionId := domain.AuctionId(1)
+	expiry := now.Add(2 * time.Hour)
+	title, description := "Amended", "Oil on canvas"
+	return []domain.Command{
+		domain.AddAuctionCommand{Time: now, Auction: sampleAuction(1), IdempotencyKey: "key"},
+		domain.PlaceBidCommand{Time: now, Bid: sampleBid(), IdempotencyKey: "key"},
+		domain.FileReportCommand{Time: now, Report: sampleReport(auctionId)},
+		domain.ChangeReportStatusCommand{Time: now, ReportId: 1, Status: domain.ReportTriaged, By: "support"},
+		domain.TranslateListingCommand{Time: now, AuctionId: auctionId, Translation: sampleTranslation()},
+		domain.ReviseListingCommand{Time: now, AuctionId: auctionId, Expiry: &expiry, AddTags: []string{"summer"}},
+		domain.SubmitKeyShareCommand{Time: now, AuctionId: auctionId, Custodian: "custodian", Share: "AQID"},
+		domain.RevealTenderCommand{Time: now, AuctionId: auctionId},
+		domain.DetermineWinnerCommand{Time: now, AuctionId: auctionId},
+		domain.SendContactMessageCommand{Time: now, AuctionId: auctionId, Sender: "buyer", Text: "When can I pick it up?", Attachments: []domain.ContactAttachment{sampleAttachment()}},
+		domain.BuyNowCommand{Time: now, AuctionId: auctionId, Buyer: domain.NewBuyerOrSeller("buyer", "Buyer")},
+		domain.SetMaxBidCommand{Time: now, AuctionId: auctionId, Bidder: domain.NewBuyerOrSeller("buyer", "Buyer"), Max: 50},
+		domain.PlaceProxyBidCommand{Time: now, AuctionId: auctionId},
+		domain.AmendAuctionCommand{Time: now, AuctionId: auctionId, Seller: domain.NewBuyerOrSeller("seller", "Seller"), Title: &title, Description: &description, Expiry: &expiry},
+		domain.CancelAuctionCommand{Time: now, AuctionId: auctionId, By: domain.NewSupport("support"), Override: true, Reason: "counterfeit"},
+	}
+}
+
+// sampleEvents returns an event of every type
+func sampleEvents() []domain.Event {
+	auctionId := domain.AuctionId(1)
+	expiry := now.Add(2 * time.Hour)
+	title, description := "Amended", "Oil on canvas"
+	return []domain.Event{
+		sampleAdded(auctionId),
+		domain.BidAcceptedEvent{Time: now, Bid: sampleBid(), Metadata: &domain.EventMetadata{
+			CorrelationId: "request", CausationId: "command", UserId: "buyer", SourceIP: "203.0.113.7",
+		}},
+		domain.ReportFiledEvent{Time: now, Report: sampleReport(auctionId)},
+		domain.ReportStatusChangedEvent{Time: now, ReportId: 1, Status: domain.ReportTriaged, By: "support"},
+		domain.ListingModeratedEvent{Time: now, AuctionId: auctionId, Decision: domain.ModerationDecision{
+			Verdict: domain.ModerationReview, Reasons: []string{"keyword"}, Provider: "keywords",
+		}},
+		domain.RuleSetPublishedEvent{Time: now, RuleSet: domain.RuleSet{
+			Version:     1,
+			Rules:       []domain.ProhibitedItemRule{{Keyword: "ivory", Verdict: domain.ModerationRejected}},
+			PublishedAt: now,
+			PublishedBy: "support",
+		}},
+		domain.UserScreenedEvent{Time: now, UserId: "seller", Context: "listing", Result: domain.ScreeningResult{
+			Match: true, Lists: []string{"denied"}, Provider: "denylist",
+		}},
+		domain.ListingTranslatedEvent{Time: now, AuctionId: auctionId, Translation: sampleTranslation()},
+		domain.ListingRevisedEvent{Time: now, AuctionId: auctionId, Expiry: &expiry, AddTags: []string{"summer"}},
+		domain.KeyShareSubmittedEvent{Time: now, AuctionId: auctionId, Custodian: "custodian", Share: "AQID"},
+		domain.TenderRevealedEvent{Time: now, AuctionId: auctionId, Amounts: map[domain.UserId]int64{"buyer": 10}, Invalid: []domain.UserId{"other"}},
+		domain.WinnerDeterminedEvent{Time: now, AuctionId: auctionId, Winner: "buyer", Price: 10, Bids: 2},
+		domain.ReserveMetEvent{Time: now, AuctionId: auctionId},
+		domain.AuctionStartedEvent{Time: now, AuctionId: auctionId, StartsAt: now},
+		domain.AuctionAmendedEvent{Time: now, AuctionId: auctionId, Title: &title, Description: &description, Expiry: &expiry},
+		domain.AuctionCancelledEvent{Time: now, AuctionId: auctionId, By: "support", Override: true, Reason: "counterfeit", Bids: 2},
+		domain.UnitsAllocatedEvent{Time: now, AuctionId: auctionId, Settlement: domain.UniformPrice, Allocations: []domain.Allocation{{Bidder: "buyer", Amount: 12, Price: 10}}, Bids: 3},
+		domain.AuctionExtendedEvent{Time: now, AuctionId: auctionId, EndsAt: now.Add(time.Minute), Extensions: 1},
+		domain.ReserveNotMetEvent{Time: now, AuctionId: auctionId},
+		domain.BoughtNowEvent{Time: now, AuctionId: auctionId, Buyer: domain.NewBuyerOrSeller("buyer", "Buyer"), Price: 100},
+		domain.MaxBidSetEvent{Time: now, AuctionId: auctionId, Bidder: domain.NewBuyerOrSeller("buyer", "Buyer"), Max: 50},
+		domain.ProxyBidPlacedEvent{Time: now, Bid: sampleBid(), Max: 50},
+		domain.ContactMessageSentEvent{Time: now, AuctionId: auctionId, Message: domain.ContactMessage{
+			Seq: 1, At: now, Sender: "buyer", Role: domain.ContactWinner, Text: "When can I pick it up?", Attachments: []domain.ContactAttachment{sampleAttachment()},
+		}},
+	}
+}
+
+func sampleBid() domain.Bid {
+	return domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("buyer", "Buyer"), At: now, Amount: 10}
+}
+
+func sampleAttachment() domain.ContactAttachment {
+	return domain.ContactAttachment{Name: "receipt.pdf", ContentType: "application/pdf", Size: 1024}
+}
+
+func sampleReport(auctionId domain.AuctionId) domain.Report {
+	return domain.Report{
+		ID:       1,
+		Reporter: "buyer",
+		Target:   domain.ReportTarget{AuctionId: &auctionId},
+		Reason:   domain.ReportCounterfeit,
+		Text:     "fake",
+		Evidence: []string{"photo"},
+		Status:   domain.ReportOpen,
+		FiledAt:  now,
+		DueBy:    now.Add(24 * time.Hour),
+	}
+}
+
+func sampleTranslation() domain.Translation {
+	return domain.Translation{Language: "de", Title: "Auktion", Source: domain.TranslationSeller}
+}
+
+func testStartsEmpty(t *testing.T, store persistence.Store) {
+	commands, err := store.ReadCommands()
+	if err != nil || len(commands) != 0 {
+		t.Errorf("Expected no commands and no error, got %d and %v", len(commands), err)
+	}
+	events, err := store.ReadEvents()
+	if err != nil || len(events) != 0 {
+		t.Errorf("Expected no events and no error, got %d and %v", len(events), err)
+	}
+	events, err = store.ReadEventsSince(5)
+	if err != nil || len(events) != 0 {
+		t.Errorf("Expected no events since 5 and no error, got %d and %v", len(events), err)
+	}
+	snapshot, err := store.ReadLatestSnapshot()
+	if err != nil || snapshot != nil {
+		t.Errorf("Expected no snapshot and no error, got %v and %v", snapshot, err)
+	}
+}
+
+func testKeepsWriteOrder(t *testing.T, store persistence.Store) {
+	for i := 1; i <= 6; i += 2 {
+		batch := []domain.Event{sampleAdded(domain.AuctionId(i)), sampleAdded(domain.AuctionId(i + 1))}
+		if err := store.WriteEvents(batch); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	// An empty batch must not change anything
+	if err := store.WriteEvents(nil); err != nil {
+		t.Fatalf("Expected no error for an empty batch, got %v", err)
+	}
+
+	events, err := store.ReadEvents()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 6 {
+		t.Fatalf("Expected 6 events, got %d", len(events))
+	}
+	for i, event := range events {
+		if id, _ := domain.EventAuctionId(event); id != domain.AuctionId(i+1) {
+			t.Errorf("Expected auction %d at position %d, got %d", i+1, i+1, id)
+		}
+	}
+}
+
+func testReadsEventsSince(t *testing.T, store persistence.Store) {
+	if err := store.WriteEvents([]domain.Event{sampleAdded(1), sampleAdded(2), sampleAdded(3)}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for position, expected := range map[int64]int{0: 3, 1: 2, 3: 0, 10: 0} {
+		events, err := store.ReadEventsSince(position)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(events) != expected {
+			t.Errorf("Expected %d events since %d, got %d", expected, position, len(events))
+			continue
+		}
+		if expected > 0 {
+			if id, _ := domain.EventAuctionId(events[0]); id != domain.AuctionId(position+1) {
+				t.Errorf("Expected events since %d to start with auction %d, got %d", position, position+1, id)
+			}
+		}
+	}
+}
+
+func testRoundTripsCommands(t *testing.T, store persistence.Store) {
+	written := sampleCommands()
+	if err := store.WriteCommands(written); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	read, err := store.ReadCommands()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(read) != len(written) {
+		t.Fatalf("Expected %d commands, got %d", len(written), len(read))
+	}
+	for i := range written {
+		assertSameJSON(t, fmt.Sprintf("command %T", written[i]), written[i], read[i])
+	}
+}
+
+func testRoundTripsEvents(t *testing.T, store persistence.Store) {
+	written := sampleEvents()
+	if err := store.WriteEvents(written); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	read, err := store.ReadEvents()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(read) != len(written) {
+		t.Fatalf("Expected %d events, got %d", len(written), len(read))
+	}
+	for i := range written {
+		assertSameJSON(t, fmt.Sprintf("event %T", written[i]), written[i], read[i])
+	}
+}
+
+func testLatestSnapshot(t *testing.T, store persistence.Store) {
+	repo := domain.ApplyEvents(make(domain.Repository), []domain.Event{sampleAdded(1)})
+	auctions, err := domain.SnapshotRepository(repo)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, position := range []int64{1, 2} {
+		if err := store.WriteSnapshot(persistence.Snapshot{Position: position, Auctions: auctions}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	snapshot, err := store.ReadLatestSnapshot()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if snapshot == nil || snapshot.Position != 2 {
+		t.Fatalf("Expected the snapshot at position 2, got %v", snapshot)
+	}
+	assertSameJSON(t, "snapshot auctions", auctions, snapshot.Auctions)
+}
+
+func testConcurrentBatches(t *testing.T, store persistence.Store) {
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(id domain.AuctionId) {
+			defer wg.Done()
+			if err := store.WriteEvents([]domain.Event{sampleAdded(id), sampleAdded(id)}); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}(domain.AuctionId(i + 1))
+	}
+	wg.Wait()
+
+	events, err := store.ReadEvents()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 2*writers {
+		t.Fatalf("Expected %d events, got %d", 2*writers, len(events))
+	}
+	// Batches must not interleave
+	for i := 0; i < len(events); i += 2 {
+		first, _ := domain.EventAuctionId(events[i])
+		second, _ := domain.EventAuctionId(events[i+1])
+		if first != second {
+			t.Errorf("Expected a whole batch at positions %d and %d, got auctions %d and %d", i+1, i+2, first, second)
+		}
+	}
+}
+
+// assertSameJSON compares values by their JSON, the form they're stored in
+func assertSameJSON(t *testing.T, what string, expected, actual interface{}) {
+	t.Helper()
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Failed to marshal %s: %v", what, err)
+	}
+	actualJSON, err := json.Marshal(actual)
+	if err != nil {
+		t.Fatalf("Failed to marshal %s: %v", what, err)
+	}
+	if !bytes.Equal(expectedJSON, actualJSON) {
+		t.Errorf("Expected %s to round-trip as %s, got %s", what, expectedJSON, actualJSON)
+	}
+}
@@ -0,0 +1,164 @@
+package persistence
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// Span is a single traced operation
+type Span interface {
+	// SetAttribute attaches a key/value pair to the span
+	SetAttribute(key string, value interface{})
+
+	// End finishes the span, recording the error of the operation if any
+	End(err error)
+}
+
+// Tracer starts spans
+type Tracer interface {
+	Start(name string) Span
+}
+
+// LogTracer is a Tracer writing finished spans to the standard logger
+type LogTracer struct{}
+
+// Start starts a span that is logged when it ends
+func (LogTracer) Start(name string) Span {
+	return &logSpan{
+		name:       name,
+		start:      time.Now(),
+		attributes: make(map[string]interface{}),
+	}
+}
+
+// logSpan is the span created by LogTracer
+type logSpan struct {
+	name       string
+	start      time.Time
+	attributes map[string]interface{}
+}
+
+func (s *logSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *logSpan) End(err error) {
+	if err != nil {
+		log.Printf("span %s took %s %v error: %v", s.name, time.Since(s.start), s.attributes, err)
+		return
+	}
+	log.Printf("span %s took %s %v", s.name, time.Since(s.start), s.attributes)
+}
+
+// TracingStore is a Store decorator creating a span per operation with the
+// backend, batch size and affected auction IDs as attributes
+type TracingStore struct {
+	store   Store
+	backend string
+	tracer  Tracer
+}
+
+// NewTracingStore wraps a store, tracing its operations with the given tracer
+func NewTracingStore(store Store, backend string, tracer Tracer) *TracingStore {
+	return &TracingStore{
+		store:   store,
+		backend: backend,
+		tracer:  tracer,
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *TracingStore) ReadCommands() ([]domain.Command, error) {
+	span := s.start("ReadCommands")
+	commands, err := s.store.ReadCommands()
+	span.SetAttribute("batchSize", len(commands))
+	span.End(err)
+	return commands, err
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *TracingStore) WriteCommands(commands []domain.Command) error {
+	span := s.start("WriteCommands")
+	span.SetAttribute("batchSize", len(commands))
+	ids := make([]domain.AuctionId, 0, len(commands))
+	for _, cmd := range commands {
+		if id, ok := domain.CommandAuctionId(cmd); ok {
+			ids = append(ids, id)
+		}
+	}
+	span.SetAttribute("auctionIds", ids)
+	err := s.store.WriteCommands(commands)
+	span.End(err)
+	return err
+}
+
+// ReadEvents reads events from the underlying store
+func (s *TracingStore) ReadEvents() ([]domain.Event, error) {
+	span := s.start("ReadEvents")
+	events, err := s.store.ReadEvents()
+	span.SetAttribute("batchSize", len(events))
+	span.End(err)
+	return events, err
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *TracingStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	span := s.start("ReadEventsSince")
+	span.SetAttribute("position", position)
+	events, err := s.store.ReadEventsSince(position)
+	span.SetAttribute("batchSize", len(events))
+	span.End(err)
+	return events, err
+}
+
+// WriteEvents writes events to the underlying store
+func (s *TracingStore) WriteEvents(events []domain.Event) error {
+	span := s.start("WriteEvents")
+	span.SetAttribute("batchSize", len(events))
+	ids := make([]domain.AuctionId, 0, len(events))
+	for _, event := range events {
+		if id, ok := domain.EventAuctionId(event); ok {
+			ids = append(ids, id)
+		}
+	}
+	span.SetAttribute("auctionIds", ids)
+	err := s.store.WriteEvents(events)
+	span.End(err)
+	return err
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *TracingStore) ReadLatestSnapshot() (*Snapshot, error) {
+	span := s.start("ReadLatestSnapshot")
+	snapshot, err := s.store.ReadLatestSnapshot()
+	if snapshot != nil {
+		span.SetAttribute("position", snapshot.Position)
+	}
+	span.End(err)
+	return snapshot, err
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *TracingStore) WriteSnapshot(snapshot Snapshot) error {
+	span := s.start("WriteSnapshot")
+	span.SetAttribute("position", snapshot.Position)
+	span.SetAttribute("batchSize", len(snapshot.Auctions))
+	err := s.store.WriteSnapshot(snapshot)
+	span.End(err)
+	return err
+}
+
+// Ping checks the health of the underlying store
+func (s *TracingStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// start starts a span named after the operation, tagged with the backend
+func (s *TracingStore) start(op string) Span {
+	span := s.tracer.Start("store." + op)
+	span.SetAttribute("backend", s.backend)
+	return span
+}
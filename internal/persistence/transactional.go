@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+
+	"auction-site-go/internal/domain"
+)
+
+// ErrCheckpointMoved is returned by a commit whose checkpoint was advanced
+// by someone else since it was read, such as another instance catching up
+var ErrCheckpointMoved = errors.New("checkpoint moved")
+
+// TransactionalProjection is a projection keeping its checkpoint with its
+// read model, such as in the same database, so an update of the read model
+// and the checkpoint are committed together or not at all. Unlike with
+// Checkpoints, a crash can't leave events applied but not checkpointed, so
+// they are never applied twice.
+type TransactionalProjection interface {
+	// Name identifies the projection in logs
+	Name() string
+	// Checkpoint returns the position of the last committed event
+	Checkpoint() (int64, error)
+	// Commit applies the events after the checkpoint at from, and advances
+	// the checkpoint past them, in a single transaction. It fails with
+	// ErrCheckpointMoved, applying nothing, if the checkpoint isn't at from.
+	Commit(from int64, events []domain.Event) error
+}
+
+// CatchUpExactlyOnce feeds a transactional projection the events after its
+// checkpoint, committing them in batches of batchSize. On an error the
+// batches committed so far stay committed and the failed one is rolled back.
+func CatchUpExactlyOnce(store Store, projection TransactionalProjection, batchSize int) (ReplayResult, error) {
+	var result ReplayResult
+	from, err := projection.Checkpoint()
+	if err != nil {
+		return result, err
+	}
+
+	events, err := store.ReadEventsSince(from)
+	if err != nil {
+		return result, err
+	}
+	for start := 0; start < len(events); start += batchSize {
+		end := start + batchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		batch := events[start:end]
+		if err := projection.Commit(from, batch); err != nil {
+			return result, fmt.Errorf("projection %s failed committing the events after %d: %w", projection.Name(), from, err)
+		}
+		from += int64(len(batch))
+		result.Read += int64(len(batch))
+		result.Replayed += int64(len(batch))
+	}
+	return result, nil
+}
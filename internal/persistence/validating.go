@@ -0,0 +1,350 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// InvariantViolation describes why a single event of a batch was rejected
+type InvariantViolation struct {
+	Index     int
+	AuctionId domain.AuctionId
+	Reason    string
+}
+
+// InvariantViolationError is returned when a batch of events breaks the
+// invariants of the event history. Nothing of the batch is written.
+type InvariantViolationError struct {
+	Violations []InvariantViolation
+}
+
+func (e InvariantViolationError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		reasons[i] = fmt.Sprintf("event %d (auction %d): %s", v.Index, v.AuctionId, v.Reason)
+	}
+	return "invariant violation: " + strings.Join(reasons, "; ")
+}
+
+// ValidatingStore is a Store decorator that validates event batches before
+// appending them, as a last line of defense against corrupting the history:
+//   - events must be of a known type with a valid payload
+//   - an auction's stream must start with AuctionAdded, which occurs only once
+//   - timestamps must not decrease within an auction's stream
+type ValidatingStore struct {
+	store Store
+
+	mu       sync.Mutex
+	loaded   bool
+	lastSeen map[domain.AuctionId]time.Time
+}
+
+// NewValidatingStore wraps a store with event validation
+func NewValidatingStore(store Store) *ValidatingStore {
+	return &ValidatingStore{
+		store:    store,
+		lastSeen: make(map[domain.AuctionId]time.Time),
+	}
+}
+
+// ReadCommands reads commands from the underlying store
+func (s *ValidatingStore) ReadCommands() ([]domain.Command, error) {
+	return s.store.ReadCommands()
+}
+
+// WriteCommands writes commands to the underlying store
+func (s *ValidatingStore) WriteCommands(commands []domain.Command) error {
+	return s.store.WriteCommands(commands)
+}
+
+// ReadEvents reads events from the underlying store
+func (s *ValidatingStore) ReadEvents() ([]domain.Event, error) {
+	return s.store.ReadEvents()
+}
+
+// ReadEventsSince reads events after a position from the underlying store
+func (s *ValidatingStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	return s.store.ReadEventsSince(position)
+}
+
+// WriteEvents validates the batch and writes it to the underlying store
+func (s *ValidatingStore) WriteEvents(events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.loaded {
+		history, err := s.store.ReadEvents()
+		if err != nil {
+			return err
+		}
+		for _, event := range history {
+			if id, ok := domain.EventAuctionId(event); ok && !event.GetTime().Before(s.lastSeen[id]) {
+				s.lastSeen[id] = event.GetTime()
+			}
+		}
+		s.loaded = true
+	}
+
+	// Validate against the history plus the preceding events of the batch
+	pending := make(map[domain.AuctionId]time.Time)
+	var violations []InvariantViolation
+	for i, event := range events {
+		id, ok := domain.EventAuctionId(event)
+		if !ok {
+			// Events outside of auction streams only need a valid payload
+			if reason := validateEvent(event, false, time.Time{}); reason != "" {
+				violations = append(violations, InvariantViolation{Index: i, Reason: reason})
+			}
+			continue
+		}
+		last, seen := pending[id]
+		if !seen {
+			last, seen = s.lastSeen[id]
+		}
+
+		if reason := validateEvent(event, seen, last); reason != "" {
+			violations = append(violations, InvariantViolation{Index: i, AuctionId: id, Reason: reason})
+			continue
+		}
+		if !seen || event.GetTime().After(last) {
+			last = event.GetTime()
+		}
+		pending[id] = last
+	}
+
+	if len(violations) > 0 {
+		return InvariantViolationError{Violations: violations}
+	}
+
+	if err := s.store.WriteEvents(events); err != nil {
+		return err
+	}
+
+	for id, at := range pending {
+		s.lastSeen[id] = at
+	}
+	return nil
+}
+
+// ReadLatestSnapshot reads the latest snapshot from the underlying store
+func (s *ValidatingStore) ReadLatestSnapshot() (*Snapshot, error) {
+	return s.store.ReadLatestSnapshot()
+}
+
+// WriteSnapshot writes a snapshot to the underlying store
+func (s *ValidatingStore) WriteSnapshot(snapshot Snapshot) error {
+	return s.store.WriteSnapshot(snapshot)
+}
+
+// Ping checks the health of the underlying store
+func (s *ValidatingStore) Ping(ctx context.Context) error {
+	return Ping(ctx, s.store)
+}
+
+// validateEvent returns the reason an event is invalid, or an empty string.
+// seen tells whether the auction already has a stream, last is the time of
+// its latest event.
+func validateEvent(event domain.Event, seen bool, last time.Time) string {
+	if event.GetTime().IsZero() {
+		return "missing timestamp"
+	}
+	// A start is dated when the auction started, before the events recorded
+	// until it was noticed
+	if _, started := event.(domain.AuctionStartedEvent); seen && !started && event.GetTime().Before(last) {
+		return fmt.Sprintf("timestamp %s precedes previous event at %s", event.GetTime().Format(time.RFC3339Nano), last.Format(time.RFC3339Nano))
+	}
+
+	switch e := event.(type) {
+	case domain.AuctionAddedEvent:
+		if seen {
+			return "auction already exists"
+		}
+		return validateAuction(e.Auction)
+	case domain.BidAcceptedEvent:
+		if !seen {
+			return "bid for unknown auction"
+		}
+		return validateBid(e.Bid)
+	case domain.ProxyBidPlacedEvent:
+		if !seen {
+			return "proxy bid for unknown auction"
+		}
+		if e.Bid.Amount > e.Max {
+			return "proxy bid is over its maximum"
+		}
+		return validateBid(e.Bid)
+	case domain.MaxBidSetEvent:
+		if !seen {
+			return "maximum bid for unknown auction"
+		}
+		if e.Bidder.ID == "" || e.Max <= 0 {
+			return "maximum bid has no bidder or amount"
+		}
+		return ""
+	case domain.ListingTranslatedEvent:
+		if !seen {
+			return "translation for unknown auction"
+		}
+		if !domain.ValidLanguageTag(e.Translation.Language) {
+			return "translation has an invalid language"
+		}
+		return ""
+	case domain.ListingRevisedEvent:
+		if !seen {
+			return "revision for unknown auction"
+		}
+		return ""
+	case domain.KeyShareSubmittedEvent:
+		if !seen {
+			return "key share for unknown auction"
+		}
+		if e.Custodian == "" || e.Share == "" {
+			return "key share has no custodian or share"
+		}
+		return ""
+	case domain.TenderRevealedEvent:
+		if !seen {
+			return "reveal for unknown auction"
+		}
+		return ""
+	case domain.WinnerDeterminedEvent:
+		if !seen {
+			return "winner of unknown auction"
+		}
+		return ""
+	case domain.AuctionStartedEvent:
+		if !seen {
+			return "start of unknown auction"
+		}
+		return ""
+	case domain.AuctionAmendedEvent:
+		if !seen {
+			return "amendment of unknown auction"
+		}
+		if e.Title == nil && e.Description == nil && e.Expiry == nil {
+			return "amendment changes nothing"
+		}
+		return ""
+	case domain.AuctionCancelledEvent:
+		if !seen {
+			return "cancellation of unknown auction"
+		}
+		if e.By == "" {
+			return "cancellation has no user"
+		}
+		return ""
+	case domain.UnitsAllocatedEvent:
+		if !seen {
+			return "allocations of unknown auction"
+		}
+		for _, allocation := range e.Allocations {
+			if allocation.Bidder == "" || allocation.Price <= 0 {
+				return "allocation has no bidder or price"
+			}
+		}
+		return ""
+	case domain.BoughtNowEvent:
+		if !seen {
+			return "purchase of unknown auction"
+		}
+		if e.Buyer.ID == "" || e.Price <= 0 {
+			return "purchase has no buyer or price"
+		}
+		return ""
+	case domain.ReserveMetEvent, domain.ReserveNotMetEvent:
+		if !seen {
+			return "reserve of unknown auction"
+		}
+		return ""
+	case domain.AuctionExtendedEvent:
+		if !seen {
+			return "extension of unknown auction"
+		}
+		if e.Extensions <= 0 {
+			return "extension has no count"
+		}
+		return ""
+	case domain.ContactMessageSentEvent:
+		if !seen {
+			return "contact message for unknown auction"
+		}
+		if e.Message.Sender == "" || e.Message.Seq <= 0 {
+			return "contact message has no sender or sequence number"
+		}
+		return ""
+	case domain.ReportFiledEvent:
+		if e.Report.Reporter == "" {
+			return "report has no reporter"
+		}
+		if !domain.ValidReportReason(e.Report.Reason) {
+			return "report has an unknown reason"
+		}
+		return ""
+	case domain.ReportStatusChangedEvent:
+		if e.By == "" {
+			return "report status change has no moderator"
+		}
+		return ""
+	case domain.ListingModeratedEvent:
+		if e.Decision.Verdict == "" {
+			return "moderation decision has no verdict"
+		}
+		return ""
+	case domain.UserScreenedEvent:
+		if e.UserId == "" {
+			return "screening has no user"
+		}
+		return ""
+	case domain.RuleSetPublishedEvent:
+		if e.RuleSet.Version <= 0 {
+			return "rule set has no version"
+		}
+		if err := domain.ValidateRules(e.RuleSet.Rules); err != nil {
+			return "rule set is invalid"
+		}
+		return ""
+	case domain.EventArchivedEvent:
+		if e.EventId == "" {
+			return "archived event has no ID"
+		}
+		return ""
+	default:
+		return fmt.Sprintf("unknown event type %T", event)
+	}
+}
+
+// validateAuction returns the reason an auction payload is invalid, or an empty string
+func validateAuction(auction domain.Auction) string {
+	if auction.Seller.ID == "" {
+		return "auction has no seller"
+	}
+	if auction.Currency == "" {
+		return "auction has no currency"
+	}
+	if auction.Expiry.Before(auction.StartsAt) {
+		return "auction expires before it starts"
+	}
+	if !domain.ValidVisibility(auction.Visibility) {
+		return "auction has an unknown visibility"
+	}
+	return ""
+}
+
+// validateBid returns the reason a bid payload is invalid, or an empty string
+func validateBid(bid domain.Bid) string {
+	if bid.Bidder.ID == "" {
+		return "bid has no bidder"
+	}
+	if bid.Amount < 0 {
+		return "bid amount is negative"
+	}
+	if bid.At.IsZero() {
+		return "bid has no timestamp"
+	}
+	return ""
+}
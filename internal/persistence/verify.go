@@ -0,0 +1,129 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"auction-site-go/internal/domain"
+)
+
+// RebuildMismatch is an auction whose state differs between two rebuilds
+type RebuildMismatch struct {
+	AuctionId domain.AuctionId
+	// Rebuild names the rebuild that differs from the first replay
+	Rebuild  string
+	Expected string
+	Actual   string
+}
+
+// RebuildReport is the result of verifying the rebuilds of the auctions
+type RebuildReport struct {
+	Events   int
+	Auctions int
+	// SnapshotPosition is the position of the snapshot rebuilt from, 0 when
+	// none was checked
+	SnapshotPosition int64
+	Mismatches       []RebuildMismatch
+}
+
+// Deterministic tells whether all rebuilds produced the same auctions
+func (r RebuildReport) Deterministic() bool {
+	return len(r.Mismatches) == 0
+}
+
+// VerifyRebuild replays all stored events twice, once in a single batch and
+// once event by event like the server folds them, and compares the auctions
+// they produce, flagging fold logic that isn't deterministic. With
+// fromSnapshot it also rebuilds from the latest snapshot and the events after
+// it, as the server does on startup, and compares that too.
+func VerifyRebuild(store Store, fromSnapshot bool) (RebuildReport, error) {
+	var report RebuildReport
+
+	events, err := store.ReadEvents()
+	if err != nil {
+		return report, err
+	}
+	report.Events = len(events)
+
+	expected, err := snapshotAuctions(domain.ApplyEvents(make(domain.Repository), events))
+	if err != nil {
+		return report, err
+	}
+	report.Auctions = len(expected)
+
+	incremental := make(domain.Repository)
+	for _, event := range events {
+		incremental = domain.ApplyEvents(incremental, []domain.Event{event})
+	}
+	actual, err := snapshotAuctions(incremental)
+	if err != nil {
+		return report, err
+	}
+	report.Mismatches = append(report.Mismatches, compareAuctions("second replay", expected, actual)...)
+
+	if fromSnapshot {
+		snapshot, err := store.ReadLatestSnapshot()
+		if err != nil {
+			return report, err
+		}
+		if snapshot != nil {
+			repo, position, err := LoadRepository(store)
+			if err != nil {
+				return report, err
+			}
+			if position != int64(len(events)) {
+				return report, fmt.Errorf("snapshot rebuild reached event %d of %d", position, len(events))
+			}
+			restored, err := snapshotAuctions(repo)
+			if err != nil {
+				return report, err
+			}
+			report.SnapshotPosition = snapshot.Position
+			report.Mismatches = append(report.Mismatches, compareAuctions(fmt.Sprintf("snapshot at %d", snapshot.Position), expected, restored)...)
+		}
+	}
+	return report, nil
+}
+
+// snapshotAuctions captures the auctions of a repository as JSON, by ID
+func snapshotAuctions(repo domain.Repository) (map[domain.AuctionId]string, error) {
+	snapshots, err := domain.SnapshotRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+	auctions := make(map[domain.AuctionId]string, len(snapshots))
+	for _, snapshot := range snapshots {
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		auctions[snapshot.Auction.ID] = string(data)
+	}
+	return auctions, nil
+}
+
+// compareAuctions lists the auctions that differ from the expected ones, or
+// are missing from either side, ordered by ID
+func compareAuctions(rebuild string, expected, actual map[domain.AuctionId]string) []RebuildMismatch {
+	ids := make(map[domain.AuctionId]bool, len(expected))
+	for id := range expected {
+		ids[id] = true
+	}
+	for id := range actual {
+		ids[id] = true
+	}
+	sorted := make([]domain.AuctionId, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var mismatches []RebuildMismatch
+	for _, id := range sorted {
+		if expected[id] != actual[id] {
+			mismatches = append(mismatches, RebuildMismatch{AuctionId: id, Rebuild: rebuild, Expected: expected[id], Actual: actual[id]})
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Bounds of the pages of the activity feed
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 200
+)
+
+// getMyActivity returns the activity of the requesting user across their
+// roles, newest first, continuing before the "before" cursor
+func (a *App) getMyActivity(w http.ResponseWriter, r *http.Request) {
+	user, err := extractUserFromRequest(r)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if a.Activity == nil {
+		respondError(w, http.StatusNotFound, "Activity not available")
+		return
+	}
+
+	query := r.URL.Query()
+	var before int64
+	if s := query.Get("before"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n <= 0 {
+			respondError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		before = n
+	}
+	limit := defaultActivityLimit
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxActivityLimit {
+			respondError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	// One more than the page tells whether there is a next one
+	activities := a.Activity.Page(user.ID, a.GetCurrentTime(), before, limit+1)
+	response := ActivityResponse{Activities: activities}
+	if len(activities) > limit {
+		response.Activities = activities[:limit]
+		response.Next = activities[limit-1].Seq
+	}
+	respondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AdmissionControl limits the number of requests each client may make in a
+// fixed window of time
+type AdmissionControl struct {
+	limit          int
+	window         time.Duration
+	getCurrentTime func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// NewAdmissionControl creates an admission control admitting limit requests
+// per client and window
+func NewAdmissionControl(limit int, window time.Duration, getCurrentTime func() time.Time) *AdmissionControl {
+	return &AdmissionControl{
+		limit:          limit,
+		window:         window,
+		getCurrentTime: getCurrentTime,
+		counts:         make(map[string]int),
+	}
+}
+
+// Admit counts a request of a client. It returns the number of requests the
+// client has left, the time until the window resets, and whether the request
+// is admitted.
+func (a *AdmissionControl) Admit(client string) (int, time.Duration, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.getCurrentTime()
+	if !now.Before(a.windowStart.Add(a.window)) {
+		a.windowStart = now.Truncate(a.window)
+		a.counts = make(map[string]int)
+	}
+	reset := a.windowStart.Add(a.window).Sub(now)
+
+	if a.counts[client] >= a.limit {
+		return 0, reset, false
+	}
+	a.counts[client]++
+	return a.limit - a.counts[client], reset, true
+}
+
+// Middleware applies the admission control to a handler. Responses carry
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers so clients
+// can pace themselves, and rejected requests get a 429 with a backoff hint.
+func (a *AdmissionControl) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining, reset, ok := a.Admit(admissionClient(r))
+		resetSeconds := strconv.Itoa(int(math.Ceil(reset.Seconds())))
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(a.limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", resetSeconds)
+		if !ok {
+			w.Header().Set("Retry-After", resetSeconds)
+			respondJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+				"type":              "Throttled",
+				"retryAfterSeconds": math.Ceil(reset.Seconds()),
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// admissionClient identifies the client of a request by its user, falling
+// back to its remote address
+func admissionClient(r *http.Request) string {
+	if user, err := extractUserFromRequest(r); err == nil {
+		return "user:" + string(user.ID)
+	}
+	return "addr:" + r.RemoteAddr
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// amendAuction amends the title, description or end time of an auction of
+// the seller before bidding starts. A new title or description is moderated
+// like a new listing, and a new title is translated again.
+func amendAuction(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time, moderate func(domain.Auction) (*domain.ModerationDecision, error), translate func(domain.Auction, func(domain.Event) error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+		var req AmendAuctionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondInvalidBody(w, err)
+			return
+		}
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		now := getCurrentTime()
+		cmd := domain.AmendAuctionCommand{
+			Time:        now,
+			AuctionId:   domain.AuctionId(id),
+			Seller:      user,
+			Title:       req.Title,
+			Description: req.Description,
+			Expiry:      req.EndsAt,
+		}
+
+		// Moderate a new title or description before amending the listing
+		var moderated *domain.ListingModeratedEvent
+		if entry, ok := state.GetRepository()[cmd.AuctionId]; ok && (req.Title != nil || req.Description != nil) && entry.Auction.Seller.ID == user.ID {
+			description := state.GetDescriptions()[cmd.AuctionId]
+			if req.Description != nil {
+				description = *req.Description
+			}
+			decision, err := moderate(listingWithDescription(entry.Auction.Amended(cmd), description))
+			if err != nil {
+				log.Printf("Failed to moderate listing: %v", err)
+				respondError(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			if decision != nil {
+				moderated = &domain.ListingModeratedEvent{Time: now, AuctionId: cmd.AuctionId, Decision: *decision}
+				if decision.Verdict == domain.ModerationRejected {
+					if err := onEvent(*moderated); err != nil {
+						log.Printf("Failed to observe event: %v", err)
+						respondError(w, http.StatusInternalServerError, "Internal server error")
+						return
+					}
+					respondDomainError(w, domain.NewListingRejectedError(cmd.AuctionId, decision.Reasons))
+					return
+				}
+			}
+		}
+
+		event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		state.UpdateRepository(newRepo)
+		state.ApplyDescriptions([]domain.Event{event})
+
+		if err := onEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if moderated != nil {
+			if err := observeModeration(state, *moderated, onEvent); err != nil {
+				log.Printf("Failed to observe event: %v", err)
+				respondError(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+		}
+
+		if req.Title != nil {
+			translate(newRepo[cmd.AuctionId].Auction, onEvent)
+		}
+
+		respondJSON(w, http.StatusOK, event)
+	}
+}
+
+// listingWithDescription returns the listing to moderate for an auction with
+// a description. Moderation matches the title, so the description is checked
+// along with it as part of the title.
+func listingWithDescription(auction domain.Auction, description string) domain.Auction {
+	if description != "" {
+		auction.Title += "\n" + description
+	}
+	return auction
+}
@@ -1,6 +1,8 @@
 package web
 
 import (
+	"context"
+	"expvar"
 	"log"
 	"net/http"
 	"time"
@@ -18,6 +20,53 @@ type App struct {
 	OnCommand      func(domain.Command) error
 	OnEvent        func(domain.Event) error
 	GetCurrentTime func() time.Time
+
+	// Moderator checks listings before they are published, if set
+	Moderator domain.ModerationProvider
+
+	// Screener screens sellers on listing and bidders of at least
+	// ScreeningThreshold against denied-party lists, if set
+	Screener           domain.ScreeningProvider
+	ScreeningThreshold int64
+
+	// Translator translates new listings into TranslationLanguages, if set
+	Translator           domain.TranslationProvider
+	TranslationLanguages []string
+
+	// BidAdmission limits the rate of bids per client, if set
+	BidAdmission *AdmissionControl
+
+	// AsyncBids processes the bids of clients preferring an asynchronous
+	// response in the background, if set
+	AsyncBids *AsyncCommands
+
+	// DeadLetters keeps the commands that fail, for support to retry them,
+	// if set
+	DeadLetters DeadLetterQueue
+
+	// ReadEventsSince reads the stored events after a position, for the
+	// event feed, if set
+	ReadEventsSince func(position int64) ([]domain.Event, error)
+	// ReadEventIdsSince reads the IDs of the stored events after a position,
+	// for the event feed, if set
+	ReadEventIdsSince func(position int64) ([]string, error)
+
+	// Activity is the read model of the activity of each user, if set
+	Activity *domain.ActivityFeed
+
+	// ForgetUser erases the personal data of a user from the store, such as
+	// by deleting the user's key, if set
+	ForgetUser func(userId domain.UserId) error
+
+	// Commands dispatches the commands on auctions, recording them with
+	// OnCommand. Middleware may be added before serving.
+	Commands *domain.CommandBus
+
+	// HealthCheck tells whether the store can serve requests, if set
+	HealthCheck func(ctx context.Context) error
+
+	storeStatus    *storeStatus
+	priceHistories *priceHistories
 }
 
 // NewApp creates a new web application
@@ -31,6 +80,8 @@ func NewApp(repo domain.Repository, onCommand func(domain.Command) error, onEven
 		OnCommand:      onCommand,
 		OnEvent:        onEvent,
 		GetCurrentTime: getCurrentTime,
+		storeStatus:    &storeStatus{},
+		priceHistories: &priceHistories{},
 	}
 
 	app.setupRoutes()
@@ -44,12 +95,120 @@ func (a *App) setupRoutes() {
 	a.Router.Use(func(next http.Handler) http.Handler {
 		return handlers.LoggingHandler(log.Writer(), next)
 	})
+	a.Router.Use(degradationMiddleware(a.storeStatus, a.GetCurrentTime))
+	a.Router.Use(timingMiddleware)
+	a.Router.Use(metadataMiddleware)
+
+	// Track the availability of the store through the writes of handlers
+	onCommand := func(command domain.Command) error {
+		return a.storeStatus.observe(a.OnCommand(command), a.GetCurrentTime())
+	}
+	onEvent := func(event domain.Event) error {
+		return a.storeStatus.observe(a.OnEvent(event), a.GetCurrentTime())
+	}
+	a.Commands = domain.NewCommandBus(
+		domain.DeadLetterCommands(a.deadLetter),
+		domain.RecordCommands(func(ctx context.Context, command domain.Command) error {
+			defer timeContextPhase(ctx, "command")()
+			return onCommand(command)
+		}),
+		timeCommands("validation"),
+		domain.ValidateCommands(),
+		domain.RejectArchivedAuctions(a.State.GetArchivedAuctions),
+		domain.StampEvents(newEventId),
+	)
 
 	// Routes
-	a.Router.HandleFunc("/auctions", getAuctions(a.State)).Methods("GET")
-	a.Router.HandleFunc("/auctions/{id}", getAuction(a.State, a.GetCurrentTime)).Methods("GET")
-	a.Router.HandleFunc("/auctions", createAuction(a.State, a.OnCommand, a.OnEvent, a.GetCurrentTime)).Methods("POST")
-	a.Router.HandleFunc("/auctions/{id}/bids", placeBid(a.State, a.OnCommand, a.OnEvent, a.GetCurrentTime)).Methods("POST")
+	a.Router.HandleFunc("/auctions", getAuctions(a.State, a.GetCurrentTime)).Methods("GET")
+	a.Router.HandleFunc("/auctions/{id}", a.getAuctionAsOf(getAuction(a.State, a.GetCurrentTime))).Methods("GET")
+	a.Router.HandleFunc("/auctions/{id}/countdown", getAuctionCountdown(a.State, a.GetCurrentTime)).Methods("GET")
+	a.Router.HandleFunc("/auctions/{id}/history", a.getPriceHistory).Methods("GET")
+	a.Router.HandleFunc("/lite/v1/auctions", getLiteAuctions(a.State, a.GetCurrentTime)).Methods("GET")
+	a.Router.HandleFunc("/lite/v1/auctions/{id}", getLiteAuction(a.State, a.GetCurrentTime)).Methods("GET")
+	a.Router.HandleFunc("/time", getServerTime(a.GetCurrentTime)).Methods("GET")
+	a.Router.HandleFunc("/bid-increments", getDefaultIncrements).Methods("GET")
+	a.Router.HandleFunc("/auctions/{id}/velocity", getAuctionVelocity(a.State, a.GetCurrentTime)).Methods("GET")
+	a.Router.HandleFunc("/auctions", createAuction(a.State, a.Commands, onEvent, a.GetCurrentTime, a.moderateListing, a.screenUser, a.translateNewListing)).Methods("POST")
+	a.Router.HandleFunc("/auctions:revise", bulkReviseAuctions(a.State, a.Commands, onEvent, a.GetCurrentTime)).Methods("POST")
+	a.Router.HandleFunc("/auctions/{id:[0-9]+}:clone", cloneAuction(a.State, a.Commands, onEvent, a.GetCurrentTime, a.moderateListing, a.screenUser, a.translateNewListing)).Methods("POST")
+	a.Router.Handle("/auctions/{id}/bids", a.admitBid(a.asyncBid(placeBid(a.State, a.Commands, onEvent, a.GetCurrentTime, a.screenUser)))).Methods("POST")
+	a.Router.HandleFunc("/auctions/{id}/max-bid", setMaxBid(a.State, a.Commands, onEvent, a.GetCurrentTime, a.screenUser)).Methods("PUT")
+	a.Router.HandleFunc("/auctions/{id}/buy-now", buyNow(a.State, a.Commands, onEvent, a.GetCurrentTime, a.screenUser)).Methods("POST")
+	a.Router.HandleFunc("/auctions/{id}", amendAuction(a.State, a.Commands, onEvent, a.GetCurrentTime, a.moderateListing, a.translateNewListing)).Methods("PATCH")
+	a.Router.HandleFunc("/auctions/{id}", cancelAuction(a.State, a.Commands, onEvent, a.GetCurrentTime)).Methods("DELETE")
+	a.Router.HandleFunc("/commands/{id}/status", a.getCommandStatus).Methods("GET")
+	a.Router.HandleFunc("/me/activity", a.getMyActivity).Methods("GET")
+	a.Router.HandleFunc("/auctions/{id}/translations", translateListing(a.State, a.Commands, onEvent, a.GetCurrentTime)).Methods("POST")
+	a.Router.HandleFunc("/reports", createReport(a.State, onCommand, onEvent, a.GetCurrentTime)).Methods("POST")
+	a.Router.HandleFunc("/reports", getReports(a.State, a.GetCurrentTime)).Methods("GET")
+	a.Router.HandleFunc("/reports/{id}/status", changeReportStatus(a.State, onCommand, onEvent, a.GetCurrentTime)).Methods("POST")
+	a.Router.HandleFunc("/auctions/{id}/contact", a.getContactThread).Methods("GET")
+	a.Router.HandleFunc("/auctions/{id}/contact/messages", a.sendContactMessage(onCommand, onEvent)).Methods("POST")
+	a.Router.HandleFunc("/auctions/{id}/contact/messages/{seq}/report", a.reportContactMessage(onCommand, onEvent)).Methods("POST")
+	a.Router.HandleFunc("/admin/rules", getRuleSet(a.State)).Methods("GET")
+	a.Router.HandleFunc("/admin/rules/{version}", getRuleSet(a.State)).Methods("GET")
+	a.Router.HandleFunc("/admin/rules", publishRuleSet(a.State, onEvent, a.GetCurrentTime)).Methods("POST")
+	a.Router.HandleFunc("/admin/dead-letters", a.getDeadLetters).Methods("GET")
+	a.Router.HandleFunc("/admin/dead-letters/{id}/retry", a.retryDeadLetter(onEvent)).Methods("POST")
+	a.Router.HandleFunc("/admin/users/{id}/forget", a.forgetUser).Methods("POST")
+	a.Router.HandleFunc("/admin/auctions/{id}/key-shares", submitKeyShare(a.State, a.Commands, onEvent, a.GetCurrentTime)).Methods("POST")
+
+	a.Router.HandleFunc("/events", a.getEvents).Methods("GET")
+
+	// Readiness probe
+	a.Router.HandleFunc("/healthz", a.getHealth).Methods("GET")
+
+	// Metrics published through expvar, which include the command line
+	a.Router.Handle("/debug/vars", supportOnly(expvar.Handler())).Methods("GET")
+}
+
+// moderateListing moderates a listing with the latest prohibited item rules
+// and the configured moderator, keeping the most severe decision. It returns
+// no decision when there are neither rules nor a moderator.
+func (a *App) moderateListing(auction domain.Auction) (*domain.ModerationDecision, error) {
+	var providers []domain.ModerationProvider
+	if ruleSets := a.State.GetRuleSets(); len(ruleSets) > 0 {
+		providers = append(providers, ruleSets[len(ruleSets)-1])
+	}
+	if a.Moderator != nil {
+		providers = append(providers, a.Moderator)
+	}
+
+	var result *domain.ModerationDecision
+	for _, provider := range providers {
+		decision, err := provider.ModerateListing(auction)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil || domain.MostSevereVerdict(result.Verdict, decision.Verdict) != result.Verdict {
+			result = &decision
+		}
+	}
+	return result, nil
+}
+
+// screenUser screens sellers on listing and bidders of high-value bids with
+// the configured screener, returning nil when no screening is needed
+func (a *App) screenUser(user domain.User, context string, amount int64) (*domain.UserScreenedEvent, error) {
+	if a.Screener == nil || (context == domain.ScreeningBid && amount < a.ScreeningThreshold) {
+		return nil, nil
+	}
+	result, err := a.Screener.Screen(user)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UserScreenedEvent{Time: a.GetCurrentTime(), UserId: user.ID, Context: context, Result: result}, nil
+}
+
+// admitBid applies the bid admission control, if set, to a handler
+func (a *App) admitBid(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.BidAdmission == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		a.BidAdmission.Middleware(next).ServeHTTP(w, r)
+	})
 }
 
 // Run starts the web server
@@ -0,0 +1,242 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// Statuses of an asynchronous command
+const (
+	CommandQueued     = "Queued"
+	CommandProcessing = "Processing"
+	CommandCompleted  = "Completed"
+)
+
+// commandStatusRetention is how long the outcome of a completed command can
+// be polled
+const commandStatusRetention = 10 * time.Minute
+
+// AsyncCommands queues requests that prefer an asynchronous response, with a
+// "Prefer: respond-async" header, and processes them with a pool of workers.
+// Clients get a 202 with the command ID and poll GET /commands/:id/status
+// for the outcome, which is the response the request would have had.
+type AsyncCommands struct {
+	queue          chan *asyncCommand
+	getCurrentTime func() time.Time
+
+	mu       sync.Mutex
+	commands map[string]*asyncCommand
+}
+
+// asyncCommand is a queued request and, once processed, its outcome
+type asyncCommand struct {
+	id        string
+	owner     domain.UserId
+	request   *http.Request
+	handler   http.Handler
+	status    string
+	code      int
+	body      []byte
+	completed time.Time
+}
+
+// NewAsyncCommands creates a queue of queueSize requests processed by
+// workers, which are started right away
+func NewAsyncCommands(workers, queueSize int, getCurrentTime func() time.Time) *AsyncCommands {
+	a := &AsyncCommands{
+		queue:          make(chan *asyncCommand, queueSize),
+		getCurrentTime: getCurrentTime,
+		commands:       make(map[string]*asyncCommand),
+	}
+	for i := 0; i < workers; i++ {
+		go a.work()
+	}
+	return a
+}
+
+// Middleware queues the requests preferring an asynchronous response for
+// next, responding with 401 when the request has no user and 503 when the
+// queue is full
+func (a *AsyncCommands) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Prefer") != "respond-async" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Only the user submitting a command can poll its outcome
+		user := requestUser(r)
+		if user == nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		// The request outlives its connection, so it keeps the values of its
+		// context, such as the route and the event metadata, but not its
+		// cancellation
+		request := r.Clone(detachedContext{r.Context()})
+		request.Body = io.NopCloser(bytes.NewReader(body))
+
+		command := &asyncCommand{id: newEventId(), owner: user.ID, request: request, handler: next, status: CommandQueued}
+
+		a.mu.Lock()
+		a.evict()
+		a.commands[command.id] = command
+		a.mu.Unlock()
+
+		select {
+		case a.queue <- command:
+		default:
+			a.mu.Lock()
+			delete(a.commands, command.id)
+			a.mu.Unlock()
+			w.Header().Set("Retry-After", "1")
+			respondError(w, http.StatusServiceUnavailable, "Command queue is full")
+			return
+		}
+
+		location := "/commands/" + command.id + "/status"
+		w.Header().Set("Location", location)
+		respondJSON(w, http.StatusAccepted, map[string]string{"commandId": command.id, "status": location})
+	})
+}
+
+// work processes queued requests, buffering their responses
+func (a *AsyncCommands) work() {
+	for command := range a.queue {
+		a.mu.Lock()
+		command.status = CommandProcessing
+		a.mu.Unlock()
+
+		response := &bufferedResponse{header: make(http.Header), code: http.StatusOK}
+		command.handler.ServeHTTP(response, command.request)
+
+		a.mu.Lock()
+		command.status = CommandCompleted
+		command.code = response.code
+		command.body = response.body.Bytes()
+		command.completed = a.getCurrentTime()
+		command.request = nil
+		a.mu.Unlock()
+	}
+}
+
+// evict forgets the outcomes of commands completed longer than the
+// retention ago. It must be called with the lock held.
+func (a *AsyncCommands) evict() {
+	cutoff := a.getCurrentTime().Add(-commandStatusRetention)
+	for id, command := range a.commands {
+		if command.status == CommandCompleted && command.completed.Before(cutoff) {
+			delete(a.commands, id)
+		}
+	}
+}
+
+// Status returns the status of a command submitted by a user
+func (a *AsyncCommands) Status(id string, user domain.UserId) (CommandStatusResponse, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.evict()
+
+	command, ok := a.commands[id]
+	if !ok || command.owner != user {
+		return CommandStatusResponse{}, false
+	}
+	response := CommandStatusResponse{ID: id, Status: command.status}
+	if command.status == CommandCompleted {
+		response.Code = command.code
+		response.Result = json.RawMessage(command.body)
+		if command.code == http.StatusOK {
+			if event, err := domain.UnmarshalEvent(command.body); err == nil {
+				response.Events = []domain.Event{event}
+			}
+		}
+	}
+	return response, true
+}
+
+// getCommandStatus returns the status of an asynchronous command to the user
+// who submitted it
+func (a *App) getCommandStatus(w http.ResponseWriter, r *http.Request) {
+	user := requestUser(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if a.AsyncBids == nil {
+		respondError(w, http.StatusNotFound, "Command not found")
+		return
+	}
+	status, ok := a.AsyncBids.Status(mux.Vars(r)["id"], user.ID)
+	if !ok {
+		respondError(w, http.StatusNotFound, "Command not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, status)
+}
+
+// asyncBid applies the asynchronous processing of bids, if set, to a handler
+func (a *App) asyncBid(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.AsyncBids == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		a.AsyncBids.Middleware(next).ServeHTTP(w, r)
+	})
+}
+
+// bufferedResponse is a response writer keeping the response in memory
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	b.code = code
+}
+
+// detachedContext keeps the values of a context without its deadline and
+// cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
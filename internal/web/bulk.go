@@ -0,0 +1,135 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// maxBulkItems bounds the number of auctions a bulk operation applies to
+const maxBulkItems = 500
+
+// bulkReviseAuctions revises all the seller's auctions that haven't ended,
+// carry the tag and match the filter, one command per auction. Auctions that
+// can't be revised, such as those with bids, are reported without failing
+// the others.
+func bulkReviseAuctions(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse request body
+		var req BulkReviseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		// Extract user from JWT
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		filter, err := parseFilter(req.Filter, auctionFilterFields)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid filter: "+err.Error())
+			return
+		}
+		var extendBy time.Duration
+		if req.ExtendBy != "" {
+			if extendBy, err = time.ParseDuration(req.ExtendBy); err != nil || extendBy <= 0 {
+				respondError(w, http.StatusBadRequest, "Invalid extendBy")
+				return
+			}
+		}
+		if extendBy == 0 && len(req.AddTags) == 0 {
+			respondError(w, http.StatusBadRequest, "Nothing to revise")
+			return
+		}
+
+		// Select the auctions up front, so revisions don't change the selection
+		now := getCurrentTime()
+		repo := state.GetRepository()
+		var selected []domain.Auction
+		for _, entry := range repo {
+			auction := entry.Auction
+			if auction.Seller.ID != user.ID || (req.Tag != "" && !auction.HasTag(req.Tag)) {
+				continue
+			}
+			item := toAuctionListItem(auction, entry.State, now, nil)
+			if item.Status != "Ended" && item.Status != "Cancelled" && filter(item) {
+				selected = append(selected, auction)
+			}
+		}
+		if len(selected) > maxBulkItems {
+			respondError(w, http.StatusBadRequest, "Too many auctions selected")
+			return
+		}
+		sort.Slice(selected, func(i, j int) bool {
+			return selected[i].ID < selected[j].ID
+		})
+
+		response := BulkReviseResponse{Results: make([]BulkItemResult, 0, len(selected))}
+		for _, auction := range selected {
+			cmd := domain.ReviseListingCommand{
+				Time:      now,
+				AuctionId: auction.ID,
+				AddTags:   req.AddTags,
+			}
+			if extendBy > 0 {
+				expiry := auction.Expiry.Add(extendBy)
+				cmd.Expiry = &expiry
+			}
+
+			result := BulkItemResult{ID: auction.ID, Status: "revised"}
+			event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+			if err == nil {
+				state.UpdateRepository(newRepo)
+				err = onEvent(event)
+			}
+			if err != nil {
+				result.Status = "failed"
+				result.Error = bulkItemError(err)
+				response.Failed++
+			} else {
+				response.Revised++
+			}
+			response.Results = append(response.Results, result)
+		}
+
+		respondJSON(w, http.StatusOK, response)
+	}
+}
+
+// bulkItemError renders the error of an item of a bulk operation like the
+// error response of a single operation
+func bulkItemError(err error) map[string]interface{} {
+	if domainErr, ok := err.(domain.DomainError); ok {
+		if renderer, ok := domainErrorRenderers[domainErr.Type]; ok {
+			if renderer.problem != nil {
+				return problemPayload(renderer.problem(domainErr.Data))
+			}
+			return renderer.payload(domainErr.Data)
+		}
+	}
+	log.Printf("non-domain error in bulk operation: %v", err)
+	return map[string]interface{}{"message": "Internal server error"}
+}
+
+// problemPayload renders problem details as the error of a bulk item, with
+// the fields of the problem response of a single operation
+func problemPayload(problem ProblemDetails) map[string]interface{} {
+	payload := map[string]interface{}{}
+	data, err := json.Marshal(problem)
+	if err == nil {
+		err = json.Unmarshal(data, &payload)
+	}
+	if err != nil {
+		log.Printf("failed to render problem in bulk operation: %v", err)
+		return map[string]interface{}{"type": problem.Type, "title": problem.Title}
+	}
+	return payload
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// buyNow buys an auction at its buy now price, ending it with the buyer as
+// the winner. Buyers are screened like bidders of the price.
+func buyNow(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time, screen screenFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		auctionId := domain.AuctionId(id)
+		var price int64
+		if entry, ok := state.GetRepository()[auctionId]; ok {
+			price, _ = domain.BuyNowPrice(entry.State, getCurrentTime())
+		}
+		if !screenUser(w, screen, onEvent, user, domain.ScreeningBid, price) {
+			return
+		}
+
+		cmd := domain.BuyNowCommand{
+			Time:      getCurrentTime(),
+			AuctionId: auctionId,
+			Buyer:     user,
+		}
+		event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		state.UpdateRepository(newRepo)
+
+		if err := onEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		// The purchase may have met the reserve price
+		reportReserve(state, onEvent, auctionId, cmd.Time)
+
+		respondJSON(w, http.StatusOK, event)
+	}
+}
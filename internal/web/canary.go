@@ -0,0 +1,215 @@
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// canaryMetrics counts the probes of the canary and their outcomes, with the
+// error of the last failure, published as the "canary" expvar
+var (
+	canaryMetrics     = expvar.NewMap("canary")
+	canaryLastFailure = new(expvar.String)
+)
+
+func init() {
+	canaryMetrics.Set("lastFailure", canaryLastFailure)
+}
+
+// Users the canary acts as
+var (
+	canarySeller   = canaryJWT(`{"sub":"canary-seller","name":"Canary","u_typ":"0"}`)
+	canaryBidder   = canaryJWT(`{"sub":"canary-bidder","name":"Canary","u_typ":"0"}`)
+	canaryOutsider = canaryJWT(`{"sub":"canary-outsider","name":"Canary","u_typ":"0"}`)
+	canarySupport  = canaryJWT(`{"sub":"canary-support","u_typ":"1"}`)
+)
+
+// Canary probes a running server end to end through its API, the way
+// clients use it: it lists a private auction, checks it's hidden from others,
+// places an accepted and a rejected bid, reads the auction back and follows
+// the event feed to the events of the auction. Canary auctions have negative
+// IDs, so they never collide with the IDs clients choose.
+type Canary struct {
+	baseURL        string
+	client         *http.Client
+	getCurrentTime func() time.Time
+
+	mu sync.Mutex
+	// position is how far the canary has followed the event feed
+	position int64
+}
+
+// NewCanary creates a canary probing the server at a base URL such as
+// "http://localhost:8080"
+func NewCanary(baseURL string, client *http.Client, getCurrentTime func() time.Time) *Canary {
+	return &Canary{
+		baseURL:        baseURL,
+		client:         client,
+		getCurrentTime: getCurrentTime,
+	}
+}
+
+// Probe runs one probe, recording its outcome and duration in the metrics
+func (c *Canary) Probe() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := time.Now()
+	err := c.probe()
+	canaryMetrics.Add("runs", 1)
+	canaryMetrics.Add("latencyMicros", time.Since(start).Microseconds())
+	if err != nil {
+		canaryMetrics.Add("failures", 1)
+		canaryLastFailure.Set(err.Error())
+		return err
+	}
+	canaryMetrics.Add("passes", 1)
+	return nil
+}
+
+// probe runs the steps of a probe, stopping at the first failing one
+func (c *Canary) probe() error {
+	now := c.getCurrentTime()
+	id := domain.AuctionId(-now.UnixNano() / int64(time.Microsecond))
+	path := "/auctions/" + strconv.FormatInt(int64(id), 10)
+
+	auction := AddAuctionRequest{
+		ID:         id,
+		StartsAt:   now.Add(-time.Second),
+		Title:      "canary",
+		EndsAt:     now.Add(time.Minute),
+		Currency:   domain.VAC,
+		Type:       domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()),
+		Visibility: domain.VisibilityPrivate,
+		Invitees:   []domain.UserId{"canary-bidder"},
+	}
+	if _, err := c.expect("POST", "/auctions", canarySeller, auction, http.StatusOK); err != nil {
+		return fmt.Errorf("create auction: %v", err)
+	}
+	if _, err := c.expect("GET", path, canaryOutsider, nil, http.StatusNotFound); err != nil {
+		return fmt.Errorf("private auction visible: %v", err)
+	}
+	if _, err := c.expect("POST", path+"/bids", canaryBidder, BidRequest{Amount: 10}, http.StatusOK); err != nil {
+		return fmt.Errorf("place bid: %v", err)
+	}
+	if _, err := c.expect("POST", path+"/bids", canaryBidder, BidRequest{Amount: 5}, http.StatusBadRequest); err != nil {
+		return fmt.Errorf("lower bid accepted: %v", err)
+	}
+
+	body, err := c.expect("GET", path, canaryBidder, nil, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("read auction: %v", err)
+	}
+	var read AuctionResponse
+	if err := json.Unmarshal(body, &read); err != nil {
+		return fmt.Errorf("read auction: %v", err)
+	}
+	if len(read.Bids) != 1 || read.Bids[0].Amount != 10 {
+		return fmt.Errorf("read auction: expected the bid of 10, got %+v", read.Bids)
+	}
+
+	return c.followEvents(id)
+}
+
+// followEvents reads the event feed up to its end, expecting the events of
+// the canary auction. A server without an event feed is not checked.
+func (c *Canary) followEvents(id domain.AuctionId) error {
+	added, accepted := false, false
+	for {
+		status, body, err := c.do("GET", fmt.Sprintf("/events?since=%d", c.position), canarySupport, nil)
+		if err != nil {
+			return fmt.Errorf("read events: %v", err)
+		}
+		if status == http.StatusNotFound {
+			return nil
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("read events: expected status %d, got %d", http.StatusOK, status)
+		}
+		var feed struct {
+			Events []struct {
+				Event json.RawMessage `json:"event"`
+			} `json:"events"`
+			Position int64 `json:"position"`
+		}
+		if err := json.Unmarshal(body, &feed); err != nil {
+			return fmt.Errorf("read events: %v", err)
+		}
+		for _, positioned := range feed.Events {
+			event, err := domain.UnmarshalEvent(positioned.Event)
+			if err != nil {
+				continue
+			}
+			if auctionId, ok := domain.EventAuctionId(event); !ok || auctionId != id {
+				continue
+			}
+			switch event.(type) {
+			case domain.AuctionAddedEvent:
+				added = true
+			case domain.BidAcceptedEvent:
+				accepted = true
+			}
+		}
+		if len(feed.Events) == 0 || feed.Position == c.position {
+			break
+		}
+		c.position = feed.Position
+	}
+	if !added || !accepted {
+		return fmt.Errorf("read events: expected the auction and its bid, found added %v, accepted %v", added, accepted)
+	}
+	return nil
+}
+
+// expect sends a request, expecting a status, and returns the response body
+func (c *Canary) expect(method, path, jwt string, payload interface{}, expected int) ([]byte, error) {
+	status, body, err := c.do(method, path, jwt, payload)
+	if err != nil {
+		return nil, err
+	}
+	if status != expected {
+		return nil, fmt.Errorf("expected status %d, got %d: %s", expected, status, body)
+	}
+	return body, nil
+}
+
+// do sends a request as a user, with a JSON payload if not nil
+func (c *Canary) do(method, path, jwt string, payload interface{}) (int, []byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("x-jwt-payload", jwt)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, body, err
+}
+
+// canaryJWT encodes a JWT payload for the x-jwt-payload header
+func canaryJWT(payload string) string {
+	return base64.StdEncoding.EncodeToString([]byte(payload))
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// maxCancelReasonLength bounds the reason given for a cancellation
+const maxCancelReasonLength = 500
+
+// cancelAuction cancels an auction, as its seller before the first bid, or
+// as support with ?override=true once it has bids. An optional ?reason= is
+// recorded with the cancellation.
+func cancelAuction(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		query := r.URL.Query()
+		override := false
+		if s := query.Get("override"); s != "" {
+			if override, err = strconv.ParseBool(s); err != nil {
+				respondError(w, http.StatusBadRequest, "Invalid override")
+				return
+			}
+		}
+		reason := query.Get("reason")
+		if len(reason) > maxCancelReasonLength {
+			respondError(w, http.StatusBadRequest, "Reason too long")
+			return
+		}
+
+		cmd := domain.CancelAuctionCommand{
+			Time:      getCurrentTime(),
+			AuctionId: domain.AuctionId(id),
+			By:        user,
+			Override:  override,
+			Reason:    reason,
+		}
+		event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		state.UpdateRepository(newRepo)
+
+		if err := onEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		respondJSON(w, http.StatusOK, event)
+	}
+}
+
+// cancelledAt returns when an auction was cancelled, nil if it wasn't
+func cancelledAt(state domain.State) *time.Time {
+	at, ok := domain.CancelledAt(state)
+	if !ok {
+		return nil
+	}
+	return &at
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// cloneAuction lists a copy of an auction of the seller on a fresh schedule,
+// going through the same screening, moderation and translation as a new
+// listing
+func cloneAuction(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time, moderate func(domain.Auction) (*domain.ModerationDecision, error), screen screenFunc, translate func(domain.Auction, func(domain.Event) error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse auction ID from path
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+
+		// Parse request body
+		var req CloneAuctionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		// Extract user from JWT
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		entry, ok := state.GetRepository()[domain.AuctionId(id)]
+		if !ok {
+			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+			return
+		}
+		if entry.Auction.Seller.ID != user.ID {
+			respondError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+		if entry.Auction.Tender != nil {
+			// The key of a tender may have been revealed, a clone needs a new one
+			respondError(w, http.StatusBadRequest, "Tenders cannot be cloned")
+			return
+		}
+
+		clone := entry.Auction.Clone(req.ID, req.StartsAt, req.EndsAt)
+		// The clone is listed by the seller as they are now
+		clone.Seller = user
+
+		publishListing(w, r, state, commands, onEvent, getCurrentTime(), moderate, screen, translate, clone)
+	}
+}
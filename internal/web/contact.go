@@ -0,0 +1,205 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// getContactThread returns the contact thread of a closed auction to its
+// winner or seller, who only see each other's role
+func (a *App) getContactThread(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid auction ID")
+		return
+	}
+	user, err := extractUserFromRequest(r)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	auctionId := domain.AuctionId(id)
+	now := a.GetCurrentTime()
+	repo := a.State.GetRepository()
+	role, err := domain.ContactRole(repo, auctionId, user.ID, now)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	entry := repo[auctionId]
+	_, _, closesAt, _ := domain.ContactParties(entry.Auction, entry.State, now)
+
+	response := ContactThreadResponse{
+		AuctionId: auctionId,
+		Role:      role,
+		OpenUntil: closesAt,
+		Messages:  []ContactMessageResponse{},
+	}
+	for _, message := range a.State.GetContacts()[auctionId].Messages {
+		response.Messages = append(response.Messages, ContactMessageResponse{
+			Seq:         message.Seq,
+			At:          message.At,
+			From:        message.Role,
+			Text:        message.Text,
+			Attachments: message.Attachments,
+		})
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// sendContactMessage relays a message from the winner or the seller of a
+// closed auction to the other, masking contact details in its text
+func (a *App) sendContactMessage(onCommand func(domain.Command) error, onEvent func(domain.Event) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+		var req ContactMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondInvalidBody(w, err)
+			return
+		}
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		cmd := domain.SendContactMessageCommand{
+			Time:        a.GetCurrentTime(),
+			AuctionId:   domain.AuctionId(id),
+			Sender:      user.ID,
+			Text:        req.Text,
+			Attachments: req.Attachments,
+		}
+		if err := onCommand(cmd); err != nil {
+			log.Printf("Failed to observe command: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		var event domain.ContactMessageSentEvent
+		var eventErr error
+		err = a.State.UpdateContacts(func(threads domain.ContactThreads) (domain.ContactThreads, error) {
+			sent, newThreads, err := domain.HandleContact(cmd, threads, a.State.GetRepository())
+			if err != nil {
+				return nil, err
+			}
+			event = sent.(domain.ContactMessageSentEvent)
+			// Observed while holding the threads, so messages are written
+			// in the order they are numbered
+			eventErr = onEvent(event)
+			return newThreads, nil
+		})
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		if eventErr != nil {
+			log.Printf("Failed to observe event: %v", eventErr)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		message := event.Message
+		respondJSON(w, http.StatusOK, ContactMessageResponse{
+			Seq:         message.Seq,
+			At:          message.At,
+			From:        message.Role,
+			Text:        message.Text,
+			Attachments: message.Attachments,
+		})
+	}
+}
+
+// reportContactMessage files an abuse report about the sender of a message
+// received in a contact thread. The report points at the message as its
+// evidence, and the reporter only learns its ID.
+func (a *App) reportContactMessage(onCommand func(domain.Command) error, onEvent func(domain.Event) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+		seq, err := strconv.Atoi(vars["seq"])
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid message number")
+			return
+		}
+		var req ContactReportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		auctionId := domain.AuctionId(id)
+		role, err := domain.ContactRole(a.State.GetRepository(), auctionId, user.ID, a.GetCurrentTime())
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		// Only messages received from the other party can be reported
+		messages := a.State.GetContacts()[auctionId].Messages
+		if seq < 1 || seq > len(messages) || messages[seq-1].Role == role {
+			respondDomainError(w, domain.NewContactMessageNotFoundError(auctionId, seq))
+			return
+		}
+		sender := messages[seq-1].Sender
+
+		cmd := domain.FileReportCommand{
+			Time: a.GetCurrentTime(),
+			Report: domain.Report{
+				Reporter: user.ID,
+				Target:   domain.ReportTarget{UserId: &sender},
+				Reason:   req.Reason,
+				Text:     req.Text,
+				Evidence: []string{fmt.Sprintf("contact/%d/%d", auctionId, seq)},
+			},
+		}
+		if err := onCommand(cmd); err != nil {
+			log.Printf("Failed to observe command: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		var report domain.Report
+		var eventErr error
+		err = a.State.UpdateReports(func(reports domain.Reports) (domain.Reports, error) {
+			event, newReports, err := domain.HandleReport(cmd, reports)
+			if err != nil {
+				return nil, err
+			}
+			report = event.(domain.ReportFiledEvent).Report
+			eventErr = onEvent(event)
+			return newReports, nil
+		})
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		if eventErr != nil {
+			log.Printf("Failed to observe event: %v", eventErr)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		respondJSON(w, http.StatusOK, ContactReportResponse{ReportId: report.ID, Status: report.Status})
+	}
+}
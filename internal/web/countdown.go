@@ -0,0 +1,67 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// getServerTime returns the server time, so clients can estimate their clock
+// offset as serverTimeMillis + round trip / 2 - local time
+func getServerTime(getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		now := getCurrentTime()
+		respondJSON(w, http.StatusOK, ServerTimeResponse{
+			ServerTime:       now,
+			ServerTimeMillis: now.UnixNano() / int64(time.Millisecond),
+		})
+	}
+}
+
+// getAuctionCountdown returns the time left until an auction starts and
+// closes. The durations don't depend on the client clock, so clients should
+// count them down with a monotonic timer and refetch after late bids, which
+// may extend the expiry.
+func getAuctionCountdown(state *AppState, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse auction ID from path
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+
+		repo := state.GetRepository()
+		entry, ok := repo[domain.AuctionId(id)]
+		if !ok || !entry.Auction.VisibleTo(requestUser(r)) {
+			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+			return
+		}
+
+		now := getCurrentTime()
+		auctionState := entry.State.Increment(now)
+		expiry := domain.CurrentExpiry(auctionState)
+
+		response := AuctionCountdownResponse{
+			ID:               entry.Auction.ID,
+			ServerTime:       now,
+			ServerTimeMillis: now.UnixNano() / int64(time.Millisecond),
+			StartsAt:         entry.Auction.StartsAt,
+			Expiry:           expiry,
+			HasEnded:         auctionState.HasEnded(),
+		}
+		if now.Before(entry.Auction.StartsAt) {
+			response.StartsInMillis = entry.Auction.StartsAt.Sub(now).Milliseconds()
+		}
+		if now.Before(expiry) {
+			response.RemainingMillis = expiry.Sub(now).Milliseconds()
+		}
+
+		respondJSON(w, http.StatusOK, response)
+	}
+}
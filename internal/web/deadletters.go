@@ -0,0 +1,91 @@
+package web
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// DeadLetterQueue keeps the commands that failed validation or processing
+type DeadLetterQueue interface {
+	Add(at time.Time, cmd domain.Command, cause error) (domain.DeadLetter, error)
+	Get(id int64) (domain.DeadLetter, bool)
+	List(all bool) []domain.DeadLetter
+	Retried(id int64, at time.Time, cause error) (domain.DeadLetter, error)
+}
+
+// retryKey marks the context of a retried dead letter, which isn't dead
+// lettered again when it fails
+type retryKey struct{}
+
+// deadLetter records a failed command, if a dead letter queue is set
+func (a *App) deadLetter(ctx context.Context, cmd domain.Command, cause error) {
+	if a.DeadLetters == nil || ctx.Value(retryKey{}) != nil {
+		return
+	}
+	if _, err := a.DeadLetters.Add(a.GetCurrentTime(), cmd, cause); err != nil {
+		log.Printf("Failed to record dead letter: %v", err)
+	}
+}
+
+// getDeadLetters lists the unresolved dead letters, or all of them with
+// ?all=true
+func (a *App) getDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if _, ok := extractSupportUser(w, r); !ok {
+		return
+	}
+	if a.DeadLetters == nil {
+		respondError(w, http.StatusNotFound, "Dead letters not available")
+		return
+	}
+	respondJSON(w, http.StatusOK, a.DeadLetters.List(r.URL.Query().Get("all") == "true"))
+}
+
+// retryDeadLetter dispatches a dead letter's command again, as it was, and
+// records the outcome of the retry
+func (a *App) retryDeadLetter(onEvent func(domain.Event) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := extractSupportUser(w, r); !ok {
+			return
+		}
+		if a.DeadLetters == nil {
+			respondError(w, http.StatusNotFound, "Dead letters not available")
+			return
+		}
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid dead letter ID")
+			return
+		}
+		letter, ok := a.DeadLetters.Get(id)
+		if !ok {
+			respondError(w, http.StatusNotFound, "Dead letter not found")
+			return
+		}
+		if letter.Resolved {
+			respondError(w, http.StatusConflict, "Dead letter already resolved")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), retryKey{}, true)
+		event, newRepo, cause := a.Commands.Dispatch(ctx, letter.Command, a.State.GetRepository())
+		if cause == nil {
+			a.State.UpdateRepository(newRepo)
+			cause = onEvent(event)
+		}
+		if _, err := a.DeadLetters.Retried(id, a.GetCurrentTime(), cause); err != nil {
+			log.Printf("Failed to record retry of dead letter %d: %v", id, err)
+		}
+		if cause != nil {
+			respondDomainError(w, cause)
+			return
+		}
+		respondJSON(w, http.StatusOK, event)
+	}
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// storeRetryInterval is how long writes are rejected after the store failed,
+// before a write is let through to check if it recovered
+const storeRetryInterval = 5 * time.Second
+
+// storeStatus tracks whether the store accepts writes. Reads are served from
+// the in-memory state and keep working while the store is down.
+type storeStatus struct {
+	mu          sync.Mutex
+	failing     bool
+	lastSuccess time.Time
+	lastFailure time.Time
+}
+
+// observe records the outcome of a write to the store and returns its error.
+// Domain errors are rejections of the write, not failures of the store.
+func (s *storeStatus) observe(err error, now time.Time) error {
+	if _, ok := err.(domain.DomainError); ok {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.failing = true
+		s.lastFailure = now
+	} else {
+		s.failing = false
+		s.lastSuccess = now
+	}
+	return err
+}
+
+// degraded returns whether the store is failing, whether writes should be
+// rejected, and how long until the next write is let through
+func (s *storeStatus) degraded(now time.Time) (bool, bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.failing {
+		return false, false, 0
+	}
+	retryAt := s.lastFailure.Add(storeRetryInterval)
+	return true, now.Before(retryAt), retryAt.Sub(now)
+}
+
+// staleSince returns when the last write succeeded
+func (s *storeStatus) staleSince() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastSuccess
+}
+
+// degradationMiddleware serves reads with staleness markers while the store
+// is failing, and rejects writes with a 503 instead of letting each of them
+// fail on the store
+func degradationMiddleware(status *storeStatus, getCurrentTime func() time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			failing, rejecting, retryIn := status.degraded(getCurrentTime())
+			if !failing {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				w.Header().Set("Warning", `110 - "Response is Stale"`)
+				if since := status.staleSince(); !since.IsZero() {
+					w.Header().Set("X-Stale-Since", since.UTC().Format(time.RFC3339))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if rejecting {
+				retryAfter := math.Ceil(retryIn.Seconds())
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+				respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+					"type":              "StoreUnavailable",
+					"retryAfterSeconds": retryAfter,
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package web
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// forgetUser erases the personal data of a user from the store, by deleting
+// their key, and from the auctions in memory they took part in
+func (a *App) forgetUser(w http.ResponseWriter, r *http.Request) {
+	if _, ok := extractSupportUser(w, r); !ok {
+		return
+	}
+	if a.ForgetUser == nil {
+		respondError(w, http.StatusNotFound, "Erasure not available")
+		return
+	}
+	userId := domain.UserId(mux.Vars(r)["id"])
+	if err := a.ForgetUser(userId); err != nil {
+		log.Printf("Failed to forget user %s: %v", userId, err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	forgotten, err := domain.ForgetUser(a.State.GetRepository(), userId)
+	if err != nil {
+		log.Printf("Failed to forget user %s in memory: %v", userId, err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	a.State.UpdateRepository(forgotten)
+	respondJSON(w, http.StatusOK, ForgetUserResponse{UserId: userId, Auctions: len(forgotten)})
+}
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxEventFeedLimit bounds the number of events returned by a single request
+const maxEventFeedLimit = 1000
+
+// getEvents returns the events after the position given by "since", each
+// with its position, for consumers building their own projections. The
+// response position is where to continue from.
+func (a *App) getEvents(w http.ResponseWriter, r *http.Request) {
+	if _, ok := extractSupportUser(w, r); !ok {
+		return
+	}
+	if a.ReadEventsSince == nil {
+		respondError(w, http.StatusNotFound, "Event feed not available")
+		return
+	}
+
+	query := r.URL.Query()
+	var since int64
+	if s := query.Get("since"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			respondError(w, http.StatusBadRequest, "Invalid position")
+			return
+		}
+		since = n
+	}
+	limit := maxEventFeedLimit
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxEventFeedLimit {
+			respondError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	events, err := a.ReadEventsSince(since)
+	if err != nil {
+		log.Printf("Failed to read events: %v", err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	var ids []string
+	if a.ReadEventIdsSince != nil {
+		if ids, err = a.ReadEventIdsSince(since); err != nil {
+			log.Printf("Failed to read event IDs: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+	}
+
+	response := EventFeedResponse{
+		Events:   make([]PositionedEventResponse, len(events)),
+		Position: since + int64(len(events)),
+	}
+	for i, event := range events {
+		response.Events[i] = PositionedEventResponse{Position: since + int64(i) + 1, Event: event}
+		if i < len(ids) {
+			response.Events[i].ID = ids[i]
+		}
+	}
+	respondJSON(w, http.StatusOK, response)
+}
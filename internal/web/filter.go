@@ -0,0 +1,323 @@
+package web
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// List endpoints accept a "filter" query parameter with a small expression
+// language over an allowlist of fields per resource:
+//
+//	currency eq VAC and (expiry between 2020-01-01T00:00:00Z and 2020-02-01T00:00:00Z or title contains 'old car')
+//
+// Comparisons are eq, ne, gt, ge, lt, le, contains (strings only) and
+// between, which is inclusive. "and" binds tighter than "or". Values are bare
+// words or single-quoted strings, where '' stands for a quote. Values are
+// converted to the type of their field when parsing, so a filter either fails
+// up front or evaluates without errors.
+
+// maxFilterLength bounds the size of filter expressions
+const maxFilterLength = 1000
+
+// filterFieldKind is the type of the values of a filterable field
+type filterFieldKind int
+
+const (
+	numberField filterFieldKind = iota
+	stringField
+	timeField
+)
+
+// filterField is a filterable field, get returns its value for an item as an
+// int64, a string or a time.Time according to kind
+type filterField struct {
+	kind filterFieldKind
+	get  func(item interface{}) interface{}
+}
+
+// filterFields is the allowlist of the filterable fields of a resource
+type filterFields map[string]filterField
+
+// filterPredicate tells whether an item matches a filter
+type filterPredicate func(item interface{}) bool
+
+// auctionFilterFields are the filterable fields of the auctions list
+var auctionFilterFields = filterFields{
+	"id":       {numberField, func(item interface{}) interface{} { return int64(item.(AuctionListItem).ID) }},
+	"title":    {stringField, func(item interface{}) interface{} { return item.(AuctionListItem).Title }},
+	"currency": {stringField, func(item interface{}) interface{} { return string(item.(AuctionListItem).Currency) }},
+	"startsAt": {timeField, func(item interface{}) interface{} { return item.(AuctionListItem).StartsAt }},
+	"expiry":   {timeField, func(item interface{}) interface{} { return item.(AuctionListItem).Expiry }},
+	"status":   {stringField, func(item interface{}) interface{} { return item.(AuctionListItem).Status }},
+	"bidCount": {numberField, func(item interface{}) interface{} { return int64(item.(AuctionListItem).BidCount) }},
+}
+
+// reportFilterFields are the filterable fields of the reports list
+var reportFilterFields = filterFields{
+	"id":       {numberField, func(item interface{}) interface{} { return int64(item.(ReportResponse).ID) }},
+	"status":   {stringField, func(item interface{}) interface{} { return string(item.(ReportResponse).Status) }},
+	"reason":   {stringField, func(item interface{}) interface{} { return string(item.(ReportResponse).Reason) }},
+	"reporter": {stringField, func(item interface{}) interface{} { return string(item.(ReportResponse).Reporter) }},
+	"filedAt":  {timeField, func(item interface{}) interface{} { return item.(ReportResponse).FiledAt }},
+	"dueBy":    {timeField, func(item interface{}) interface{} { return item.(ReportResponse).DueBy }},
+}
+
+// parseFilter compiles a filter expression over the allowed fields. An empty
+// expression matches everything.
+func parseFilter(expr string, fields filterFields) (filterPredicate, error) {
+	if strings.TrimSpace(expr) == "" {
+		return func(interface{}) bool { return true }, nil
+	}
+	if len(expr) > maxFilterLength {
+		return nil, fmt.Errorf("filter is longer than %d characters", maxFilterLength)
+	}
+
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens, fields: fields}
+	predicate, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return predicate, nil
+}
+
+// filterToken is a token of a filter expression, quoted tells string
+// literals apart from words
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+// lexFilter splits a filter expression into tokens
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, filterToken{text: string(c)})
+			i++
+		case c == '\'':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(expr) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				if expr[i] == '\'' {
+					if i+1 < len(expr) && expr[i+1] == '\'' {
+						b.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(expr[i])
+				i++
+			}
+			tokens = append(tokens, filterToken{text: b.String(), quoted: true})
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t\n()'", rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, filterToken{text: expr[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser is a recursive descent parser over filter tokens
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	fields filterFields
+}
+
+// next returns the next token, failing at the end of the expression
+func (p *filterParser) next() (filterToken, error) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, fmt.Errorf("unexpected end of filter")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+// keyword consumes the next token if it's the given unquoted keyword
+func (p *filterParser) keyword(word string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterPredicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item interface{}) bool { return l(item) || right(item) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterPredicate, error) {
+	left, err := p.parseCondition()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item interface{}) bool { return l(item) && right(item) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseCondition() (filterPredicate, error) {
+	if p.keyword("(") {
+		predicate, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return predicate, nil
+	}
+
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	field, ok := p.fields[name.text]
+	if !ok || name.quoted {
+		return nil, fmt.Errorf("unknown field %q", name.text)
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(op.text, "between") && !op.quoted {
+		low, err := p.value(field)
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("and") {
+			return nil, fmt.Errorf("expected and in between")
+		}
+		high, err := p.value(field)
+		if err != nil {
+			return nil, err
+		}
+		return func(item interface{}) bool {
+			v := field.get(item)
+			return compareFilterValues(v, low) >= 0 && compareFilterValues(v, high) <= 0
+		}, nil
+	}
+
+	value, err := p.value(field)
+	if err != nil {
+		return nil, err
+	}
+	var test func(c int) bool
+	switch strings.ToLower(op.text) {
+	case "eq":
+		test = func(c int) bool { return c == 0 }
+	case "ne":
+		test = func(c int) bool { return c != 0 }
+	case "gt":
+		test = func(c int) bool { return c > 0 }
+	case "ge":
+		test = func(c int) bool { return c >= 0 }
+	case "lt":
+		test = func(c int) bool { return c < 0 }
+	case "le":
+		test = func(c int) bool { return c <= 0 }
+	case "contains":
+		if field.kind != stringField {
+			return nil, fmt.Errorf("contains only applies to text fields, not %s", name.text)
+		}
+		needle := strings.ToLower(value.(string))
+		return func(item interface{}) bool {
+			return strings.Contains(strings.ToLower(field.get(item).(string)), needle)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op.text)
+	}
+	return func(item interface{}) bool {
+		return test(compareFilterValues(field.get(item), value))
+	}, nil
+}
+
+// value parses the next token as a value of the field's type
+func (p *filterParser) value(field filterField) (interface{}, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch field.kind {
+	case numberField:
+		n, err := strconv.ParseInt(token.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token.text)
+		}
+		return n, nil
+	case timeField:
+		t, err := time.Parse(time.RFC3339, token.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q, expected RFC 3339", token.text)
+		}
+		return t, nil
+	default:
+		return token.text, nil
+	}
+}
+
+// compareFilterValues compares two values of the same field type
+func compareFilterValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		b := b.(int64)
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	case time.Time:
+		b := b.(time.Time)
+		if a.Before(b) {
+			return -1
+		}
+		if a.After(b) {
+			return 1
+		}
+		return 0
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,21 +15,36 @@ import (
 	"auction-site-go/internal/domain"
 )
 
-// getAuctions returns all auctions
-func getAuctions(state *AppState) http.HandlerFunc {
+// getAuctions returns all auctions with their current price, bid count and
+// status, read from the repository kept up to date by the applied events
+func getAuctions(state *AppState, getCurrentTime func() time.Time) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseFilter(r.URL.Query().Get("filter"), auctionFilterFields)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid filter: "+err.Error())
+			return
+		}
+		phase := r.URL.Query().Get("phase")
+		if _, ok := auctionPhases[phase]; phase != "" && !ok {
+			respondError(w, http.StatusBadRequest, "Invalid phase")
+			return
+		}
+
 		repo := state.GetRepository()
 		auctions := domain.GetAuctions(repo)
+		languages := acceptedLanguages(r.Header.Get("Accept-Language"))
+		w.Header().Set("Vary", "Accept-Language")
+		now := getCurrentTime()
 
 		// Convert to AuctionListItem
-		auctionItems := make([]AuctionListItem, len(auctions))
-		for i, auction := range auctions {
-			auctionItems[i] = AuctionListItem{
-				ID:       auction.ID,
-				StartsAt: auction.StartsAt,
-				Title:    auction.Title,
-				Expiry:   auction.Expiry,
-				Currency: auction.Currency,
+		auctionItems := make([]AuctionListItem, 0, len(auctions))
+		for _, auction := range auctions {
+			if !auction.Listed() {
+				continue
+			}
+			item := toAuctionListItem(auction, repo[auction.ID].State, now, languages)
+			if filter(item) && (phase == "" || auctionPhases[phase][item.Status]) {
+				auctionItems = append(auctionItems, item)
 			}
 		}
 
@@ -36,6 +52,77 @@ func getAuctions(state *AppState) http.HandlerFunc {
 	}
 }
 
+// toAuctionListItem summarizes an auction and its state at the given time
+func toAuctionListItem(auction domain.Auction, state domain.State, now time.Time, languages []string) AuctionListItem {
+	state = state.Increment(now)
+	title, language := auction.LocalizedTitle(languages)
+	item := AuctionListItem{
+		ID:       auction.ID,
+		StartsAt: auction.StartsAt,
+		Title:    title,
+		Language: language,
+		Expiry:   auction.Expiry,
+		Currency: auction.Currency,
+		Tags:     auction.Tags,
+		Status:   auctionStatus(auction, state, now),
+	}
+	item.CurrentPrice, item.BidCount = visibleBids(auction, state)
+	item.Reserve = domain.ReserveStatus(auction, state)
+	item.BuyNowPrice = buyNowPrice(state, now)
+	item.ExtendedUntil = extendedUntil(state)
+	return item
+}
+
+// auctionPhases maps the phases auctions can be listed by to their statuses
+var auctionPhases = map[string]map[string]bool{
+	"upcoming": {"NotStarted": true},
+	"live":     {"Open": true},
+	"ended":    {"Ended": true, "Cancelled": true},
+}
+
+// auctionStatus returns whether an auction is NotStarted, Open, Ended or
+// Cancelled
+func auctionStatus(auction domain.Auction, state domain.State, now time.Time) string {
+	_, cancelled := domain.CancelledAt(state)
+	switch {
+	case cancelled:
+		return "Cancelled"
+	case state.HasEnded():
+		return "Ended"
+	case now.Before(auction.StartsAt):
+		return "NotStarted"
+	default:
+		return "Open"
+	}
+}
+
+// visibleBids returns the highest bid on an auction, nil while sealed bids
+// are undisclosed or when there are no bids, along with the number of bids
+func visibleBids(auction domain.Auction, state domain.State) (*int64, int) {
+	bids := state.GetBids()
+	if sealedBids(auction, state) {
+		return nil, len(bids)
+	}
+	var highest *int64
+	for _, bid := range bids {
+		if highest == nil || bid.Amount > *highest {
+			amount := bid.Amount
+			highest = &amount
+		}
+	}
+	return highest, len(bids)
+}
+
+// sealedBids tells whether the bids of an auction are undisclosed, as those
+// of a sealed bid auction are until it closes, and for good once cancelled
+func sealedBids(auction domain.Auction, state domain.State) bool {
+	if auction.Type.Type != domain.SingleSealedBid {
+		return false
+	}
+	_, cancelled := domain.CancelledAt(state)
+	return cancelled || !state.HasEnded()
+}
+
 // getAuction returns a specific auction
 func getAuction(state *AppState, getCurrentTime func() time.Time) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -51,56 +138,224 @@ func getAuction(state *AppState, getCurrentTime func() time.Time) http.HandlerFu
 		// Get auction from repository
 		repo := state.GetRepository()
 		entry, ok := repo[domain.AuctionId(id)]
-		if !ok {
+		if !ok || !entry.Auction.VisibleTo(requestUser(r)) {
 			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
 			return
 		}
 
-		auction := entry.Auction
 		// Advance state to the current time so a winner surfaces once the auction has ended.
-		auctionState := entry.State.Increment(getCurrentTime())
-
-		// Get bids
-		bids := auctionState.GetBids()
-		bidResponses := make([]AuctionBidResponse, len(bids))
-		for i, bid := range bids {
-			bidResponses[i] = AuctionBidResponse{
-				Amount: bid.Amount,
-				Bidder: bid.Bidder,
+		now := getCurrentTime()
+		response := auctionResponse(w, r, entry.Auction, entry.State.Increment(now), now)
+		response.Description = state.GetDescriptions()[entry.Auction.ID]
+		respondJSON(w, http.StatusOK, response)
+	}
+}
+
+// auctionResponse returns the response for an auction in a state at a time,
+// with the title in the language of the request. Until a sealed bid auction
+// closes, or if it's cancelled, bidders only see their own bid.
+func auctionResponse(w http.ResponseWriter, r *http.Request, auction domain.Auction, auctionState domain.State, now time.Time) AuctionResponse {
+	// Get bids
+	sealed := sealedBids(auction, auctionState)
+	user := requestUser(r)
+	bidResponses := []AuctionBidResponse{}
+	for _, bid := range auctionState.GetBids() {
+		if sealed && (user == nil || bid.Bidder.ID != user.ID) {
+			continue
+		}
+		bidResponses = append(bidResponses, AuctionBidResponse{
+			Amount: bid.Amount,
+			Bidder: bid.Bidder,
+		})
+	}
+
+	// Get winner information
+	var winner *domain.UserId
+	var winnerPrice *int64
+	if amount, userId, found := auctionState.TryGetAmountAndWinner(); found {
+		winner = &userId
+		winnerPrice = &amount
+	}
+
+	var currentPrice *int64
+	if dutch, ok := auctionState.(*domain.DutchState); ok && !dutch.HasEnded() {
+		price := dutch.CurrentPrice(now)
+		currentPrice = &price
+	}
+
+	increments, minimumBid := bidIncrements(auction, auctionState)
+
+	title, language := auction.LocalizedTitle(acceptedLanguages(r.Header.Get("Accept-Language")))
+	w.Header().Set("Vary", "Accept-Language")
+
+	return AuctionResponse{
+		ID:            auction.ID,
+		StartsAt:      auction.StartsAt,
+		Title:         title,
+		Language:      language,
+		Expiry:        auction.Expiry,
+		Currency:      auction.Currency,
+		Visibility:    auction.Visibility,
+		Tags:          auction.Tags,
+		Bids:          bidResponses,
+		Winner:        winner,
+		WinnerPrice:   winnerPrice,
+		CurrentPrice:  currentPrice,
+		Reserve:       domain.ReserveStatus(auction, auctionState),
+		BuyNowPrice:   buyNowPrice(auctionState, now),
+		MaxBid:        maxBid(auctionState, user, now),
+		Increments:    increments,
+		MinimumBid:    minimumBid,
+		ExtendedUntil: extendedUntil(auctionState),
+		Extensions:    domain.Extensions(auctionState),
+		Allocations:   domain.Allocations(auctionState),
+		CancelledAt:   cancelledAt(auctionState),
+	}
+}
+
+// withIncrements sets the bid increment table of a timed ascending auction
+// type, the default one unless given or set by the type already
+func withIncrements(auctionType domain.AuctionType, increments domain.IncrementTable) domain.AuctionType {
+	if auctionType.Type != domain.TimedAscending {
+		return auctionType
+	}
+	options, err := domain.ParseTimedAscendingOptions(auctionType.Options)
+	if err != nil {
+		// Left for validation to reject
+		return auctionType
+	}
+	if increments != nil {
+		options.Increments = increments
+	} else if options.Increments == nil {
+		options.Increments = domain.DefaultIncrementTable
+	}
+	return domain.NewTimedAscendingType(*options)
+}
+
+// bidIncrements returns the bid increments of a timed ascending auction and
+// the least the next bid can be, nil once it has ended
+func bidIncrements(auction domain.Auction, state domain.State) (domain.IncrementTable, *int64) {
+	if auction.Type.Type != domain.TimedAscending {
+		return nil, nil
+	}
+	options, err := domain.ParseTimedAscendingOptions(auction.Type.Options)
+	if err != nil {
+		return nil, nil
+	}
+	if state.HasEnded() {
+		return options.Increments, nil
+	}
+	minimum := int64(1)
+	if bids := state.GetBids(); len(bids) > 0 {
+		minimum = bids[0].Amount + options.MinimumRaise(bids[0].Amount)
+	}
+	return options.Increments, &minimum
+}
+
+// getDefaultIncrements returns the bid increment table of the auctions listed
+// without one
+func getDefaultIncrements(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, domain.DefaultIncrementTable)
+}
+
+// maxBid returns the maximum bid of a user on an auction, nil when they
+// have none
+func maxBid(state domain.State, user *domain.User, now time.Time) *int64 {
+	if user == nil {
+		return nil
+	}
+	max, ok := domain.MaxBid(state, user.ID, now)
+	if !ok {
+		return nil
+	}
+	return &max
+}
+
+// buyNowPrice returns the buy now price of an auction, nil when it can't be
+// bought now
+func buyNowPrice(state domain.State, now time.Time) *int64 {
+	price, ok := domain.BuyNowPrice(state, now)
+	if !ok {
+		return nil
+	}
+	return &price
+}
+
+// maxVelocityBuckets limits the size of a bid velocity time series
+const maxVelocityBuckets = 1000
+
+// hotAuctionBids is the number of bids in the latest interval that makes an auction hot
+const hotAuctionBids = 5
+
+// getAuctionVelocity returns the bid velocity of an auction to its seller
+func getAuctionVelocity(state *AppState, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse auction ID from path
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+
+		interval := time.Hour
+		if s := r.URL.Query().Get("interval"); s != "" {
+			if interval, err = time.ParseDuration(s); err != nil || interval <= 0 {
+				respondError(w, http.StatusBadRequest, "Invalid interval")
+				return
 			}
 		}
 
-		// Get winner information
-		var winner *domain.UserId
-		var winnerPrice *int64
-		if amount, userId, found := auctionState.TryGetAmountAndWinner(); found {
-			winner = &userId
-			winnerPrice = &amount
+		// Extract user from JWT
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
 		}
 
-		// Create response
-		response := AuctionResponse{
-			ID:          auction.ID,
-			StartsAt:    auction.StartsAt,
-			Title:       auction.Title,
-			Expiry:      auction.Expiry,
-			Currency:    auction.Currency,
-			Bids:        bidResponses,
-			Winner:      winner,
-			WinnerPrice: winnerPrice,
+		repo := state.GetRepository()
+		entry, ok := repo[domain.AuctionId(id)]
+		if !ok {
+			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+			return
 		}
 
-		respondJSON(w, http.StatusOK, response)
+		// Only the seller and support can see the activity of an auction
+		auction := entry.Auction
+		if user.ID != auction.Seller.ID && user.Type != "Support" {
+			respondError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		now := getCurrentTime()
+		to := auction.Expiry
+		if now.Before(to) {
+			to = now
+		}
+		if to.Sub(auction.StartsAt)/interval >= maxVelocityBuckets {
+			respondError(w, http.StatusBadRequest, "Interval too small")
+			return
+		}
+
+		velocity := domain.ComputeBidVelocity(entry.State.Increment(now).GetBids(), auction.StartsAt, to, interval)
+		respondJSON(w, http.StatusOK, AuctionVelocityResponse{
+			ID:            auction.ID,
+			Interval:      interval.String(),
+			Buckets:       velocity.Buckets,
+			TotalBids:     velocity.TotalBids,
+			UniqueBidders: velocity.UniqueBidders,
+			Hot:           velocity.IsHot(hotAuctionBids),
+		})
 	}
 }
 
 // createAuction creates a new auction
-func createAuction(state *AppState, onCommand func(domain.Command) error, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+func createAuction(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time, moderate func(domain.Auction) (*domain.ModerationDecision, error), screen screenFunc, translate func(domain.Auction, func(domain.Event) error)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse request body
 		var req AddAuctionRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid request body")
+			respondInvalidBody(w, err)
 			return
 		}
 
@@ -113,69 +368,155 @@ func createAuction(state *AppState, onCommand func(domain.Command) error, onEven
 
 		// Create auction
 		var auctionType domain.AuctionType
-		if req.Type.Type != 0 {
+		// TimedAscending is the zero type, so a given type is told by its options
+		if req.Type.Options != "" {
 			auctionType = req.Type
 		} else {
 			// Default to English auction
 			options := domain.DefaultTimedAscendingOptions()
 			auctionType = domain.NewTimedAscendingType(options)
 		}
+		auctionType = withIncrements(auctionType, req.Increments)
 
-		auction := domain.Auction{
-			ID:       req.ID,
-			StartsAt: req.StartsAt,
-			Title:    req.Title,
-			Expiry:   req.EndsAt,
-			Seller:   user,
-			Type:     auctionType,
-			Currency: req.Currency,
+		if err := domain.ValidateTranslations(req.Translations); err != nil {
+			respondDomainError(w, err)
+			return
 		}
-
-		now := getCurrentTime()
-
-		// Reject auctions whose EndsAt is not strictly in the future.
-		if !req.EndsAt.After(now) {
-			respondDomainError(w, domain.NewAuctionHasEndedError(req.ID))
+		if !domain.ValidVisibility(req.Visibility) {
+			respondError(w, http.StatusBadRequest, "Invalid visibility")
 			return
 		}
 
-		// Create command
-		cmd := domain.AddAuctionCommand{
-			Time:    now,
-			Auction: auction,
+		auction := domain.Auction{
+			ID:           req.ID,
+			StartsAt:     req.StartsAt,
+			Title:        req.Title,
+			Expiry:       req.EndsAt,
+			Seller:       user,
+			Type:         auctionType,
+			Currency:     req.Currency,
+			Translations: req.Translations,
+			Visibility:   req.Visibility,
+			Invitees:     req.Invitees,
 		}
-
-		if err := onCommand(cmd); err != nil {
-			log.Printf("Failed to observe command: %v", err)
-			respondError(w, http.StatusInternalServerError, "Internal server error")
-			return
+		if req.Tender != nil {
+			auction.Tender = &domain.Tender{
+				PublicKey:  req.Tender.PublicKey,
+				Threshold:  req.Tender.Threshold,
+				Custodians: req.Tender.Custodians,
+			}
 		}
 
-		// Handle command
-		repo := state.GetRepository()
-		event, newRepo, err := domain.Handle(cmd, repo)
-		if err != nil {
-			respondDomainError(w, err)
+		publishListing(w, r, state, commands, onEvent, getCurrentTime(), moderate, screen, translate, auction)
+	}
+}
+
+// publishListing screens the seller, adds the auction and moderates and
+// translates the listing, responding with the AuctionAdded event
+func publishListing(w http.ResponseWriter, r *http.Request, state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, now time.Time, moderate func(domain.Auction) (*domain.ModerationDecision, error), screen screenFunc, translate func(domain.Auction, func(domain.Event) error), auction domain.Auction) {
+	if !screenUser(w, screen, onEvent, auction.Seller, domain.ScreeningListing, 0) {
+		return
+	}
+
+	// Reject auctions whose EndsAt is not strictly in the future.
+	if !auction.Expiry.After(now) {
+		respondDomainError(w, domain.NewAuctionHasEndedError(auction.ID))
+		return
+	}
+
+	// Create command
+	cmd := domain.AddAuctionCommand{
+		Time:           now,
+		Auction:        auction,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	}
+
+	// Record and handle the command
+	event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	// Moderate the listing before publishing it
+	decision, err := moderate(auction)
+	if err != nil {
+		log.Printf("Failed to moderate listing: %v", err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	var moderated *domain.ListingModeratedEvent
+	if decision != nil {
+		moderated = &domain.ListingModeratedEvent{Time: now, AuctionId: auction.ID, Decision: *decision}
+		if decision.Verdict == domain.ModerationRejected {
+			if err := onEvent(*moderated); err != nil {
+				log.Printf("Failed to observe event: %v", err)
+				respondError(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			respondDomainError(w, domain.NewListingRejectedError(auction.ID, decision.Reasons))
 			return
 		}
+	}
 
-		// Update repository
-		state.UpdateRepository(newRepo)
+	// Update repository
+	state.UpdateRepository(newRepo)
 
-		// Call event handler
-		if err := onEvent(event); err != nil {
+	// Call event handler
+	if err := onEvent(event); err != nil {
+		log.Printf("Failed to observe event: %v", err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if moderated != nil {
+		if err := observeModeration(state, *moderated, onEvent); err != nil {
 			log.Printf("Failed to observe event: %v", err)
 			respondError(w, http.StatusInternalServerError, "Internal server error")
 			return
 		}
+	}
 
-		// Return the event
-		respondJSON(w, http.StatusOK, event)
+	translate(auction, onEvent)
+
+	// Return the event
+	respondJSON(w, http.StatusOK, event)
+}
+
+// observeModeration records the moderation decision on a published listing,
+// filing a report into the moderation queue when it needs human review
+func observeModeration(state *AppState, moderated domain.ListingModeratedEvent, onEvent func(domain.Event) error) error {
+	if err := onEvent(moderated); err != nil {
+		return err
 	}
+	if moderated.Decision.Verdict != domain.ModerationReview {
+		return nil
+	}
+
+	auctionId := moderated.AuctionId
+	cmd := domain.FileReportCommand{
+		Time: moderated.Time,
+		Report: domain.Report{
+			Reporter: domain.UserId("moderation:" + moderated.Decision.Provider),
+			Target:   domain.ReportTarget{AuctionId: &auctionId},
+			Reason:   domain.ReportProhibitedItem,
+			Text:     strings.Join(moderated.Decision.Reasons, "; "),
+		},
+	}
+	return state.UpdateReports(func(reports domain.Reports) (domain.Reports, error) {
+		event, newReports, err := domain.HandleReport(cmd, reports)
+		if err != nil {
+			return nil, err
+		}
+		if err := onEvent(event); err != nil {
+			return nil, err
+		}
+		return newReports, nil
+	})
 }
 
 // placeBid places a bid on an auction
-func placeBid(state *AppState, onCommand func(domain.Command) error, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+func placeBid(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time, screen screenFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse auction ID from path
 		vars := mux.Vars(r)
@@ -189,7 +530,7 @@ func placeBid(state *AppState, onCommand func(domain.Command) error, onEvent fun
 		// Parse request body
 		var req BidRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid request body")
+			respondInvalidBody(w, err)
 			return
 		}
 
@@ -200,29 +541,37 @@ func placeBid(state *AppState, onCommand func(domain.Command) error, onEvent fun
 			return
 		}
 
+		// The amount of an encrypted bid is unknown, so it's screened as high-value
+		screenedAmount := req.Amount
+		if req.Sealed != "" {
+			screenedAmount = math.MaxInt64
+		}
+		endScreening := timePhase(r, "screening")
+		admitted := screenUser(w, screen, onEvent, user, domain.ScreeningBid, screenedAmount)
+		endScreening()
+		if !admitted {
+			return
+		}
+
 		// Create bid
 		bid := domain.Bid{
 			ForAuction: domain.AuctionId(id),
 			Bidder:     user,
 			At:         getCurrentTime(),
 			Amount:     req.Amount,
+			Sealed:     req.Sealed,
 		}
 
 		// Create command
 		cmd := domain.PlaceBidCommand{
-			Time: getCurrentTime(),
-			Bid:  bid,
-		}
-
-		if err := onCommand(cmd); err != nil {
-			log.Printf("Failed to observe command: %v", err)
-			respondError(w, http.StatusInternalServerError, "Internal server error")
-			return
+			Time:           getCurrentTime(),
+			Bid:            bid,
+			IdempotencyKey: r.Header.Get("Idempotency-Key"),
 		}
 
-		// Handle command
-		repo := state.GetRepository()
-		event, newRepo, err := domain.Handle(cmd, repo)
+		// Record and handle the command
+		before := state.GetRepository()[domain.AuctionId(id)].State
+		event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
 		if err != nil {
 			respondDomainError(w, err)
 			return
@@ -231,18 +580,56 @@ func placeBid(state *AppState, onCommand func(domain.Command) error, onEvent fun
 		// Update repository
 		state.UpdateRepository(newRepo)
 
-		// Call event handler
-		if err := onEvent(event); err != nil {
+		// Call event handler, which persists the event and notifies observers
+		endEvent := timePhase(r, "persistence")
+		err = onEvent(event)
+		endEvent()
+		if err != nil {
 			log.Printf("Failed to observe event: %v", err)
 			respondError(w, http.StatusInternalServerError, "Internal server error")
 			return
 		}
 
+		// Maximum bids counter the bid, either of which may have extended the
+		// auction or met the reserve price
+		runProxyBids(r.Context(), state, commands, onEvent, domain.AuctionId(id), cmd.Time)
+		reportExtension(state, onEvent, domain.AuctionId(id), before, cmd.Time)
+		reportReserve(state, onEvent, domain.AuctionId(id), getCurrentTime())
+
 		// Return the event
 		respondJSON(w, http.StatusOK, event)
 	}
 }
 
+// screenFunc screens a user in a context, returning the screening to record
+// or nil when the user doesn't need screening
+type screenFunc func(user domain.User, context string, amount int64) (*domain.UserScreenedEvent, error)
+
+// screenUser screens a user and records the screening, responding with an
+// error if the user is blocked. It returns false when the request must stop.
+func screenUser(w http.ResponseWriter, screen screenFunc, onEvent func(domain.Event) error, user domain.User, context string, amount int64) bool {
+	screened, err := screen(user, context, amount)
+	if err != nil {
+		log.Printf("Failed to screen user: %v", err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return false
+	}
+	if screened == nil {
+		return true
+	}
+
+	if err := onEvent(*screened); err != nil {
+		log.Printf("Failed to observe event: %v", err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return false
+	}
+	if screened.Result.Match {
+		respondDomainError(w, domain.NewUserBlockedError(user.ID))
+		return false
+	}
+	return true
+}
+
 // extractUserFromRequest extracts a user from an HTTP request
 func extractUserFromRequest(r *http.Request) (domain.User, error) {
 	authHeader := r.Header.Get("x-jwt-payload")
@@ -281,6 +668,8 @@ func respondError(w http.ResponseWriter, status int, message string) {
 type domainErrorRenderer struct {
 	status  int
 	payload func(data interface{}) map[string]interface{}
+	// problem renders the error as RFC 7807 problem+json instead, if set
+	problem func(data interface{}) ProblemDetails
 }
 
 // withAuctionId returns a renderer that produces {type, auctionId} payloads.
@@ -316,6 +705,118 @@ var domainErrorRenderers = map[domain.ErrorType]domainErrorRenderer{
 			return resp
 		},
 	},
+	domain.ErrorDuplicateCommand: {
+		status: http.StatusConflict,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "DuplicateCommand", "idempotencyKey": data}
+		},
+	},
+	domain.ErrorDuplicateEvent: {
+		status: http.StatusConflict,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "DuplicateEvent", "eventId": data}
+		},
+	},
+	domain.ErrorInvalidReport: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "InvalidReport", "reason": data}
+		},
+	},
+	domain.ErrorReportNotFound: {
+		status: http.StatusNotFound,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "ReportNotFound", "reportId": data}
+		},
+	},
+	domain.ErrorInvalidReportTransition: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			resp := map[string]interface{}{"type": "InvalidReportTransition"}
+			if d, ok := data.(map[string]interface{}); ok {
+				for k, v := range d {
+					resp[k] = v
+				}
+			}
+			return resp
+		},
+	},
+	domain.ErrorListingRejected: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			resp := map[string]interface{}{"type": "ListingRejected"}
+			if d, ok := data.(map[string]interface{}); ok {
+				for k, v := range d {
+					resp[k] = v
+				}
+			}
+			return resp
+		},
+	},
+	domain.ErrorInvalidRuleSet: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "InvalidRuleSet", "reason": data}
+		},
+	},
+	domain.ErrorInvalidTranslation: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "InvalidTranslation", "reason": data}
+		},
+	},
+	domain.ErrorAuctionHasBids: withAuctionId("AuctionHasBids", http.StatusBadRequest),
+	domain.ErrorNotACustodian:  withAuctionId("NotACustodian", http.StatusForbidden),
+	domain.ErrorInvalidTender: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "InvalidTender", "reason": data}
+		},
+	},
+	domain.ErrorInvalidRevision: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "InvalidRevision", "reason": data}
+		},
+	},
+	domain.ErrorUserBlocked: {
+		status: http.StatusForbidden,
+		payload: func(_ interface{}) map[string]interface{} {
+			// The match itself is only recorded, never disclosed
+			return map[string]interface{}{"type": "UserBlocked"}
+		},
+	},
+	domain.ErrorContactThreadClosed: withAuctionId("ContactThreadClosed", http.StatusConflict),
+	domain.ErrorNotAContactParty:    withAuctionId("NotAContactParty", http.StatusForbidden),
+	domain.ErrorContactMessageNotFound: {
+		status: http.StatusNotFound,
+		payload: func(data interface{}) map[string]interface{} {
+			resp := map[string]interface{}{"type": "ContactMessageNotFound"}
+			if d, ok := data.(map[string]interface{}); ok {
+				for k, v := range d {
+					resp[k] = v
+				}
+			}
+			return resp
+		},
+	},
+	domain.ErrorInvalidCommand: {
+		status:  http.StatusBadRequest,
+		problem: invalidCommandProblem,
+	},
+	domain.ErrorWinnerNotDeterminable:   withAuctionId("WinnerNotDeterminable", http.StatusConflict),
+	domain.ErrorBuyNowNotAvailable:      withAuctionId("BuyNowNotAvailable", http.StatusConflict),
+	domain.ErrorProxyBiddingUnsupported: withAuctionId("ProxyBiddingUnsupported", http.StatusBadRequest),
+	domain.ErrorNoProxyBidDue:           withAuctionId("NoProxyBidDue", http.StatusConflict),
+	domain.ErrorCannotCancelAuction:     withAuctionId("CannotCancelAuction", http.StatusForbidden),
+	domain.ErrorAuctionCancelled:        withAuctionId("AuctionCancelled", http.StatusConflict),
+	domain.ErrorCannotAmendAuction:      withAuctionId("CannotAmendAuction", http.StatusForbidden),
+	domain.ErrorBidBelowCurrentPrice: {
+		status: http.StatusBadRequest,
+		payload: func(data interface{}) map[string]interface{} {
+			return map[string]interface{}{"type": "BidBelowCurrentPrice", "price": data}
+		},
+	},
 	domain.ErrorMustPlaceBidOverHighest: {
 		status: http.StatusBadRequest,
 		payload: func(data interface{}) map[string]interface{} {
@@ -343,5 +844,9 @@ func respondDomainError(w http.ResponseWriter, err error) {
 		return
 	}
 
+	if renderer.problem != nil {
+		respondProblem(w, renderer.problem(domainErr.Data))
+		return
+	}
 	respondJSON(w, renderer.status, renderer.payload(domainErr.Data))
 }
@@ -0,0 +1,24 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// healthCheckTimeout bounds how long a readiness probe waits for the store
+const healthCheckTimeout = 2 * time.Second
+
+// getHealth reports whether the application is ready to serve requests,
+// responding 503 when the health check fails
+func (a *App) getHealth(w http.ResponseWriter, r *http.Request) {
+	if a.HealthCheck != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+		if err := a.HealthCheck(ctx); err != nil {
+			respondJSON(w, http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Error: err.Error()})
+			return
+		}
+	}
+	respondJSON(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
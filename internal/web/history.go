@@ -0,0 +1,70 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// priceHistories caches the price histories of ended auctions, which no
+// longer change, so each is computed once after the close
+type priceHistories struct {
+	mu        sync.Mutex
+	byAuction map[domain.AuctionId]domain.PriceHistory
+}
+
+// get returns the cached history of an auction or computes and caches it
+func (h *priceHistories) get(id domain.AuctionId, compute func() domain.PriceHistory) domain.PriceHistory {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.byAuction == nil {
+		h.byAuction = make(map[domain.AuctionId]domain.PriceHistory)
+	}
+	history, ok := h.byAuction[id]
+	if !ok {
+		history = compute()
+		h.byAuction[id] = history
+	}
+	return history
+}
+
+// getPriceHistory returns the price trajectory of an ended auction for charting
+func (a *App) getPriceHistory(w http.ResponseWriter, r *http.Request) {
+	// Parse auction ID from path
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid auction ID")
+		return
+	}
+
+	entry, ok := a.State.GetRepository()[domain.AuctionId(id)]
+	if !ok || !entry.Auction.VisibleTo(requestUser(r)) {
+		respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+		return
+	}
+	state := entry.State.Increment(a.GetCurrentTime())
+	if !state.HasEnded() {
+		respondError(w, http.StatusConflict, "Auction has not ended")
+		return
+	}
+	if sealedBids(entry.Auction, state) {
+		// A cancelled sealed bid auction never discloses its bids
+		respondError(w, http.StatusConflict, "Bids are sealed")
+		return
+	}
+	if entry.Auction.AwaitingReveal() {
+		// The history is cached, so it waits for the amounts
+		respondError(w, http.StatusConflict, "Tender has not been revealed")
+		return
+	}
+
+	history := a.priceHistories.get(entry.Auction.ID, func() domain.PriceHistory {
+		return domain.ComputePriceHistory(entry.Auction, state)
+	})
+	respondJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// liteTimeLayout is the ISO 8601 format of times in the lite representation,
+// always in UTC and without fractional seconds
+const liteTimeLayout = "2006-01-02T15:04:05Z"
+
+// getLiteAuctions returns all auctions in the lite representation, ordered by ID
+func getLiteAuctions(state *AppState, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		now := getCurrentTime()
+		languages := acceptedLanguages(r.Header.Get("Accept-Language"))
+
+		repo := state.GetRepository()
+		auctions := make([]LiteAuction, 0, len(repo))
+		for _, entry := range repo {
+			if !entry.Auction.Listed() {
+				continue
+			}
+			auctions = append(auctions, toLiteAuction(entry.Auction, entry.State, now, languages))
+		}
+		sort.Slice(auctions, func(i, j int) bool {
+			return auctions[i].ID < auctions[j].ID
+		})
+
+		w.Header().Set("Vary", "Accept-Language")
+		respondJSON(w, http.StatusOK, auctions)
+	}
+}
+
+// getLiteAuction returns an auction in the lite representation
+func getLiteAuction(state *AppState, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse auction ID from path
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+
+		entry, ok := state.GetRepository()[domain.AuctionId(id)]
+		if !ok || !entry.Auction.VisibleTo(requestUser(r)) {
+			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+			return
+		}
+
+		languages := acceptedLanguages(r.Header.Get("Accept-Language"))
+		w.Header().Set("Vary", "Accept-Language")
+		respondJSON(w, http.StatusOK, toLiteAuction(entry.Auction, entry.State, getCurrentTime(), languages))
+	}
+}
+
+// toLiteAuction flattens an auction and its state at the given time
+func toLiteAuction(auction domain.Auction, state domain.State, now time.Time, languages []string) LiteAuction {
+	state = state.Increment(now)
+	title, language := auction.LocalizedTitle(languages)
+
+	lite := LiteAuction{
+		ID:          int64(auction.ID),
+		Title:       title,
+		Language:    language,
+		Currency:    string(auction.Currency),
+		AuctionType: liteAuctionType(auction.Type),
+		StartsAt:    auction.StartsAt.UTC().Format(liteTimeLayout),
+		EndsAt:      domain.CurrentExpiry(state).UTC().Format(liteTimeLayout),
+		Status:      auctionStatus(auction, state, now),
+		SellerName:  auction.Seller.Name,
+	}
+	lite.HighestBid, lite.BidCount = visibleBids(auction, state)
+	if amount, winner, ok := state.TryGetAmountAndWinner(); ok {
+		lite.WinnerID = string(winner)
+		lite.WinnerPrice = &amount
+	}
+	return lite
+}
+
+// liteAuctionType names the kind of an auction without its options
+func liteAuctionType(t domain.AuctionType) string {
+	switch t.Type {
+	case domain.SingleSealedBid:
+		return t.Options
+	case domain.Dutch:
+		return "Dutch"
+	case domain.MultiUnit:
+		return "MultiUnit"
+	}
+	return "English"
+}
@@ -0,0 +1,70 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+
+	"auction-site-go/internal/domain"
+)
+
+// maxCorrelationIdLength bounds the correlation IDs accepted from clients
+const maxCorrelationIdLength = 128
+
+// metadataMiddleware puts the event metadata of requests in their context,
+// for the command bus to stamp on events. The correlation ID is taken from
+// the X-Correlation-Id header when the client sends one and echoed back, so
+// a disputed bid can be traced from the client's logs.
+func metadataMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationId := r.Header.Get("X-Correlation-Id")
+		if !validCorrelationId(correlationId) {
+			correlationId = newEventId()
+		}
+		w.Header().Set("X-Correlation-Id", correlationId)
+
+		metadata := domain.EventMetadata{
+			CorrelationId: correlationId,
+			SourceIP:      sourceIP(r),
+		}
+		if user := requestUser(r); user != nil {
+			metadata.UserId = user.ID
+		}
+		next.ServeHTTP(w, r.WithContext(domain.ContextWithMetadata(r.Context(), metadata)))
+	})
+}
+
+// validCorrelationId tells whether a correlation ID is non-empty, at most
+// 128 characters and printable ASCII
+func validCorrelationId(id string) bool {
+	if id == "" || len(id) > maxCorrelationIdLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceIP returns the IP address of the client of a request. Forwarding
+// headers are ignored, since clients can forge them.
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// newEventId returns a random ID for correlation and causation
+func newEventId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		// The ID only serves tracing, so a failure mustn't fail requests
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,102 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"auction-site-go/internal/domain"
+)
+
+// requestFields maps the fields of commands to the fields of the requests
+// they are created from, so problems point at what the client sent
+var requestFields = map[string]string{
+	"auction.title":    "title",
+	"auction.startsAt": "startsAt",
+	"auction.expiry":   "endsAt",
+	"auction.currency": "currency",
+	"auction.type":     "typ",
+	"bid.amount":       "amount",
+}
+
+// fieldMessages render the codes of field errors for clients
+var fieldMessages = map[string]func(field, ref string) string{
+	domain.FieldRequired:          func(field, _ string) string { return fmt.Sprintf("%s is required", field) },
+	domain.FieldMustBePositive:    func(field, _ string) string { return fmt.Sprintf("%s must be positive", field) },
+	domain.FieldMustNotBeNegative: func(field, _ string) string { return fmt.Sprintf("%s must not be negative", field) },
+	domain.FieldMustBeAfter:       func(field, ref string) string { return fmt.Sprintf("%s must be after %s", field, ref) },
+	domain.FieldUnknown:           func(field, _ string) string { return fmt.Sprintf("%s has an unknown value", field) },
+	domain.FieldMalformed:         func(field, _ string) string { return fmt.Sprintf("%s is malformed", field) },
+	domain.FieldTooLong:           func(field, _ string) string { return fmt.Sprintf("%s is too long", field) },
+	domain.FieldTooMany:           func(field, _ string) string { return fmt.Sprintf("%s has too many items", field) },
+	domain.FieldTooLarge:          func(field, _ string) string { return fmt.Sprintf("%s is too large", field) },
+}
+
+// requestField returns the request field of a command field, or the command
+// field when it isn't part of the request, such as the user of the JWT
+func requestField(field string) string {
+	if f, ok := requestFields[field]; ok {
+		return f
+	}
+	return field
+}
+
+// fieldProblem renders a field error of a command
+func fieldProblem(fieldError domain.FieldError) FieldProblem {
+	field := requestField(fieldError.Field)
+	message := fieldError.Code
+	if render, ok := fieldMessages[fieldError.Code]; ok {
+		message = render(field, requestField(fieldError.Ref))
+	}
+	return FieldProblem{Field: field, Code: fieldError.Code, Message: message}
+}
+
+// invalidCommandProblem renders an InvalidCommand domain error
+func invalidCommandProblem(data interface{}) ProblemDetails {
+	problem := ProblemDetails{
+		Type:   string(domain.ErrorInvalidCommand),
+		Title:  "Invalid command",
+		Status: http.StatusBadRequest,
+	}
+	d, _ := data.(map[string]interface{})
+	problem.Command, _ = d["command"].(string)
+	fieldErrors, _ := d["errors"].([]domain.FieldError)
+	for _, fieldError := range fieldErrors {
+		problem.Errors = append(problem.Errors, fieldProblem(fieldError))
+	}
+	problem.Detail = fmt.Sprintf("%d invalid fields", len(problem.Errors))
+	return problem
+}
+
+// respondInvalidBody responds with a problem for a request body that can't
+// be decoded, pointing at the field of a value of the wrong type
+func respondInvalidBody(w http.ResponseWriter, err error) {
+	problem := ProblemDetails{
+		Type:   string(domain.ErrorInvalidCommand),
+		Title:  "Invalid request body",
+		Status: http.StatusBadRequest,
+	}
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		problem.Errors = []FieldProblem{fieldProblem(domain.FieldError{Field: typeError.Field, Code: domain.FieldMalformed})}
+	} else {
+		problem.Errors = []FieldProblem{{Code: domain.FieldMalformed, Message: "body is malformed JSON"}}
+	}
+	problem.Detail = problem.Errors[0].Message
+	respondProblem(w, problem)
+}
+
+// respondProblem responds with an RFC 7807 problem
+func respondProblem(w http.ResponseWriter, problem ProblemDetails) {
+	response, err := json.Marshal(problem)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	w.Write(response)
+}
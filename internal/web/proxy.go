@@ -0,0 +1,102 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// maxProxyRounds bounds the bids the proxy bidding engine places after a
+// change, which settles in one bid as it bids past every other maximum
+const maxProxyRounds = 10
+
+// setMaxBid sets the maximum bid of the requester, which the proxy bidding
+// engine then bids up to on their behalf. Bidders are screened like bidders
+// of their maximum.
+func setMaxBid(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time, screen screenFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+		var req MaxBidRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondInvalidBody(w, err)
+			return
+		}
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		if !screenUser(w, screen, onEvent, user, domain.ScreeningBid, req.Max) {
+			return
+		}
+
+		auctionId := domain.AuctionId(id)
+		cmd := domain.SetMaxBidCommand{
+			Time:      getCurrentTime(),
+			AuctionId: auctionId,
+			Bidder:    user,
+			Max:       req.Max,
+		}
+		before := state.GetRepository()[auctionId].State
+		event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		state.UpdateRepository(newRepo)
+		if err := onEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		runProxyBids(r.Context(), state, commands, onEvent, auctionId, cmd.Time)
+		reportExtension(state, onEvent, auctionId, before, cmd.Time)
+		reportReserve(state, onEvent, auctionId, cmd.Time)
+
+		response := MaxBidResponse{AuctionId: auctionId, Max: req.Max}
+		if bids := state.GetRepository()[auctionId].State.GetBids(); len(bids) > 0 && bids[0].Bidder.ID == user.ID {
+			response.Leading = true
+		}
+		respondJSON(w, http.StatusOK, response)
+	}
+}
+
+// runProxyBids places the bids the proxy bidding engine has due in an
+// auction. Each is dispatched as a command, so the log records every bid the
+// engine placed and why. Failures are logged, the engine bids again on the
+// next change.
+func runProxyBids(ctx context.Context, state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, id domain.AuctionId, now time.Time) {
+	for i := 0; i < maxProxyRounds; i++ {
+		entry, ok := state.GetRepository()[id]
+		if !ok {
+			return
+		}
+		if _, due := domain.NextProxyBid(id, entry.State, now); !due {
+			return
+		}
+
+		cmd := domain.PlaceProxyBidCommand{Time: now, AuctionId: id}
+		event, newRepo, err := commands.Dispatch(ctx, cmd, state.GetRepository())
+		if err != nil {
+			log.Printf("Failed to place a proxy bid on auction %d: %v", id, err)
+			return
+		}
+		state.UpdateRepository(newRepo)
+		if err := onEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			return
+		}
+	}
+}
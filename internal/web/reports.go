@@ -0,0 +1,181 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// createReport files an abuse report about a listing or a user
+func createReport(state *AppState, onCommand func(domain.Command) error, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse request body
+		var req ReportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		// Extract user from JWT
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		// Reported listings must exist
+		if id := req.Target.AuctionId; id != nil {
+			if _, ok := state.GetRepository()[*id]; !ok {
+				respondDomainError(w, domain.NewAuctionNotFoundError(*id))
+				return
+			}
+		}
+
+		cmd := domain.FileReportCommand{
+			Time: getCurrentTime(),
+			Report: domain.Report{
+				Reporter: user.ID,
+				Target:   domain.ReportTarget{AuctionId: req.Target.AuctionId, UserId: req.Target.UserId},
+				Reason:   req.Reason,
+				Text:     req.Text,
+				Evidence: req.Evidence,
+			},
+		}
+		handleReportCommand(w, state, cmd, onCommand, onEvent)
+	}
+}
+
+// getReports returns the moderation queue to support users, optionally
+// filtered by status
+func getReports(state *AppState, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := extractSupportUser(w, r); !ok {
+			return
+		}
+
+		filter, err := parseFilter(r.URL.Query().Get("filter"), reportFilterFields)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid filter: "+err.Error())
+			return
+		}
+
+		status := domain.ReportStatus(r.URL.Query().Get("status"))
+		now := getCurrentTime()
+		responses := []ReportResponse{}
+		for _, report := range state.GetReports() {
+			if status != "" && report.Status != status {
+				continue
+			}
+			response := ReportResponse{Report: report, Overdue: report.IsOverdue(now)}
+			if filter(response) {
+				responses = append(responses, response)
+			}
+		}
+		sort.Slice(responses, func(i, j int) bool {
+			return responses[i].ID < responses[j].ID
+		})
+
+		respondJSON(w, http.StatusOK, responses)
+	}
+}
+
+// changeReportStatus moves a report through the moderation queue
+func changeReportStatus(state *AppState, onCommand func(domain.Command) error, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse report ID from path
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid report ID")
+			return
+		}
+
+		// Parse request body
+		var req ReportStatusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		user, ok := extractSupportUser(w, r)
+		if !ok {
+			return
+		}
+
+		cmd := domain.ChangeReportStatusCommand{
+			Time:     getCurrentTime(),
+			ReportId: domain.ReportId(id),
+			Status:   req.Status,
+			By:       user.ID,
+		}
+		handleReportCommand(w, state, cmd, onCommand, onEvent)
+	}
+}
+
+// handleReportCommand observes a report command, handles it against the
+// moderation queue and responds with the resulting event
+func handleReportCommand(w http.ResponseWriter, state *AppState, cmd domain.Command, onCommand func(domain.Command) error, onEvent func(domain.Event) error) {
+	if err := onCommand(cmd); err != nil {
+		log.Printf("Failed to observe command: %v", err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	var event domain.Event
+	var eventErr error
+	err := state.UpdateReports(func(reports domain.Reports) (domain.Reports, error) {
+		var newReports domain.Reports
+		var err error
+		event, newReports, err = domain.HandleReport(cmd, reports)
+		if err != nil {
+			return nil, err
+		}
+		// The event is observed while holding the queue, so events are
+		// written in the order report IDs are assigned
+		eventErr = onEvent(event)
+		return newReports, nil
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	if eventErr != nil {
+		log.Printf("Failed to observe event: %v", eventErr)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, event)
+}
+
+// extractSupportUser extracts the user from the request and checks it is a
+// support user, responding with an error otherwise
+func extractSupportUser(w http.ResponseWriter, r *http.Request) (domain.User, bool) {
+	user, err := extractUserFromRequest(r)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return domain.User{}, false
+	}
+	if user.Type != "Support" {
+		respondError(w, http.StatusForbidden, "Forbidden")
+		return domain.User{}, false
+	}
+	return user, true
+}
+
+// supportOnly restricts a handler to support users
+func supportOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := extractSupportUser(w, r); !ok {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// reportReserve records a change of the reserve status of an auction, if
+// any, returning whether it recorded one. Failures are logged, the status
+// is reported again by the next sweep.
+func reportReserve(state *AppState, onEvent func(domain.Event) error, id domain.AuctionId, now time.Time) bool {
+	entry, ok := state.GetRepository()[id]
+	if !ok {
+		return false
+	}
+
+	reported := false
+	err := state.UpdateReserves(func(reserves domain.Reserves) (domain.Reserves, error) {
+		event := domain.ReserveEvent(entry.Auction, entry.State, reserves, now)
+		if event == nil {
+			return reserves, nil
+		}
+		// Observed while holding the statuses, so each is recorded once
+		if err := onEvent(event); err != nil {
+			return nil, err
+		}
+		reported = true
+		return domain.ApplyReserveEvents(reserves, []domain.Event{event}), nil
+	})
+	if err != nil {
+		log.Printf("Failed to observe event: %v", err)
+	}
+	return reported
+}
+
+// ReportReserves records the reserve status of each auction that has met
+// its reserve, or closed below it, since the last call, returning the number
+// of statuses it recorded
+func (a *App) ReportReserves() int {
+	now := a.GetCurrentTime()
+	var ids []domain.AuctionId
+	for id, entry := range a.State.GetRepository() {
+		if _, ok := domain.ReservePrice(entry.Auction); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	reported := 0
+	for _, id := range ids {
+		if reportReserve(a.State, a.OnEvent, id, now) {
+			reported++
+		}
+	}
+	return reported
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// getRuleSet returns the latest version of the prohibited item rules, or the
+// version given in the path
+func getRuleSet(state *AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := extractSupportUser(w, r); !ok {
+			return
+		}
+
+		ruleSets := state.GetRuleSets()
+		version := len(ruleSets)
+		if s, ok := mux.Vars(r)["version"]; ok {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "Invalid version")
+				return
+			}
+			version = v
+		}
+		if version < 1 || version > len(ruleSets) {
+			respondError(w, http.StatusNotFound, "Rule set not found")
+			return
+		}
+
+		respondJSON(w, http.StatusOK, ruleSets[version-1])
+	}
+}
+
+// publishRuleSet publishes a new version of the prohibited item rules
+func publishRuleSet(state *AppState, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse request body
+		var req RuleSetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		user, ok := extractSupportUser(w, r)
+		if !ok {
+			return
+		}
+
+		var event domain.RuleSetPublishedEvent
+		var eventErr error
+		err := state.PublishRuleSet(func(ruleSets []domain.RuleSet) (domain.RuleSet, error) {
+			var err error
+			if event, err = domain.PublishRuleSet(ruleSets, req.Rules, user.ID, getCurrentTime()); err != nil {
+				return domain.RuleSet{}, err
+			}
+			if eventErr = onEvent(event); eventErr != nil {
+				return domain.RuleSet{}, eventErr
+			}
+			return event.RuleSet, nil
+		})
+		if eventErr != nil {
+			log.Printf("Failed to observe event: %v", eventErr)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+
+		respondJSON(w, http.StatusOK, event)
+	}
+}
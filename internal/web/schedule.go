@@ -0,0 +1,48 @@
+package web
+
+import (
+	"log"
+	"sort"
+
+	"auction-site-go/internal/domain"
+)
+
+// StartAuctions records the start of each auction that reached its start
+// time since the last call, returning the number of starts it recorded. The
+// started auctions are held for the whole sweep, so each start is recorded
+// once. A failure is logged and ends the sweep, the next one reports the
+// remaining starts.
+func (a *App) StartAuctions() int {
+	now := a.GetCurrentTime()
+	repo := a.State.GetRepository()
+
+	recorded := 0
+	err := a.State.UpdateStartedAuctions(func(started domain.StartedAuctions) (domain.StartedAuctions, error) {
+		var events []domain.Event
+		for id, entry := range repo {
+			if started[id] {
+				continue
+			}
+			if event := domain.StartEvent(entry.Auction, entry.State, started, now); event != nil {
+				events = append(events, event)
+			}
+		}
+		sort.Slice(events, func(i, j int) bool {
+			return events[i].(domain.AuctionStartedEvent).AuctionId < events[j].(domain.AuctionStartedEvent).AuctionId
+		})
+
+		for i, event := range events {
+			if err := a.OnEvent(event); err != nil {
+				log.Printf("Failed to observe event: %v", err)
+				events = events[:i]
+				break
+			}
+		}
+		recorded = len(events)
+		return domain.ApplyStartedEvents(started, events), nil
+	})
+	if err != nil {
+		log.Printf("Failed to record auction starts: %v", err)
+	}
+	return recorded
+}
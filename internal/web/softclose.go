@@ -0,0 +1,35 @@
+package web
+
+import (
+	"log"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// reportExtension records that late bids extended an auction from its state
+// before them, if they did. Failures are logged, the new close still shows
+// in the auction reads.
+func reportExtension(state *AppState, onEvent func(domain.Event) error, id domain.AuctionId, before domain.State, now time.Time) {
+	entry, ok := state.GetRepository()[id]
+	if !ok {
+		return
+	}
+	event := domain.ExtensionEvent(id, before, entry.State, now)
+	if event == nil {
+		return
+	}
+	if err := onEvent(event); err != nil {
+		log.Printf("Failed to observe event: %v", err)
+	}
+}
+
+// extendedUntil returns the close of an auction extended by late bids, nil
+// when it wasn't extended
+func extendedUntil(state domain.State) *time.Time {
+	if domain.Extensions(state) == 0 {
+		return nil
+	}
+	expiry := domain.CurrentExpiry(state)
+	return &expiry
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// getAuctionAsOf serves the auction as it was at the time or event position
+// given by "asOf", rebuilt from the stored events, for dispute resolution and
+// audits. Requests without "asOf" go to next.
+func (a *App) getAuctionAsOf(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		param := r.URL.Query().Get("asOf")
+		if param == "" {
+			next(w, r)
+			return
+		}
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+		asOf, err := parseAsOf(param)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid asOf, expected an RFC 3339 time or an event position")
+			return
+		}
+		if a.ReadEventsSince == nil {
+			respondError(w, http.StatusNotFound, "Event history not available")
+			return
+		}
+
+		// Visibility is decided by the auction as it is now, so that a
+		// listing made private doesn't leak through its past
+		entry, ok := a.State.GetRepository()[domain.AuctionId(id)]
+		if !ok || !entry.Auction.VisibleTo(requestUser(r)) {
+			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+			return
+		}
+
+		events, err := a.ReadEventsSince(0)
+		if err != nil {
+			log.Printf("Failed to read events: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		auction, state, at, ok := domain.AuctionAsOf(events, domain.AuctionId(id), asOf)
+		if !ok {
+			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+			return
+		}
+
+		response := auctionResponse(w, r, auction, state, at)
+		response.AsOf = &at
+		respondJSON(w, http.StatusOK, response)
+	}
+}
+
+// parseAsOf parses an RFC 3339 time, or a positive event position
+func parseAsOf(param string) (domain.AsOf, error) {
+	if position, err := strconv.ParseInt(param, 10, 64); err == nil && position > 0 {
+		return domain.AsOf{Position: position}, nil
+	}
+	at, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return domain.AsOf{}, err
+	}
+	return domain.AsOf{Time: at}, nil
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// submitKeyShare records the key share of a custodian of a closed tender,
+// who is the authenticated user, and reveals the bids once enough shares are
+// submitted. It responds with the last event.
+func submitKeyShare(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse auction ID from path
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+
+		// Parse request body
+		var req KeyShareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		// Extract user from JWT
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		cmd := domain.SubmitKeyShareCommand{
+			Time:      getCurrentTime(),
+			AuctionId: domain.AuctionId(id),
+			Custodian: user.ID,
+			Share:     req.Share,
+		}
+		event, ok := dispatchTenderCommand(w, r, state, commands, onEvent, cmd)
+		if !ok {
+			return
+		}
+
+		tender := state.GetRepository()[domain.AuctionId(id)].Auction.Tender
+		if len(tender.Shares) < tender.Threshold {
+			respondJSON(w, http.StatusOK, event)
+			return
+		}
+
+		reveal := domain.RevealTenderCommand{Time: getCurrentTime(), AuctionId: domain.AuctionId(id)}
+		if event, ok = dispatchTenderCommand(w, r, state, commands, onEvent, reveal); ok {
+			respondJSON(w, http.StatusOK, event)
+		}
+	}
+}
+
+// dispatchTenderCommand handles a command of a tender and observes its
+// event, responding with an error when it fails
+func dispatchTenderCommand(w http.ResponseWriter, r *http.Request, state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, cmd domain.Command) (domain.Event, bool) {
+	event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+	if err != nil {
+		respondDomainError(w, err)
+		return nil, false
+	}
+	state.UpdateRepository(newRepo)
+
+	if err := onEvent(event); err != nil {
+		log.Printf("Failed to observe event: %v", err)
+		respondError(w, http.StatusInternalServerError, "Internal server error")
+		return nil, false
+	}
+	return event, true
+}
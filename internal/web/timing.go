@@ -0,0 +1,114 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// serverTimingKey is the context key of the serverTiming of a request
+type serverTimingKey struct{}
+
+// serverTiming collects the durations of the phases of a request, reported
+// in the Server-Timing header when a client asks for it with ?debug=timing
+// or an X-Debug-Timing header. It lets clients tell our processing time from
+// their network latency.
+type serverTiming struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []timingPhase
+}
+
+type timingPhase struct {
+	name     string
+	duration time.Duration
+}
+
+// header renders the phases and the total as a Server-Timing header value
+func (t *serverTiming) header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	metrics := make([]string, 0, len(t.phases)+1)
+	for _, phase := range t.phases {
+		metrics = append(metrics, formatTimingMetric(phase.name, phase.duration))
+	}
+	metrics = append(metrics, formatTimingMetric("total", time.Since(t.start)))
+	return strings.Join(metrics, ", ")
+}
+
+// formatTimingMetric formats a Server-Timing metric with its duration in milliseconds
+func formatTimingMetric(name string, d time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.3f", name, float64(d.Microseconds())/1000)
+}
+
+// timePhase starts timing a phase of a request and returns the function
+// ending it. It does nothing unless the client asked for timings.
+func timePhase(r *http.Request, name string) func() {
+	return timeContextPhase(r.Context(), name)
+}
+
+// timeContextPhase starts timing a phase of the request of a context
+func timeContextPhase(ctx context.Context, name string) func() {
+	t, ok := ctx.Value(serverTimingKey{}).(*serverTiming)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.phases = append(t.phases, timingPhase{name: name, duration: time.Since(start)})
+	}
+}
+
+// timeCommands times the rest of the command pipeline as a phase
+func timeCommands(name string) domain.CommandMiddleware {
+	return func(next domain.CommandHandler) domain.CommandHandler {
+		return func(ctx context.Context, cmd domain.Command, repo domain.Repository) (domain.Event, domain.Repository, error) {
+			defer timeContextPhase(ctx, name)()
+			return next(ctx, cmd, repo)
+		}
+	}
+}
+
+// timingMiddleware collects the phase durations of requests asking for them
+func timingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("debug") != "timing" && r.Header.Get("X-Debug-Timing") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		t := &serverTiming{start: time.Now()}
+		r = r.WithContext(context.WithValue(r.Context(), serverTimingKey{}, t))
+		next.ServeHTTP(&timingWriter{ResponseWriter: w, timing: t}, r)
+	})
+}
+
+// timingWriter adds the Server-Timing header when the response starts
+type timingWriter struct {
+	http.ResponseWriter
+	timing      *serverTiming
+	wroteHeader bool
+}
+
+func (w *timingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Server-Timing", w.timing.header())
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,148 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"auction-site-go/internal/domain"
+)
+
+// translateListing adds or replaces the translation of a listing, which only
+// its seller may do
+func translateListing(state *AppState, commands *domain.CommandBus, onEvent func(domain.Event) error, getCurrentTime func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse auction ID from path
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid auction ID")
+			return
+		}
+
+		// Parse request body
+		var req TranslationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		// Extract user from JWT
+		user, err := extractUserFromRequest(r)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		entry, ok := state.GetRepository()[domain.AuctionId(id)]
+		if !ok {
+			respondDomainError(w, domain.NewAuctionNotFoundError(domain.AuctionId(id)))
+			return
+		}
+		if entry.Auction.Seller.ID != user.ID {
+			respondError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		// Create command
+		cmd := domain.TranslateListingCommand{
+			Time:      getCurrentTime(),
+			AuctionId: domain.AuctionId(id),
+			Translation: domain.Translation{
+				Language: req.Language,
+				Title:    req.Title,
+				Source:   domain.TranslationSeller,
+			},
+		}
+
+		// Record and handle the command
+		event, newRepo, err := commands.Dispatch(r.Context(), cmd, state.GetRepository())
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+
+		// Update repository
+		state.UpdateRepository(newRepo)
+
+		// Call event handler
+		if err := onEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			respondError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		respondJSON(w, http.StatusOK, event)
+	}
+}
+
+// translateNewListing translates a new listing with the configured provider
+// into the configured languages the seller didn't supply. Failures are only
+// logged, since the listing is published already.
+func (a *App) translateNewListing(auction domain.Auction, onEvent func(domain.Event) error) {
+	if a.Translator == nil {
+		return
+	}
+
+	for _, language := range a.TranslationLanguages {
+		if _, ok := auction.Translations[language]; ok {
+			continue
+		}
+		translation, err := a.Translator.TranslateListing(auction, language)
+		if err != nil {
+			log.Printf("Failed to translate listing %d to %s: %v", auction.ID, language, err)
+			continue
+		}
+		event := domain.ListingTranslatedEvent{Time: a.GetCurrentTime(), AuctionId: auction.ID, Translation: translation}
+		if err := onEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			return
+		}
+		a.State.UpdateRepository(domain.ApplyEvents(a.State.GetRepository(), []domain.Event{event}))
+	}
+}
+
+// acceptedLanguages returns the languages of an Accept-Language header, most
+// preferred first, leaving out the wildcard and those with a zero quality
+func acceptedLanguages(header string) []string {
+	type accepted struct {
+		language string
+		quality  float64
+	}
+
+	var languages []accepted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		language := strings.TrimSpace(fields[0])
+		if language == "" || language == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, accepted{language, quality})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	result := make([]string, len(languages))
+	for i, l := range languages {
+		result[i] = l.language
+	}
+	return result
+}
@@ -11,6 +11,27 @@ import (
 // AppState holds the application state
 type AppState struct {
 	auctions *sync.Map // map[domain.AuctionId]struct{Auction domain.Auction, State domain.State}
+
+	reportsMu sync.Mutex
+	reports   domain.Reports
+
+	ruleSetsMu sync.Mutex
+	ruleSets   []domain.RuleSet
+
+	contactsMu sync.Mutex
+	contacts   domain.ContactThreads
+
+	reservesMu sync.Mutex
+	reserves   domain.Reserves
+
+	descriptionsMu sync.Mutex
+	descriptions   domain.Descriptions
+
+	startedMu sync.Mutex
+	started   domain.StartedAuctions
+
+	archivedMu sync.Mutex
+	archived   domain.ArchivedAuctions
 }
 
 // NewAppState creates a new application state
@@ -24,6 +45,14 @@ func NewAppState(repo domain.Repository) *AppState {
 
 	return &AppState{
 		auctions: auctions,
+		reports:  domain.Reports{},
+		ruleSets: []domain.RuleSet{},
+		contacts: domain.ContactThreads{},
+		reserves: domain.Reserves{},
+
+		descriptions: domain.Descriptions{},
+		started:      domain.StartedAuctions{},
+		archived:     domain.ArchivedAuctions{},
 	}
 }
 
@@ -51,14 +80,226 @@ func (s *AppState) UpdateRepository(repo domain.Repository) {
 	}
 }
 
+// SetReports replaces the moderation queue, such as when restoring it from events
+func (s *AppState) SetReports(reports domain.Reports) {
+	s.reportsMu.Lock()
+	defer s.reportsMu.Unlock()
+
+	s.reports = reports
+}
+
+// GetReports returns the moderation queue
+func (s *AppState) GetReports() domain.Reports {
+	s.reportsMu.Lock()
+	defer s.reportsMu.Unlock()
+
+	return s.reports
+}
+
+// UpdateReports runs an update of the moderation queue, holding it for the
+// whole update so report IDs are assigned in order. The queue is replaced
+// only if the update succeeds.
+func (s *AppState) UpdateReports(update func(domain.Reports) (domain.Reports, error)) error {
+	s.reportsMu.Lock()
+	defer s.reportsMu.Unlock()
+
+	reports, err := update(s.reports)
+	if err != nil {
+		return err
+	}
+	s.reports = reports
+	return nil
+}
+
+// SetContacts replaces the contact threads, such as when restoring them from events
+func (s *AppState) SetContacts(threads domain.ContactThreads) {
+	s.contactsMu.Lock()
+	defer s.contactsMu.Unlock()
+
+	s.contacts = threads
+}
+
+// GetContacts returns the contact threads
+func (s *AppState) GetContacts() domain.ContactThreads {
+	s.contactsMu.Lock()
+	defer s.contactsMu.Unlock()
+
+	return s.contacts
+}
+
+// UpdateContacts runs an update of the contact threads, holding them for the
+// whole update so messages are numbered in order. The threads are replaced
+// only if the update succeeds.
+func (s *AppState) UpdateContacts(update func(domain.ContactThreads) (domain.ContactThreads, error)) error {
+	s.contactsMu.Lock()
+	defer s.contactsMu.Unlock()
+
+	threads, err := update(s.contacts)
+	if err != nil {
+		return err
+	}
+	s.contacts = threads
+	return nil
+}
+
+// SetReserves replaces the reported reserve statuses, such as when restoring
+// them from events
+func (s *AppState) SetReserves(reserves domain.Reserves) {
+	s.reservesMu.Lock()
+	defer s.reservesMu.Unlock()
+
+	s.reserves = reserves
+}
+
+// GetReserves returns the reported reserve statuses
+func (s *AppState) GetReserves() domain.Reserves {
+	s.reservesMu.Lock()
+	defer s.reservesMu.Unlock()
+
+	return s.reserves
+}
+
+// UpdateReserves runs an update of the reported reserve statuses, holding
+// them for the whole update so a status is reported once. The statuses are
+// replaced only if the update succeeds.
+func (s *AppState) UpdateReserves(update func(domain.Reserves) (domain.Reserves, error)) error {
+	s.reservesMu.Lock()
+	defer s.reservesMu.Unlock()
+
+	reserves, err := update(s.reserves)
+	if err != nil {
+		return err
+	}
+	s.reserves = reserves
+	return nil
+}
+
+// SetDescriptions replaces the descriptions of the auctions, such as when
+// restoring them from events
+func (s *AppState) SetDescriptions(descriptions domain.Descriptions) {
+	s.descriptionsMu.Lock()
+	defer s.descriptionsMu.Unlock()
+
+	s.descriptions = descriptions
+}
+
+// GetDescriptions returns the descriptions of the auctions
+func (s *AppState) GetDescriptions() domain.Descriptions {
+	s.descriptionsMu.Lock()
+	defer s.descriptionsMu.Unlock()
+
+	return s.descriptions
+}
+
+// ApplyDescriptions folds events onto the descriptions of the auctions
+func (s *AppState) ApplyDescriptions(events []domain.Event) {
+	s.descriptionsMu.Lock()
+	defer s.descriptionsMu.Unlock()
+
+	s.descriptions = domain.ApplyDescriptionEvents(s.descriptions, events)
+}
+
+// SetStartedAuctions replaces the auctions whose start was reported, such as
+// when restoring them from events
+func (s *AppState) SetStartedAuctions(started domain.StartedAuctions) {
+	s.startedMu.Lock()
+	defer s.startedMu.Unlock()
+
+	s.started = started
+}
+
+// UpdateStartedAuctions runs an update of the started auctions, holding them
+// for the whole update so a start is reported once. The auctions are
+// replaced only if the update succeeds.
+func (s *AppState) UpdateStartedAuctions(update func(domain.StartedAuctions) (domain.StartedAuctions, error)) error {
+	s.startedMu.Lock()
+	defer s.startedMu.Unlock()
+
+	started, err := update(s.started)
+	if err != nil {
+		return err
+	}
+	s.started = started
+	return nil
+}
+
+// SetArchivedAuctions replaces the auctions moved to the archive, such as
+// when restoring them from events
+func (s *AppState) SetArchivedAuctions(archived domain.ArchivedAuctions) {
+	s.archivedMu.Lock()
+	defer s.archivedMu.Unlock()
+
+	s.archived = archived
+}
+
+// GetArchivedAuctions returns the auctions moved to the archive
+func (s *AppState) GetArchivedAuctions() domain.ArchivedAuctions {
+	s.archivedMu.Lock()
+	defer s.archivedMu.Unlock()
+
+	return s.archived
+}
+
+// SetRuleSets replaces the published rule sets, such as when restoring them from events
+func (s *AppState) SetRuleSets(ruleSets []domain.RuleSet) {
+	s.ruleSetsMu.Lock()
+	defer s.ruleSetsMu.Unlock()
+
+	s.ruleSets = ruleSets
+}
+
+// GetRuleSets returns the published rule sets, oldest first
+func (s *AppState) GetRuleSets() []domain.RuleSet {
+	s.ruleSetsMu.Lock()
+	defer s.ruleSetsMu.Unlock()
+
+	return s.ruleSets
+}
+
+// PublishRuleSet runs the publication of a rule set, holding the rule sets
+// for the whole publication so versions are assigned in order
+func (s *AppState) PublishRuleSet(publish func([]domain.RuleSet) (domain.RuleSet, error)) error {
+	s.ruleSetsMu.Lock()
+	defer s.ruleSetsMu.Unlock()
+
+	ruleSet, err := publish(s.ruleSets)
+	if err != nil {
+		return err
+	}
+	ruleSets := make([]domain.RuleSet, len(s.ruleSets), len(s.ruleSets)+1)
+	copy(ruleSets, s.ruleSets)
+	s.ruleSets = append(ruleSets, ruleSet)
+	return nil
+}
+
 // ApiError represents an API error response
 type ApiError struct {
 	Message string `json:"message"`
 }
 
+// ProblemDetails represents an RFC 7807 problem+json response, listing the
+// invalid fields of a request
+type ProblemDetails struct {
+	Type    string         `json:"type"`
+	Title   string         `json:"title"`
+	Status  int            `json:"status"`
+	Detail  string         `json:"detail,omitempty"`
+	Command string         `json:"command,omitempty"`
+	Errors  []FieldProblem `json:"errors,omitempty"`
+}
+
+// FieldProblem represents an invalid field of a request
+type FieldProblem struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 // BidRequest represents a request to place a bid
 type BidRequest struct {
 	Amount int64 `json:"amount"`
+	// Sealed is the encrypted amount of a bid on a tender, instead of Amount
+	Sealed string `json:"sealed,omitempty"`
 }
 
 // AddAuctionRequest represents a request to add an auction
@@ -69,6 +310,26 @@ type AddAuctionRequest struct {
 	EndsAt   time.Time          `json:"endsAt"`
 	Currency domain.Currency    `json:"currency"`
 	Type     domain.AuctionType `json:"typ,omitempty"`
+
+	// Translations maps language tags to translated titles
+	Translations map[string]string `json:"translations,omitempty"`
+
+	// Visibility is public, unlisted or private, public by default
+	Visibility domain.Visibility `json:"visibility,omitempty"`
+	// Invitees may see and bid on a private auction
+	Invitees []domain.UserId `json:"invitees,omitempty"`
+	// Tender encrypts the bids of a sealed bid auction
+	Tender *TenderRequest `json:"tender,omitempty"`
+	// Increments override the default bid increment table of a timed
+	// ascending auction
+	Increments domain.IncrementTable `json:"increments,omitempty"`
+}
+
+// TenderRequest represents the encryption settings of a tender
+type TenderRequest struct {
+	PublicKey  string          `json:"publicKey"`
+	Threshold  int             `json:"threshold"`
+	Custodians []domain.UserId `json:"custodians"`
 }
 
 // UnmarshalJSON implements json.Unmarshaler
@@ -89,22 +350,121 @@ func (r *AddAuctionRequest) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// KeyShareRequest represents a request to submit a custodian's key share
+type KeyShareRequest struct {
+	Share string `json:"share"`
+}
+
+// AmendAuctionRequest represents a request to amend an auction before
+// bidding starts, keeping the fields left out
+type AmendAuctionRequest struct {
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	EndsAt      *time.Time `json:"endsAt,omitempty"`
+}
+
+// CloneAuctionRequest represents a request to clone an auction onto a new schedule
+type CloneAuctionRequest struct {
+	ID       domain.AuctionId `json:"id"`
+	StartsAt time.Time        `json:"startsAt"`
+	EndsAt   time.Time        `json:"endsAt"`
+}
+
+// BulkReviseRequest represents a request to revise the seller's auctions
+// carrying a tag and matching a filter, both optional
+type BulkReviseRequest struct {
+	Tag    string `json:"tag,omitempty"`
+	Filter string `json:"filter,omitempty"`
+	// ExtendBy is a duration such as "24h" to add to the end times
+	ExtendBy string   `json:"extendBy,omitempty"`
+	AddTags  []string `json:"addTags,omitempty"`
+}
+
+// BulkItemResult represents the outcome of a bulk operation on an auction
+type BulkItemResult struct {
+	ID domain.AuctionId `json:"id"`
+	// Status is "revised" or "failed"
+	Status string                 `json:"status"`
+	Error  map[string]interface{} `json:"error,omitempty"`
+}
+
+// BulkReviseResponse represents the per-auction results of a bulk revision
+type BulkReviseResponse struct {
+	Revised int              `json:"revised"`
+	Failed  int              `json:"failed"`
+	Results []BulkItemResult `json:"results"`
+}
+
 // AuctionBidResponse represents a bid in an auction response
 type AuctionBidResponse struct {
 	Amount int64       `json:"amount"`
 	Bidder domain.User `json:"bidder"`
 }
 
+// MaxBidRequest represents a request to set a maximum bid
+type MaxBidRequest struct {
+	Max int64 `json:"max"`
+}
+
+// MaxBidResponse represents the maximum bid of the requester, and whether
+// the proxy bidding engine has them leading
+type MaxBidResponse struct {
+	AuctionId domain.AuctionId `json:"auctionId"`
+	Max       int64            `json:"max"`
+	Leading   bool             `json:"leading"`
+}
+
 // AuctionResponse represents an auction with bids and winner information
 type AuctionResponse struct {
 	ID          domain.AuctionId     `json:"id"`
 	StartsAt    time.Time            `json:"startsAt"`
 	Title       string               `json:"title"`
+	Language    string               `json:"language,omitempty"`
 	Expiry      time.Time            `json:"expiry"`
 	Currency    domain.Currency      `json:"currency"`
+	Visibility  domain.Visibility    `json:"visibility,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
 	Bids        []AuctionBidResponse `json:"bids"`
 	Winner      *domain.UserId       `json:"winner"`
 	WinnerPrice *int64               `json:"winnerPrice"`
+	// CurrentPrice is the price on the clock of a Dutch auction
+	CurrentPrice *int64 `json:"currentPrice,omitempty"`
+	// Reserve tells whether the reserve price is met, met or notMet, and is
+	// empty without a reserve. The reserve price itself is never disclosed.
+	Reserve string `json:"reserve,omitempty"`
+	// BuyNowPrice is the price the auction can be bought at, while it can
+	BuyNowPrice *int64 `json:"buyNowPrice,omitempty"`
+	// MaxBid is the maximum bid of the requester, only shown to them
+	MaxBid *int64 `json:"maxBid,omitempty"`
+	// Increments are the bid increments of a timed ascending auction, and
+	// MinimumBid the least the next bid can be while it's open
+	Increments domain.IncrementTable `json:"increments,omitempty"`
+	MinimumBid *int64                `json:"minimumBid,omitempty"`
+	// ExtendedUntil is the close of an auction late bids extended past its
+	// expiry, and Extensions how many times they did
+	ExtendedUntil *time.Time `json:"extendedUntil,omitempty"`
+	Extensions    int        `json:"extensions,omitempty"`
+	// Allocations are the units of a multi-unit auction by winner, those
+	// its standing bids win while it's open
+	Allocations []domain.Allocation `json:"allocations,omitempty"`
+	// Description is set by amending the auction
+	Description string `json:"description,omitempty"`
+	// CancelledAt is when the auction was cancelled, if it was
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+	// AsOf is the time a temporal query reconstructed the auction at
+	AsOf *time.Time `json:"asOf,omitempty"`
+}
+
+// CommandStatusResponse represents the status of an asynchronous command
+type CommandStatusResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// Code and Result are the status and body of the response the request
+	// would have had, once completed
+	Code   int             `json:"code,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	// Events are the events the command resulted in
+	Events []domain.Event `json:"events,omitempty"`
 }
 
 // AuctionListItem represents an auction in a list
@@ -112,6 +472,176 @@ type AuctionListItem struct {
 	ID       domain.AuctionId `json:"id"`
 	StartsAt time.Time        `json:"startsAt"`
 	Title    string           `json:"title"`
+	Language string           `json:"language,omitempty"`
 	Expiry   time.Time        `json:"expiry"`
 	Currency domain.Currency  `json:"currency"`
+	Tags     []string         `json:"tags,omitempty"`
+	// Status is NotStarted, Open, Ended or Cancelled
+	Status string `json:"status"`
+	// CurrentPrice is the highest bid, null without bids or while sealed bids are undisclosed
+	CurrentPrice *int64 `json:"currentPrice"`
+	BidCount     int    `json:"bidCount"`
+	// Reserve tells whether the reserve price is met, without disclosing it
+	Reserve string `json:"reserve,omitempty"`
+	// BuyNowPrice is the price the auction can be bought at, while it can
+	BuyNowPrice *int64 `json:"buyNowPrice,omitempty"`
+	// ExtendedUntil is the close of an auction late bids extended
+	ExtendedUntil *time.Time `json:"extendedUntil,omitempty"`
+}
+
+// PositionedEventResponse represents an event with its position in the store
+type PositionedEventResponse struct {
+	Position int64 `json:"position"`
+	// ID is the deterministic ID of the event, for consumers to deduplicate
+	ID    string       `json:"id,omitempty"`
+	Event domain.Event `json:"event"`
+}
+
+// EventFeedResponse represents a page of the event feed, Position is where
+// the next page starts
+type EventFeedResponse struct {
+	Events   []PositionedEventResponse `json:"events"`
+	Position int64                     `json:"position"`
+}
+
+// HealthResponse represents the readiness of the application
+type HealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// LiteAuction is the lite representation of an auction, for lightweight and
+// assistive clients: flat, with ISO 8601 UTC times and explicit nulls. It is
+// versioned apart from the main API, under /lite/v1, so fields may only be
+// added to it.
+type LiteAuction struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Language    string `json:"language"`
+	Currency    string `json:"currency"`
+	AuctionType string `json:"auctionType"`
+	StartsAt    string `json:"startsAt"`
+	EndsAt      string `json:"endsAt"`
+	// Status is "NotStarted", "Open", "Ended" or "Cancelled"
+	Status      string `json:"status"`
+	SellerName  string `json:"sellerName"`
+	BidCount    int    `json:"bidCount"`
+	HighestBid  *int64 `json:"highestBid"`
+	WinnerID    string `json:"winnerId"`
+	WinnerPrice *int64 `json:"winnerPrice"`
+}
+
+// TranslationRequest represents the translation of a listing by its seller
+type TranslationRequest struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
+}
+
+// ServerTimeResponse represents the server clock
+type ServerTimeResponse struct {
+	ServerTime       time.Time `json:"serverTime"`
+	ServerTimeMillis int64     `json:"serverTimeMillis"`
+}
+
+// AuctionCountdownResponse represents the time left in an auction
+type AuctionCountdownResponse struct {
+	ID               domain.AuctionId `json:"id"`
+	ServerTime       time.Time        `json:"serverTime"`
+	ServerTimeMillis int64            `json:"serverTimeMillis"`
+	StartsAt         time.Time        `json:"startsAt"`
+	Expiry           time.Time        `json:"expiry"`
+	StartsInMillis   int64            `json:"startsInMillis"`
+	RemainingMillis  int64            `json:"remainingMillis"`
+	HasEnded         bool             `json:"hasEnded"`
+}
+
+// AuctionVelocityResponse represents the bidding activity of an auction
+type AuctionVelocityResponse struct {
+	ID            domain.AuctionId           `json:"id"`
+	Interval      string                     `json:"interval"`
+	Buckets       []domain.BidVelocityBucket `json:"buckets"`
+	TotalBids     int                        `json:"totalBids"`
+	UniqueBidders int                        `json:"uniqueBidders"`
+	Hot           bool                       `json:"hot"`
+}
+
+// ReportTargetRequest identifies the listing or user of a report
+type ReportTargetRequest struct {
+	AuctionId *domain.AuctionId `json:"auctionId,omitempty"`
+	UserId    *domain.UserId    `json:"userId,omitempty"`
+}
+
+// ReportRequest represents a request to report a listing or a user
+type ReportRequest struct {
+	Target   ReportTargetRequest `json:"target"`
+	Reason   domain.ReportReason `json:"reason"`
+	Text     string              `json:"text"`
+	Evidence []string            `json:"evidence"`
+}
+
+// ReportStatusRequest represents a request to move a report through the moderation queue
+type ReportStatusRequest struct {
+	Status domain.ReportStatus `json:"status"`
+}
+
+// ReportResponse represents a report in the moderation queue
+type ReportResponse struct {
+	domain.Report
+	Overdue bool `json:"overdue"`
+}
+
+// ContactMessageRequest represents a message to the other party of a closed auction
+type ContactMessageRequest struct {
+	Text        string                     `json:"text"`
+	Attachments []domain.ContactAttachment `json:"attachments"`
+}
+
+// ContactMessageResponse represents a message of a contact thread, whose
+// sender is only known by their role
+type ContactMessageResponse struct {
+	Seq         int                        `json:"seq"`
+	At          time.Time                  `json:"at"`
+	From        string                     `json:"from"`
+	Text        string                     `json:"text"`
+	Attachments []domain.ContactAttachment `json:"attachments"`
+}
+
+// ContactThreadResponse represents the contact thread of a closed auction as
+// one of its parties sees it
+type ContactThreadResponse struct {
+	AuctionId domain.AuctionId         `json:"auctionId"`
+	Role      string                   `json:"role"`
+	OpenUntil time.Time                `json:"openUntil"`
+	Messages  []ContactMessageResponse `json:"messages"`
+}
+
+// ContactReportRequest represents a request to report a message of a contact thread
+type ContactReportRequest struct {
+	Reason domain.ReportReason `json:"reason"`
+	Text   string              `json:"text"`
+}
+
+// ContactReportResponse represents a report filed about a contact message,
+// without the reported user
+type ContactReportResponse struct {
+	ReportId domain.ReportId     `json:"reportId"`
+	Status   domain.ReportStatus `json:"status"`
+}
+
+// RuleSetRequest represents a request to publish a new version of the prohibited item rules
+type RuleSetRequest struct {
+	Rules []domain.ProhibitedItemRule `json:"rules"`
+}
+
+// ForgetUserResponse represents the erasure of a user's personal data
+type ForgetUserResponse struct {
+	UserId   domain.UserId `json:"userId"`
+	Auctions int           `json:"auctions"`
+}
+
+// ActivityResponse represents a page of the activity of the requesting user,
+// Next is the cursor of the following page, absent on the last one
+type ActivityResponse struct {
+	Activities []domain.Activity `json:"activities"`
+	Next       int64             `json:"next,omitempty"`
 }
@@ -0,0 +1,17 @@
+package web
+
+import (
+	"net/http"
+
+	"auction-site-go/internal/domain"
+)
+
+// requestUser returns the user of a request, nil when it's anonymous or its
+// token can't be decoded, for endpoints open to anonymous users
+func requestUser(r *http.Request) *domain.User {
+	user, err := extractUserFromRequest(r)
+	if err != nil {
+		return nil
+	}
+	return &user
+}
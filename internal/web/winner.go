@@ -0,0 +1,40 @@
+package web
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"auction-site-go/internal/domain"
+)
+
+// DetermineWinners records the result of each sealed bid auction that has
+// closed since the last call, once its bids are revealed, and of each
+// multi-unit auction, returning the number of auctions it determined
+func (a *App) DetermineWinners(ctx context.Context) int {
+	now := a.GetCurrentTime()
+	var ids []domain.AuctionId
+	for id, entry := range a.State.GetRepository() {
+		if domain.AwaitingWinner(entry.Auction, entry.State, now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	determined := 0
+	for _, id := range ids {
+		cmd := domain.DetermineWinnerCommand{Time: now, AuctionId: id}
+		event, newRepo, err := a.Commands.Dispatch(ctx, cmd, a.State.GetRepository())
+		if err != nil {
+			log.Printf("Failed to determine the winner of auction %d: %v", id, err)
+			continue
+		}
+		a.State.UpdateRepository(newRepo)
+		if err := a.OnEvent(event); err != nil {
+			log.Printf("Failed to observe event: %v", err)
+			continue
+		}
+		determined++
+	}
+	return determined
+}
@@ -0,0 +1,159 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestActivityFeed(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: at,
+		Title:    "bike",
+		Expiry:   at.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()),
+		Currency: domain.VAC,
+	}
+	bid := func(bidder domain.UserId, minutes time.Duration, amount int64) domain.Event {
+		bidAt := at.Add(minutes * time.Minute)
+		return domain.BidAcceptedEvent{Time: bidAt, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller(bidder, "Buyer"), At: bidAt, Amount: amount}}
+	}
+
+	feed := domain.NewActivityFeed()
+	for _, event := range []domain.Event{
+		domain.AuctionAddedEvent{Time: at, Auction: auction},
+		bid("a2", 1, 10),
+		bid("a3", 2, 20),
+		bid("a3", 3, 30),
+	} {
+		feed.Observe(event)
+	}
+
+	kinds := func(activities []domain.Activity) []string {
+		var kinds []string
+		for _, activity := range activities {
+			kinds = append(kinds, activity.Kind)
+		}
+		return kinds
+	}
+	assertKinds := func(t *testing.T, activities []domain.Activity, expected ...string) {
+		actual := kinds(activities)
+		if len(actual) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, actual)
+		}
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Fatalf("Expected %v, got %v", expected, actual)
+			}
+		}
+	}
+
+	t.Run("Ongoing", func(t *testing.T) {
+		now := at.Add(10 * time.Minute)
+		assertKinds(t, feed.Page("a1", now, 0, 10), domain.ActivityListed)
+		// Outbidding yourself isn't being outbid
+		assertKinds(t, feed.Page("a2", now, 0, 10), domain.ActivityOutbid, domain.ActivityBidPlaced)
+		assertKinds(t, feed.Page("a3", now, 0, 10), domain.ActivityBidPlaced, domain.ActivityBidPlaced)
+	})
+
+	t.Run("Settled", func(t *testing.T) {
+		now := at.Add(2 * time.Hour)
+		sold := feed.Page("a1", now, 0, 10)
+		assertKinds(t, sold, domain.ActivitySold, domain.ActivityListed)
+		if sold[0].Amount != 30 || !sold[0].At.Equal(at.Add(time.Hour)) {
+			t.Errorf("Expected sold for 30 at the expiry, got %+v", sold[0])
+		}
+		assertKinds(t, feed.Page("a3", now, 0, 10), domain.ActivityWon, domain.ActivityBidPlaced, domain.ActivityBidPlaced)
+
+		// Settled only once
+		assertKinds(t, feed.Page("a3", now.Add(time.Hour), 0, 10), domain.ActivityWon, domain.ActivityBidPlaced, domain.ActivityBidPlaced)
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		now := at.Add(2 * time.Hour)
+		first := feed.Page("a3", now, 0, 2)
+		assertKinds(t, first, domain.ActivityWon, domain.ActivityBidPlaced)
+		rest := feed.Page("a3", now, first[1].Seq, 2)
+		assertKinds(t, rest, domain.ActivityBidPlaced)
+		if rest[0].Amount != 20 {
+			t.Errorf("Expected the first bid last, got %+v", rest[0])
+		}
+	})
+}
+
+func TestActivityFeedSettlements(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	bid := func(id domain.AuctionId, bidder domain.UserId, minutes time.Duration, amount int64) domain.Event {
+		bidAt := at.Add(minutes * time.Minute)
+		return domain.BidAcceptedEvent{Time: bidAt, Bid: domain.Bid{ForAuction: id, Bidder: domain.NewBuyerOrSeller(bidder, "Buyer"), At: bidAt, Amount: amount}}
+	}
+	closed := at.Add(2 * time.Hour)
+
+	for _, test := range []struct {
+		settlement domain.MultiUnitSettlement
+		prices     map[domain.UserId]int64
+		sold       int64
+	}{
+		{domain.UniformPrice, map[domain.UserId]int64{"a2": 20, "a3": 20}, 40},
+		{domain.PayAsBid, map[domain.UserId]int64{"a2": 30, "a3": 20}, 50},
+	} {
+		t.Run("MultiUnit"+string(test.settlement), func(t *testing.T) {
+			auction := domain.Auction{
+				ID:       1,
+				StartsAt: at,
+				Title:    "chairs",
+				Expiry:   at.Add(time.Hour),
+				Seller:   seller,
+				Type:     domain.NewMultiUnitType(domain.MultiUnitOptions{Units: 2, Settlement: test.settlement}),
+				Currency: domain.VAC,
+			}
+			feed := domain.NewActivityFeed()
+			for _, event := range []domain.Event{
+				domain.AuctionAddedEvent{Time: at, Auction: auction},
+				bid(1, "a2", 1, 30),
+				bid(1, "a3", 2, 20),
+				bid(1, "a4", 3, 10),
+			} {
+				feed.Observe(event)
+			}
+
+			for bidder, price := range test.prices {
+				won := feed.Page(bidder, closed, 0, 10)[0]
+				if won.Kind != domain.ActivityWon || won.Amount != price || won.Units != 1 {
+					t.Errorf("Expected %s to win a unit at %d, got %+v", bidder, price, won)
+				}
+			}
+			if lost := feed.Page("a4", closed, 0, 10)[0]; lost.Kind != domain.ActivityBidPlaced {
+				t.Errorf("Expected a4 to win nothing, got %+v", lost)
+			}
+			if sold := feed.Page("a1", closed, 0, 10)[0]; sold.Kind != domain.ActivitySold || sold.Amount != test.sold || sold.Units != 2 {
+				t.Errorf("Expected 2 units sold for %d, got %+v", test.sold, sold)
+			}
+		})
+	}
+
+	t.Run("DutchPaysTheCurrentPrice", func(t *testing.T) {
+		auction := domain.Auction{
+			ID:       2,
+			StartsAt: at,
+			Title:    "tulips",
+			Expiry:   at.Add(time.Hour),
+			Seller:   seller,
+			Type:     domain.NewDutchType(domain.DutchOptions{StartPrice: 100, Decrement: 15, Interval: 10 * time.Minute, Floor: 50}),
+			Currency: domain.VAC,
+		}
+		feed := domain.NewActivityFeed()
+		feed.Observe(domain.AuctionAddedEvent{Time: at, Auction: auction})
+		feed.Observe(bid(2, "a2", 15, 100))
+
+		activities := feed.Page("a2", closed, 0, 10)
+		if len(activities) != 2 || activities[0].Amount != 85 || activities[1].Amount != 85 {
+			t.Errorf("Expected the bid and the win at the price of 85, got %+v", activities)
+		}
+	})
+}
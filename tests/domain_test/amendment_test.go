@@ -0,0 +1,117 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestAmendAuction(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	auction := domain.NewAuction(1, start, "painting", start.Add(time.Hour), seller,
+		domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	bid := domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: start.Add(time.Minute), Amount: 10}
+	open := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}})
+	at := start.Add(2 * time.Minute)
+	title, description := "landscape painting", "Oil on canvas"
+	expiry := start.Add(3 * time.Hour)
+
+	t.Run("AmendsBeforeTheFirstBid", func(t *testing.T) {
+		command := domain.AmendAuctionCommand{Time: at, AuctionId: 1, Seller: seller, Title: &title, Description: &description, Expiry: &expiry}
+		event, repo, err := domain.Handle(command, open)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		amended := repo[1].Auction
+		if amended.Title != title || !amended.Expiry.Equal(expiry) {
+			t.Errorf("expected the title and end time amended, got %+v", amended)
+		}
+		if !domain.CurrentExpiry(repo[1].State.Increment(at)).Equal(expiry) {
+			t.Errorf("expected the state to close at the new end time")
+		}
+
+		descriptions := domain.EventsToDescriptions([]domain.Event{event})
+		if descriptions[1] != description {
+			t.Errorf("expected the description recorded, got %v", descriptions)
+		}
+		empty := ""
+		cleared := domain.ApplyDescriptionEvents(descriptions, []domain.Event{domain.AuctionAmendedEvent{AuctionId: 1, Description: &empty}})
+		if _, ok := cleared[1]; ok || descriptions[1] != description {
+			t.Errorf("expected an empty description to clear a copy, got %v", cleared)
+		}
+	})
+
+	t.Run("KeepsMaxBids", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates([]domain.Event{
+			domain.AuctionAddedEvent{Time: start, Auction: auction},
+			domain.MaxBidSetEvent{Time: bid.At, AuctionId: 1, Bidder: bid.Bidder, Max: 50},
+		})
+		command := domain.AmendAuctionCommand{Time: at, AuctionId: 1, Seller: seller, Expiry: &expiry}
+		_, repo, err := domain.Handle(command, repo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if max, ok := domain.MaxBid(repo[1].State, "a2", at); !ok || max != 50 {
+			t.Errorf("expected the maximum bid kept, got %d", max)
+		}
+		if !domain.CurrentExpiry(repo[1].State.Increment(at)).Equal(expiry) {
+			t.Errorf("expected the state to close at the new end time")
+		}
+	})
+
+	t.Run("NewTitleDropsTranslations", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates([]domain.Event{
+			domain.AuctionAddedEvent{Time: start, Auction: auction},
+			domain.ListingTranslatedEvent{Time: start, AuctionId: 1, Translation: domain.Translation{Language: "sv", Title: "tavla", Source: domain.TranslationSeller}},
+		})
+		command := domain.AmendAuctionCommand{Time: at, AuctionId: 1, Seller: seller, Title: &title}
+		_, repo, err := domain.Handle(command, repo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if translated, _ := repo[1].Auction.LocalizedTitle([]string{"sv"}); translated != title {
+			t.Errorf("expected the stale translation dropped, got %q", translated)
+		}
+	})
+
+	t.Run("RejectedOnceBiddingHasBegun", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates([]domain.Event{
+			domain.AuctionAddedEvent{Time: start, Auction: auction},
+			domain.BidAcceptedEvent{Time: bid.At, Bid: bid},
+		})
+		command := domain.AmendAuctionCommand{Time: at, AuctionId: 1, Seller: seller, Title: &title}
+		if _, _, err := domain.Handle(command, repo); !isErrorType(err, domain.ErrorAuctionHasBids) {
+			t.Errorf("expected AuctionHasBids, got %v", err)
+		}
+	})
+
+	t.Run("OnlyBySeller", func(t *testing.T) {
+		command := domain.AmendAuctionCommand{Time: at, AuctionId: 1, Seller: domain.NewBuyerOrSeller("a2", "Buyer"), Title: &title}
+		if _, _, err := domain.Handle(command, open); !isErrorType(err, domain.ErrorCannotAmendAuction) {
+			t.Errorf("expected CannotAmendAuction, got %v", err)
+		}
+	})
+
+	t.Run("EndTimeInTheFuture", func(t *testing.T) {
+		past := at.Add(-time.Second)
+		command := domain.AmendAuctionCommand{Time: at, AuctionId: 1, Seller: seller, Expiry: &past}
+		if _, _, err := domain.Handle(command, open); !isErrorType(err, domain.ErrorInvalidRevision) {
+			t.Errorf("expected InvalidRevision, got %v", err)
+		}
+	})
+
+	t.Run("InvalidFields", func(t *testing.T) {
+		blank, long := " ", string(make([]rune, domain.MaxDescriptionLength+1))
+		for name, command := range map[string]domain.AmendAuctionCommand{
+			"Nothing":         {Time: at, AuctionId: 1, Seller: seller},
+			"BlankTitle":      {Time: at, AuctionId: 1, Seller: seller, Title: &blank},
+			"LongDescription": {Time: at, AuctionId: 1, Seller: seller, Description: &long},
+		} {
+			if err := domain.ValidateCommand(command); !isErrorType(err, domain.ErrorInvalidCommand) {
+				t.Errorf("%s: expected InvalidCommand, got %v", name, err)
+			}
+		}
+	})
+}
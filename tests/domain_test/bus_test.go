@@ -0,0 +1,126 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestCommandBus(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	addAuction := domain.AddAuctionCommand{
+		Time:    startsAt,
+		Auction: domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC),
+	}
+
+	// trace is a middleware appending its name to a log on the way in
+	trace := func(log *[]string, name string) domain.CommandMiddleware {
+		return func(next domain.CommandHandler) domain.CommandHandler {
+			return func(ctx context.Context, cmd domain.Command, repo domain.Repository) (domain.Event, domain.Repository, error) {
+				*log = append(*log, name)
+				return next(ctx, cmd, repo)
+			}
+		}
+	}
+
+	t.Run("HandlesCommands", func(t *testing.T) {
+		bus := domain.NewCommandBus()
+		event, repo, err := bus.Dispatch(context.Background(), addAuction, domain.Repository{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, ok := event.(domain.AuctionAddedEvent); !ok {
+			t.Errorf("Expected an AuctionAdded event, got %#v", event)
+		}
+		if _, ok := repo[1]; !ok {
+			t.Errorf("Expected the auction to be added")
+		}
+	})
+
+	t.Run("MiddlewareOrder", func(t *testing.T) {
+		var log []string
+		bus := domain.NewCommandBus(trace(&log, "first"), trace(&log, "second"))
+		bus.Use(trace(&log, "third"))
+		if _, _, err := bus.Dispatch(context.Background(), addAuction, domain.Repository{}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.Join(log, ",") != "first,second,third" {
+			t.Errorf("Expected the middleware in order, got %v", log)
+		}
+	})
+
+	t.Run("RecordsRejectedCommands", func(t *testing.T) {
+		var recorded []domain.Command
+		bus := domain.NewCommandBus(domain.RecordCommands(func(ctx context.Context, cmd domain.Command) error {
+			recorded = append(recorded, cmd)
+			return nil
+		}))
+		bid := domain.PlaceBidCommand{Time: startsAt, Bid: domain.Bid{ForAuction: 2, Bidder: seller, At: startsAt, Amount: 10}}
+		_, _, err := bus.Dispatch(context.Background(), bid, domain.Repository{})
+		if err == nil || err.Error() != string(domain.ErrorAuctionNotFound) {
+			t.Errorf("Expected auction not found, got %v", err)
+		}
+		if len(recorded) != 1 {
+			t.Errorf("Expected the rejected command to be recorded, got %d", len(recorded))
+		}
+	})
+
+	t.Run("FailedRecordingStopsHandling", func(t *testing.T) {
+		failure := errors.New("disk full")
+		var log []string
+		bus := domain.NewCommandBus(
+			domain.RecordCommands(func(ctx context.Context, cmd domain.Command) error { return failure }),
+			trace(&log, "after"),
+		)
+		if _, _, err := bus.Dispatch(context.Background(), addAuction, domain.Repository{}); err != failure {
+			t.Errorf("Expected the recording error, got %v", err)
+		}
+		if len(log) != 0 {
+			t.Errorf("Expected the command not to be handled")
+		}
+	})
+
+	t.Run("Authorization", func(t *testing.T) {
+		forbidden := errors.New("forbidden")
+		bus := domain.NewCommandBus(domain.AuthorizeCommands(func(ctx context.Context, cmd domain.Command, repo domain.Repository) error {
+			if _, ok := cmd.(domain.PlaceBidCommand); ok {
+				return forbidden
+			}
+			return nil
+		}))
+		_, repo, err := bus.Dispatch(context.Background(), addAuction, domain.Repository{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		bid := domain.PlaceBidCommand{Time: startsAt, Bid: domain.Bid{ForAuction: 1, Bidder: seller, At: startsAt, Amount: 10}}
+		if _, _, err := bus.Dispatch(context.Background(), bid, repo); err != forbidden {
+			t.Errorf("Expected the command to be forbidden, got %v", err)
+		}
+	})
+
+	t.Run("Logging", func(t *testing.T) {
+		var lines []string
+		bus := domain.NewCommandBus(domain.LogCommands(func(format string, args ...interface{}) {
+			lines = append(lines, fmt.Sprintf(format, args...))
+		}))
+		bus.Dispatch(context.Background(), addAuction, domain.Repository{})
+		if len(lines) != 1 || !strings.HasPrefix(lines[0], "command AddAuctionCommand on auction 1 took ") || !strings.HasSuffix(lines[0], ": ok") {
+			t.Errorf("Expected a log line for the command, got %v", lines)
+		}
+	})
+
+	t.Run("RejectsArchivedAuctions", func(t *testing.T) {
+		archived := func() domain.ArchivedAuctions { return domain.ArchivedAuctions{1: true} }
+		bus := domain.NewCommandBus(domain.RejectArchivedAuctions(archived))
+		_, _, err := bus.Dispatch(context.Background(), addAuction, domain.Repository{})
+		if !isErrorType(err, domain.ErrorAuctionAlreadyExists) {
+			t.Errorf("Expected AuctionAlreadyExists, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package domain_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestBuyNow(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	options := domain.TimedAscendingOptions{ReservePrice: 50, BuyNowPrice: 100, BuyNowThreshold: 40}
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: start,
+		Title:    "painting",
+		Expiry:   start.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewTimedAscendingType(options),
+		Currency: domain.VAC,
+	}
+	bid := func(amount int64) domain.Event {
+		at := start.Add(time.Minute)
+		return domain.BidAcceptedEvent{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: at, Amount: amount}}
+	}
+	buyer := domain.NewBuyerOrSeller("a3", "Other")
+	open := start.Add(2 * time.Minute)
+
+	t.Run("OptionsRoundTrip", func(t *testing.T) {
+		if s := options.String(); s != "English|50|0|0|100|40" {
+			t.Errorf("expected the buy now price in the options, got %s", s)
+		}
+		parsed, err := domain.ParseTimedAscendingOptions(options.String())
+		if err != nil || !reflect.DeepEqual(*parsed, options) {
+			t.Errorf("expected %+v, got %+v, %v", options, parsed, err)
+		}
+	})
+
+	t.Run("EndsTheAuctionWithTheBuyerAsWinner", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}, bid(40)})
+		event, newRepo, err := domain.Handle(domain.BuyNowCommand{Time: open, AuctionId: 1, Buyer: buyer}, repo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if bought := event.(domain.BoughtNowEvent); bought.Price != 100 || bought.Buyer.ID != "a3" {
+			t.Errorf("expected a3 to buy at 100, got %+v", bought)
+		}
+
+		state := newRepo[1].State
+		if !state.HasEnded() {
+			t.Error("expected the auction ended")
+		}
+		amount, winner, ok := state.TryGetAmountAndWinner()
+		if !ok || winner != "a3" || amount != 100 {
+			t.Errorf("expected a3 to win at 100, got %v %v %v", amount, winner, ok)
+		}
+		if _, _, err := domain.Handle(domain.BuyNowCommand{Time: open, AuctionId: 1, Buyer: buyer}, newRepo); !isErrorType(err, domain.ErrorAuctionHasEnded) {
+			t.Errorf("expected AuctionHasEnded, got %v", err)
+		}
+
+		// Restoring from a snapshot keeps the auction ended
+		snapshots, err := domain.SnapshotRepository(newRepo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		restored, err := domain.RestoreRepository(snapshots)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, winner, ok := restored[1].State.TryGetAmountAndWinner(); !ok || winner != "a3" {
+			t.Errorf("expected a3 to stay the winner, got %v %v", winner, ok)
+		}
+	})
+
+	t.Run("WithdrawnOnceABidExceedsTheThreshold", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}, bid(41)})
+		if _, ok := domain.BuyNowPrice(repo[1].State, open); ok {
+			t.Error("expected no buy now price")
+		}
+		if _, _, err := domain.Handle(domain.BuyNowCommand{Time: open, AuctionId: 1, Buyer: buyer}, repo); !isErrorType(err, domain.ErrorBuyNowNotAvailable) {
+			t.Errorf("expected BuyNowNotAvailable, got %v", err)
+		}
+	})
+
+	t.Run("NotBeforeTheStart", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}})
+		if _, _, err := domain.Handle(domain.BuyNowCommand{Time: start, AuctionId: 1, Buyer: buyer}, repo); !isErrorType(err, domain.ErrorAuctionHasNotStarted) {
+			t.Errorf("expected AuctionHasNotStarted, got %v", err)
+		}
+	})
+
+	t.Run("NotBySeller", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}})
+		if _, _, err := domain.Handle(domain.BuyNowCommand{Time: open, AuctionId: 1, Buyer: auction.Seller}, repo); !isErrorType(err, domain.ErrorSellerCannotPlaceBids) {
+			t.Errorf("expected SellerCannotPlaceBids, got %v", err)
+		}
+	})
+
+	t.Run("PriceBelowReserveIsInvalid", func(t *testing.T) {
+		invalid := auction
+		invalid.Type = domain.NewTimedAscendingType(domain.TimedAscendingOptions{ReservePrice: 50, BuyNowPrice: 40})
+		if err := domain.ValidateCommand(domain.AddAuctionCommand{Time: start, Auction: invalid}); !isErrorType(err, domain.ErrorInvalidCommand) {
+			t.Errorf("expected InvalidCommand, got %v", err)
+		}
+	})
+
+	t.Run("PriceAtReserveIsInvalid", func(t *testing.T) {
+		invalid := auction
+		invalid.Type = domain.NewTimedAscendingType(domain.TimedAscendingOptions{ReservePrice: 40, BuyNowPrice: 40})
+		if err := domain.ValidateCommand(domain.AddAuctionCommand{Time: start, Auction: invalid}); !isErrorType(err, domain.ErrorInvalidCommand) {
+			t.Errorf("expected InvalidCommand, got %v", err)
+		}
+	})
+}
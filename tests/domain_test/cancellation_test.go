@@ -0,0 +1,95 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestCancelAuction(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	support := domain.NewSupport("s1")
+	auction := domain.NewAuction(1, start, "painting", start.Add(time.Hour), seller,
+		domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	bid := domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: start.Add(time.Minute), Amount: 10}
+	open := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}})
+	withBid := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: start, Auction: auction},
+		domain.BidAcceptedEvent{Time: bid.At, Bid: bid},
+	})
+	at := start.Add(2 * time.Minute)
+	cancel := func(by domain.User, override bool) domain.CancelAuctionCommand {
+		return domain.CancelAuctionCommand{Time: at, AuctionId: 1, By: by, Override: override}
+	}
+
+	t.Run("SellerCancelsBeforeTheFirstBid", func(t *testing.T) {
+		event, repo, err := domain.Handle(cancel(seller, false), open)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cancelled, ok := event.(domain.AuctionCancelledEvent); !ok || cancelled.By != "a1" || cancelled.Bids != 0 {
+			t.Errorf("expected a cancellation by a1, got %+v", event)
+		}
+
+		state := repo[1].State
+		if cancelledAt, ok := domain.CancelledAt(state); !ok || !cancelledAt.Equal(at) || !state.HasEnded() {
+			t.Errorf("expected the auction cancelled at %v", at)
+		}
+		if _, _, err := domain.Handle(domain.PlaceBidCommand{Time: at, Bid: bid}, repo); !isErrorType(err, domain.ErrorAuctionCancelled) {
+			t.Errorf("expected bids refused, got %v", err)
+		}
+		if _, _, err := domain.Handle(cancel(seller, false), repo); !isErrorType(err, domain.ErrorAuctionCancelled) {
+			t.Errorf("expected a second cancellation refused, got %v", err)
+		}
+	})
+
+	t.Run("SellerCannotCancelWithBids", func(t *testing.T) {
+		if _, _, err := domain.Handle(cancel(seller, false), withBid); !isErrorType(err, domain.ErrorAuctionHasBids) {
+			t.Errorf("expected AuctionHasBids, got %v", err)
+		}
+		if _, _, err := domain.Handle(cancel(seller, true), withBid); !isErrorType(err, domain.ErrorCannotCancelAuction) {
+			t.Errorf("expected sellers unable to override, got %v", err)
+		}
+	})
+
+	t.Run("SupportOverridesBids", func(t *testing.T) {
+		if _, _, err := domain.Handle(cancel(support, false), withBid); !isErrorType(err, domain.ErrorAuctionHasBids) {
+			t.Errorf("expected an override required, got %v", err)
+		}
+		event, repo, err := domain.Handle(cancel(support, true), withBid)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cancelled := event.(domain.AuctionCancelledEvent); !cancelled.Override || cancelled.Bids != 1 {
+			t.Errorf("expected an override of 1 bid, got %+v", cancelled)
+		}
+		if _, _, ok := repo[1].State.TryGetAmountAndWinner(); ok || len(repo[1].State.GetBids()) != 1 {
+			t.Error("expected the bids kept without a winner")
+		}
+
+		snapshot, err := domain.SnapshotState(repo[1].State)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		restored, err := domain.RestoreState(snapshot)
+		if _, ok := domain.CancelledAt(restored); err != nil || !ok {
+			t.Errorf("expected the cancellation restored, got %v", err)
+		}
+	})
+
+	t.Run("OthersCannotCancel", func(t *testing.T) {
+		if _, _, err := domain.Handle(cancel(domain.NewBuyerOrSeller("a2", "Buyer"), false), open); !isErrorType(err, domain.ErrorCannotCancelAuction) {
+			t.Errorf("expected CannotCancelAuction, got %v", err)
+		}
+	})
+
+	t.Run("NotAfterTheClose", func(t *testing.T) {
+		command := cancel(seller, false)
+		command.Time = start.Add(2 * time.Hour)
+		if _, _, err := domain.Handle(command, open); !isErrorType(err, domain.ErrorAuctionHasEnded) {
+			t.Errorf("expected AuctionHasEnded, got %v", err)
+		}
+	})
+}
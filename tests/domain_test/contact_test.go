@@ -0,0 +1,90 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestMaskContactDetails(t *testing.T) {
+	for text, expected := range map[string]string{
+		"mail me at jane.doe+bike@example.com": "mail me at " + domain.MaskedContactDetails,
+		"call +46 70-123 45 67 after six":      "call " + domain.MaskedContactDetails + " after six",
+		"pick it up on the 12th at 18:00":      "pick it up on the 12th at 18:00",
+	} {
+		if masked := domain.MaskContactDetails(text); masked != expected {
+			t.Errorf("expected %q, got %q", expected, masked)
+		}
+	}
+}
+
+func TestHandleContact(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: at,
+		Title:    "bike",
+		Expiry:   at.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()),
+		Currency: domain.VAC,
+	}
+	bidAt := at.Add(time.Minute)
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: at, Auction: auction},
+		domain.BidAcceptedEvent{Time: bidAt, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: bidAt, Amount: 10}},
+	})
+	closed := auction.Expiry.Add(time.Minute)
+	send := func(sender domain.UserId, now time.Time, text string) domain.SendContactMessageCommand {
+		return domain.SendContactMessageCommand{Time: now, AuctionId: 1, Sender: sender, Text: text}
+	}
+
+	t.Run("RelaysMaskedMessagesBetweenParties", func(t *testing.T) {
+		threads := domain.ContactThreads{}
+		var events []domain.Event
+		for _, cmd := range []domain.SendContactMessageCommand{
+			send("a2", closed, "I can pay today, a2@example.com"),
+			send("a1", closed.Add(time.Minute), "Great"),
+		} {
+			event, newThreads, err := domain.HandleContact(cmd, threads, repo)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			threads = newThreads
+			events = append(events, event)
+		}
+
+		messages := threads[1].Messages
+		if len(messages) != 2 || messages[0].Role != domain.ContactWinner || messages[1].Role != domain.ContactSeller || messages[1].Seq != 2 {
+			t.Fatalf("expected a message of the winner and one of the seller, got %+v", messages)
+		}
+		if strings.Contains(messages[0].Text, "@") {
+			t.Errorf("expected the email address masked, got %q", messages[0].Text)
+		}
+		if rebuilt := domain.EventsToContactThreads(events); len(rebuilt[1].Messages) != 2 {
+			t.Errorf("expected the thread rebuilt from its events, got %+v", rebuilt[1])
+		}
+	})
+
+	t.Run("RejectsOthersAndClosedThreads", func(t *testing.T) {
+		for name, c := range map[string]struct {
+			cmd      domain.SendContactMessageCommand
+			expected domain.ErrorType
+		}{
+			"Outsider":        {send("a3", closed, "hi"), domain.ErrorNotAContactParty},
+			"BeforeTheClose":  {send("a2", bidAt.Add(time.Minute), "hi"), domain.ErrorContactThreadClosed},
+			"AfterRetention":  {send("a2", auction.Expiry.Add(domain.ContactRetention+time.Minute), "hi"), domain.ErrorContactThreadClosed},
+			"EmptyMessage":    {send("a2", closed, ""), domain.ErrorInvalidCommand},
+			"TooManyAttached": {domain.SendContactMessageCommand{Time: closed, AuctionId: 1, Sender: "a2", Attachments: make([]domain.ContactAttachment, domain.MaxContactAttachments+1)}, domain.ErrorInvalidCommand},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, _, err := domain.HandleContact(c.cmd, domain.ContactThreads{}, repo)
+				if !isErrorType(err, c.expected) {
+					t.Errorf("expected %v, got %v", c.expected, err)
+				}
+			})
+		}
+	})
+}
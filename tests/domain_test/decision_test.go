@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestLogDecisions(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	buyer := domain.NewBuyerOrSeller("a2", "Buyer")
+	auction := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: auction},
+		domain.BidAcceptedEvent{Time: startsAt.Add(time.Minute), Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: startsAt.Add(time.Minute), Amount: 20}},
+	})
+	ctx := domain.ContextWithMetadata(context.Background(), domain.EventMetadata{CorrelationId: "request-1"})
+
+	var lines []string
+	logf := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	decision := func() domain.Decision {
+		if len(lines) != 1 || !strings.HasPrefix(lines[0], "decision ") {
+			t.Fatalf("Expected a decision line, got %v", lines)
+		}
+		var d domain.Decision
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[0], "decision ")), &d); err != nil {
+			t.Fatalf("Expected JSON, got %v", err)
+		}
+		lines = nil
+		return d
+	}
+
+	bus := domain.NewCommandBus(domain.LogDecisions(logf, 1, func() float64 { return 0.5 }))
+	at := startsAt.Add(2 * time.Minute)
+
+	t.Run("Rejected", func(t *testing.T) {
+		low := domain.PlaceBidCommand{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: at, Amount: 10}}
+		if _, _, err := bus.Dispatch(ctx, low, repo); err == nil {
+			t.Fatalf("Expected the bid to be rejected")
+		}
+		d := decision()
+		if d.Outcome != domain.DecisionRejected || d.Rejection == nil || d.Rejection.Type != domain.ErrorMustPlaceBidOverHighest {
+			t.Errorf("Expected the rejection reason, got %+v", d)
+		}
+		if d.State == nil || d.State.HighestBid == nil || *d.State.HighestBid != 20 || d.State.Bids != 1 || d.State.Ended {
+			t.Errorf("Expected the state the bid was decided on, got %+v", d.State)
+		}
+		if d.CorrelationId != "request-1" || d.Command != "PlaceBidCommand" || d.Inputs["amount"] != float64(10) {
+			t.Errorf("Expected the command and its inputs, got %+v", d)
+		}
+	})
+
+	t.Run("Accepted", func(t *testing.T) {
+		high := domain.PlaceBidCommand{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: at, Amount: 30}}
+		if _, _, err := bus.Dispatch(ctx, high, repo); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if d := decision(); d.Outcome != domain.DecisionAccepted || d.Event != "BidAccepted" || d.Rejection != nil {
+			t.Errorf("Expected the resulting event, got %+v", d)
+		}
+	})
+
+	t.Run("Sampled", func(t *testing.T) {
+		bus := domain.NewCommandBus(domain.LogDecisions(logf, 0.1, func() float64 { return 0.5 }))
+		bus.Dispatch(ctx, domain.PlaceBidCommand{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: at, Amount: 30}}, repo)
+		if len(lines) != 0 {
+			t.Errorf("Expected the command to be left out of the sample, got %v", lines)
+		}
+	})
+}
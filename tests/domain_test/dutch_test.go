@@ -0,0 +1,117 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestDutchAuction(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	expiry := start.Add(time.Hour)
+	options := domain.DutchOptions{StartPrice: 100, Decrement: 15, Interval: 10 * time.Minute, Floor: 50}
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: start,
+		Title:    "vase",
+		Expiry:   expiry,
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewDutchType(options),
+		Currency: domain.VAC,
+	}
+	bid := func(bidder domain.UserId, at time.Time, amount int64) domain.Bid {
+		return domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller(bidder, "Buyer"), At: at, Amount: amount}
+	}
+
+	t.Run("PriceDropsToTheFloor", func(t *testing.T) {
+		for minutes, expected := range map[time.Duration]int64{0: 100, 9: 100, 10: 85, 25: 70, 40: 50, 59: 50} {
+			if price := options.PriceAt(start, start.Add(minutes*time.Minute)); price != expected {
+				t.Errorf("expected %d after %v minutes, got %d", expected, int64(minutes), price)
+			}
+		}
+	})
+
+	t.Run("FirstBidAtThePriceWins", func(t *testing.T) {
+		state := auction.CreateEmptyState()
+		if _, err := state.AddBid(bid("a2", start.Add(12*time.Minute), 80)); !isErrorType(err, domain.ErrorBidBelowCurrentPrice) {
+			t.Fatalf("expected BidBelowCurrentPrice, got %v", err)
+		}
+		state, err := state.AddBid(bid("a2", start.Add(12*time.Minute), 90))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := state.AddBid(bid("a3", start.Add(13*time.Minute), 100)); !isErrorType(err, domain.ErrorAuctionHasEnded) {
+			t.Errorf("expected AuctionHasEnded, got %v", err)
+		}
+
+		amount, winner, ok := state.TryGetAmountAndWinner()
+		if !ok || winner != "a2" || amount != 85 {
+			t.Errorf("expected a2 to win at 85, got %v %v %v", winner, amount, ok)
+		}
+		if !state.HasEnded() || !domain.CurrentExpiry(state).Equal(start.Add(12*time.Minute)) {
+			t.Errorf("expected the auction closed by the bid, got expiry %v", domain.CurrentExpiry(state))
+		}
+	})
+
+	t.Run("UnsoldAtExpiry", func(t *testing.T) {
+		state := auction.CreateEmptyState().Increment(expiry)
+		if !state.HasEnded() {
+			t.Fatal("expected the auction to have ended")
+		}
+		if _, _, ok := state.TryGetAmountAndWinner(); ok {
+			t.Error("expected no winner")
+		}
+		if _, err := state.AddBid(bid("a2", expiry, 50)); !isErrorType(err, domain.ErrorAuctionHasEnded) {
+			t.Errorf("expected AuctionHasEnded, got %v", err)
+		}
+	})
+
+	t.Run("SnapshotsRoundTrip", func(t *testing.T) {
+		won, err := auction.CreateEmptyState().AddBid(bid("a2", start.Add(time.Minute), 100))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for _, state := range []domain.State{auction.CreateEmptyState(), won, auction.CreateEmptyState().Increment(expiry)} {
+			snapshot, err := domain.SnapshotState(state)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			restored, err := domain.RestoreState(snapshot)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			again, _ := domain.SnapshotState(restored)
+			if a, b := mustJSON(t, snapshot), mustJSON(t, again); a != b {
+				t.Errorf("expected %s, got %s", a, b)
+			}
+		}
+	})
+
+	t.Run("TypeRoundTrips", func(t *testing.T) {
+		var typ domain.AuctionType
+		if err := json.Unmarshal([]byte(`"Dutch|100|15|600|50"`), &typ); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if typ.Type != domain.Dutch || typ.Options != options.String() {
+			t.Errorf("expected %v, got %+v", options, typ)
+		}
+	})
+
+	t.Run("RejectsInvalidOptions", func(t *testing.T) {
+		invalid := auction
+		invalid.Type = domain.NewDutchType(domain.DutchOptions{StartPrice: 100, Decrement: 0, Interval: time.Minute})
+		if err := domain.ValidateCommand(domain.AddAuctionCommand{Time: start, Auction: invalid}); !isErrorType(err, domain.ErrorInvalidCommand) {
+			t.Errorf("expected InvalidCommand, got %v", err)
+		}
+	})
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return string(data)
+}
@@ -0,0 +1,61 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Run("EmptyObject", func(t *testing.T) {
+		data, err := domain.MarshalEnvelope("Empty", struct{}{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if string(data) != `{"$type":"Empty"}` {
+			t.Errorf("Expected an envelope with only the type, got %s", data)
+		}
+	})
+
+	t.Run("RejectsNonObjects", func(t *testing.T) {
+		if _, err := domain.MarshalEnvelope("Number", 1); err == nil {
+			t.Errorf("Expected an error for a value that isn't an object")
+		}
+	})
+
+	t.Run("TypeIgnoresWhitespace", func(t *testing.T) {
+		typeName, err := domain.EnvelopeType([]byte(" {\n \"at\" : null,\n \"$type\" : \"PlaceBid\" }"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if typeName != "PlaceBid" {
+			t.Errorf("Expected PlaceBid, got %s", typeName)
+		}
+	})
+
+	t.Run("MissingType", func(t *testing.T) {
+		if _, err := domain.EnvelopeType([]byte(`{}`)); err == nil {
+			t.Errorf("Expected an error for a missing type")
+		}
+		if _, err := domain.UnmarshalEvent([]byte(`{"at":"2020-01-01T00:00:00Z"}`)); err == nil {
+			t.Errorf("Expected an error unmarshaling an event without type")
+		}
+	})
+
+	t.Run("CommandRoundTrip", func(t *testing.T) {
+		at, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+		cmd := domain.PlaceBidCommand{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: at, Amount: 10}}
+		data, err := domain.MarshalCommand(cmd)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		decoded, err := domain.UnmarshalCommand(data)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if decoded.(domain.PlaceBidCommand).Bid.Amount != 10 {
+			t.Errorf("Expected the bid to survive the round trip, got %+v", decoded)
+		}
+	})
+}
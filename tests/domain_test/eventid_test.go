@@ -0,0 +1,43 @@
+package domain_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestEventIds(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	buyer := domain.NewBuyerOrSeller("a2", "Buyer")
+	auctionType := domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions())
+	events := []domain.Event{
+		domain.AuctionAddedEvent{Time: at, Auction: domain.NewAuction(1, at, "Old car", at.Add(time.Hour), seller, auctionType, domain.VAC)},
+		domain.AuctionAddedEvent{Time: at, Auction: domain.NewAuction(2, at, "Bicycle", at.Add(time.Hour), seller, auctionType, domain.VAC)},
+		domain.BidAcceptedEvent{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: at, Amount: 10}},
+		domain.UserScreenedEvent{Time: at, UserId: "a2", Context: domain.ScreeningBid},
+	}
+
+	ids := domain.EventIds(events)
+	expected := []string{
+		domain.NewEventId("auction/1", 1),
+		domain.NewEventId("auction/2", 1),
+		domain.NewEventId("auction/1", 2),
+		domain.NewEventId("user/a2", 1),
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected event %d to have ID %s, got %s", i, expected[i], ids[i])
+		}
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(ids[0]) {
+		t.Errorf("Expected a version 5 UUID, got %s", ids[0])
+	}
+	if domain.NewEventId("auction/1", 1) != ids[0] || ids[0] == ids[2] {
+		t.Errorf("Expected IDs to be deterministic and distinct per version")
+	}
+}
@@ -0,0 +1,81 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestComputePriceHistory(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	expiry := startsAt.Add(time.Hour)
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	bid := func(auction domain.AuctionId, bidder domain.UserId, at time.Time, amount int64) domain.Event {
+		return domain.BidAcceptedEvent{Time: at, Bid: domain.Bid{ForAuction: auction, Bidder: domain.NewBuyerOrSeller(bidder, "Buyer"), At: at, Amount: amount}}
+	}
+
+	t.Run("TimedAscending", func(t *testing.T) {
+		options := domain.TimedAscendingOptions{TimeFrame: 10 * time.Minute}
+		auction := domain.NewAuction(1, startsAt, "Old car", expiry, seller, domain.NewTimedAscendingType(options), domain.VAC)
+		repo := domain.EventsToAuctionStates([]domain.Event{
+			domain.AuctionAddedEvent{Time: startsAt, Auction: auction},
+			bid(1, "a2", startsAt.Add(10*time.Minute), 10),
+			bid(1, "a3", startsAt.Add(55*time.Minute), 15),
+			bid(1, "a2", startsAt.Add(62*time.Minute), 20),
+		})
+		state := repo[1].State.Increment(startsAt.Add(2 * time.Hour))
+
+		history := domain.ComputePriceHistory(auction, state)
+		if len(history.Points) != 3 {
+			t.Fatalf("Expected 3 points, got %d", len(history.Points))
+		}
+		for i, price := range []int64{10, 15, 20} {
+			if history.Points[i].Price != price {
+				t.Errorf("Expected point %d at %d, got %d", i, price, history.Points[i].Price)
+			}
+		}
+		if history.Points[0].ExtendedTo != nil {
+			t.Errorf("Expected the early bid not to extend the auction")
+		}
+		if p := history.Points[1]; p.ExtendedTo == nil || !p.ExtendedTo.Equal(startsAt.Add(65*time.Minute)) {
+			t.Errorf("Expected the late bid to extend the auction to 01:05, got %v", p.ExtendedTo)
+		}
+		if p := history.Points[2]; p.ExtendedTo == nil || !p.ExtendedTo.Equal(startsAt.Add(72*time.Minute)) {
+			t.Errorf("Expected the last bid to extend the auction to 01:12, got %v", p.ExtendedTo)
+		}
+		if !history.EndedAt.Equal(startsAt.Add(72 * time.Minute)) {
+			t.Errorf("Expected the auction to end at 01:12, got %v", history.EndedAt)
+		}
+		if history.FinalPrice == nil || *history.FinalPrice != 20 {
+			t.Errorf("Expected a final price of 20, got %v", history.FinalPrice)
+		}
+	})
+
+	t.Run("Vickrey", func(t *testing.T) {
+		auction := domain.NewAuction(2, startsAt, "Painting", expiry, seller, domain.NewSingleSealedBidType(domain.Vickrey), domain.VAC)
+		repo := domain.EventsToAuctionStates([]domain.Event{
+			domain.AuctionAddedEvent{Time: startsAt, Auction: auction},
+			bid(2, "a3", startsAt.Add(20*time.Minute), 30),
+			bid(2, "a2", startsAt.Add(10*time.Minute), 20),
+		})
+		state := repo[2].State.Increment(startsAt.Add(2 * time.Hour))
+
+		history := domain.ComputePriceHistory(auction, state)
+		if len(history.Points) != 2 || history.Points[0].Price != 20 || history.Points[1].Price != 30 {
+			t.Errorf("Expected the bids in time order, got %+v", history.Points)
+		}
+		if history.FinalPrice == nil || *history.FinalPrice != 20 {
+			t.Errorf("Expected the second price, got %v", history.FinalPrice)
+		}
+	})
+
+	t.Run("NoBids", func(t *testing.T) {
+		auction := domain.NewAuction(3, startsAt, "Bicycle", expiry, seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+		state := auction.CreateEmptyState().Increment(startsAt.Add(2 * time.Hour))
+		history := domain.ComputePriceHistory(auction, state)
+		if len(history.Points) != 0 || history.FinalPrice != nil || !history.EndedAt.Equal(expiry) {
+			t.Errorf("Expected an empty history ending at the expiry, got %+v", history)
+		}
+	})
+}
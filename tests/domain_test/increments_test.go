@@ -0,0 +1,69 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestBidIncrements(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	options := domain.TimedAscendingOptions{Increments: domain.DefaultIncrementTable}
+
+	t.Run("IncrementOfTheBand", func(t *testing.T) {
+		for amount, increment := range map[int64]int64{0: 1, 99: 1, 100: 5, 999: 5, 1000: 10, 50000: 10} {
+			if got := domain.DefaultIncrementTable.Increment(amount); got != increment {
+				t.Errorf("expected an increment of %d at %d, got %d", increment, amount, got)
+			}
+		}
+		if raise := (domain.TimedAscendingOptions{MinRaise: 7, Increments: domain.DefaultIncrementTable}).MinimumRaise(150); raise != 7 {
+			t.Errorf("expected the min raise to apply over the band, got %d", raise)
+		}
+	})
+
+	t.Run("OptionsRoundTrip", func(t *testing.T) {
+		s := options.String()
+		if s != "English|0|0|0|0|0|0:1,100:5,1000:10" {
+			t.Errorf("expected the table in the options, got %s", s)
+		}
+		parsed, err := domain.ParseTimedAscendingOptions(s)
+		if err != nil || parsed.Increments.String() != domain.DefaultIncrementTable.String() {
+			t.Errorf("expected the table parsed back, got %+v, %v", parsed, err)
+		}
+	})
+
+	t.Run("RejectsBidsNotClearingTheIncrement", func(t *testing.T) {
+		state := domain.NewTimedAscendingState(start, start.Add(time.Hour), options)
+		bid := func(amount int64) domain.Bid {
+			at := start.Add(time.Minute)
+			return domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: at, Amount: amount}
+		}
+
+		next, err := state.AddBid(bid(100))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := next.AddBid(bid(104)); !isErrorType(err, domain.ErrorMustPlaceBidOverHighest) {
+			t.Errorf("expected a raise under 5 rejected, got %v", err)
+		}
+		if _, err := next.AddBid(bid(105)); err != nil {
+			t.Errorf("expected a raise of 5 accepted, got %v", err)
+		}
+	})
+
+	t.Run("InvalidTables", func(t *testing.T) {
+		for name, table := range map[string]domain.IncrementTable{
+			"NotFromZero":  {{From: 10, Increment: 1}},
+			"Unordered":    {{From: 0, Increment: 1}, {From: 100, Increment: 5}, {From: 50, Increment: 2}},
+			"NotPositive":  {{From: 0, Increment: 0}},
+			"TooManyBands": make(domain.IncrementTable, domain.MaxIncrementBands+1),
+		} {
+			auction := domain.NewAuction(1, start, "painting", start.Add(time.Hour), domain.NewBuyerOrSeller("a1", "Test"),
+				domain.NewTimedAscendingType(domain.TimedAscendingOptions{Increments: table}), domain.VAC)
+			if err := domain.ValidateCommand(domain.AddAuctionCommand{Time: start, Auction: auction}); !isErrorType(err, domain.ErrorInvalidCommand) {
+				t.Errorf("%s: expected InvalidCommand, got %v", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestEventMetadata(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	addAuction := domain.AddAuctionCommand{
+		Time:    startsAt,
+		Auction: domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC),
+	}
+	bus := domain.NewCommandBus(domain.StampEvents(func() string { return "cause" }))
+
+	t.Run("StampsContextMetadata", func(t *testing.T) {
+		ctx := domain.ContextWithMetadata(context.Background(), domain.EventMetadata{CorrelationId: "request", UserId: "a1", SourceIP: "203.0.113.7"})
+		event, _, err := bus.Dispatch(ctx, addAuction, domain.Repository{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		metadata := domain.GetEventMetadata(event)
+		if metadata == nil || metadata.CorrelationId != "request" || metadata.CausationId != "cause" {
+			t.Fatalf("Expected stamped metadata, got %+v", metadata)
+		}
+
+		data, err := domain.MarshalEvent(event)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(string(data), `"$meta":{"correlationId":"request","causationId":"cause","userId":"a1","sourceIp":"203.0.113.7"}`) {
+			t.Errorf("Expected the metadata in a $meta field, got %s", data)
+		}
+		decoded, err := domain.UnmarshalEvent(data)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := domain.GetEventMetadata(decoded); got == nil || *got != *metadata {
+			t.Errorf("Expected the metadata to round-trip, got %+v", got)
+		}
+	})
+
+	t.Run("LeavesEventsWithoutContextMetadata", func(t *testing.T) {
+		event, _, err := bus.Dispatch(context.Background(), addAuction, domain.Repository{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if domain.GetEventMetadata(event) != nil {
+			t.Errorf("Expected no metadata")
+		}
+		data, _ := json.Marshal(event)
+		if strings.Contains(string(data), "$meta") {
+			t.Errorf("Expected no $meta field, got %s", data)
+		}
+	})
+}
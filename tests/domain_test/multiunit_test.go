@@ -0,0 +1,130 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestMultiUnitAuction(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	expiry := start.Add(time.Hour)
+	newAuction := func(settlement domain.MultiUnitSettlement) domain.Auction {
+		return domain.Auction{
+			ID:       1,
+			StartsAt: start,
+			Title:    "tickets",
+			Expiry:   expiry,
+			Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+			Type:     domain.NewMultiUnitType(domain.MultiUnitOptions{Units: 2, Settlement: settlement}),
+			Currency: domain.VAC,
+		}
+	}
+	bid := func(bidder domain.UserId, minutes time.Duration, amount int64) domain.Bid {
+		at := start.Add(minutes * time.Minute)
+		return domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller(bidder, "Buyer"), At: at, Amount: amount}
+	}
+	placeBids := func(t *testing.T, state domain.State, bids ...domain.Bid) domain.State {
+		for _, b := range bids {
+			next, err := state.AddBid(b)
+			if err != nil {
+				t.Fatalf("expected the bid of %s accepted, got %v", b.Bidder.ID, err)
+			}
+			state = next
+		}
+		return state
+	}
+
+	t.Run("TypeRoundTrip", func(t *testing.T) {
+		var auctionType domain.AuctionType
+		if err := json.Unmarshal([]byte(`"MultiUnit|3|payAsBid"`), &auctionType); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if auctionType.Type != domain.MultiUnit || auctionType.Options != "MultiUnit|3|payAsBid" {
+			t.Errorf("expected a multi-unit type, got %+v", auctionType)
+		}
+		if err := json.Unmarshal([]byte(`"MultiUnit|3|secondPrice"`), &auctionType); err == nil {
+			t.Error("expected an unknown settlement rejected")
+		}
+	})
+
+	t.Run("TopBiddersWinAtUniformPrice", func(t *testing.T) {
+		state := placeBids(t, newAuction(domain.UniformPrice).CreateEmptyState(),
+			bid("a2", 1, 10), bid("a3", 2, 20), bid("a4", 3, 15), bid("a2", 4, 25))
+		state = state.Increment(expiry)
+
+		expected := []domain.Allocation{{Bidder: "a2", Amount: 25, Price: 20}, {Bidder: "a3", Amount: 20, Price: 20}}
+		if allocations := domain.Allocations(state); !reflect.DeepEqual(allocations, expected) {
+			t.Errorf("expected %+v, got %+v", expected, allocations)
+		}
+		if price, winner, ok := state.TryGetAmountAndWinner(); !ok || winner != "a2" || price != 20 {
+			t.Errorf("expected a2 to top the winners at 20, got %v %v %v", winner, price, ok)
+		}
+	})
+
+	t.Run("WinnersPayTheirBids", func(t *testing.T) {
+		state := placeBids(t, newAuction(domain.PayAsBid).CreateEmptyState(),
+			bid("a2", 1, 10), bid("a4", 2, 10), bid("a3", 3, 20))
+		expected := []domain.Allocation{{Bidder: "a3", Amount: 20, Price: 20}, {Bidder: "a2", Amount: 10, Price: 10}}
+		if allocations := domain.Allocations(state.Increment(expiry)); !reflect.DeepEqual(allocations, expected) {
+			t.Errorf("expected the earliest of equal bids to win, got %+v", allocations)
+		}
+	})
+
+	t.Run("BidsMustBeatTheLowestWinningBid", func(t *testing.T) {
+		state := placeBids(t, newAuction(domain.PayAsBid).CreateEmptyState(), bid("a2", 1, 10), bid("a3", 2, 20))
+		if _, err := state.AddBid(bid("a4", 3, 10)); !isErrorType(err, domain.ErrorMustPlaceBidOverHighest) {
+			t.Errorf("expected a bid at the lowest winning bid rejected, got %v", err)
+		}
+		if _, err := state.AddBid(bid("a3", 3, 15)); !isErrorType(err, domain.ErrorMustPlaceBidOverHighest) {
+			t.Errorf("expected a lower bid than the bidder's own rejected, got %v", err)
+		}
+		if _, err := state.AddBid(bid("a4", 60, 30)); !isErrorType(err, domain.ErrorAuctionHasEnded) {
+			t.Errorf("expected a bid at the expiry rejected, got %v", err)
+		}
+	})
+
+	t.Run("AllocationsRecordedAtTheClose", func(t *testing.T) {
+		auction := newAuction(domain.UniformPrice)
+		repo := domain.EventsToAuctionStates([]domain.Event{
+			domain.AuctionAddedEvent{Time: start, Auction: auction},
+			domain.BidAcceptedEvent{Time: start.Add(time.Minute), Bid: bid("a2", 1, 10)},
+		})
+		closed := expiry.Add(time.Second)
+		if !domain.AwaitingWinner(auction, repo[1].State, closed) {
+			t.Fatal("expected the auction awaiting its allocations")
+		}
+
+		event, newRepo, err := domain.Handle(domain.DetermineWinnerCommand{Time: closed, AuctionId: 1}, repo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		allocated, ok := event.(domain.UnitsAllocatedEvent)
+		if !ok || len(allocated.Allocations) != 1 || allocated.Allocations[0].Price != 10 || allocated.Bids != 1 {
+			t.Errorf("expected a2 allocated a unit at 10, got %+v", event)
+		}
+		if domain.AwaitingWinner(auction, newRepo[1].State, closed) {
+			t.Error("expected the allocations recorded once")
+		}
+
+		snapshot, err := domain.SnapshotState(newRepo[1].State)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		restored, err := domain.RestoreState(snapshot)
+		if err != nil || !restored.HasEnded() || domain.AwaitingWinner(auction, restored, closed) {
+			t.Errorf("expected the settled state restored, got %v", err)
+		}
+	})
+
+	t.Run("InvalidUnits", func(t *testing.T) {
+		auction := newAuction(domain.PayAsBid)
+		auction.Type = domain.NewMultiUnitType(domain.MultiUnitOptions{Units: 0, Settlement: domain.PayAsBid})
+		if err := domain.ValidateCommand(domain.AddAuctionCommand{Time: start, Auction: auction}); !isErrorType(err, domain.ErrorInvalidCommand) {
+			t.Errorf("expected InvalidCommand, got %v", err)
+		}
+	})
+}
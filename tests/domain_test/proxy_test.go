@@ -0,0 +1,149 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestProxyBidding(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: start,
+		Title:    "painting",
+		Expiry:   start.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewTimedAscendingType(domain.TimedAscendingOptions{MinRaise: 5}),
+		Currency: domain.VAC,
+	}
+	buyer := domain.NewBuyerOrSeller("a2", "Buyer")
+	other := domain.NewBuyerOrSeller("a3", "Other")
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	// handle handles commands in order, running the engine after each like
+	// the server does, and returns the events
+	handle := func(t *testing.T, commands ...domain.Command) ([]domain.Event, domain.Repository) {
+		repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}})
+		var events []domain.Event
+		for _, cmd := range commands {
+			event, newRepo, err := domain.Handle(cmd, repo)
+			if err != nil {
+				t.Fatalf("expected no error handling %T, got %v", cmd, err)
+			}
+			events, repo = append(events, event), newRepo
+			for {
+				if _, due := domain.NextProxyBid(1, repo[1].State, cmd.GetTime()); !due {
+					break
+				}
+				event, newRepo, err := domain.Handle(domain.PlaceProxyBidCommand{Time: cmd.GetTime(), AuctionId: 1}, repo)
+				if err != nil {
+					t.Fatalf("expected no error placing a proxy bid, got %v", err)
+				}
+				events, repo = append(events, event), newRepo
+			}
+		}
+		return events, repo
+	}
+	proxyBids := func(events []domain.Event) []domain.Bid {
+		var bids []domain.Bid
+		for _, event := range events {
+			if e, ok := event.(domain.ProxyBidPlacedEvent); ok {
+				bids = append(bids, e.Bid)
+			}
+		}
+		return bids
+	}
+
+	t.Run("OpensAtTheIncrement", func(t *testing.T) {
+		events, repo := handle(t, domain.SetMaxBidCommand{Time: at(1), AuctionId: 1, Bidder: buyer, Max: 100})
+		bids := proxyBids(events)
+		if len(bids) != 1 || bids[0].Bidder.ID != "a2" || bids[0].Amount != 5 {
+			t.Errorf("expected a2 to open at 5, got %+v", bids)
+		}
+		if max, ok := domain.MaxBid(repo[1].State, "a2", at(1)); !ok || max != 100 {
+			t.Errorf("expected a max bid of 100, got %v %v", max, ok)
+		}
+	})
+
+	t.Run("CountersBidsUpToTheMax", func(t *testing.T) {
+		events, repo := handle(t,
+			domain.SetMaxBidCommand{Time: at(1), AuctionId: 1, Bidder: buyer, Max: 100},
+			domain.PlaceBidCommand{Time: at(2), Bid: domain.Bid{ForAuction: 1, Bidder: other, At: at(2), Amount: 50}},
+		)
+		if bids := repo[1].State.GetBids(); bids[0].Bidder.ID != "a2" || bids[0].Amount != 55 {
+			t.Errorf("expected a2 to lead at 55, got %+v", bids[0])
+		}
+
+		// A bid over the max isn't countered
+		event, repo, err := domain.Handle(domain.PlaceBidCommand{Time: at(3), Bid: domain.Bid{ForAuction: 1, Bidder: other, At: at(3), Amount: 100}}, repo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		events = append(events, event)
+		if _, due := domain.NextProxyBid(1, repo[1].State, at(3)); due {
+			t.Error("expected no proxy bid over the max")
+		}
+		if len(proxyBids(events)) != 2 {
+			t.Errorf("expected two proxy bids, got %+v", proxyBids(events))
+		}
+	})
+
+	t.Run("StrongerMaxWinsInOneBid", func(t *testing.T) {
+		events, repo := handle(t,
+			domain.SetMaxBidCommand{Time: at(1), AuctionId: 1, Bidder: buyer, Max: 100},
+			domain.SetMaxBidCommand{Time: at(2), AuctionId: 1, Bidder: other, Max: 70},
+		)
+		bids := proxyBids(events)
+		if len(bids) != 2 || bids[1].Bidder.ID != "a2" || bids[1].Amount != 75 {
+			t.Errorf("expected a2 raised to 75 past the max of a3, got %+v", bids)
+		}
+		if amount, winner, _ := repo[1].State.Increment(at(61)).TryGetAmountAndWinner(); winner != "a2" || amount != 75 {
+			t.Errorf("expected a2 to win at 75, got %v %v", winner, amount)
+		}
+	})
+
+	t.Run("EarlierMaxWinsTies", func(t *testing.T) {
+		_, repo := handle(t,
+			domain.SetMaxBidCommand{Time: at(1), AuctionId: 1, Bidder: buyer, Max: 70},
+			domain.SetMaxBidCommand{Time: at(2), AuctionId: 1, Bidder: other, Max: 70},
+		)
+		if bids := repo[1].State.GetBids(); bids[0].Bidder.ID != "a2" || bids[0].Amount != 70 {
+			t.Errorf("expected a2 to lead at 70, got %+v", bids[0])
+		}
+	})
+
+	t.Run("MaxMustBeatTheHighestBid", func(t *testing.T) {
+		_, repo := handle(t, domain.PlaceBidCommand{Time: at(1), Bid: domain.Bid{ForAuction: 1, Bidder: other, At: at(1), Amount: 50}})
+		_, _, err := domain.Handle(domain.SetMaxBidCommand{Time: at(2), AuctionId: 1, Bidder: buyer, Max: 54}, repo)
+		if !isErrorType(err, domain.ErrorMustPlaceBidOverHighest) {
+			t.Errorf("expected MustPlaceBidOverHighest, got %v", err)
+		}
+	})
+
+	t.Run("OnlyTimedAscending", func(t *testing.T) {
+		sealed := auction
+		sealed.Type = domain.NewSingleSealedBidType(domain.Vickrey)
+		repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: sealed}})
+		_, _, err := domain.Handle(domain.SetMaxBidCommand{Time: at(1), AuctionId: 1, Bidder: buyer, Max: 100}, repo)
+		if !isErrorType(err, domain.ErrorProxyBiddingUnsupported) {
+			t.Errorf("expected ProxyBiddingUnsupported, got %v", err)
+		}
+	})
+
+	t.Run("SnapshotKeepsTheMaxBids", func(t *testing.T) {
+		_, repo := handle(t, domain.SetMaxBidCommand{Time: at(1), AuctionId: 1, Bidder: buyer, Max: 100})
+		snapshots, err := domain.SnapshotRepository(repo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		restored, err := domain.RestoreRepository(snapshots)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if max, ok := domain.MaxBid(restored[1].State, "a2", at(2)); !ok || max != 100 {
+			t.Errorf("expected the max bid restored, got %v %v", max, ok)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestReports(t *testing.T) {
+	filedAt := sampleStartsAt
+	auctionId := sampleAuctionId
+	fileReport := domain.FileReportCommand{
+		Time: filedAt,
+		Report: domain.Report{
+			Reporter: buyer1.ID,
+			Target:   domain.ReportTarget{AuctionId: &auctionId},
+			Reason:   domain.ReportCounterfeit,
+			Text:     "fake",
+		},
+	}
+
+	event, reports, err := domain.HandleReport(fileReport, domain.Reports{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report := reports[1]
+	if report.Status != domain.ReportOpen || !report.DueBy.Equal(filedAt.Add(domain.ReportSLA)) {
+		t.Errorf("Expected an open report due after the SLA, got %+v", report)
+	}
+
+	t.Run("Serialization", func(t *testing.T) {
+		data, _ := json.Marshal(event)
+		parsed, err := domain.UnmarshalEvent(data)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if restored := domain.EventsToReports([]domain.Event{parsed}); restored[1].Reason != domain.ReportCounterfeit {
+			t.Errorf("Expected the report to survive a round trip, got %+v", restored[1])
+		}
+	})
+
+	t.Run("Transitions", func(t *testing.T) {
+		change := func(status domain.ReportStatus) domain.ChangeReportStatusCommand {
+			return domain.ChangeReportStatusCommand{Time: filedAt.Add(time.Hour), ReportId: 1, Status: status, By: "s1"}
+		}
+
+		_, _, err := domain.HandleReport(change(domain.ReportActioned), reports)
+		if domainErr, ok := err.(domain.DomainError); !ok || domainErr.Type != domain.ErrorInvalidReportTransition {
+			t.Errorf("Expected InvalidReportTransition error, got %v", err)
+		}
+
+		_, triaged, err := domain.HandleReport(change(domain.ReportTriaged), reports)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_, actioned, err := domain.HandleReport(change(domain.ReportActioned), triaged)
+		if err != nil || actioned[1].Status != domain.ReportActioned {
+			t.Errorf("Expected the report to be actioned, got %v %v", actioned[1].Status, err)
+		}
+		if actioned[1].IsOverdue(filedAt.Add(2 * domain.ReportSLA)) {
+			t.Errorf("Expected a resolved report not to be overdue")
+		}
+	})
+
+	t.Run("Overdue", func(t *testing.T) {
+		if report.IsOverdue(filedAt.Add(time.Hour)) || !report.IsOverdue(filedAt.Add(2*domain.ReportSLA)) {
+			t.Errorf("Expected the report to be overdue only after its SLA")
+		}
+	})
+
+	t.Run("RejectsInvalidTarget", func(t *testing.T) {
+		cmd := fileReport
+		cmd.Report.Target = domain.ReportTarget{}
+		if _, _, err := domain.HandleReport(cmd, reports); err == nil {
+			t.Errorf("Expected an error for a report without target")
+		}
+	})
+}
+
+func TestRuleSets(t *testing.T) {
+	rules := []domain.ProhibitedItemRule{
+		{Keyword: "replica", Verdict: domain.ModerationReview},
+		{Keyword: "ivory", Verdict: domain.ModerationRejected},
+	}
+	event, err := domain.PublishRuleSet(nil, rules, "s1", sampleStartsAt)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if event.RuleSet.Version != 1 {
+		t.Errorf("Expected version 1, got %d", event.RuleSet.Version)
+	}
+
+	auction := sampleAuctionOfType(domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()))
+	auction.Title = "Replica ivory figurine"
+	decision, _ := event.RuleSet.ModerateListing(auction)
+	if decision.Verdict != domain.ModerationRejected || len(decision.Reasons) != 2 {
+		t.Errorf("Expected the rejecting rule to take precedence, got %+v", decision)
+	}
+}
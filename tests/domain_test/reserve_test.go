@@ -0,0 +1,90 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestReserve(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: start,
+		Title:    "painting",
+		Expiry:   start.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewTimedAscendingType(domain.TimedAscendingOptions{ReservePrice: 20}),
+		Currency: domain.VAC,
+	}
+	bid := func(amount int64) domain.Event {
+		at := start.Add(time.Minute)
+		return domain.BidAcceptedEvent{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: at, Amount: amount}}
+	}
+	open := start.Add(2 * time.Minute)
+	closed := auction.Expiry.Add(time.Second)
+
+	t.Run("NoReserve", func(t *testing.T) {
+		without := auction
+		without.Type = domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions())
+		entry := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: without}, bid(10)})[1]
+		if status := domain.ReserveStatus(entry.Auction, entry.State); status != "" {
+			t.Errorf("expected no reserve status, got %q", status)
+		}
+		if event := domain.ReserveEvent(entry.Auction, entry.State, domain.Reserves{}, closed); event != nil {
+			t.Errorf("expected nothing to report, got %+v", event)
+		}
+	})
+
+	t.Run("ClosingBelowReserveHasNoWinner", func(t *testing.T) {
+		entry := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}, bid(19)})[1]
+		if event := domain.ReserveEvent(entry.Auction, entry.State, domain.Reserves{}, open); event != nil {
+			t.Errorf("expected nothing to report while open, got %+v", event)
+		}
+
+		event := domain.ReserveEvent(entry.Auction, entry.State, domain.Reserves{}, closed)
+		if _, ok := event.(domain.ReserveNotMetEvent); !ok {
+			t.Fatalf("expected ReserveNotMet at the close, got %+v", event)
+		}
+		if _, _, ok := entry.State.Increment(closed).TryGetAmountAndWinner(); ok {
+			t.Error("expected no winner below the reserve")
+		}
+
+		reported := domain.EventsToReserves([]domain.Event{event})
+		if event := domain.ReserveEvent(entry.Auction, entry.State, reported, closed); event != nil {
+			t.Errorf("expected ReserveNotMet reported once, got %+v", event)
+		}
+	})
+
+	t.Run("BidAtReserveDoesNotMeetIt", func(t *testing.T) {
+		entry := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}, bid(20)})[1]
+		if status := domain.ReserveStatus(entry.Auction, entry.State); status != domain.ReserveNotMet {
+			t.Errorf("expected the reserve not met, got %q", status)
+		}
+		if _, _, ok := entry.State.Increment(closed).TryGetAmountAndWinner(); ok {
+			t.Error("expected no winner at the reserve")
+		}
+	})
+
+	t.Run("BidOverReserveMeetsIt", func(t *testing.T) {
+		entry := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: start, Auction: auction}, bid(21)})[1]
+		if status := domain.ReserveStatus(entry.Auction, entry.State); status != domain.ReserveMet {
+			t.Errorf("expected the reserve met, got %q", status)
+		}
+
+		event := domain.ReserveEvent(entry.Auction, entry.State, domain.Reserves{}, open)
+		if _, ok := event.(domain.ReserveMetEvent); !ok {
+			t.Fatalf("expected ReserveMet while open, got %+v", event)
+		}
+		reported := domain.EventsToReserves([]domain.Event{event})
+		if event := domain.ReserveEvent(entry.Auction, entry.State, reported, closed); event != nil {
+			t.Errorf("expected nothing more to report at the close, got %+v", event)
+		}
+
+		amount, winner, ok := entry.State.Increment(closed).TryGetAmountAndWinner()
+		if !ok || winner != "a2" || amount != 21 {
+			t.Errorf("expected a2 to win at 21, got %v %v %v", amount, winner, ok)
+		}
+	})
+}
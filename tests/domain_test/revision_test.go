@@ -0,0 +1,77 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestReviseListing(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	expiry := startsAt.Add(time.Hour)
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	buyer := domain.NewBuyerOrSeller("a2", "Buyer")
+	auctionType := domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions())
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(1, startsAt, "Old car", expiry, seller, auctionType, domain.VAC)},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(2, startsAt, "Bicycle", expiry, seller, auctionType, domain.VAC)},
+		domain.BidAcceptedEvent{Time: startsAt, Bid: domain.Bid{ForAuction: 2, Bidder: buyer, At: startsAt.Add(time.Second), Amount: 10}},
+	})
+	at := startsAt.Add(time.Minute)
+	later := expiry.Add(24 * time.Hour)
+
+	t.Run("ExtendsAndTags", func(t *testing.T) {
+		cmd := domain.ReviseListingCommand{Time: at, AuctionId: 1, Expiry: &later, AddTags: []string{"winter", "cars"}}
+		event, newRepo, err := domain.Handle(cmd, repo)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		auction := newRepo[1].Auction
+		if !auction.Expiry.Equal(later) || !auction.HasTag("winter") || !auction.HasTag("cars") {
+			t.Errorf("Expected the revision to apply, got %+v", auction)
+		}
+		if newRepo[1].State.Increment(expiry.Add(time.Minute)).HasEnded() {
+			t.Errorf("Expected the auction to stay open past the old end time")
+		}
+		if repo[1].Auction.HasTag("winter") {
+			t.Errorf("Expected the original repository to be unchanged")
+		}
+
+		// Replaying the event gives the same auction
+		replayed := domain.ApplyEvents(repo, []domain.Event{event})
+		if !replayed[1].Auction.Expiry.Equal(later) || len(replayed[1].Auction.Tags) != 2 {
+			t.Errorf("Expected the replayed revision to match, got %+v", replayed[1].Auction)
+		}
+	})
+
+	t.Run("TagsOnce", func(t *testing.T) {
+		cmd := domain.ReviseListingCommand{Time: at, AuctionId: 1, AddTags: []string{"winter"}}
+		_, newRepo, _ := domain.Handle(cmd, repo)
+		_, newRepo, _ = domain.Handle(cmd, newRepo)
+		if tags := newRepo[1].Auction.Tags; len(tags) != 1 {
+			t.Errorf("Expected a single tag, got %v", tags)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		cmd      domain.ReviseListingCommand
+		expected domain.ErrorType
+	}{
+		{"HasBids", domain.ReviseListingCommand{Time: at, AuctionId: 2, Expiry: &later}, domain.ErrorAuctionHasBids},
+		{"HasEnded", domain.ReviseListingCommand{Time: expiry.Add(time.Minute), AuctionId: 1, Expiry: &later}, domain.ErrorAuctionHasEnded},
+		{"NotFound", domain.ReviseListingCommand{Time: at, AuctionId: 3, Expiry: &later}, domain.ErrorAuctionNotFound},
+		{"Shortens", domain.ReviseListingCommand{Time: at, AuctionId: 1, Expiry: &at}, domain.ErrorInvalidRevision},
+		{"InvalidTag", domain.ReviseListingCommand{Time: at, AuctionId: 1, AddTags: []string{"two words"}}, domain.ErrorInvalidRevision},
+		{"Nothing", domain.ReviseListingCommand{Time: at, AuctionId: 1}, domain.ErrorInvalidRevision},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := domain.Handle(tt.cmd, repo)
+			if domainErr, ok := err.(domain.DomainError); !ok || domainErr.Type != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestSchedule(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: start,
+		Title:    "painting",
+		Expiry:   start.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewSingleSealedBidType(domain.Blind),
+		Currency: domain.VAC,
+	}
+	_, repo, err := domain.Handle(domain.AddAuctionCommand{Time: start.Add(-time.Hour), Auction: auction}, domain.Repository{})
+	if err != nil {
+		t.Fatalf("expected the auction added, got %v", err)
+	}
+	bid := func(at time.Time) domain.Command {
+		return domain.PlaceBidCommand{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: at, Amount: 10}}
+	}
+
+	t.Run("BidBeforeStartIsRejected", func(t *testing.T) {
+		_, _, err := domain.Handle(bid(start.Add(-time.Minute)), repo)
+		if !isErrorType(err, domain.ErrorAuctionHasNotStarted) {
+			t.Errorf("expected AuctionHasNotStarted, got %v", err)
+		}
+		if _, _, err := domain.Handle(bid(start.Add(time.Minute)), repo); err != nil {
+			t.Errorf("expected the bid accepted once started, got %v", err)
+		}
+	})
+
+	t.Run("StartIsReportedOnce", func(t *testing.T) {
+		state := repo[1].State
+		if event := domain.StartEvent(auction, state, domain.StartedAuctions{}, start); event != nil {
+			t.Errorf("expected nothing to report before the start, got %+v", event)
+		}
+
+		event := domain.StartEvent(auction, state, domain.StartedAuctions{}, start.Add(time.Second))
+		started, ok := event.(domain.AuctionStartedEvent)
+		if !ok || started.AuctionId != 1 || !started.StartsAt.Equal(start) || !started.Time.Equal(start) {
+			t.Fatalf("expected AuctionStarted for auction 1 dated at its start, got %+v", event)
+		}
+
+		reported := domain.EventsToStartedAuctions([]domain.Event{event})
+		if event := domain.StartEvent(auction, state, reported, start.Add(time.Minute)); event != nil {
+			t.Errorf("expected the start reported once, got %+v", event)
+		}
+	})
+
+	t.Run("EndedAuctionIsNotReported", func(t *testing.T) {
+		if event := domain.StartEvent(auction, repo[1].State, domain.StartedAuctions{}, auction.Expiry.Add(time.Second)); event != nil {
+			t.Errorf("expected nothing to report once ended, got %+v", event)
+		}
+	})
+}
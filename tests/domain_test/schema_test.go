@@ -0,0 +1,85 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// TestFrozenSchemas fails when the shape of a stored event or command no
+// longer matches the frozen one, see cmd/compat
+func TestFrozenSchemas(t *testing.T) {
+	frozen, err := domain.FrozenSchemas()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, problem := range domain.CheckSchemas(frozen) {
+		t.Error(problem)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	type inner struct {
+		Amount int64 `json:"amount"`
+	}
+	type before struct {
+		At     time.Time         `json:"at"`
+		Bids   []inner           `json:"bids,omitempty"`
+		Tags   map[string]string `json:"tags"`
+		Hidden string            `json:"-"`
+	}
+	type reordered struct {
+		Tags map[string]string `json:"tags"`
+		Bids []inner           `json:"bids"`
+		At   time.Time         `json:"at"`
+	}
+	type renamed struct {
+		At   time.Time         `json:"at"`
+		Bids []inner           `json:"offers"`
+		Tags map[string]string `json:"tags"`
+	}
+	type retyped struct {
+		At   time.Time      `json:"at"`
+		Bids []inner        `json:"bids"`
+		Tags map[string]int `json:"tags"`
+	}
+
+	if schema := domain.Schema(before{}); schema != "{at:time,bids:[]{amount:int64},tags:map[string]string}" {
+		t.Errorf("Unexpected schema %s", schema)
+	}
+	if domain.SchemaHash(before{}) != domain.SchemaHash(reordered{}) {
+		t.Errorf("Expected field order and options not to change the shape")
+	}
+	if domain.SchemaHash(before{}) == domain.SchemaHash(renamed{}) || domain.SchemaHash(before{}) == domain.SchemaHash(retyped{}) {
+		t.Errorf("Expected renamed and retyped fields to change the shape")
+	}
+}
+
+func TestCheckSchemas(t *testing.T) {
+	current := domain.SchemaHashes()
+	frozen := map[string]string{}
+	for key, hash := range current {
+		frozen[key] = hash
+	}
+	if problems := domain.CheckSchemas(frozen); len(problems) != 0 {
+		t.Fatalf("Expected the current shapes to pass, got %v", problems)
+	}
+
+	frozen["AuctionAdded@v1"] = "changed"
+	delete(frozen, "PlaceBid@v1")
+	frozen["AuctionClosed@v1"] = "removed"
+	frozen["BidAccepted@v2"] = "upcaster missing"
+	problems := strings.Join(domain.CheckSchemas(frozen), "\n")
+	for _, expected := range []string{
+		"AuctionAdded@v1 is frozen but its shape changed",
+		"PlaceBid@v1 is not frozen",
+		"AuctionClosed@v1 is frozen but the type no longer exists",
+		"BidAccepted@v2 is frozen but the type is at an earlier version",
+	} {
+		if !strings.Contains(problems, expected) {
+			t.Errorf("Expected %q in %s", expected, problems)
+		}
+	}
+}
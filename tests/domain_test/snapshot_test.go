@@ -0,0 +1,77 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// roundTrip snapshots a state, serializes the snapshot and restores it
+func roundTrip(t *testing.T, state domain.State) domain.State {
+	snapshot, err := domain.SnapshotState(state)
+	if err != nil {
+		t.Fatalf("Failed to snapshot state: %v", err)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to marshal snapshot: %v", err)
+	}
+	var parsed domain.StateSnapshot
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Failed to unmarshal snapshot: %v", err)
+	}
+	restored, err := domain.RestoreState(parsed)
+	if err != nil {
+		t.Fatalf("Failed to restore state: %v", err)
+	}
+	return restored
+}
+
+func TestStateSnapshots(t *testing.T) {
+	t.Run("TimedAscendingOngoing", func(t *testing.T) {
+		auction := sampleAuctionOfType(domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()))
+		state := auction.CreateEmptyState().Increment(sampleStartsAt.Add(time.Second))
+		state, _ = state.AddBid(createBid1())
+
+		restored := roundTrip(t, state)
+
+		if len(restored.GetBids()) != 1 {
+			t.Errorf("Expected 1 bid, got %d", len(restored.GetBids()))
+		}
+		// The restored state must keep accepting bids like the original
+		if _, err := restored.AddBid(createBid2()); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		ended := restored.Increment(sampleEndsAt.Add(time.Second))
+		if !ended.HasEnded() {
+			t.Errorf("Expected restored auction to end at its expiry")
+		}
+	})
+
+	t.Run("TimedAscendingAwaitingStart", func(t *testing.T) {
+		auction := sampleAuctionOfType(domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()))
+		restored := roundTrip(t, auction.CreateEmptyState())
+
+		if _, err := restored.AddBid(createBid1()); err != nil {
+			t.Errorf("Expected restored auction to accept bids after start, got %v", err)
+		}
+	})
+
+	t.Run("SealedBid", func(t *testing.T) {
+		auction := sampleAuctionOfType(domain.NewSingleSealedBidType(domain.Vickrey))
+		state, _ := auction.CreateEmptyState().AddBid(createBid1())
+		state, _ = state.AddBid(createBid2())
+
+		restored := roundTrip(t, state)
+
+		if _, err := restored.AddBid(createBid1()); err == nil {
+			t.Errorf("Expected restored state to remember bidder of bid 1")
+		}
+		amount, winner, found := roundTrip(t, restored.Increment(sampleEndsAt)).TryGetAmountAndWinner()
+		if !found || amount != bidAmount1 || winner != buyer2.ID {
+			t.Errorf("Expected buyer 2 to win paying %d, got %v %d %s", bidAmount1, found, amount, winner)
+		}
+	})
+}
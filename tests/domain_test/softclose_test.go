@@ -0,0 +1,98 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestSoftClose(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	end := start.Add(time.Hour)
+	options := domain.TimedAscendingOptions{
+		SoftClose: domain.SoftClose{Window: 30 * time.Second, Extension: time.Minute, MaxExtensions: 2},
+	}
+	bidder := domain.NewBuyerOrSeller("a2", "Buyer")
+	bid := func(at time.Time, amount int64) domain.Bid {
+		return domain.Bid{ForAuction: 1, Bidder: bidder, At: at, Amount: amount}
+	}
+
+	t.Run("OptionsRoundTrip", func(t *testing.T) {
+		s := options.String()
+		if s != "English|0|0|0|0|0||30:60:2" {
+			t.Errorf("expected the soft close in the options, got %s", s)
+		}
+		parsed, err := domain.ParseTimedAscendingOptions(s)
+		if err != nil || parsed.SoftClose != options.SoftClose || parsed.Increments != nil {
+			t.Errorf("expected the soft close parsed back, got %+v, %v", parsed, err)
+		}
+	})
+
+	t.Run("EarlyBidDoesNotExtend", func(t *testing.T) {
+		state := domain.NewTimedAscendingState(start, end, options)
+		next, err := state.AddBid(bid(end.Add(-time.Minute), 10))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !domain.CurrentExpiry(next).Equal(end) || domain.Extensions(next) != 0 {
+			t.Errorf("expected the close unchanged, got %v", domain.CurrentExpiry(next))
+		}
+	})
+
+	t.Run("LateBidsExtendUpToTheMax", func(t *testing.T) {
+		var state domain.State = domain.NewTimedAscendingState(start, end, options)
+		before := state.Increment(end.Add(-time.Minute))
+		expiry := end
+		for i, amount := range []int64{10, 20, 30} {
+			next, err := state.AddBid(bid(expiry.Add(-10*time.Second), amount))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if i < 2 {
+				expiry = expiry.Add(time.Minute)
+			}
+			if !domain.CurrentExpiry(next).Equal(expiry) {
+				t.Errorf("bid %d: expected the close at %v, got %v", i, expiry, domain.CurrentExpiry(next))
+			}
+			state = next
+		}
+		if domain.Extensions(state) != 2 {
+			t.Errorf("expected 2 extensions, got %d", domain.Extensions(state))
+		}
+
+		event, ok := domain.ExtensionEvent(1, before, state, expiry).(domain.AuctionExtendedEvent)
+		if !ok || !event.EndsAt.Equal(expiry) || event.Extensions != 2 {
+			t.Errorf("expected an extension to %v, got %+v", expiry, event)
+		}
+		if domain.ExtensionEvent(1, state, state, expiry) != nil {
+			t.Error("expected no event without a new extension")
+		}
+
+		snapshot, err := domain.SnapshotState(state)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		restored, err := domain.RestoreState(snapshot)
+		if err != nil || domain.Extensions(restored) != 2 {
+			t.Errorf("expected the extensions restored, got %v", err)
+		}
+		if ended := state.Increment(expiry); !ended.HasEnded() || domain.Extensions(ended) != 2 {
+			t.Errorf("expected the extensions kept at the close")
+		}
+	})
+
+	t.Run("InvalidSoftClose", func(t *testing.T) {
+		for name, softClose := range map[string]domain.SoftClose{
+			"Negative":       {Window: -time.Second, Extension: time.Minute, MaxExtensions: 1},
+			"NoExtension":    {Window: time.Second, MaxExtensions: 1},
+			"NoMaxExtension": {Window: time.Second, Extension: time.Minute},
+		} {
+			auction := domain.NewAuction(1, start, "painting", end, domain.NewBuyerOrSeller("a1", "Test"),
+				domain.NewTimedAscendingType(domain.TimedAscendingOptions{SoftClose: softClose}), domain.VAC)
+			if err := domain.ValidateCommand(domain.AddAuctionCommand{Time: start, Auction: auction}); !isErrorType(err, domain.ErrorInvalidCommand) {
+				t.Errorf("%s: expected InvalidCommand, got %v", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestAuctionAsOf(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	expiry := startsAt.Add(time.Hour)
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	buyer := domain.NewBuyerOrSeller("a2", "Buyer")
+	auctionType := domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions())
+	events := []domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(1, startsAt, "Old car", expiry, seller, auctionType, domain.VAC)},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(2, startsAt, "Bicycle", expiry, seller, auctionType, domain.VAC)},
+		domain.BidAcceptedEvent{Time: startsAt.Add(time.Minute), Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: startsAt.Add(time.Minute), Amount: 10}},
+		domain.BidAcceptedEvent{Time: startsAt.Add(2 * time.Minute), Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: startsAt.Add(2 * time.Minute), Amount: 20}},
+	}
+
+	tests := []struct {
+		name     string
+		asOf     domain.AsOf
+		bids     int
+		ended    bool
+		expected time.Time
+	}{
+		{"BeforeBids", domain.AsOf{Time: startsAt.Add(30 * time.Second)}, 0, false, startsAt.Add(30 * time.Second)},
+		{"BetweenBids", domain.AsOf{Time: startsAt.Add(time.Minute)}, 1, false, startsAt.Add(time.Minute)},
+		{"AfterExpiry", domain.AsOf{Time: expiry.Add(time.Hour)}, 2, true, expiry.Add(time.Hour)},
+		{"AtPosition", domain.AsOf{Position: 3}, 1, false, startsAt.Add(time.Minute)},
+		{"PositionBeforeBids", domain.AsOf{Position: 2}, 0, false, startsAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, state, at, ok := domain.AuctionAsOf(events, 1, tt.asOf)
+			if !ok {
+				t.Fatalf("Expected the auction to exist")
+			}
+			if len(state.GetBids()) != tt.bids || state.HasEnded() != tt.ended || !at.Equal(tt.expected) {
+				t.Errorf("Expected %d bids, ended %v at %s, got %d, %v at %s", tt.bids, tt.ended, tt.expected, len(state.GetBids()), state.HasEnded(), at)
+			}
+		})
+	}
+
+	if _, _, _, ok := domain.AuctionAsOf(events, 1, domain.AsOf{Time: startsAt.Add(-time.Second)}); ok {
+		t.Errorf("Expected no auction before it was added")
+	}
+	if _, _, _, ok := domain.AuctionAsOf(events, 3, domain.AsOf{Time: expiry}); ok {
+		t.Errorf("Expected no unknown auction")
+	}
+}
@@ -0,0 +1,222 @@
+package domain_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestSplitSecret(t *testing.T) {
+	secret := []byte("the private key")
+	shares, err := domain.SplitSecret(secret, 5, 3, rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var picked [][]byte
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+		combined, err := domain.CombineShares(picked)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !bytes.Equal(combined, secret) {
+			t.Errorf("Expected shares %v to recover the secret, got %q", subset, combined)
+		}
+	}
+
+	combined, _ := domain.CombineShares(shares[:2])
+	if bytes.Equal(combined, secret) {
+		t.Errorf("Expected fewer shares than the threshold not to recover the secret")
+	}
+	if _, err := domain.CombineShares([][]byte{shares[0], shares[0]}); err == nil {
+		t.Errorf("Expected duplicate shares to be rejected")
+	}
+	if _, err := domain.SplitSecret(secret, 2, 3, rand.Reader); err == nil {
+		t.Errorf("Expected a threshold above the number of shares to be rejected")
+	}
+}
+
+// dealTender returns a tender of three custodians with a threshold of two,
+// and their shares
+func dealTender(t *testing.T) (*domain.Tender, *rsa.PublicKey, map[domain.UserId]string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	publicKey, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	custodians := []domain.UserId{"c1", "c2", "c3"}
+	split, err := domain.SplitSecret(x509.MarshalPKCS1PrivateKey(key), len(custodians), 2, rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to split key: %v", err)
+	}
+	shares := make(map[domain.UserId]string)
+	for i, custodian := range custodians {
+		shares[custodian] = base64.StdEncoding.EncodeToString(split[i])
+	}
+	tender := &domain.Tender{
+		PublicKey:  base64.StdEncoding.EncodeToString(publicKey),
+		Threshold:  2,
+		Custodians: custodians,
+	}
+	return tender, &key.PublicKey, shares
+}
+
+func sealAmount(t *testing.T, key *rsa.PublicKey, amount string) string {
+	ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, []byte(amount), nil)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func TestTender(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	expiry := startsAt.Add(time.Hour)
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	tender, publicKey, shares := dealTender(t)
+
+	auction := domain.NewAuction(1, startsAt, "Road works", expiry, seller, domain.NewSingleSealedBidType(domain.Vickrey), domain.VAC)
+	auction.Tender = tender
+	_, repo, err := domain.Handle(domain.AddAuctionCommand{Time: startsAt, Auction: auction}, domain.Repository{})
+	if err != nil {
+		t.Fatalf("Expected the tender to be added, got %v", err)
+	}
+
+	var events []domain.Event
+	bids := map[domain.UserId]string{"b1": "100", "b2": "250", "b3": "not an amount"}
+	for _, bidder := range []domain.UserId{"b1", "b2", "b3"} {
+		bid := domain.Bid{
+			ForAuction: 1,
+			Bidder:     domain.NewBuyerOrSeller(bidder, string(bidder)),
+			At:         startsAt.Add(time.Minute),
+			Sealed:     sealAmount(t, publicKey, bids[bidder]),
+		}
+		event, newRepo, err := domain.Handle(domain.PlaceBidCommand{Time: bid.At, Bid: bid}, repo)
+		if err != nil {
+			t.Fatalf("Expected the encrypted bid to be accepted, got %v", err)
+		}
+		events = append(events, event)
+		repo = newRepo
+	}
+
+	plain := domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("b4", "b4"), At: startsAt.Add(time.Minute), Amount: 10}
+	if _, _, err := domain.Handle(domain.PlaceBidCommand{Time: plain.At, Bid: plain}, repo); !isErrorType(err, domain.ErrorInvalidTender) {
+		t.Errorf("Expected a plain bid on a tender to be rejected, got %v", err)
+	}
+
+	open := domain.SubmitKeyShareCommand{Time: startsAt.Add(time.Minute), AuctionId: 1, Custodian: "c1", Share: shares["c1"]}
+	if _, _, err := domain.Handle(open, repo); !isErrorType(err, domain.ErrorInvalidTender) {
+		t.Errorf("Expected shares to be refused before the close, got %v", err)
+	}
+
+	closed := expiry.Add(time.Minute)
+	if _, _, found := repo[1].State.Increment(closed).TryGetAmountAndWinner(); found {
+		t.Errorf("Expected no winner before the reveal")
+	}
+
+	stranger := domain.SubmitKeyShareCommand{Time: closed, AuctionId: 1, Custodian: "a3", Share: shares["c1"]}
+	if _, _, err := domain.Handle(stranger, repo); !isErrorType(err, domain.ErrorNotACustodian) {
+		t.Errorf("Expected a share from a non-custodian to be refused, got %v", err)
+	}
+
+	// c2 mistypes their share by submitting c3's, then corrects it
+	submissions := []domain.SubmitKeyShareCommand{
+		{Time: closed, AuctionId: 1, Custodian: "c1", Share: shares["c1"]},
+		{Time: closed, AuctionId: 1, Custodian: "c2", Share: shares["c1"]},
+	}
+	for _, cmd := range submissions {
+		event, newRepo, err := domain.Handle(cmd, repo)
+		if err != nil {
+			t.Fatalf("Expected the share to be accepted, got %v", err)
+		}
+		events = append(events, event)
+		repo = newRepo
+	}
+	reveal := domain.RevealTenderCommand{Time: closed, AuctionId: 1}
+	if _, _, err := domain.Handle(reveal, repo); !isErrorType(err, domain.ErrorInvalidTender) {
+		t.Errorf("Expected wrong shares not to reveal the bids, got %v", err)
+	}
+
+	corrected := domain.SubmitKeyShareCommand{Time: closed, AuctionId: 1, Custodian: "c2", Share: shares["c2"]}
+	event, repo, err := domain.Handle(corrected, repo)
+	if err != nil {
+		t.Fatalf("Expected the corrected share to be accepted, got %v", err)
+	}
+	events = append(events, event)
+
+	event, repo, err = domain.Handle(reveal, repo)
+	if err != nil {
+		t.Fatalf("Expected the bids to be revealed, got %v", err)
+	}
+	revealed := event.(domain.TenderRevealedEvent)
+	if revealed.Amounts["b1"] != 100 || revealed.Amounts["b2"] != 250 || len(revealed.Invalid) != 1 || revealed.Invalid[0] != "b3" {
+		t.Errorf("Expected the decrypted amounts, got %+v", revealed)
+	}
+	events = append(events, event)
+
+	amount, winner, found := repo[1].State.TryGetAmountAndWinner()
+	if !found || winner != "b2" || amount != 100 {
+		t.Errorf("Expected b2 to win at the second price, got %d %s %v", amount, winner, found)
+	}
+	if _, _, err := domain.Handle(reveal, repo); !isErrorType(err, domain.ErrorInvalidTender) {
+		t.Errorf("Expected a second reveal to be refused, got %v", err)
+	}
+
+	// Replaying the events gives the same outcome without decrypting again
+	added := domain.AuctionAddedEvent{Time: startsAt, Auction: auction}
+	replayed := domain.EventsToAuctionStates(append([]domain.Event{added}, events...))
+	amount, winner, found = replayed[1].State.TryGetAmountAndWinner()
+	if !found || winner != "b2" || amount != 100 || !replayed[1].Auction.Tender.Revealed {
+		t.Errorf("Expected the replayed reveal to match, got %d %s %v", amount, winner, found)
+	}
+}
+
+func TestTenderValidation(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	tender, _, _ := dealTender(t)
+
+	tests := []struct {
+		name   string
+		typ    domain.AuctionType
+		tender domain.Tender
+	}{
+		{"NotSealed", domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), *tender},
+		{"InvalidKey", domain.NewSingleSealedBidType(domain.Blind), domain.Tender{PublicKey: "key", Threshold: 1, Custodians: []domain.UserId{"c1"}}},
+		{"ThresholdTooHigh", domain.NewSingleSealedBidType(domain.Blind), domain.Tender{PublicKey: tender.PublicKey, Threshold: 4, Custodians: tender.Custodians}},
+		{"DuplicateCustodians", domain.NewSingleSealedBidType(domain.Blind), domain.Tender{PublicKey: tender.PublicKey, Threshold: 1, Custodians: []domain.UserId{"c1", "c1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := domain.NewAuction(1, startsAt, "Road works", startsAt.Add(time.Hour), seller, tt.typ, domain.VAC)
+			tender := tt.tender
+			auction.Tender = &tender
+			_, _, err := domain.Handle(domain.AddAuctionCommand{Time: startsAt, Auction: auction}, domain.Repository{})
+			if !isErrorType(err, domain.ErrorInvalidTender) {
+				t.Errorf("Expected an invalid tender error, got %v", err)
+			}
+		})
+	}
+
+	sealed := domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: startsAt.Add(time.Second), Sealed: "AQID"}
+	auction := domain.NewAuction(1, startsAt, "Road works", startsAt.Add(time.Hour), seller, domain.NewSingleSealedBidType(domain.Blind), domain.VAC)
+	_, repo, _ := domain.Handle(domain.AddAuctionCommand{Time: startsAt, Auction: auction}, domain.Repository{})
+	if _, _, err := domain.Handle(domain.PlaceBidCommand{Time: sealed.At, Bid: sealed}, repo); !isErrorType(err, domain.ErrorInvalidTender) {
+		t.Errorf("Expected an encrypted bid on a plain auction to be rejected, got %v", err)
+	}
+}
+
+func isErrorType(err error, expected domain.ErrorType) bool {
+	domainErr, ok := err.(domain.DomainError)
+	return ok && domainErr.Type == expected
+}
@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+// priceChangedEvent is a test event whose "amount" field was renamed to "price"
+type priceChangedEvent struct {
+	Time  time.Time `json:"at"`
+	Price int64     `json:"price"`
+}
+
+func (e priceChangedEvent) GetTime() time.Time {
+	return e.Time
+}
+
+func (e priceChangedEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"$type": "PriceChanged", "at": e.Time, "price": e.Price})
+}
+
+func init() {
+	domain.RegisterEventType("PriceChanged", func(data []byte) (domain.Event, error) {
+		var evt struct {
+			Time  time.Time `json:"at"`
+			Price int64     `json:"price"`
+		}
+		err := json.Unmarshal(data, &evt)
+		return priceChangedEvent{Time: evt.Time, Price: evt.Price}, err
+	})
+	domain.RegisterEventUpcaster("PriceChanged", 1, func(fields map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+		fields["price"] = fields["amount"]
+		delete(fields, "amount")
+		return fields, nil
+	})
+}
+
+func TestEventUpcasting(t *testing.T) {
+	t.Run("UpcastsOldShape", func(t *testing.T) {
+		event, err := domain.UnmarshalEvent([]byte(`{"$type":"PriceChanged","at":"2020-01-01T00:00:00Z","amount":10}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if event.(priceChangedEvent).Price != 10 {
+			t.Errorf("Expected price 10, got %+v", event)
+		}
+	})
+
+	t.Run("StampsCurrentVersion", func(t *testing.T) {
+		data, err := domain.MarshalEvent(priceChangedEvent{Price: 12})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var fields map[string]interface{}
+		json.Unmarshal(data, &fields)
+		if fields["$version"] != float64(2) {
+			t.Errorf("Expected version 2, got %v", fields["$version"])
+		}
+
+		// Current events are not upcast again
+		event, err := domain.UnmarshalEvent(data)
+		if err != nil || event.(priceChangedEvent).Price != 12 {
+			t.Errorf("Expected price 12, got %+v %v", event, err)
+		}
+	})
+
+	t.Run("UnversionedTypesAreUnchanged", func(t *testing.T) {
+		data, _ := domain.MarshalEvent(domain.BidAcceptedEvent{})
+		var fields map[string]interface{}
+		json.Unmarshal(data, &fields)
+		if _, ok := fields["$version"]; ok {
+			t.Errorf("Expected no version on unversioned types, got %s", data)
+		}
+	})
+
+	t.Run("RejectsUnknownVersions", func(t *testing.T) {
+		if _, err := domain.UnmarshalEvent([]byte(`{"$type":"PriceChanged","$version":3,"price":1}`)); err == nil {
+			t.Errorf("Expected an error for a version from the future")
+		}
+	})
+}
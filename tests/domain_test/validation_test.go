@@ -0,0 +1,83 @@
+package domain_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestValidateCommand(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: at,
+		Title:    "auction",
+		Expiry:   at.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()),
+		Currency: domain.VAC,
+	}
+	bid := domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: at, Amount: 10}
+
+	fieldErrors := func(err error) []domain.FieldError {
+		if !isErrorType(err, domain.ErrorInvalidCommand) {
+			t.Fatalf("Expected an InvalidCommand error, got %v", err)
+		}
+		return err.(domain.DomainError).Data.(map[string]interface{})["errors"].([]domain.FieldError)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		if err := domain.ValidateCommand(domain.AddAuctionCommand{Time: at, Auction: auction}); err != nil {
+			t.Errorf("Expected the auction valid, got %v", err)
+		}
+		if err := domain.ValidateCommand(domain.PlaceBidCommand{Time: at, Bid: bid}); err != nil {
+			t.Errorf("Expected the bid valid, got %v", err)
+		}
+	})
+
+	t.Run("AddAuction", func(t *testing.T) {
+		invalid := auction
+		invalid.Title = " "
+		invalid.Expiry = at
+		invalid.Currency = "XYZ"
+		invalid.Type = domain.NewTimedAscendingType(domain.TimedAscendingOptions{MinRaise: -1})
+		expected := []domain.FieldError{
+			{Field: "auction.title", Code: domain.FieldRequired},
+			{Field: "auction.expiry", Code: domain.FieldMustBeAfter, Ref: "auction.startsAt"},
+			{Field: "auction.currency", Code: domain.FieldUnknown},
+			{Field: "auction.type", Code: domain.FieldMustNotBeNegative},
+		}
+		if errors := fieldErrors(domain.ValidateCommand(domain.AddAuctionCommand{Time: at, Auction: invalid})); !reflect.DeepEqual(errors, expected) {
+			t.Errorf("Expected %v, got %v", expected, errors)
+		}
+	})
+
+	t.Run("PlaceBid", func(t *testing.T) {
+		invalid := bid
+		invalid.Amount = 0
+		errors := fieldErrors(domain.ValidateCommand(domain.PlaceBidCommand{Time: at, Bid: invalid}))
+		if len(errors) != 1 || errors[0].Field != "bid.amount" || errors[0].Code != domain.FieldMustBePositive {
+			t.Errorf("Expected the amount invalid, got %v", errors)
+		}
+
+		// The amount of an encrypted bid is unknown until revealed
+		invalid.Sealed = "ciphertext"
+		if err := domain.ValidateCommand(domain.PlaceBidCommand{Time: at, Bid: invalid}); err != nil {
+			t.Errorf("Expected the sealed bid valid, got %v", err)
+		}
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		bus := domain.NewCommandBus(domain.ValidateCommands())
+		invalid := bid
+		invalid.Amount = -5
+		repo := domain.Repository{}
+		_, next, err := bus.Dispatch(context.Background(), domain.PlaceBidCommand{Time: at, Bid: invalid}, repo)
+		if !isErrorType(err, domain.ErrorInvalidCommand) || len(next) != 0 {
+			t.Errorf("Expected the bid rejected before handling, got %v", err)
+		}
+	})
+}
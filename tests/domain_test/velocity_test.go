@@ -0,0 +1,36 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestBidVelocity(t *testing.T) {
+	from := sampleStartsAt
+	bids := []domain.Bid{
+		domain.NewBid(sampleAuctionId, buyer1, from.Add(10*time.Minute), 10),
+		domain.NewBid(sampleAuctionId, buyer2, from.Add(70*time.Minute), 12),
+		domain.NewBid(sampleAuctionId, buyer1, from.Add(80*time.Minute), 14),
+		domain.NewBid(sampleAuctionId, buyer1, from.Add(90*time.Minute), 16),
+	}
+
+	velocity := domain.ComputeBidVelocity(bids, from, from.Add(2*time.Hour), time.Hour)
+
+	if len(velocity.Buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", len(velocity.Buckets))
+	}
+	if velocity.Buckets[0].Bids != 1 || velocity.Buckets[0].UniqueBidders != 1 {
+		t.Errorf("Expected 1 bid by 1 bidder in the first hour, got %+v", velocity.Buckets[0])
+	}
+	if velocity.Buckets[1].Bids != 3 || velocity.Buckets[1].UniqueBidders != 2 {
+		t.Errorf("Expected 3 bids by 2 bidders in the second hour, got %+v", velocity.Buckets[1])
+	}
+	if velocity.TotalBids != 4 || velocity.UniqueBidders != 2 {
+		t.Errorf("Expected 4 bids by 2 bidders in total, got %d by %d", velocity.TotalBids, velocity.UniqueBidders)
+	}
+	if !velocity.IsHot(3) || velocity.IsHot(4) {
+		t.Errorf("Expected the auction to be hot at 3 bids per hour only")
+	}
+}
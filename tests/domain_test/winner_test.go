@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+)
+
+func TestDetermineWinner(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	auction := domain.Auction{
+		ID:       1,
+		StartsAt: start,
+		Title:    "painting",
+		Expiry:   start.Add(time.Hour),
+		Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+		Type:     domain.NewSingleSealedBidType(domain.Vickrey),
+		Currency: domain.VAC,
+	}
+	bid := func(bidder domain.UserId, amount int64) domain.Event {
+		at := start.Add(time.Minute)
+		return domain.BidAcceptedEvent{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller(bidder, "Buyer"), At: at, Amount: amount}}
+	}
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: start, Auction: auction},
+		bid("a2", 30),
+		bid("a3", 20),
+	})
+	closed := auction.Expiry.Add(time.Second)
+
+	t.Run("NotBeforeTheClose", func(t *testing.T) {
+		_, _, err := domain.Handle(domain.DetermineWinnerCommand{Time: start.Add(2 * time.Minute), AuctionId: 1}, repo)
+		if !isErrorType(err, domain.ErrorWinnerNotDeterminable) {
+			t.Errorf("expected WinnerNotDeterminable, got %v", err)
+		}
+	})
+
+	t.Run("WinnerPaysTheSecondPrice", func(t *testing.T) {
+		event, newRepo, err := domain.Handle(domain.DetermineWinnerCommand{Time: closed, AuctionId: 1}, repo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		determined := event.(domain.WinnerDeterminedEvent)
+		if determined.Winner != "a2" || determined.Price != 20 || determined.Bids != 2 {
+			t.Errorf("expected a2 to win at 20 of 2 bids, got %+v", determined)
+		}
+
+		entry := newRepo[1]
+		if domain.AwaitingWinner(entry.Auction, entry.State, closed) {
+			t.Error("expected the winner determined")
+		}
+		if _, _, err := domain.Handle(domain.DetermineWinnerCommand{Time: closed, AuctionId: 1}, newRepo); !isErrorType(err, domain.ErrorWinnerNotDeterminable) {
+			t.Errorf("expected WinnerNotDeterminable, got %v", err)
+		}
+
+		// Restoring from a snapshot keeps the winner determined
+		snapshots, err := domain.SnapshotRepository(newRepo)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		restored, err := domain.RestoreRepository(snapshots)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if entry := restored[1]; domain.AwaitingWinner(entry.Auction, entry.State, closed) {
+			t.Error("expected the winner still determined after restoring")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestAnonymizeEvents(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	events := []domain.Event{
+		sampleAuctionAdded(1, now),
+		sampleBidAccepted(1, now.Add(time.Minute), 110),
+		sampleBidAccepted(1, now.Add(2*time.Minute), 150),
+		sampleBidAccepted(1, now.Add(3*time.Minute), 950),
+	}
+	options := persistence.AnonymizeOptions{Salt: "salt", SampleRate: 1, AmountBucket: 100, K: 2}
+
+	dataset := persistence.AnonymizeEvents(events, options)
+
+	t.Run("SuppressesSmallGroups", func(t *testing.T) {
+		if len(dataset.Bids) != 2 || dataset.Suppressed != 1 {
+			t.Fatalf("Expected 2 bids and 1 suppressed, got %d and %d", len(dataset.Bids), dataset.Suppressed)
+		}
+	})
+
+	t.Run("BucketsAndPseudonymizes", func(t *testing.T) {
+		bid := dataset.Bids[0]
+		if bid.AmountBucket != 100 || bid.OffsetSeconds != 60 {
+			t.Errorf("Expected amount bucket 100 at offset 60s, got %+v", bid)
+		}
+		if bid.Bidder == "" || bid.Bidder == "buyer" || bid.Auction == "1" {
+			t.Errorf("Expected pseudonymized IDs, got %+v", bid)
+		}
+	})
+
+	t.Run("SamplesByAuction", func(t *testing.T) {
+		options.SampleRate = 0
+		if sampled := persistence.AnonymizeEvents(events, options); len(sampled.Bids) != 0 {
+			t.Errorf("Expected no bids at sample rate 0, got %d", len(sampled.Bids))
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package persistence_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// flakyStore is a store whose event writes fail while down is set
+type flakyStore struct {
+	countingStore
+	down bool
+}
+
+func (s *flakyStore) WriteEvents(events []domain.Event) error {
+	if s.down {
+		return errors.New("store down")
+	}
+	return s.countingStore.WriteEvents(events)
+}
+
+func TestBufferedStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	t.Run("FlushesWhenFull", func(t *testing.T) {
+		inner := &batchRecordingStore{}
+		store := persistence.NewBufferedStore(inner, time.Hour, 3)
+
+		for i := 1; i <= 3; i++ {
+			if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(domain.AuctionId(i), now)}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if i < 3 && len(inner.batches) != 0 {
+				t.Fatalf("Expected events to stay buffered, got %d batches", len(inner.batches))
+			}
+		}
+		if len(inner.batches) != 1 || inner.batches[0] != 3 {
+			t.Errorf("Expected a single batch of 3 events, got %v", inner.batches)
+		}
+	})
+
+	t.Run("FlushesAfterInterval", func(t *testing.T) {
+		inner := &batchRecordingStore{}
+		store := persistence.NewBufferedStore(inner, 10*time.Millisecond, 100)
+		store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)})
+
+		deadline := time.Now().Add(time.Second)
+		for store.Buffered() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if store.Buffered() != 0 {
+			t.Errorf("Expected the buffer to be flushed after the interval")
+		}
+	})
+
+	t.Run("ReadsSeeBufferedEvents", func(t *testing.T) {
+		store := persistence.NewBufferedStore(&countingStore{}, time.Hour, 100)
+		store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)})
+
+		events, err := store.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(events) != 1 {
+			t.Errorf("Expected 1 event, got %d", len(events))
+		}
+	})
+
+	t.Run("KeepsEventsWhenFlushFails", func(t *testing.T) {
+		inner := &flakyStore{down: true}
+		store := persistence.NewBufferedStore(inner, time.Hour, 100)
+
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+			t.Fatalf("Expected the event to be buffered, got %v", err)
+		}
+		if err := store.Flush(); err == nil {
+			t.Fatalf("Expected the flush to fail")
+		}
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(2, now)}); err == nil {
+			t.Errorf("Expected new events to be refused while the store is down")
+		}
+
+		inner.down = false
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(3, now)}); err != nil {
+			t.Fatalf("Expected no error once the store is back, got %v", err)
+		}
+		store.Flush()
+
+		if len(inner.events) != 2 {
+			t.Fatalf("Expected 2 events, got %d", len(inner.events))
+		}
+		for i, id := range []domain.AuctionId{1, 3} {
+			if got, _ := domain.EventAuctionId(inner.events[i]); got != id {
+				t.Errorf("Expected auction %d at %d, got %d", id, i, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// blockingStore blocks event reads until released
+type blockingStore struct {
+	countingStore
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) ReadEvents() ([]domain.Event, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return nil, nil
+}
+
+func TestBulkheadStore(t *testing.T) {
+	inner := &blockingStore{started: make(chan struct{}), release: make(chan struct{})}
+	store := persistence.NewBulkheadStore(inner, persistence.BulkheadOptions{
+		MaxConcurrentReads:  1,
+		MaxConcurrentWrites: 1,
+		MaxQueuedReads:      0,
+		MaxQueuedWrites:     0,
+		QueueTimeout:        10 * time.Millisecond,
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := store.ReadEvents()
+		done <- err
+	}()
+	<-inner.started
+
+	t.Run("RejectsReadsBeyondLimit", func(t *testing.T) {
+		if _, err := store.ReadEvents(); err != persistence.ErrStoreOverloaded {
+			t.Errorf("Expected ErrStoreOverloaded, got %v", err)
+		}
+	})
+
+	t.Run("WritesAreNotBlockedByReads", func(t *testing.T) {
+		if err := store.WriteEvents(nil); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	close(inner.release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error from the first read, got %v", err)
+	}
+}
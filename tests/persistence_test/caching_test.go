@@ -0,0 +1,175 @@
+package persistence_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// countingStore is an in-memory store that counts event reads
+type countingStore struct {
+	commands   []domain.Command
+	events     []domain.Event
+	eventReads int
+}
+
+func (s *countingStore) ReadCommands() ([]domain.Command, error) {
+	return s.commands, nil
+}
+
+func (s *countingStore) WriteCommands(commands []domain.Command) error {
+	s.commands = append(s.commands, commands...)
+	return nil
+}
+
+func (s *countingStore) ReadEvents() ([]domain.Event, error) {
+	s.eventReads++
+	return s.events, nil
+}
+
+func (s *countingStore) ReadEventsSince(position int64) ([]domain.Event, error) {
+	s.eventReads++
+	return s.events[position:], nil
+}
+
+func (s *countingStore) WriteEvents(events []domain.Event) error {
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *countingStore) ReadLatestSnapshot() (*persistence.Snapshot, error) {
+	return nil, nil
+}
+
+func (s *countingStore) WriteSnapshot(snapshot persistence.Snapshot) error {
+	return nil
+}
+
+// pausingStore is an in-memory store whose first event read waits to be
+// released, returning the events stored when it started
+type pausingStore struct {
+	*persistence.MemoryStore
+	once    sync.Once
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (s *pausingStore) ReadEvents() ([]domain.Event, error) {
+	events, err := s.MemoryStore.ReadEvents()
+	s.once.Do(func() {
+		close(s.reading)
+		<-s.release
+	})
+	return events, err
+}
+
+func sampleAuctionAdded(id domain.AuctionId, at time.Time) domain.AuctionAddedEvent {
+	return domain.AuctionAddedEvent{
+		Time: at,
+		Auction: domain.Auction{
+			ID:       id,
+			StartsAt: at,
+			Title:    "auction",
+			Expiry:   at.Add(time.Hour),
+			Seller:   domain.NewBuyerOrSeller("seller", "Seller"),
+			Type:     domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()),
+			Currency: domain.VAC,
+		},
+	}
+}
+
+func TestCachingStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	getCurrentTime := func() time.Time { return now }
+
+	t.Run("CachesAuctionStreams", func(t *testing.T) {
+		inner := &countingStore{events: []domain.Event{sampleAuctionAdded(1, now)}}
+		store := persistence.NewCachingStore(inner, time.Minute, time.Minute, getCurrentTime)
+
+		for i := 0; i < 3; i++ {
+			events, err := store.ReadAuctionEvents(1)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(events))
+			}
+		}
+
+		if inner.eventReads != 1 {
+			t.Errorf("Expected 1 read of the underlying store, got %d", inner.eventReads)
+		}
+	})
+
+	t.Run("CachesUnknownAuctions", func(t *testing.T) {
+		inner := &countingStore{}
+		store := persistence.NewCachingStore(inner, time.Minute, time.Minute, getCurrentTime)
+
+		for i := 0; i < 3; i++ {
+			events, _ := store.ReadAuctionEvents(42)
+			if len(events) != 0 {
+				t.Fatalf("Expected no events, got %d", len(events))
+			}
+		}
+
+		if inner.eventReads != 1 {
+			t.Errorf("Expected 1 read of the underlying store, got %d", inner.eventReads)
+		}
+	})
+
+	t.Run("ExpiresEntries", func(t *testing.T) {
+		current := now
+		inner := &countingStore{}
+		store := persistence.NewCachingStore(inner, time.Minute, time.Second, func() time.Time { return current })
+
+		store.ReadAuctionEvents(42)
+		current = current.Add(2 * time.Second)
+		store.ReadAuctionEvents(42)
+
+		if inner.eventReads != 2 {
+			t.Errorf("Expected expired negative entry to be reloaded, got %d reads", inner.eventReads)
+		}
+	})
+
+	t.Run("InvalidatesOnAppend", func(t *testing.T) {
+		inner := &countingStore{}
+		store := persistence.NewCachingStore(inner, time.Minute, time.Minute, getCurrentTime)
+
+		store.ReadAuctionEvents(1)
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		events, _ := store.ReadAuctionEvents(1)
+		if len(events) != 1 {
+			t.Errorf("Expected appended event to be visible, got %d events", len(events))
+		}
+	})
+
+	t.Run("DoesNotCacheReadsRacingWrites", func(t *testing.T) {
+		inner := &pausingStore{MemoryStore: persistence.NewMemoryStore(), reading: make(chan struct{}), release: make(chan struct{})}
+		store := persistence.NewCachingStore(inner, time.Minute, time.Minute, getCurrentTime)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			store.ReadAuctionEvents(1)
+		}()
+
+		// The read saw no auction, which is written before it completes
+		<-inner.reading
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		close(inner.release)
+		<-done
+
+		events, _ := store.ReadAuctionEvents(1)
+		if len(events) != 1 {
+			t.Errorf("Expected the written event to be visible, got %d events", len(events))
+		}
+	})
+}
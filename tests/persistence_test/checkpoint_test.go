@@ -0,0 +1,130 @@
+package persistence_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// recordingProjection records the positions it's applied at, failing at failAt
+type recordingProjection struct {
+	name      string
+	positions []int64
+	failAt    int64
+}
+
+func (p *recordingProjection) Name() string {
+	return p.name
+}
+
+func (p *recordingProjection) Apply(position int64, event domain.Event) error {
+	if position == p.failAt {
+		return errors.New("failed")
+	}
+	p.positions = append(p.positions, position)
+	return nil
+}
+
+func TestCheckpoints(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	newStore := func(t *testing.T, n int) persistence.Store {
+		store := persistence.NewMemoryStore()
+		for i := 1; i <= n; i++ {
+			if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(domain.AuctionId(i), now)}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		return store
+	}
+
+	t.Run("ResumesAfterReopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "checkpoints.json")
+		store := newStore(t, 3)
+
+		checkpoints, err := persistence.OpenCheckpoints(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := persistence.CatchUp(store, checkpoints, &recordingProjection{name: "reports"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(4, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		reopened, err := persistence.OpenCheckpoints(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		projection := &recordingProjection{name: "reports"}
+		result, err := persistence.CatchUp(store, reopened, projection)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Replayed != 1 || len(projection.positions) != 1 || projection.positions[0] != 4 {
+			t.Errorf("Expected only position 4 to be processed, got %v", projection.positions)
+		}
+		if reopened.Position("reports") != 4 {
+			t.Errorf("Expected checkpoint 4, got %d", reopened.Position("reports"))
+		}
+	})
+
+	t.Run("TracksProjectionsSeparately", func(t *testing.T) {
+		checkpoints, err := persistence.OpenCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := checkpoints.Save("old", 2); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		old := &recordingProjection{name: "old"}
+		fresh := &recordingProjection{name: "new"}
+		if _, err := persistence.CatchUp(newStore(t, 3), checkpoints, old, fresh); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(old.positions) != 1 || len(fresh.positions) != 3 {
+			t.Errorf("Expected 1 and 3 events processed, got %v and %v", old.positions, fresh.positions)
+		}
+	})
+
+	t.Run("SavesProgressOnError", func(t *testing.T) {
+		checkpoints, err := persistence.OpenCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := persistence.CatchUp(newStore(t, 3), checkpoints, &recordingProjection{name: "reports", failAt: 3}); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if checkpoints.Position("reports") != 2 {
+			t.Errorf("Expected checkpoint 2, got %d", checkpoints.Position("reports"))
+		}
+	})
+
+	t.Run("ResetRebuildsFromStart", func(t *testing.T) {
+		checkpoints, err := persistence.OpenCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		store := newStore(t, 2)
+		if _, err := persistence.CatchUp(store, checkpoints, &recordingProjection{name: "reports"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := checkpoints.Reset("reports"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		projection := &recordingProjection{name: "reports"}
+		if _, err := persistence.CatchUp(store, checkpoints, projection); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(projection.positions) != 2 {
+			t.Errorf("Expected 2 events processed, got %v", projection.positions)
+		}
+	})
+}
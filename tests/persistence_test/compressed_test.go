@@ -0,0 +1,52 @@
+package persistence_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestCompressingStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	dir := t.TempDir()
+	fileStore := persistence.NewFileStore(filepath.Join(dir, "commands.jsonl"), filepath.Join(dir, "events.jsonl"), filepath.Join(dir, "snapshots.jsonl"))
+
+	// Written before compression was enabled
+	if err := fileStore.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	store := persistence.NewCompressingStore(fileStore, 512)
+	large := sampleAuctionAdded(2, now)
+	large.Auction.Title = strings.Repeat("a very long description ", 100)
+	if err := store.WriteEvents([]domain.Event{large, sampleBidAccepted(1, now, 10)}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("CompressesLargeEventsOnly", func(t *testing.T) {
+		stored, _ := fileStore.ReadEvents()
+		if _, ok := stored[1].(persistence.CompressedEvent); !ok {
+			t.Errorf("Expected the large event to be compressed, got %T", stored[1])
+		}
+		if _, ok := stored[2].(domain.BidAcceptedEvent); !ok {
+			t.Errorf("Expected the small event to be stored as is, got %T", stored[2])
+		}
+	})
+
+	t.Run("ReadsOldAndCompressedEvents", func(t *testing.T) {
+		events, err := store.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("Expected 3 events, got %d", len(events))
+		}
+		if added, ok := events[1].(domain.AuctionAddedEvent); !ok || added.Auction.Title != large.Auction.Title {
+			t.Errorf("Expected the large event to be decompressed, got %#v", events[1])
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package persistence_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"auction-site-go/internal/persistence"
+	"auction-site-go/internal/persistence/storetest"
+)
+
+func TestStoreConformance(t *testing.T) {
+	t.Run("MemoryStore", func(t *testing.T) {
+		storetest.Run(t, func(t *testing.T) persistence.Store {
+			return persistence.NewMemoryStore()
+		}, storetest.Options{})
+	})
+
+	newFileStore := func(t *testing.T) *persistence.FileStore {
+		dir := t.TempDir()
+		return persistence.NewFileStore(filepath.Join(dir, "commands.jsonl"), filepath.Join(dir, "events.jsonl"), filepath.Join(dir, "snapshots.jsonl"))
+	}
+
+	t.Run("FileStore", func(t *testing.T) {
+		storetest.Run(t, func(t *testing.T) persistence.Store {
+			return newFileStore(t)
+		}, storetest.Options{})
+	})
+
+	t.Run("DailyFileStore", func(t *testing.T) {
+		storetest.Run(t, func(t *testing.T) persistence.Store {
+			return persistence.NewDailyFileStore(t.TempDir())
+		}, storetest.Options{})
+	})
+
+	t.Run("CompressingStore", func(t *testing.T) {
+		storetest.Run(t, func(t *testing.T) persistence.Store {
+			return persistence.NewCompressingStore(newFileStore(t), 100)
+		}, storetest.Options{})
+	})
+
+	t.Run("EncryptedStore", func(t *testing.T) {
+		secrets, err := persistence.ParseSecretKeys("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+		if err != nil {
+			t.Fatalf("Failed to parse keys: %v", err)
+		}
+		storetest.Run(t, func(t *testing.T) persistence.Store {
+			return persistence.NewEncryptedStore(newFileStore(t), secrets)
+		}, storetest.Options{})
+	})
+}
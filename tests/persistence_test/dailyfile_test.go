@@ -0,0 +1,83 @@
+package persistence_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestDailyFileStore(t *testing.T) {
+	day1, _ := time.Parse(time.RFC3339, "2020-01-01T23:00:00Z")
+	day2 := day1.Add(2 * time.Hour)
+
+	dir := t.TempDir()
+	store := persistence.NewDailyFileStore(dir)
+
+	events := []domain.Event{sampleAuctionAdded(1, day1), sampleAuctionAdded(2, day2), sampleAuctionAdded(3, day2)}
+	if err := store.WriteEvents(events); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("WritesOneFilePerDay", func(t *testing.T) {
+		for _, name := range []string{"events-2020-01-01.jsonl", "events-2020-01-02.jsonl"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("Expected %s to exist, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("ReadEventsSinceAcrossDays", func(t *testing.T) {
+		all, err := store.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(all) != 3 {
+			t.Fatalf("Expected 3 events, got %d", len(all))
+		}
+
+		since, err := store.ReadEventsSince(2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(since) != 1 {
+			t.Fatalf("Expected 1 event, got %d", len(since))
+		}
+		if id, _ := domain.EventAuctionId(since[0]); id != 3 {
+			t.Errorf("Expected auction 3 after position 2, got %d", id)
+		}
+	})
+
+	t.Run("LateEventsKeepWriteOrder", func(t *testing.T) {
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(4, day1)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		since, err := store.ReadEventsSince(3)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(since) != 1 {
+			t.Fatalf("Expected 1 event, got %d", len(since))
+		}
+		if id, _ := domain.EventAuctionId(since[0]); id != 4 {
+			t.Errorf("Expected auction 4 last, got %d", id)
+		}
+	})
+
+	t.Run("Commands", func(t *testing.T) {
+		cmd := domain.AddAuctionCommand{Time: day1, Auction: sampleAuctionAdded(5, day1).Auction}
+		if err := store.WriteCommands([]domain.Command{cmd}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		commands, err := store.ReadCommands()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(commands) != 1 {
+			t.Errorf("Expected 1 command, got %d", len(commands))
+		}
+	})
+}
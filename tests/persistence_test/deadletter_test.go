@@ -0,0 +1,59 @@
+package persistence_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestDeadLetters(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	path := filepath.Join(t.TempDir(), "dead_letters.jsonl")
+	bid := domain.PlaceBidCommand{Time: now, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: now, Amount: 10}}
+
+	letters, err := persistence.OpenDeadLetters(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first, err := letters.Add(now, bid, domain.NewAuctionNotFoundError(1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, _ := letters.Add(now, bid, errors.New("store unavailable"))
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("Expected sequential IDs, got %d and %d", first.ID, second.ID)
+	}
+
+	if _, err := letters.Retried(1, now.Add(time.Minute), errors.New("still failing")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := letters.Retried(2, now.Add(time.Minute), nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := letters.Retried(3, now, nil); err == nil {
+		t.Errorf("Expected an unknown dead letter to fail")
+	}
+
+	// Reopening gives the latest version of each dead letter
+	reopened, err := persistence.OpenDeadLetters(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	open := reopened.List(false)
+	if len(open) != 1 || open[0].ID != 1 || open[0].Retries != 1 || open[0].Error != "still failing" {
+		t.Errorf("Expected the unresolved dead letter, got %+v", open)
+	}
+	if command, ok := open[0].Command.(domain.PlaceBidCommand); !ok || command.Bid.Amount != 10 {
+		t.Errorf("Expected the command to round trip, got %+v", open[0].Command)
+	}
+	if all := reopened.List(true); len(all) != 2 || !all[1].Resolved {
+		t.Errorf("Expected both dead letters, got %+v", all)
+	}
+	if third, _ := reopened.Add(now, bid, errors.New("failed")); third.ID != 3 {
+		t.Errorf("Expected IDs to continue, got %d", third.ID)
+	}
+}
@@ -0,0 +1,91 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestCheckDeterminism(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	added := sampleAuctionAdded(1, now)
+	bid := sampleBidAccepted(1, now.Add(time.Minute), 10)
+
+	newStore := func(t *testing.T, commands []domain.Command, events []domain.Event) persistence.Store {
+		store := persistence.NewMemoryStore()
+		if err := store.WriteCommands(commands); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := store.WriteEvents(events); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return store
+	}
+	commands := []domain.Command{
+		domain.AddAuctionCommand{Time: added.Time, Auction: added.Auction},
+		domain.PlaceBidCommand{Time: bid.Time, Bid: bid.Bid},
+		// Rejected, since the auction already exists
+		domain.AddAuctionCommand{Time: added.Time, Auction: added.Auction},
+	}
+
+	t.Run("ReproducesStoredEvents", func(t *testing.T) {
+		report, err := persistence.CheckDeterminism(newStore(t, commands, []domain.Event{added, bid}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !report.Deterministic() {
+			t.Errorf("Expected no divergences, got %v", report.Divergences)
+		}
+		if report.Commands != 3 || report.Events != 2 {
+			t.Errorf("Expected 3 commands and 2 events, got %d and %d", report.Commands, report.Events)
+		}
+	})
+
+	t.Run("ReportsChangedEvents", func(t *testing.T) {
+		changed := bid
+		changed.Bid.Amount = 20
+		report, err := persistence.CheckDeterminism(newStore(t, commands, []domain.Event{added, changed}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(report.Divergences) != 1 || report.Divergences[0].Command != 2 || report.Divergences[0].Event != 2 {
+			t.Errorf("Expected a divergence between command 2 and event 2, got %v", report.Divergences)
+		}
+	})
+
+	t.Run("ReportsMissingAndExtraEvents", func(t *testing.T) {
+		extra := sampleBidAccepted(1, now.Add(2*time.Minute), 30)
+		report, err := persistence.CheckDeterminism(newStore(t, commands[:1], []domain.Event{added, extra}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(report.Divergences) != 1 || report.Divergences[0].Command != 0 || report.Divergences[0].Event != 2 {
+			t.Errorf("Expected event 2 to be reported as not produced, got %v", report.Divergences)
+		}
+
+		report, err = persistence.CheckDeterminism(newStore(t, commands, []domain.Event{added}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(report.Divergences) != 1 || report.Divergences[0].Command != 2 || report.Divergences[0].Event != 0 {
+			t.Errorf("Expected command 2 to be reported as producing a missing event, got %v", report.Divergences)
+		}
+	})
+
+	t.Run("LeavesOutListingsRejectedByModeration", func(t *testing.T) {
+		moderated := domain.ListingModeratedEvent{
+			Time:      now,
+			AuctionId: 1,
+			Decision:  domain.ModerationDecision{Verdict: domain.ModerationRejected, Provider: "keywords"},
+		}
+		report, err := persistence.CheckDeterminism(newStore(t, commands[:2], []domain.Event{moderated}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !report.Deterministic() {
+			t.Errorf("Expected no divergences, got %v", report.Divergences)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package persistence_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	dir := t.TempDir()
+	fileStore := persistence.NewFileStore(filepath.Join(dir, "commands.jsonl"), filepath.Join(dir, "events.jsonl"), filepath.Join(dir, "snapshots.jsonl"))
+
+	secrets, err := persistence.ParseSecretKeys("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		t.Fatalf("Failed to parse keys: %v", err)
+	}
+	store := persistence.NewEncryptedStore(fileStore, secrets)
+
+	events := []domain.Event{sampleAuctionAdded(1, now), sampleBidAccepted(1, now.Add(time.Second), 10)}
+	if err := store.WriteEvents(events); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("PayloadIsNotStoredInClear", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(dir, "events.jsonl"))
+		if err != nil {
+			t.Fatalf("Failed to read events file: %v", err)
+		}
+		if bytes.Contains(data, []byte("BuyerOrSeller")) {
+			t.Errorf("Expected user data to be encrypted, got %s", data)
+		}
+	})
+
+	t.Run("RoundTrips", func(t *testing.T) {
+		read, err := store.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(read) != 2 {
+			t.Fatalf("Expected 2 events, got %d", len(read))
+		}
+		bid, ok := read[1].(domain.BidAcceptedEvent)
+		if !ok || bid.Bid.Amount != 10 || bid.Bid.Bidder.ID != "buyer" {
+			t.Errorf("Expected decrypted bid event, got %#v", read[1])
+		}
+	})
+
+	t.Run("SealsSnapshotReadModels", func(t *testing.T) {
+		models := domain.ReadModels{Descriptions: domain.Descriptions{1: "Mint condition"}}
+		activity := domain.NewActivityFeed().Snapshot()
+		if err := store.WriteSnapshot(persistence.Snapshot{Position: 2, ReadModels: &models, Activity: &activity}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "snapshots.jsonl"))
+		if err != nil {
+			t.Fatalf("Failed to read snapshots file: %v", err)
+		}
+		if bytes.Contains(data, []byte("Mint condition")) {
+			t.Errorf("Expected read models to be encrypted, got %s", data)
+		}
+
+		snapshot, err := store.ReadLatestSnapshot()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if snapshot.ReadModels == nil || snapshot.ReadModels.Descriptions[1] != "Mint condition" || snapshot.Activity == nil {
+			t.Errorf("Expected decrypted read models, got %+v", snapshot)
+		}
+	})
+
+	t.Run("ReadsWithRotatedKeys", func(t *testing.T) {
+		rotated, _ := persistence.ParseSecretKeys("k2:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=,k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+		rotatedStore := persistence.NewEncryptedStore(fileStore, rotated)
+		if err := rotatedStore.WriteEvents([]domain.Event{sampleBidAccepted(1, now.Add(2*time.Second), 12)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		read, err := rotatedStore.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(read) != 3 {
+			t.Errorf("Expected 3 events, got %d", len(read))
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package persistence_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestErasableStore(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	added := sampleAuctionAdded(1, at)
+	bid := sampleBidAccepted(1, at, 10)
+
+	t.Run("ForgetsUsers", func(t *testing.T) {
+		inner := persistence.NewMemoryStore()
+		keys, _ := persistence.OpenUserKeys("")
+		store := persistence.NewErasableStore(inner, keys)
+		if err := store.WriteEvents([]domain.Event{added, bid}); err != nil {
+			t.Fatalf("Failed to write events: %v", err)
+		}
+		if added.Auction.Seller.Name != "Seller" {
+			t.Errorf("Expected the written event unchanged, got %s", added.Auction.Seller.Name)
+		}
+
+		stored, _ := inner.ReadEvents()
+		if name := stored[0].(domain.AuctionAddedEvent).Auction.Seller.Name; !strings.HasPrefix(name, "pii:") {
+			t.Errorf("Expected the stored name encrypted, got %s", name)
+		}
+
+		events, err := store.ReadEvents()
+		if err != nil {
+			t.Fatalf("Failed to read events: %v", err)
+		}
+		if !reflect.DeepEqual(events, []domain.Event{added, bid}) {
+			t.Errorf("Expected the events as written, got %v", events)
+		}
+
+		if err := keys.Forget("seller"); err != nil {
+			t.Fatalf("Failed to forget the seller: %v", err)
+		}
+		events, _ = store.ReadEventsSince(0)
+		if name := events[0].(domain.AuctionAddedEvent).Auction.Seller.Name; name != domain.ForgottenName {
+			t.Errorf("Expected the seller forgotten, got %s", name)
+		}
+		if !reflect.DeepEqual(events[1], domain.Event(bid)) {
+			t.Errorf("Expected the bidder kept, got %v", events[1])
+		}
+	})
+
+	t.Run("Snapshots", func(t *testing.T) {
+		inner := persistence.NewMemoryStore()
+		keys, _ := persistence.OpenUserKeys("")
+		store := persistence.NewErasableStore(inner, keys)
+		repo := domain.ApplyEvents(domain.Repository{}, []domain.Event{added})
+		auctions, _ := domain.SnapshotRepository(repo)
+		if err := store.WriteSnapshot(persistence.Snapshot{Position: 1, Auctions: auctions}); err != nil {
+			t.Fatalf("Failed to write snapshot: %v", err)
+		}
+
+		stored, _ := inner.ReadLatestSnapshot()
+		if name := stored.Auctions[0].Auction.Seller.Name; !strings.HasPrefix(name, "pii:") {
+			t.Errorf("Expected the stored name encrypted, got %s", name)
+		}
+		snapshot, err := store.ReadLatestSnapshot()
+		if err != nil {
+			t.Fatalf("Failed to read snapshot: %v", err)
+		}
+		if name := snapshot.Auctions[0].Auction.Seller.Name; name != "Seller" {
+			t.Errorf("Expected the seller's name, got %s", name)
+		}
+	})
+
+	t.Run("PlaintextNames", func(t *testing.T) {
+		inner := persistence.NewMemoryStore()
+		inner.WriteEvents([]domain.Event{added})
+		keys, _ := persistence.OpenUserKeys("")
+		events, err := persistence.NewErasableStore(inner, keys).ReadEvents()
+		if err != nil || !reflect.DeepEqual(events, []domain.Event{added}) {
+			t.Errorf("Expected names written before erasure unchanged, got %v, %v", events, err)
+		}
+	})
+}
+
+func TestUserKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user_keys.json")
+	keys, err := persistence.OpenUserKeys(path)
+	if err != nil {
+		t.Fatalf("Failed to open user keys: %v", err)
+	}
+	key, err := keys.KeyFor("a1")
+	if err != nil || len(key) != 32 {
+		t.Fatalf("Expected a 32 byte key, got %v, %v", key, err)
+	}
+	keys.KeyFor("a2")
+
+	if err := keys.Forget("a1"); err != nil {
+		t.Fatalf("Failed to forget: %v", err)
+	}
+	reopened, err := persistence.OpenUserKeys(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen user keys: %v", err)
+	}
+	if _, ok := reopened.Key("a1"); ok {
+		t.Errorf("Expected the key of a1 deleted")
+	}
+	if _, ok := reopened.Key("a2"); !ok {
+		t.Errorf("Expected the key of a2 kept")
+	}
+}
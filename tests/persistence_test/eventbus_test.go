@@ -0,0 +1,81 @@
+package persistence_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestEventBus(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	added := sampleAuctionAdded(1, at)
+	bid := domain.BidAcceptedEvent{Time: at, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: at, Amount: 10}}
+
+	t.Run("FiltersByType", func(t *testing.T) {
+		bus := persistence.NewEventBus()
+		var all, bids []domain.Event
+		bus.Subscribe(func(event domain.Event) { all = append(all, event) })
+		bus.Subscribe(func(event domain.Event) { bids = append(bids, event) }, domain.BidAcceptedEvent{})
+
+		bus.Publish(added)
+		bus.Publish(bid)
+		if len(all) != 2 {
+			t.Errorf("Expected 2 events for the catch-all subscriber, got %d", len(all))
+		}
+		if len(bids) != 1 || bids[0] != domain.Event(bid) {
+			t.Errorf("Expected only the bid, got %v", bids)
+		}
+	})
+
+	t.Run("AsyncInOrder", func(t *testing.T) {
+		bus := persistence.NewEventBus()
+		var mu sync.Mutex
+		var amounts []int64
+		bus.SubscribeAsync(func(event domain.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			amounts = append(amounts, event.(domain.BidAcceptedEvent).Bid.Amount)
+		}, domain.BidAcceptedEvent{})
+
+		for amount := int64(1); amount <= 100; amount++ {
+			next := bid
+			next.Bid.Amount = amount
+			bus.Publish(next)
+		}
+		bus.Publish(added)
+		bus.Close()
+
+		if len(amounts) != 100 {
+			t.Fatalf("Expected 100 events after closing, got %d", len(amounts))
+		}
+		for i, amount := range amounts {
+			if amount != int64(i+1) {
+				t.Fatalf("Expected events in order, got %d at %d", amount, i)
+			}
+		}
+
+		// Events published after closing are dropped
+		bus.Publish(bid)
+	})
+
+	t.Run("PanicIsolation", func(t *testing.T) {
+		bus := persistence.NewEventBus()
+		var received int
+		bus.Subscribe(func(event domain.Event) { panic("broken subscriber") })
+		bus.SubscribeAsync(func(event domain.Event) { panic("broken async subscriber") })
+		bus.Subscribe(func(event domain.Event) { received++ })
+
+		bus.Publish(added)
+		bus.Publish(bid)
+		bus.Close()
+		if received != 2 {
+			t.Errorf("Expected the other subscriber to receive 2 events, got %d", received)
+		}
+		if bus.Panics() != 4 {
+			t.Errorf("Expected 4 panics, got %d", bus.Panics())
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestUniqueEventStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	underlying := persistence.NewMemoryStore()
+	store := persistence.NewUniqueEventStore(underlying)
+
+	added := sampleAuctionAdded(1, now)
+	bid := sampleBidAccepted(1, now.Add(time.Second), 10)
+	if err := store.WriteEvents([]domain.Event{added, bid}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A retried append of the latest event of an aggregate is refused
+	err := store.WriteEvents([]domain.Event{bid})
+	if domainErr, ok := err.(domain.DomainError); !ok || domainErr.Type != domain.ErrorDuplicateEvent || domainErr.Data != domain.NewEventId("auction/1", 2) {
+		t.Fatalf("Expected a duplicate of the second event of auction 1, got %v", err)
+	}
+	// So is a duplicate within a batch, and nothing of the batch is written
+	other := sampleAuctionAdded(2, now)
+	if err := store.WriteEvents([]domain.Event{other, other}); err == nil {
+		t.Errorf("Expected a duplicate within a batch to be refused")
+	}
+	if events, _ := underlying.ReadEvents(); len(events) != 2 {
+		t.Errorf("Expected 2 events, got %d", len(events))
+	}
+
+	// A store opened on existing events knows their versions
+	reopened := persistence.NewUniqueEventStore(underlying)
+	if err := reopened.WriteEvents([]domain.Event{bid}); err == nil {
+		t.Errorf("Expected the duplicate to be refused after reopening")
+	}
+	if err := reopened.WriteEvents([]domain.Event{sampleBidAccepted(1, now.Add(2*time.Second), 20), other}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ids, err := reopened.ReadEventIdsSince(2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ids) != 2 || ids[0] != domain.NewEventId("auction/1", 3) || ids[1] != domain.NewEventId("auction/2", 1) {
+		t.Errorf("Expected the IDs of the new events, got %v", ids)
+	}
+
+	t.Run("StoresIds", func(t *testing.T) {
+		stored, _ := underlying.ReadEvents()
+		if identified, ok := stored[2].(persistence.IdentifiedEvent); !ok || identified.ID != domain.NewEventId("auction/1", 3) {
+			t.Errorf("Expected the event stored with its ID, got %#v", stored[2])
+		}
+		events, _ := reopened.ReadEvents()
+		if _, ok := events[2].(domain.BidAcceptedEvent); !ok {
+			t.Errorf("Expected the event read without its ID, got %#v", events[2])
+		}
+	})
+
+	t.Run("ReadsIdsWithoutTheWholeLog", func(t *testing.T) {
+		ids, err := persistence.NewUniqueEventStore(tailOnlyStore{underlying}).ReadEventIdsSince(3)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(ids) != 1 || ids[0] != domain.NewEventId("auction/2", 1) {
+			t.Errorf("Expected the ID of the last event, got %v", ids)
+		}
+	})
+
+	t.Run("NumbersEventsStoredWithoutIds", func(t *testing.T) {
+		legacy := persistence.NewMemoryStore()
+		legacy.WriteEvents([]domain.Event{added, bid})
+		store := persistence.NewUniqueEventStore(legacy)
+		if err := store.WriteEvents([]domain.Event{other}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ids, err := store.ReadEventIdsSince(1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(ids) != 2 || ids[0] != domain.NewEventId("auction/1", 2) || ids[1] != domain.NewEventId("auction/2", 1) {
+			t.Errorf("Expected the IDs of the last events, got %v", ids)
+		}
+	})
+}
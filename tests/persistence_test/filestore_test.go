@@ -0,0 +1,61 @@
+package persistence_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestFileStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	dir := t.TempDir()
+	store := persistence.NewFileStore(filepath.Join(dir, "commands.jsonl"), filepath.Join(dir, "events.jsonl"), filepath.Join(dir, "snapshots.jsonl"))
+
+	for i := 1; i <= 3; i++ {
+		event := sampleAuctionAdded(domain.AuctionId(i), now.Add(time.Duration(i)*time.Second))
+		if err := store.WriteEvents([]domain.Event{event}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	t.Run("ReadEventsSince", func(t *testing.T) {
+		events, err := store.ReadEventsSince(1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(events) != 2 {
+			t.Fatalf("Expected 2 events, got %d", len(events))
+		}
+		if id, _ := domain.EventAuctionId(events[0]); id != 2 {
+			t.Errorf("Expected first event after position 1 to be auction 2, got %d", id)
+		}
+	})
+
+	t.Run("ReadEventsSinceEnd", func(t *testing.T) {
+		events, err := store.ReadEventsSince(3)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(events) != 0 {
+			t.Errorf("Expected no events, got %d", len(events))
+		}
+	})
+	t.Run("ReplaceEvents", func(t *testing.T) {
+		if err := store.ReplaceEvents([]domain.Event{sampleAuctionAdded(4, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		events, _ := store.ReadEvents()
+		if len(events) != 1 {
+			t.Fatalf("Expected 1 event, got %d", len(events))
+		}
+		if err := store.ReplaceEvents(nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if events, _ := store.ReadEvents(); len(events) != 0 {
+			t.Errorf("Expected no events, got %d", len(events))
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package persistence_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// batchRecordingStore records the size of each event write
+type batchRecordingStore struct {
+	countingStore
+	batches []int
+}
+
+func (s *batchRecordingStore) WriteEvents(events []domain.Event) error {
+	s.batches = append(s.batches, len(events))
+	return s.countingStore.WriteEvents(events)
+}
+
+func TestGroupCommitStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	t.Run("BatchesConcurrentAppends", func(t *testing.T) {
+		inner := &batchRecordingStore{}
+		store := persistence.NewGroupCommitStore(inner, time.Minute, 3)
+
+		var wg sync.WaitGroup
+		for i := 1; i <= 3; i++ {
+			wg.Add(1)
+			go func(id domain.AuctionId) {
+				defer wg.Done()
+				if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(id, now)}); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			}(domain.AuctionId(i))
+		}
+		wg.Wait()
+
+		if len(inner.batches) != 1 || inner.batches[0] != 3 {
+			t.Errorf("Expected a single write of 3 events, got %v", inner.batches)
+		}
+	})
+
+	t.Run("CommitsAfterWindow", func(t *testing.T) {
+		inner := &batchRecordingStore{}
+		store := persistence.NewGroupCommitStore(inner, time.Millisecond, 100)
+
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		events, _ := inner.ReadEvents()
+		if len(events) != 1 {
+			t.Errorf("Expected the event to be committed on return, got %d events", len(events))
+		}
+	})
+}
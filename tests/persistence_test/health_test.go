@@ -0,0 +1,51 @@
+package persistence_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/persistence"
+)
+
+func TestPing(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("WritableFileStore", func(t *testing.T) {
+		store := persistence.NewFileStore(filepath.Join(dir, "commands.jsonl"), filepath.Join(dir, "events.jsonl"), filepath.Join(dir, "snapshots.jsonl"))
+		if err := persistence.Ping(context.Background(), store); err != nil {
+			t.Errorf("Expected a healthy store, got %v", err)
+		}
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing")
+		store := persistence.NewFileStore(filepath.Join(missing, "commands.jsonl"), filepath.Join(missing, "events.jsonl"), "")
+		if err := persistence.Ping(context.Background(), store); err == nil {
+			t.Errorf("Expected an error for a missing directory")
+		}
+	})
+
+	t.Run("ThroughDecorators", func(t *testing.T) {
+		inner := persistence.NewDailyFileStore(filepath.Join(dir, "missing"))
+		store := persistence.Instrument(persistence.NewValidatingStore(persistence.NewCachingStore(inner, time.Minute, time.Minute, time.Now)), "health_test", nil)
+		if err := persistence.Ping(context.Background(), store); err == nil {
+			t.Errorf("Expected the error of the backend to surface through the decorators")
+		}
+	})
+
+	t.Run("StoresWithoutHealthCheck", func(t *testing.T) {
+		if err := persistence.Ping(context.Background(), &countingStore{}); err != nil {
+			t.Errorf("Expected a store without health check to be healthy, got %v", err)
+		}
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := persistence.Ping(ctx, persistence.NewMemoryStore()); err == nil {
+			t.Errorf("Expected an error for a canceled context")
+		}
+	})
+}
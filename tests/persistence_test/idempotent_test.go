@@ -0,0 +1,62 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func samplePlaceBid(key string, at time.Time) domain.PlaceBidCommand {
+	return domain.PlaceBidCommand{
+		Time:           at,
+		Bid:            sampleBidAccepted(1, at, 10).Bid,
+		IdempotencyKey: key,
+	}
+}
+
+func TestIdempotentStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	t.Run("RejectsReusedKeys", func(t *testing.T) {
+		inner := &countingStore{}
+		store := persistence.NewIdempotentStore(inner)
+
+		if err := store.WriteCommands([]domain.Command{samplePlaceBid("k1", now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		err := store.WriteCommands([]domain.Command{samplePlaceBid("k1", now)})
+		if domainErr, ok := err.(domain.DomainError); !ok || domainErr.Type != domain.ErrorDuplicateCommand {
+			t.Errorf("Expected DuplicateCommand error, got %v", err)
+		}
+		if len(inner.commands) != 1 {
+			t.Errorf("Expected 1 stored command, got %d", len(inner.commands))
+		}
+	})
+
+	t.Run("LoadsKeysFromStore", func(t *testing.T) {
+		inner := &countingStore{commands: []domain.Command{samplePlaceBid("k1", now)}}
+		store := persistence.NewIdempotentStore(inner)
+
+		written, err := store.WriteCommandIdempotent("k1", samplePlaceBid("", now))
+		if err != nil || written {
+			t.Errorf("Expected the command not to be written, got %v %v", written, err)
+		}
+		written, err = store.WriteCommandIdempotent("k2", samplePlaceBid("", now))
+		if err != nil || !written {
+			t.Errorf("Expected the command to be written, got %v %v", written, err)
+		}
+	})
+
+	t.Run("AllowsCommandsWithoutKey", func(t *testing.T) {
+		inner := &countingStore{}
+		store := persistence.NewIdempotentStore(inner)
+
+		for i := 0; i < 2; i++ {
+			if err := store.WriteCommands([]domain.Command{samplePlaceBid("", now)}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+	})
+}
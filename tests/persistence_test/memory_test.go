@@ -0,0 +1,65 @@
+package persistence_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	t.Run("IsSafeForConcurrentWrites", func(t *testing.T) {
+		store := persistence.NewMemoryStore()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(id domain.AuctionId) {
+				defer wg.Done()
+				store.WriteEvents([]domain.Event{sampleAuctionAdded(id, now)})
+				store.ReadEvents()
+			}(domain.AuctionId(i))
+		}
+		wg.Wait()
+
+		events, _ := store.ReadEvents()
+		if len(events) != 50 {
+			t.Errorf("Expected 50 events, got %d", len(events))
+		}
+	})
+
+	t.Run("SnapshotsToFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		commandsPath := filepath.Join(dir, "commands.jsonl")
+		eventsPath := filepath.Join(dir, "events.jsonl")
+
+		store := persistence.NewMemoryStore()
+		store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)})
+		store.WriteCommands([]domain.Command{domain.AddAuctionCommand{Time: now, Auction: sampleAuctionAdded(1, now).Auction}})
+
+		// Snapshotting twice must replace rather than append
+		for i := 0; i < 2; i++ {
+			if err := store.SnapshotToFiles(commandsPath, eventsPath); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+
+		fileStore := persistence.NewFileStore(commandsPath, eventsPath, filepath.Join(dir, "snapshots.jsonl"))
+		events, err := fileStore.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(events) != 1 {
+			t.Errorf("Expected 1 event, got %d", len(events))
+		}
+		commands, _ := fileStore.ReadCommands()
+		if len(commands) != 1 {
+			t.Errorf("Expected 1 command, got %d", len(commands))
+		}
+	})
+}
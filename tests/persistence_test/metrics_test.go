@@ -0,0 +1,79 @@
+package persistence_test
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestMetricsStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	store := persistence.NewMetricsStore(&countingStore{}, "metrics_test")
+
+	events := []domain.Event{sampleAuctionAdded(1, now), sampleAuctionAdded(2, now)}
+	if err := store.WriteEvents(events); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := store.ReadEvents(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	backend, ok := expvar.Get("store").(*expvar.Map).Get("metrics_test").(*expvar.Map)
+	if !ok {
+		t.Fatalf("Expected metrics to be published for the backend")
+	}
+
+	counter := func(name string) int64 {
+		if v, ok := backend.Get(name).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+
+	if got := counter("WriteEvents.calls"); got != 1 {
+		t.Errorf("Expected 1 WriteEvents call, got %d", got)
+	}
+	if got := counter("WriteEvents.items"); got != 2 {
+		t.Errorf("Expected 2 written events, got %d", got)
+	}
+	if got := counter("ReadEvents.items"); got != 2 {
+		t.Errorf("Expected 2 read events, got %d", got)
+	}
+	if got := counter("WriteEvents.errors"); got != 0 {
+		t.Errorf("Expected no errors, got %d", got)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	t.Run("RecordsMetricsAndSpans", func(t *testing.T) {
+		tracer := &recordingTracer{}
+		store := persistence.Instrument(&countingStore{}, "instrument_test", tracer)
+
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		backend, ok := expvar.Get("store").(*expvar.Map).Get("instrument_test").(*expvar.Map)
+		if !ok {
+			t.Fatalf("Expected metrics to be published for the backend")
+		}
+		if v, ok := backend.Get("WriteEvents.items").(*expvar.Int); !ok || v.Value() != 1 {
+			t.Errorf("Expected 1 written event, got %v", backend.Get("WriteEvents.items"))
+		}
+		if len(tracer.spans) != 1 || tracer.spans[0].name != "store.WriteEvents" {
+			t.Errorf("Expected a store.WriteEvents span, got %v", tracer.spans)
+		}
+	})
+
+	t.Run("TracingIsOptional", func(t *testing.T) {
+		store := persistence.Instrument(&countingStore{}, "instrument_test", nil)
+		if _, ok := store.(*persistence.MetricsStore); !ok {
+			t.Errorf("Expected only metrics without a tracer, got %T", store)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package persistence_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// failingWriteStore is a store whose writes always fail
+type failingWriteStore struct {
+	countingStore
+}
+
+func (s *failingWriteStore) WriteEvents(events []domain.Event) error {
+	return errors.New("secondary down")
+}
+
+func TestMirrorStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	t.Run("WritesBothReadsPrimary", func(t *testing.T) {
+		primary, secondary := &countingStore{}, &countingStore{}
+		store := persistence.NewMirrorStore(primary, secondary)
+
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(primary.events) != 1 || len(secondary.events) != 1 {
+			t.Fatalf("Expected the event on both sides, got %d and %d", len(primary.events), len(secondary.events))
+		}
+
+		if _, err := store.ReadEvents(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if primary.eventReads != 1 || secondary.eventReads != 0 {
+			t.Errorf("Expected reads from the primary only, got %d and %d", primary.eventReads, secondary.eventReads)
+		}
+
+		verification, err := store.Verify()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !verification.InSync() || verification.Events != 1 {
+			t.Errorf("Expected both sides in sync, got %+v", verification)
+		}
+	})
+
+	t.Run("SecondaryFailureDoesNotFailWrites", func(t *testing.T) {
+		primary, secondary := &countingStore{}, &failingWriteStore{}
+		store := persistence.NewMirrorStore(primary, secondary)
+
+		if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now)}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if store.SecondaryErrors() != 1 {
+			t.Errorf("Expected 1 secondary error, got %d", store.SecondaryErrors())
+		}
+
+		verification, err := store.Verify()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(verification.Mismatches) != 1 {
+			t.Fatalf("Expected 1 mismatch, got %+v", verification.Mismatches)
+		}
+		mismatch := verification.Mismatches[0]
+		if mismatch.Stream != "events" || mismatch.Position != 1 || mismatch.Reason != "missing on secondary" {
+			t.Errorf("Expected the event missing on the secondary, got %+v", mismatch)
+		}
+	})
+
+	t.Run("DetectsDifferentContent", func(t *testing.T) {
+		primary := &countingStore{events: []domain.Event{sampleAuctionAdded(1, now)}}
+		secondary := &countingStore{events: []domain.Event{sampleAuctionAdded(2, now)}}
+		verification, err := persistence.NewMirrorStore(primary, secondary).Verify()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(verification.Mismatches) != 1 || verification.Mismatches[0].Reason != "content differs" {
+			t.Errorf("Expected differing content, got %+v", verification.Mismatches)
+		}
+	})
+}
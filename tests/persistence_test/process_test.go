@@ -0,0 +1,113 @@
+package persistence_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// tagTranslatedProcess tags auctions when they are translated, standing in for a
+// follow-up workflow
+type tagTranslatedProcess struct{}
+
+func (tagTranslatedProcess) Name() string { return "tag" }
+
+func (tagTranslatedProcess) Handle(event domain.Event) []domain.Command {
+	e, ok := event.(domain.ListingTranslatedEvent)
+	if !ok {
+		return nil
+	}
+	return []domain.Command{domain.ReviseListingCommand{Time: e.Time, AuctionId: e.AuctionId, AddTags: []string{"translated"}}}
+}
+
+func TestProcessManager(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	translated := domain.ListingTranslatedEvent{Time: at, AuctionId: 1, Translation: domain.Translation{Language: "sv", Title: "Gammal bil", Source: domain.TranslationSeller}}
+
+	t.Run("Dispatches", func(t *testing.T) {
+		var dispatched []domain.Command
+		manager := persistence.NewProcessManager(filepath.Join(t.TempDir(), "outbox.jsonl"), func(cmd domain.Command) error {
+			dispatched = append(dispatched, cmd)
+			return nil
+		}, 3, time.Millisecond)
+		manager.Register(tagTranslatedProcess{})
+
+		manager.Observe(sampleAuctionAdded(1, at))
+		manager.Observe(translated)
+		if len(dispatched) != 1 {
+			t.Fatalf("Expected 1 command, got %d", len(dispatched))
+		}
+		if _, ok := dispatched[0].(domain.ReviseListingCommand); !ok {
+			t.Errorf("Expected a ReviseListing command, got %#v", dispatched[0])
+		}
+		if pending := manager.Pending(); len(pending) != 0 {
+			t.Errorf("Expected an empty outbox, got %d commands", len(pending))
+		}
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		attempts := 0
+		manager := persistence.NewProcessManager(filepath.Join(t.TempDir(), "outbox.jsonl"), func(cmd domain.Command) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("store unavailable")
+			}
+			return nil
+		}, 3, time.Millisecond)
+		manager.Register(tagTranslatedProcess{})
+
+		manager.Observe(translated)
+		if attempts != 3 || len(manager.Pending()) != 0 {
+			t.Errorf("Expected success on the third attempt, got %d attempts and %d pending", attempts, len(manager.Pending()))
+		}
+	})
+
+	t.Run("RejectionsAreFinal", func(t *testing.T) {
+		attempts := 0
+		manager := persistence.NewProcessManager(filepath.Join(t.TempDir(), "outbox.jsonl"), func(cmd domain.Command) error {
+			attempts++
+			return domain.NewAuctionNotFoundError(1)
+		}, 3, time.Millisecond)
+		manager.Register(tagTranslatedProcess{})
+
+		manager.Observe(translated)
+		if attempts != 1 || len(manager.Pending()) != 0 {
+			t.Errorf("Expected a single attempt, got %d attempts and %d pending", attempts, len(manager.Pending()))
+		}
+	})
+
+	t.Run("ResumesAfterRestart", func(t *testing.T) {
+		outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+		failing := persistence.NewProcessManager(outbox, func(cmd domain.Command) error {
+			return errors.New("store unavailable")
+		}, 2, time.Millisecond)
+		failing.Register(tagTranslatedProcess{})
+		failing.Observe(translated)
+		if len(failing.Pending()) != 1 {
+			t.Fatalf("Expected the command to stay in the outbox, got %d", len(failing.Pending()))
+		}
+
+		var dispatched []domain.Command
+		restarted := persistence.NewProcessManager(outbox, func(cmd domain.Command) error {
+			dispatched = append(dispatched, cmd)
+			return nil
+		}, 2, time.Millisecond)
+		if err := restarted.Resume(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(dispatched) != 1 || len(restarted.Pending()) != 0 {
+			t.Errorf("Expected the outbox to be dispatched, got %d dispatched and %d pending", len(dispatched), len(restarted.Pending()))
+		}
+
+		// The outbox is empty on the next restart
+		again := persistence.NewProcessManager(outbox, func(cmd domain.Command) error {
+			t.Errorf("Expected no command to dispatch, got %#v", cmd)
+			return nil
+		}, 2, time.Millisecond)
+		again.Resume()
+	})
+}
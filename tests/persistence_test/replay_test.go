@@ -0,0 +1,82 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestReplay(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	store := persistence.NewMemoryStore()
+	err := store.WriteEvents([]domain.Event{
+		sampleAuctionAdded(1, now),
+		sampleAuctionAdded(2, now.Add(time.Second)),
+		sampleBidAccepted(1, now.Add(time.Minute), 10),
+		sampleBidAccepted(2, now.Add(2*time.Minute), 20),
+		sampleBidAccepted(1, now.Add(3*time.Minute), 30),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("FiltersByAuction", func(t *testing.T) {
+		id := domain.AuctionId(1)
+		validation := persistence.NewValidationProjection()
+		result, err := persistence.Replay(store, persistence.ReplayFilter{AuctionId: &id}, validation)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Read != 5 || result.Replayed != 3 {
+			t.Errorf("Expected 3 of 5 events replayed, got %d of %d", result.Replayed, result.Read)
+		}
+		if len(validation.Violations) != 0 {
+			t.Errorf("Expected no violations, got %v", validation.Violations)
+		}
+	})
+
+	t.Run("FiltersByTypeAndTime", func(t *testing.T) {
+		filter := persistence.ReplayFilter{
+			Types: []string{"BidAccepted"},
+			From:  now.Add(2 * time.Minute),
+			To:    now.Add(3 * time.Minute),
+		}
+		result, err := persistence.Replay(store, filter)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Replayed != 1 {
+			t.Errorf("Expected 1 event replayed, got %d", result.Replayed)
+		}
+	})
+
+	t.Run("ReportsGapsAsViolations", func(t *testing.T) {
+		validation := persistence.NewValidationProjection()
+		if _, err := persistence.Replay(store, persistence.ReplayFilter{Types: []string{"BidAccepted"}}, validation); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(validation.Violations) != 3 {
+			t.Fatalf("Expected 3 violations, got %v", validation.Violations)
+		}
+		if validation.Violations[0].Index != 3 {
+			t.Errorf("Expected the first violation at position 3, got %d", validation.Violations[0].Index)
+		}
+	})
+
+	t.Run("RebuildsSnapshot", func(t *testing.T) {
+		repository := persistence.NewRepositoryProjection()
+		if _, err := persistence.Replay(store, persistence.ReplayFilter{}, repository); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		snapshot, err := repository.Snapshot()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if snapshot.Position != 5 || len(snapshot.Auctions) != 2 {
+			t.Errorf("Expected 2 auctions at position 5, got %d at %d", len(snapshot.Auctions), snapshot.Position)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package persistence_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestArchiveEvents(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-06-01T00:00:00Z")
+	old := now.Add(-100 * 24 * time.Hour)
+	recent := now.Add(-2 * time.Hour)
+
+	store := persistence.NewMemoryStore()
+	store.WriteEvents([]domain.Event{
+		sampleAuctionAdded(1, old),
+		sampleAuctionAdded(2, recent),
+		sampleBidAccepted(1, old.Add(time.Minute), 10),
+		sampleAuctionAdded(3, now.Add(-time.Minute)),
+	})
+	store.WriteSnapshot(persistence.Snapshot{Position: 1})
+	archive := persistence.NewMemoryStore()
+
+	result, err := persistence.ArchiveEvents(store, archive, 90*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Events != 2 || len(result.Auctions) != 1 || result.Auctions[0] != 1 {
+		t.Errorf("Expected the 2 events of auction 1 to be archived, got %+v", result)
+	}
+	if archived, _ := archive.ReadEvents(); len(archived) != 2 {
+		t.Errorf("Expected 2 archived events, got %d", len(archived))
+	}
+
+	repo, position, err := persistence.LoadRepository(store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if position != 4 || len(repo) != 2 {
+		t.Errorf("Expected auctions 2 and 3 at position 4, got %d auctions at %d", len(repo), position)
+	}
+	if _, ok := repo[1]; ok {
+		t.Errorf("Expected auction 1 to be archived")
+	}
+
+	// The ID of the archived auction can't be taken again
+	restored, err := persistence.LoadState(store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !restored.ReadModels.Archived[1] || restored.ReadModels.Archived[2] {
+		t.Errorf("Expected only auction 1 archived, got %v", restored.ReadModels.Archived)
+	}
+}
+
+func TestArchiveEventsKeepsPositions(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-06-01T00:00:00Z")
+	old := now.Add(-100 * 24 * time.Hour)
+
+	store := persistence.NewMemoryStore()
+	unique := persistence.NewUniqueEventStore(store)
+	unique.WriteEvents([]domain.Event{
+		sampleAuctionAdded(1, old),
+		sampleAuctionAdded(2, now),
+		sampleBidAccepted(1, old.Add(time.Minute), 10),
+		sampleBidAccepted(2, now, 10),
+	})
+	ids, _ := unique.ReadEventIdsSince(0)
+
+	checkpoints, err := persistence.OpenCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	checkpoints.Save("reports", 3)
+
+	if _, err := persistence.ArchiveEvents(store, persistence.NewMemoryStore(), 90*24*time.Hour, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events, _ := unique.ReadEvents()
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %d", len(events))
+	}
+	for _, i := range []int{0, 2} {
+		tombstone, ok := events[i].(domain.EventArchivedEvent)
+		if !ok || tombstone.AuctionId != 1 || tombstone.EventId != ids[i] {
+			t.Errorf("Expected event %d to be replaced by a tombstone with ID %s, got %#v", i+1, ids[i], events[i])
+		}
+	}
+	if after, _ := unique.ReadEventIdsSince(0); len(after) != 4 || after[0] != ids[0] || after[1] != ids[1] || after[3] != ids[3] {
+		t.Errorf("Expected the events to keep their IDs, got %v instead of %v", after, ids)
+	}
+
+	// The projection resumes after the event it had processed
+	projection := &recordingProjection{name: "reports"}
+	if _, err := persistence.CatchUp(store, checkpoints, projection); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(projection.positions) != 1 || projection.positions[0] != 4 {
+		t.Errorf("Expected only the bid on auction 2 replayed, got %v", projection.positions)
+	}
+}
+
+func TestArchiveEventsOfDecoratedDailyStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-06-01T00:00:00Z")
+	old := now.Add(-100 * 24 * time.Hour)
+	recent := now.Add(-2 * time.Hour)
+
+	secrets, err := persistence.ParseSecretKeys("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		t.Fatalf("Failed to parse keys: %v", err)
+	}
+	dir := t.TempDir()
+	store := persistence.NewCompressingStore(persistence.NewEncryptedStore(persistence.NewDailyFileStore(dir), secrets), 100)
+	archiveFile := filepath.Join(dir, "archive.jsonl")
+	archive := persistence.NewEncryptedStore(persistence.NewFileStore("", archiveFile, ""), secrets)
+
+	if err := store.WriteEvents([]domain.Event{
+		sampleAuctionAdded(1, old),
+		sampleBidAccepted(1, old.Add(time.Minute), 10),
+		sampleAuctionAdded(2, recent),
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result, err := persistence.ArchiveEvents(store, archive, 90*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Events != 2 {
+		t.Fatalf("Expected the 2 events of auction 1 to be archived, got %+v", result)
+	}
+
+	t.Run("LeavesTombstones", func(t *testing.T) {
+		events, err := store.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("Expected 3 events, got %d", len(events))
+		}
+		if _, ok := events[0].(domain.EventArchivedEvent); !ok {
+			t.Errorf("Expected the first event to be archived, got %#v", events[0])
+		}
+		if id, _ := domain.EventAuctionId(events[2]); id != 2 {
+			t.Errorf("Expected auction 2 to remain, got %d", id)
+		}
+	})
+
+	t.Run("KeepsArchivedEventsEncrypted", func(t *testing.T) {
+		data, err := os.ReadFile(archiveFile)
+		if err != nil {
+			t.Fatalf("Failed to read archive file: %v", err)
+		}
+		if bytes.Contains(data, []byte("Seller")) {
+			t.Errorf("Expected archived events to be encrypted, got %s", data)
+		}
+		if archived, err := archive.ReadEvents(); err != nil || len(archived) != 2 {
+			t.Errorf("Expected 2 archived events, got %d (%v)", len(archived), err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestShardedStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	shards := []*persistence.MemoryStore{persistence.NewMemoryStore(), persistence.NewMemoryStore(), persistence.NewMemoryStore()}
+	store := persistence.NewShardedStore(shards[0], shards[1], shards[2])
+
+	var events []domain.Event
+	for i := 1; i <= 30; i++ {
+		at := now.Add(time.Duration(i) * time.Second)
+		events = append(events, sampleAuctionAdded(domain.AuctionId(i), at))
+	}
+	if err := store.WriteEvents(events); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("RoutesAuctionsToTheirShard", func(t *testing.T) {
+		for _, event := range events {
+			id, _ := domain.EventAuctionId(event)
+			shardEvents, _ := persistence.ReadAuctionEvents(store.ShardFor(id), id)
+			if len(shardEvents) != 1 {
+				t.Errorf("Expected auction %d on its shard, got %d events", id, len(shardEvents))
+			}
+		}
+	})
+
+	t.Run("SpreadsAuctionsOverShards", func(t *testing.T) {
+		for i, shard := range shards {
+			shardEvents, _ := shard.ReadEvents()
+			if len(shardEvents) == 0 {
+				t.Errorf("Expected shard %d to hold some auctions", i)
+			}
+		}
+	})
+
+	t.Run("FederatesReadsInTimeOrder", func(t *testing.T) {
+		read, err := store.ReadEvents()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(read) != len(events) {
+			t.Fatalf("Expected %d events, got %d", len(events), len(read))
+		}
+		for i := 1; i < len(read); i++ {
+			if read[i].GetTime().Before(read[i-1].GetTime()) {
+				t.Fatalf("Expected events ordered by time, event %d precedes event %d", i, i-1)
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestSLAMonitor(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	limits := persistence.SLALimits{Closing: time.Minute, Dispatch: time.Second, ProjectionLag: 1}
+
+	t.Run("Closing", func(t *testing.T) {
+		now := at
+		var violations []persistence.SLAViolation
+		monitor := persistence.NewSLAMonitor(limits, domain.Repository{}, 0, func() time.Time { return now }, func(v persistence.SLAViolation) {
+			violations = append(violations, v)
+		})
+		tender := sampleAuctionAdded(1, at)
+		tender.Auction.Tender = &domain.Tender{Threshold: 1, Custodians: []domain.UserId{"c1"}}
+		monitor.Observe(tender)
+		monitor.Observe(sampleAuctionAdded(2, at))
+
+		// Within the limit of the expiry
+		now = at.Add(time.Hour + time.Minute)
+		monitor.Check()
+		if len(violations) != 0 {
+			t.Fatalf("Expected no violation within the limit, got %v", violations)
+		}
+
+		// Only the tender still awaiting reveal is late, and alerted once
+		now = now.Add(time.Second)
+		monitor.Check()
+		monitor.Check()
+		if len(violations) != 1 || violations[0].Monitor != persistence.SLAClosing || violations[0].Subject != "auction/1" {
+			t.Fatalf("Expected one closing violation of auction/1, got %v", violations)
+		}
+	})
+
+	t.Run("Dispatch", func(t *testing.T) {
+		now := at
+		var violations []persistence.SLAViolation
+		monitor := persistence.NewSLAMonitor(limits, domain.Repository{}, 0, func() time.Time { return now }, func(v persistence.SLAViolation) {
+			violations = append(violations, v)
+		})
+		handled := 0
+		handler := monitor.Dispatched("mailer", func(event domain.Event) { handled++ })
+
+		handler(sampleAuctionAdded(1, at.Add(-time.Second)))
+		now = at.Add(2 * time.Second)
+		handler(sampleAuctionAdded(2, at))
+		if handled != 2 {
+			t.Errorf("Expected both events handled, got %d", handled)
+		}
+		if len(violations) != 1 || violations[0].Subject != "mailer" || violations[0].Value != "2s" {
+			t.Fatalf("Expected one dispatch violation of 2s, got %v", violations)
+		}
+	})
+
+	t.Run("Projection", func(t *testing.T) {
+		var violations []persistence.SLAViolation
+		monitor := persistence.NewSLAMonitor(limits, domain.Repository{}, 0, func() time.Time { return at }, func(v persistence.SLAViolation) {
+			violations = append(violations, v)
+		})
+		var position int64
+		monitor.Projection("search", func() int64 { return position })
+
+		monitor.Observe(sampleAuctionAdded(1, at))
+		monitor.Check()
+		monitor.Observe(sampleAuctionAdded(2, at))
+		monitor.Check()
+		if len(violations) != 1 || violations[0].Subject != "search" || violations[0].Value != "2 events" {
+			t.Fatalf("Expected one projection violation of 2 events, got %v", violations)
+		}
+
+		// Alerted again once it recovered and lags again
+		position = 2
+		monitor.Check()
+		position = 0
+		monitor.Check()
+		if len(violations) != 2 {
+			t.Errorf("Expected a second violation after recovering, got %v", violations)
+		}
+	})
+}
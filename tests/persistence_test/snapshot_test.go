@@ -0,0 +1,123 @@
+package persistence_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestSnapshots(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	store := persistence.NewMemoryStore()
+	snapshotter := persistence.NewSnapshotter(store, 2, domain.Repository{}, 0)
+
+	write := func(events ...domain.Event) {
+		if err := store.WriteEvents(events); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := snapshotter.Observe(events); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	write(sampleAuctionAdded(1, now))
+	if snapshot, _ := store.ReadLatestSnapshot(); snapshot != nil {
+		t.Fatalf("Expected no snapshot before 2 events")
+	}
+
+	write(sampleBidAccepted(1, now.Add(time.Second), 10))
+	snapshot, _ := store.ReadLatestSnapshot()
+	if snapshot == nil || snapshot.Position != 2 || len(snapshot.Auctions) != 1 {
+		t.Fatalf("Expected a snapshot of 1 auction at position 2, got %+v", snapshot)
+	}
+
+	write(sampleAuctionAdded(2, now.Add(2*time.Second)))
+
+	t.Run("LoadRepositoryUsesSnapshotAndTail", func(t *testing.T) {
+		repo, position, err := persistence.LoadRepository(store)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if position != 3 {
+			t.Errorf("Expected position 3, got %d", position)
+		}
+		if len(repo) != 2 {
+			t.Fatalf("Expected 2 auctions, got %d", len(repo))
+		}
+		if bids := repo[1].State.GetBids(); len(bids) != 1 {
+			t.Errorf("Expected auction 1 to have 1 bid, got %d", len(bids))
+		}
+	})
+}
+
+// tailOnlyStore is an in-memory store that fails reading all events, so
+// restoring from it must start from a snapshot
+type tailOnlyStore struct {
+	*persistence.MemoryStore
+}
+
+func (s tailOnlyStore) ReadEvents() ([]domain.Event, error) {
+	return nil, fmt.Errorf("read all events")
+}
+
+func TestLoadState(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	memory := persistence.NewMemoryStore()
+	snapshotter := persistence.NewSnapshotter(memory, 2, domain.Repository{}, 0)
+	snapshotter.KeepReadModels(domain.ReadModels{}, domain.NewActivityFeed().Snapshot())
+
+	write := func(events ...domain.Event) {
+		if err := memory.WriteEvents(events); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := snapshotter.Observe(events); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	write(sampleAuctionAdded(1, now), domain.AuctionStartedEvent{Time: now, AuctionId: 1, StartsAt: now})
+	write(sampleBidAccepted(1, now.Add(time.Second), 10))
+
+	t.Run("RestoresReadModelsFromSnapshotAndTail", func(t *testing.T) {
+		restored, err := persistence.LoadState(tailOnlyStore{memory})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if restored.Position != 3 || len(restored.Repository) != 1 {
+			t.Fatalf("Expected 1 auction at position 3, got %d at %d", len(restored.Repository), restored.Position)
+		}
+		if !restored.ReadModels.Started[1] {
+			t.Errorf("Expected auction 1 to have started")
+		}
+		activities := restored.Activity.Page("buyer", now.Add(time.Second), 0, 10)
+		if len(activities) != 1 || activities[0].Kind != domain.ActivityBidPlaced {
+			t.Errorf("Expected the bid of the tail in the activity feed, got %+v", activities)
+		}
+	})
+
+	t.Run("FoldsAllEventsWithoutReadModelsInSnapshot", func(t *testing.T) {
+		store := persistence.NewMemoryStore()
+		events, _ := memory.ReadEvents()
+		if err := store.WriteEvents(events); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		legacy := persistence.NewSnapshotter(store, 1, domain.Repository{}, 0)
+		if err := legacy.Observe(events[:2]); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		restored, err := persistence.LoadState(store)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if restored.Position != 3 || !restored.ReadModels.Started[1] {
+			t.Errorf("Expected started auction 1 at position 3, got %+v at %d", restored.ReadModels.Started, restored.Position)
+		}
+		if activities := restored.Activity.Page("seller", now, 0, 10); len(activities) != 1 {
+			t.Errorf("Expected the listing in the activity feed, got %+v", activities)
+		}
+	})
+}
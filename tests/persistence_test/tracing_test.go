@@ -0,0 +1,67 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// recordingSpan keeps the attributes of a span for inspection
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	ended      bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *recordingSpan) End(err error) {
+	s.ended = true
+}
+
+// recordingTracer records every span it starts
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(name string) persistence.Span {
+	span := &recordingSpan{name: name, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func TestTracingStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	tracer := &recordingTracer{}
+	store := persistence.NewTracingStore(&countingStore{}, "memory", tracer)
+
+	if err := store.WriteEvents([]domain.Event{sampleAuctionAdded(7, now)}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(tracer.spans))
+	}
+
+	span := tracer.spans[0]
+	if span.name != "store.WriteEvents" {
+		t.Errorf("Expected span store.WriteEvents, got %s", span.name)
+	}
+	if !span.ended {
+		t.Errorf("Expected span to have ended")
+	}
+	if span.attributes["backend"] != "memory" {
+		t.Errorf("Expected backend attribute memory, got %v", span.attributes["backend"])
+	}
+	if span.attributes["batchSize"] != 1 {
+		t.Errorf("Expected batchSize attribute 1, got %v", span.attributes["batchSize"])
+	}
+	ids, ok := span.attributes["auctionIds"].([]domain.AuctionId)
+	if !ok || len(ids) != 1 || ids[0] != 7 {
+		t.Errorf("Expected auctionIds attribute [7], got %v", span.attributes["auctionIds"])
+	}
+}
@@ -0,0 +1,116 @@
+package persistence_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+// bidTotalsProjection totals the bids of each auction, committing a batch
+// and its checkpoint together, and failing the batch holding failAt
+type bidTotalsProjection struct {
+	totals   map[domain.AuctionId]int64
+	position int64
+	failAt   int64
+}
+
+func (p *bidTotalsProjection) Name() string {
+	return "bidTotals"
+}
+
+func (p *bidTotalsProjection) Checkpoint() (int64, error) {
+	return p.position, nil
+}
+
+func (p *bidTotalsProjection) Commit(from int64, events []domain.Event) error {
+	if from != p.position {
+		return persistence.ErrCheckpointMoved
+	}
+	totals := make(map[domain.AuctionId]int64, len(p.totals))
+	for id, total := range p.totals {
+		totals[id] = total
+	}
+	for i, event := range events {
+		if from+int64(i+1) == p.failAt {
+			return errors.New("failed")
+		}
+		if accepted, ok := event.(domain.BidAcceptedEvent); ok {
+			totals[accepted.Bid.ForAuction] += accepted.Bid.Amount
+		}
+	}
+	p.totals = totals
+	p.position = from + int64(len(events))
+	return nil
+}
+
+func TestCatchUpExactlyOnce(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	newStore := func(t *testing.T) persistence.Store {
+		store := persistence.NewMemoryStore()
+		events := []domain.Event{sampleAuctionAdded(1, now)}
+		for i := 1; i <= 4; i++ {
+			events = append(events, sampleBidAccepted(1, now.Add(time.Duration(i)*time.Second), int64(i*10)))
+		}
+		if err := store.WriteEvents(events); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return store
+	}
+
+	t.Run("CommitsInBatches", func(t *testing.T) {
+		projection := &bidTotalsProjection{totals: map[domain.AuctionId]int64{}}
+		result, err := persistence.CatchUpExactlyOnce(newStore(t), projection, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Replayed != 5 || projection.position != 5 {
+			t.Errorf("Expected 5 events committed, got %d at %d", result.Replayed, projection.position)
+		}
+		if projection.totals[1] != 100 {
+			t.Errorf("Expected a total of 100, got %d", projection.totals[1])
+		}
+	})
+
+	t.Run("RetriesAFailedBatchWithoutDoubleApplying", func(t *testing.T) {
+		store := newStore(t)
+		projection := &bidTotalsProjection{totals: map[domain.AuctionId]int64{}, failAt: 4}
+		result, err := persistence.CatchUpExactlyOnce(store, projection, 2)
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		// The batch of events 3 and 4 rolled back, the bid of event 3 included
+		if result.Replayed != 2 || projection.position != 2 || projection.totals[1] != 10 {
+			t.Errorf("Expected the first batch only, got %d at %d totalling %d", result.Replayed, projection.position, projection.totals[1])
+		}
+
+		projection.failAt = 0
+		if _, err := persistence.CatchUpExactlyOnce(store, projection, 2); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if projection.position != 5 || projection.totals[1] != 100 {
+			t.Errorf("Expected a total of 100 at 5, got %d at %d", projection.totals[1], projection.position)
+		}
+	})
+
+	t.Run("RejectsACommitFromAMovedCheckpoint", func(t *testing.T) {
+		store := newStore(t)
+		projection := &bidTotalsProjection{totals: map[domain.AuctionId]int64{}}
+		if _, err := persistence.CatchUpExactlyOnce(store, projection, 10); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		events, err := store.ReadEventsSince(3)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := projection.Commit(3, events); !errors.Is(err, persistence.ErrCheckpointMoved) {
+			t.Errorf("Expected ErrCheckpointMoved, got %v", err)
+		}
+		if projection.totals[1] != 100 {
+			t.Errorf("Expected a total of 100, got %d", projection.totals[1])
+		}
+	})
+}
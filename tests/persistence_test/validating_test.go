@@ -0,0 +1,93 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func sampleBidAccepted(id domain.AuctionId, at time.Time, amount int64) domain.BidAcceptedEvent {
+	return domain.BidAcceptedEvent{
+		Time: at,
+		Bid: domain.Bid{
+			ForAuction: id,
+			Bidder:     domain.NewBuyerOrSeller("buyer", "Buyer"),
+			At:         at,
+			Amount:     amount,
+		},
+	}
+}
+
+func TestValidatingStore(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	t.Run("AcceptsValidBatch", func(t *testing.T) {
+		inner := &countingStore{}
+		store := persistence.NewValidatingStore(inner)
+
+		err := store.WriteEvents([]domain.Event{
+			sampleAuctionAdded(1, now),
+			sampleBidAccepted(1, now.Add(time.Second), 10),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(inner.events) != 2 {
+			t.Errorf("Expected 2 stored events, got %d", len(inner.events))
+		}
+	})
+
+	t.Run("RejectsBidForUnknownAuction", func(t *testing.T) {
+		inner := &countingStore{}
+		store := persistence.NewValidatingStore(inner)
+
+		err := store.WriteEvents([]domain.Event{sampleBidAccepted(1, now, 10)})
+		violationErr, ok := err.(persistence.InvariantViolationError)
+		if !ok {
+			t.Fatalf("Expected InvariantViolationError, got %v", err)
+		}
+		if len(violationErr.Violations) != 1 || violationErr.Violations[0].AuctionId != 1 {
+			t.Errorf("Expected a single violation for auction 1, got %v", violationErr.Violations)
+		}
+		if len(inner.events) != 0 {
+			t.Errorf("Expected nothing to be stored, got %d events", len(inner.events))
+		}
+	})
+
+	t.Run("RejectsDecreasingTimestamps", func(t *testing.T) {
+		inner := &countingStore{events: []domain.Event{sampleAuctionAdded(1, now)}}
+		store := persistence.NewValidatingStore(inner)
+
+		err := store.WriteEvents([]domain.Event{sampleBidAccepted(1, now.Add(-time.Second), 10)})
+		if _, ok := err.(persistence.InvariantViolationError); !ok {
+			t.Fatalf("Expected InvariantViolationError, got %v", err)
+		}
+	})
+
+	t.Run("AcceptsStartDatedAtTheStartTime", func(t *testing.T) {
+		inner := &countingStore{events: []domain.Event{sampleAuctionAdded(1, now), sampleBidAccepted(1, now.Add(time.Minute), 10)}}
+		store := persistence.NewValidatingStore(inner)
+
+		started := domain.AuctionStartedEvent{Time: now.Add(time.Second), AuctionId: 1, StartsAt: now.Add(time.Second)}
+		if err := store.WriteEvents([]domain.Event{started}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// Later events are still checked against the latest one
+		err := store.WriteEvents([]domain.Event{sampleBidAccepted(1, now.Add(2*time.Second), 20)})
+		if _, ok := err.(persistence.InvariantViolationError); !ok {
+			t.Fatalf("Expected InvariantViolationError, got %v", err)
+		}
+	})
+
+	t.Run("RejectsDuplicateAuction", func(t *testing.T) {
+		inner := &countingStore{events: []domain.Event{sampleAuctionAdded(1, now)}}
+		store := persistence.NewValidatingStore(inner)
+
+		err := store.WriteEvents([]domain.Event{sampleAuctionAdded(1, now.Add(time.Second))})
+		if _, ok := err.(persistence.InvariantViolationError); !ok {
+			t.Fatalf("Expected InvariantViolationError, got %v", err)
+		}
+	})
+}
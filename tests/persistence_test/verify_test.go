@@ -0,0 +1,56 @@
+package persistence_test
+
+import (
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+)
+
+func TestVerifyRebuild(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	events := []domain.Event{
+		sampleAuctionAdded(1, at),
+		sampleAuctionAdded(2, at),
+		sampleBidAccepted(1, at.Add(time.Minute), 10),
+		sampleBidAccepted(1, at.Add(2*time.Minute), 20),
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		store := persistence.NewMemoryStore()
+		store.WriteEvents(events)
+		report, err := persistence.VerifyRebuild(store, true)
+		if err != nil {
+			t.Fatalf("Failed to verify: %v", err)
+		}
+		if !report.Deterministic() || report.Events != 4 || report.Auctions != 2 || report.SnapshotPosition != 0 {
+			t.Errorf("Expected 2 matching auctions from 4 events, got %+v", report)
+		}
+	})
+
+	t.Run("SnapshotMismatch", func(t *testing.T) {
+		store := persistence.NewMemoryStore()
+		store.WriteEvents(events)
+
+		// A snapshot after the first bid, but missing it
+		repo := domain.ApplyEvents(domain.Repository{}, events[:2])
+		auctions, _ := domain.SnapshotRepository(repo)
+		store.WriteSnapshot(persistence.Snapshot{Position: 3, Auctions: auctions})
+
+		report, err := persistence.VerifyRebuild(store, false)
+		if err != nil || !report.Deterministic() {
+			t.Fatalf("Expected the snapshot unchecked, got %+v, %v", report, err)
+		}
+		report, err = persistence.VerifyRebuild(store, true)
+		if err != nil {
+			t.Fatalf("Failed to verify: %v", err)
+		}
+		if report.SnapshotPosition != 3 || len(report.Mismatches) != 1 {
+			t.Fatalf("Expected one mismatch from the snapshot at 3, got %+v", report)
+		}
+		if mismatch := report.Mismatches[0]; mismatch.AuctionId != 1 || mismatch.Rebuild != "snapshot at 3" {
+			t.Errorf("Expected auction 1 to differ, got %+v", mismatch)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestMyActivity tests paging through the activity of the requesting user
+func TestMyActivity(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	onCommand := func(command domain.Command) error { return nil }
+	activity := domain.NewActivityFeed()
+	onEvent := func(event domain.Event) error {
+		activity.Observe(event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	page := func(url, jwt string) web.ActivityResponse {
+		rr := serve("GET", url, jwt, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		var response web.ActivityResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response
+	}
+
+	t.Run("NotAvailable", func(t *testing.T) {
+		if rr := serve("GET", "/me/activity", buyerJWT, ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %v, got %v", http.StatusNotFound, rr.Code)
+		}
+	})
+	app.Activity = activity
+
+	for id := 1; id <= 3; id++ {
+		body := fmt.Sprintf(`{"id": %d, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-05T00:00:00Z", "title": "auction", "currency": "VAC"}`, id)
+		if rr := serve("POST", "/auctions", sellerJWT, body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if rr := serve("POST", fmt.Sprintf("/auctions/%d/bids", id), buyerJWT, `{"amount": 10}`); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		if rr := serve("GET", "/me/activity", "", ""); rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected status %v, got %v", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	first := page("/me/activity?limit=2", buyerJWT)
+	if len(first.Activities) != 2 || first.Activities[0].AuctionId != 3 || first.Next == 0 {
+		t.Fatalf("expected the 2 latest bids and a cursor, got %+v", first)
+	}
+	rest := page(fmt.Sprintf("/me/activity?limit=2&before=%d", first.Next), buyerJWT)
+	if len(rest.Activities) != 1 || rest.Activities[0].AuctionId != 1 || rest.Next != 0 {
+		t.Errorf("expected the first bid on the last page, got %+v", rest)
+	}
+
+	// The seller's feed holds their listings, and their sales after the close
+	now = startsAt.Add(48 * time.Hour)
+	seller := page("/me/activity", sellerJWT)
+	if len(seller.Activities) != 6 || seller.Activities[0].Kind != domain.ActivitySold {
+		t.Errorf("expected 3 sales and 3 listings, got %+v", seller.Activities)
+	}
+
+	if rr := serve("GET", "/me/activity?limit=0", buyerJWT, ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
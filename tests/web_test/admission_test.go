@@ -0,0 +1,57 @@
+package web_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestBidAdmission tests the rate limit headers and throttling of bids
+func TestBidAdmission(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:10Z")
+	getCurrentTime := func() time.Time {
+		return now
+	}
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+	app.BidAdmission = web.NewAdmissionControl(2, time.Minute, getCurrentTime)
+
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	bid := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/auctions/1/bids", bytes.NewBufferString(`{"amount": 10}`))
+		req.Header.Set("x-jwt-payload", buyerJWT)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := bid()
+	if rr.Header().Get("RateLimit-Limit") != "2" || rr.Header().Get("RateLimit-Remaining") != "1" || rr.Header().Get("RateLimit-Reset") != "50" {
+		t.Errorf("unexpected rate limit headers: %v", rr.Header())
+	}
+
+	bid()
+	rr = bid()
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status %v, got %v", http.StatusTooManyRequests, rr.Code)
+	}
+	if rr.Header().Get("Retry-After") != "50" {
+		t.Errorf("expected Retry-After 50, got %q", rr.Header().Get("Retry-After"))
+	}
+	if rr.Body.String() != `{"retryAfterSeconds":50,"type":"Throttled"}` {
+		t.Errorf("unexpected body %s", rr.Body.String())
+	}
+
+	now = now.Add(time.Minute)
+	if rr := bid(); rr.Code == http.StatusTooManyRequests {
+		t.Errorf("expected the limit to reset with the window")
+	}
+}
@@ -0,0 +1,128 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestAmendAuction tests that sellers amend their auctions until the first
+// bid
+func TestAmendAuction(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	var amendments []domain.AuctionAmendedEvent
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		if e, ok := event.(domain.AuctionAmendedEvent); ok {
+			amendments = append(amendments, e)
+		}
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("POST", "/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	amendment := `{"title": "landscape painting", "description": "Oil on canvas", "endsAt": "2018-08-04T03:00:00Z"}`
+	if rr := serve("PATCH", "/auctions/1", buyerJWT, amendment); rr.Code != http.StatusForbidden {
+		t.Errorf("expected a buyer forbidden, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve("PATCH", "/auctions/1", sellerJWT, amendment); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if len(amendments) != 1 || *amendments[0].Title != "landscape painting" {
+		t.Errorf("expected the amendment recorded, got %+v", amendments)
+	}
+
+	var response web.AuctionResponse
+	if err := json.Unmarshal(serve("GET", "/auctions/1", buyerJWT, "").Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.Title != "landscape painting" || response.Description != "Oil on canvas" || !response.Expiry.Equal(startsAt.Add(3*time.Hour)) {
+		t.Errorf("expected the amended auction, got %+v", response)
+	}
+
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr := serve("PATCH", "/auctions/1", sellerJWT, `{"title": "vase"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected amendments rejected once bidding has begun, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve("PATCH", "/auctions/1", sellerJWT, `{}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an empty amendment rejected, got %v: %s", rr.Code, rr.Body.String())
+	}
+}
+
+// TestAmendAuctionModeration tests that amended titles and descriptions are
+// moderated, and amended titles translated again
+func TestAmendAuctionModeration(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt.Add(time.Minute) }
+
+	var amendments []domain.AuctionAmendedEvent
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		if e, ok := event.(domain.AuctionAmendedEvent); ok {
+			amendments = append(amendments, e)
+		}
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+	app.Moderator = domain.NewKeywordModerator([]string{"ivory"}, nil)
+	app.Translator = prefixTranslator{}
+	app.TranslationLanguages = []string{"sv"}
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Accept-Language", "sv")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("POST", "/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "carving", "currency": "VAC"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	if rr := serve("PATCH", "/auctions/1", sellerJWT, `{"description": "Solid ivory"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected the description rejected, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if len(amendments) != 0 {
+		t.Errorf("expected no amendment recorded, got %+v", amendments)
+	}
+
+	if rr := serve("PATCH", "/auctions/1", sellerJWT, `{"title": "wooden carving"}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var response web.AuctionResponse
+	if err := json.Unmarshal(serve("GET", "/auctions/1", sellerJWT, "").Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.Title != "sv: wooden carving" {
+		t.Errorf("expected the new title translated, got %q", response.Title)
+	}
+}
@@ -0,0 +1,138 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestAsyncBids tests queueing bids and polling their outcome
+func TestAsyncBids(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt.Add(time.Minute) }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	auction := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: startsAt, Auction: auction}})
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(app *web.App, method, url, jwt, body string, async bool) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		if async {
+			req.Header.Set("Prefer", "respond-async")
+		}
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	submit := func(app *web.App, jwt, body string) string {
+		rr := serve(app, "POST", "/auctions/1/bids", jwt, body, true)
+		if rr.Code != http.StatusAccepted {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusAccepted, rr.Code, rr.Body.String())
+		}
+		var accepted struct {
+			CommandId string `json:"commandId"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &accepted)
+		if rr.Header().Get("Location") != "/commands/"+accepted.CommandId+"/status" {
+			t.Errorf("expected a Location of the status, got %q", rr.Header().Get("Location"))
+		}
+		return accepted.CommandId
+	}
+	poll := func(app *web.App, id, jwt string) web.CommandStatusResponse {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			rr := serve(app, "GET", "/commands/"+id+"/status", jwt, "", false)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+			}
+			var status struct {
+				Status string            `json:"status"`
+				Code   int               `json:"code"`
+				Events []json.RawMessage `json:"events"`
+			}
+			json.Unmarshal(rr.Body.Bytes(), &status)
+			if status.Status == web.CommandCompleted || time.Now().After(deadline) {
+				response := web.CommandStatusResponse{ID: id, Status: status.Status, Code: status.Code}
+				for _, e := range status.Events {
+					event, _ := domain.UnmarshalEvent(e)
+					response.Events = append(response.Events, event)
+				}
+				return response
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+	app.AsyncBids = web.NewAsyncCommands(2, 10, getCurrentTime)
+
+	t.Run("Synchronous", func(t *testing.T) {
+		if rr := serve(app, "POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`, false); rr.Code != http.StatusOK {
+			t.Errorf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+	})
+
+	t.Run("Accepted", func(t *testing.T) {
+		id := submit(app, buyerJWT, `{"amount": 20}`)
+		status := poll(app, id, buyerJWT)
+		if status.Status != web.CommandCompleted || status.Code != http.StatusOK || len(status.Events) != 1 {
+			t.Fatalf("expected a completed bid with its event, got %+v", status)
+		}
+		if accepted, ok := status.Events[0].(domain.BidAcceptedEvent); !ok || accepted.Bid.Amount != 20 {
+			t.Errorf("expected the bid to be accepted, got %+v", status.Events[0])
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		id := submit(app, sellerJWT, `{"amount": 30}`)
+		status := poll(app, id, sellerJWT)
+		if status.Status != web.CommandCompleted || status.Code != http.StatusBadRequest || len(status.Events) != 0 {
+			t.Errorf("expected a completed rejection, got %+v", status)
+		}
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		id := submit(app, buyerJWT, `{"amount": 40}`)
+		if rr := serve(app, "GET", "/commands/"+id+"/status", sellerJWT, "", false); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %v, got %v", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		if rr := serve(app, "POST", "/auctions/1/bids", "", `{"amount": 50}`, true); rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected status %v, got %v", http.StatusUnauthorized, rr.Code)
+		}
+		id := submit(app, buyerJWT, `{"amount": 60}`)
+		if rr := serve(app, "GET", "/commands/"+id+"/status", "", "", false); rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected status %v, got %v", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("QueueFull", func(t *testing.T) {
+		// Without workers the queue fills up
+		app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+		app.AsyncBids = web.NewAsyncCommands(0, 1, getCurrentTime)
+		id := submit(app, buyerJWT, `{"amount": 10}`)
+		rr := serve(app, "GET", "/commands/"+id+"/status", buyerJWT, "", false)
+		var status web.CommandStatusResponse
+		json.Unmarshal(rr.Body.Bytes(), &status)
+		if status.Status != web.CommandQueued {
+			t.Errorf("expected the bid to be queued, got %s", rr.Body.String())
+		}
+		rr = serve(app, "POST", "/auctions/1/bids", buyerJWT, `{"amount": 20}`, true)
+		if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+			t.Errorf("expected status %v with Retry-After, got %v", http.StatusServiceUnavailable, rr.Code)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestBulkRevise tests revising the auctions of a seller in one request
+func TestBulkRevise(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	expiry := startsAt.Add(time.Hour)
+	getCurrentTime := func() time.Time { return startsAt.Add(time.Minute) }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	other := domain.NewBuyerOrSeller("a3", "Other")
+	auctionType := domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions())
+	winter := domain.NewAuction(1, startsAt, "Skis", expiry, seller, auctionType, domain.VAC)
+	winter.Tags = []string{"winter"}
+	bidded := domain.NewAuction(2, startsAt, "Sled", expiry, seller, auctionType, domain.VAC)
+	bidded.Tags = []string{"winter"}
+	summer := domain.NewAuction(3, startsAt, "Surfboard", expiry, seller, auctionType, domain.SEK)
+	othersWinter := domain.NewAuction(4, startsAt, "Skates", expiry, other, auctionType, domain.VAC)
+	othersWinter.Tags = []string{"winter"}
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: winter},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: bidded},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: summer},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: othersWinter},
+		domain.BidAcceptedEvent{Time: startsAt, Bid: domain.Bid{ForAuction: 2, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: startsAt.Add(time.Second), Amount: 10}},
+	})
+
+	var recordedCommands []domain.Command
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error {
+		recordedCommands = append(recordedCommands, command)
+		return nil
+	}
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	revise := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/auctions:revise", bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo=")
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("ByTag", func(t *testing.T) {
+		rr := revise(`{"tag": "winter", "extendBy": "24h", "addTags": ["sale"]}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var response web.BulkReviseResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.Revised != 1 || response.Failed != 1 || len(response.Results) != 2 {
+			t.Fatalf("expected 1 revised and 1 failed auction, got %s", rr.Body.String())
+		}
+		if r := response.Results[0]; r.ID != 1 || r.Status != "revised" {
+			t.Errorf("expected auction 1 to be revised, got %+v", r)
+		}
+		if r := response.Results[1]; r.ID != 2 || r.Status != "failed" || r.Error["type"] != "AuctionHasBids" {
+			t.Errorf("expected auction 2 to fail for its bids, got %+v", r)
+		}
+
+		revised := app.State.GetRepository()[1].Auction
+		if !revised.Expiry.Equal(expiry.Add(24*time.Hour)) || !revised.HasTag("sale") {
+			t.Errorf("expected auction 1 to be extended and tagged, got %+v", revised)
+		}
+		if app.State.GetRepository()[4].Auction.HasTag("sale") {
+			t.Errorf("expected the auctions of other sellers to be left alone")
+		}
+		if len(recordedCommands) != 2 || len(recordedEvents) != 1 {
+			t.Errorf("expected 2 commands and 1 event, got %d and %d", len(recordedCommands), len(recordedEvents))
+		}
+	})
+
+	t.Run("ByFilter", func(t *testing.T) {
+		rr := revise(`{"filter": "currency eq SEK", "addTags": ["summer"]}`)
+		var response web.BulkReviseResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.Revised != 1 || response.Results[0].ID != 3 {
+			t.Errorf("expected only auction 3 to be revised, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("InvalidItem", func(t *testing.T) {
+		app.Commands.Use(func(next domain.CommandHandler) domain.CommandHandler {
+			return func(ctx context.Context, cmd domain.Command, repo domain.Repository) (domain.Event, domain.Repository, error) {
+				if id, _ := domain.CommandAuctionId(cmd); id == 3 {
+					return nil, repo, domain.NewInvalidCommandError("ReviseListing", []domain.FieldError{{Field: "addTags", Code: domain.FieldRequired}})
+				}
+				return next(ctx, cmd, repo)
+			}
+		})
+
+		rr := revise(`{"filter": "currency eq SEK", "addTags": ["autumn"]}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var response web.BulkReviseResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.Failed != 1 || len(response.Results) != 1 {
+			t.Fatalf("expected auction 3 to fail, got %s", rr.Body.String())
+		}
+		if r := response.Results[0]; r.Status != "failed" || r.Error["type"] != string(domain.ErrorInvalidCommand) || r.Error["errors"] == nil {
+			t.Errorf("expected auction 3 to fail as an invalid command, got %+v", r)
+		}
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		for _, body := range []string{
+			`{"tag": "winter"}`,
+			`{"tag": "winter", "extendBy": "-1h"}`,
+			`{"filter": "nope eq 1", "addTags": ["sale"]}`,
+		} {
+			if rr := revise(body); rr.Code != http.StatusBadRequest {
+				t.Errorf("expected status %v for %s, got %v", http.StatusBadRequest, body, rr.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestBuyNow tests that buying an auction now ends it with the buyer as the
+// winner, until a bid exceeds the threshold
+func TestBuyNow(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	otherJWT := "eyJzdWIiOiJhMyIsICJuYW1lIjoiT3RoZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func(id string) web.AuctionResponse {
+		rr := serve("GET", "/auctions/"+id, buyerJWT, "")
+		var response web.AuctionResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response
+	}
+
+	for _, request := range []struct{ jwt, url, body string }{
+		{sellerJWT, "/auctions", `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC", "typ": "English|0|0|0|100|0"}`},
+		{sellerJWT, "/auctions", `{"id": 2, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "vase", "currency": "VAC", "typ": "English|0|0|0|100|0"}`},
+		{otherJWT, "/auctions/2/bids", `{"amount": 10}`},
+	} {
+		if rr := serve("POST", request.url, request.jwt, request.body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	if price := read("1").BuyNowPrice; price == nil || *price != 100 {
+		t.Errorf("expected a buy now price of 100, got %v", price)
+	}
+	rr := serve("POST", "/auctions/1/buy-now", buyerJWT, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	auction := read("1")
+	if auction.Winner == nil || *auction.Winner != "a2" || *auction.WinnerPrice != 100 || auction.BuyNowPrice != nil {
+		t.Errorf("expected a2 to win at 100 with buy now withdrawn, got %+v", auction)
+	}
+	if rr := serve("POST", "/auctions/1/bids", otherJWT, `{"amount": 200}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected bids rejected once bought, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	// The first bid withdrew the buy now price of the other auction
+	if price := read("2").BuyNowPrice; price != nil {
+		t.Errorf("expected no buy now price, got %v", *price)
+	}
+	rr = serve("POST", "/auctions/2/buy-now", buyerJWT, "")
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+	var body map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &body)
+	if body["type"] != "BuyNowNotAvailable" {
+		t.Errorf("expected BuyNowNotAvailable, got %v", body)
+	}
+}
@@ -0,0 +1,69 @@
+package web_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestCanary tests probing the API end to end
+func TestCanary(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return now }
+
+	var mu sync.Mutex
+	var events []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+	server := httptest.NewServer(app.Router)
+	defer server.Close()
+	canary := web.NewCanary(server.URL, server.Client(), getCurrentTime)
+
+	t.Run("WithoutEventFeed", func(t *testing.T) {
+		if err := canary.Probe(); err != nil {
+			t.Fatalf("expected the probe to pass, got %v", err)
+		}
+	})
+
+	// The canary's auction stays out of listings
+	listings := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/auctions", nil)
+	app.Router.ServeHTTP(listings, req)
+	if strings.Contains(listings.Body.String(), "canary") {
+		t.Errorf("expected the canary auction hidden, got %s", listings.Body.String())
+	}
+
+	t.Run("FollowsEventFeed", func(t *testing.T) {
+		app.ReadEventsSince = func(position int64) ([]domain.Event, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]domain.Event(nil), events[position:]...), nil
+		}
+		now = now.Add(time.Minute)
+		if err := canary.Probe(); err != nil {
+			t.Fatalf("expected the probe to pass, got %v", err)
+		}
+	})
+
+	t.Run("DetectsMissingEvents", func(t *testing.T) {
+		app.ReadEventsSince = func(position int64) ([]domain.Event, error) {
+			return nil, nil
+		}
+		now = now.Add(time.Minute)
+		err := canary.Probe()
+		if err == nil || !strings.Contains(err.Error(), "read events") {
+			t.Errorf("expected the probe to fail on the events, got %v", err)
+		}
+	})
+}
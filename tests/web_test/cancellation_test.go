@@ -0,0 +1,154 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestCancelAuction tests that sellers cancel auctions without bids, and
+// support those with bids by overriding them
+func TestCancelAuction(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	var cancellations []domain.AuctionCancelledEvent
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		if e, ok := event.(domain.AuctionCancelledEvent); ok {
+			cancellations = append(cancellations, e)
+		}
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, request := range []struct{ url, jwt, body string }{
+		{"/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC"}`},
+		{"/auctions", sellerJWT, `{"id": 2, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "vase", "currency": "VAC"}`},
+		{"/auctions/2/bids", buyerJWT, `{"amount": 10}`},
+	} {
+		if rr := serve("POST", request.url, request.jwt, request.body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	if rr := serve("DELETE", "/auctions/1", buyerJWT, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("expected a buyer forbidden, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve("DELETE", "/auctions/1?reason=sold+elsewhere", sellerJWT, ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if len(cancellations) != 1 || cancellations[0].Reason != "sold elsewhere" {
+		t.Errorf("expected the cancellation recorded with its reason, got %+v", cancellations)
+	}
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`); rr.Code != http.StatusConflict {
+		t.Errorf("expected bids refused, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var response web.AuctionResponse
+	if err := json.Unmarshal(serve("GET", "/auctions/1", buyerJWT, "").Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.CancelledAt == nil || !response.CancelledAt.Equal(now) {
+		t.Errorf("expected the auction read as cancelled, got %+v", response)
+	}
+
+	if rr := serve("DELETE", "/auctions/2", sellerJWT, ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an auction with bids kept, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve("DELETE", "/auctions/2?override=true", sellerJWT, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("expected the seller unable to override, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve("DELETE", "/auctions/2?override=true", supportJWT, ""); rr.Code != http.StatusOK {
+		t.Errorf("expected support to override the bids, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if len(cancellations) != 2 || !cancellations[1].Override || cancellations[1].Bids != 1 {
+		t.Errorf("expected the override recorded, got %+v", cancellations)
+	}
+}
+
+// TestCancelSealedBidAuction tests that cancelling a sealed bid auction with
+// bids doesn't disclose them
+func TestCancelSealedBidAuction(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	otherJWT := "eyJzdWIiOiJhMyIsICJuYW1lIjoiT3RoZXIiLCAidV90eXAiOiIwIn0K"
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, request := range []struct{ url, jwt, body string }{
+		{"/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC", "typ": "Blind"}`},
+		{"/auctions/1/bids", buyerJWT, `{"amount": 10}`},
+		{"/auctions/1/bids", otherJWT, `{"amount": 20}`},
+	} {
+		if rr := serve("POST", request.url, request.jwt, request.body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+	if rr := serve("DELETE", "/auctions/1?override=true", supportJWT, ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response web.AuctionResponse
+	if err := json.Unmarshal(serve("GET", "/auctions/1", buyerJWT, "").Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(response.Bids) != 1 || response.Bids[0].Bidder.ID != "a2" {
+		t.Errorf("expected the bidder to see only their own bid, got %+v", response.Bids)
+	}
+	if err := json.Unmarshal(serve("GET", "/auctions/1", sellerJWT, "").Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(response.Bids) != 0 {
+		t.Errorf("expected the seller to see no bids, got %+v", response.Bids)
+	}
+
+	var items []web.AuctionListItem
+	if err := json.Unmarshal(serve("GET", "/auctions", buyerJWT, "").Body.Bytes(), &items); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(items) != 1 || items[0].CurrentPrice != nil {
+		t.Errorf("expected no price listed, got %+v", items)
+	}
+	var lite web.LiteAuction
+	if err := json.Unmarshal(serve("GET", "/lite/v1/auctions/1", buyerJWT, "").Body.Bytes(), &lite); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if lite.HighestBid != nil {
+		t.Errorf("expected no highest bid, got %d", *lite.HighestBid)
+	}
+	if rr := serve("GET", "/auctions/1/history", buyerJWT, ""); rr.Code != http.StatusConflict {
+		t.Errorf("expected no price history, got %v: %s", rr.Code, rr.Body.String())
+	}
+}
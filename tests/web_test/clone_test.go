@@ -0,0 +1,104 @@
+package web_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestCloneAuction tests relisting an auction through a clone
+func TestCloneAuction(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(2 * time.Hour)
+	getCurrentTime := func() time.Time { return now }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	sealed := domain.NewSingleSealedBidType(domain.Vickrey)
+	original := domain.NewAuction(1, startsAt, "Painting", startsAt.Add(time.Hour), seller, sealed, domain.SEK)
+	original.Translations = map[string]string{"sv": "Målning"}
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: original},
+	})
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	clone := func(url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	schedule := `{"id": 2, "startsAt": "2018-08-05T00:00:00Z", "endsAt": "2018-08-06T00:00:00Z"}`
+
+	t.Run("NotTheSeller", func(t *testing.T) {
+		rr := clone("/auctions/1:clone", buyerJWT, schedule)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("UnknownAuction", func(t *testing.T) {
+		rr := clone("/auctions/9:clone", sellerJWT, schedule)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %v, got %v", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("ScheduleInThePast", func(t *testing.T) {
+		rr := clone("/auctions/1:clone", sellerJWT, `{"id": 2, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z"}`)
+		if rr.Code == http.StatusOK {
+			t.Errorf("expected the clone to be rejected, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		recordedEvents = nil
+		rr := clone("/auctions/1:clone", sellerJWT, schedule)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		if len(recordedEvents) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recordedEvents))
+		}
+		added, ok := recordedEvents[0].(domain.AuctionAddedEvent)
+		if !ok {
+			t.Fatalf("expected an AuctionAdded event, got %#v", recordedEvents[0])
+		}
+		cloned := added.Auction
+		if cloned.ID != 2 || cloned.ClonedFrom == nil || *cloned.ClonedFrom != 1 {
+			t.Errorf("expected auction 2 cloned from 1, got %+v", cloned)
+		}
+		if cloned.Title != "Painting" || cloned.Type != sealed || cloned.Currency != domain.SEK || cloned.Translations["sv"] != "Målning" {
+			t.Errorf("expected the settings of the original, got %+v", cloned)
+		}
+		if !cloned.StartsAt.Equal(startsAt.Add(24*time.Hour)) || !cloned.Expiry.Equal(startsAt.Add(48*time.Hour)) {
+			t.Errorf("expected the new schedule, got %v to %v", cloned.StartsAt, cloned.Expiry)
+		}
+		if _, ok := app.State.GetRepository()[2]; !ok {
+			t.Errorf("expected the clone to be listed")
+		}
+	})
+
+	t.Run("ExistingId", func(t *testing.T) {
+		rr := clone("/auctions/1:clone", sellerJWT, `{"id": 1, "startsAt": "2018-08-05T00:00:00Z", "endsAt": "2018-08-06T00:00:00Z"}`)
+		if rr.Code == http.StatusOK {
+			t.Errorf("expected the clone to be rejected, got %s", rr.Body.String())
+		}
+	})
+}
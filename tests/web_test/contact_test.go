@@ -0,0 +1,106 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestContactThread tests the masked messaging between the winner and the
+// seller of a closed auction
+func TestContactThread(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	expect := func(rr *httptest.ResponseRecorder, status int) {
+		t.Helper()
+		if rr.Code != status {
+			t.Fatalf("expected status %v, got %v: %s", status, rr.Code, rr.Body.String())
+		}
+	}
+
+	expect(serve("POST", "/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "bike", "currency": "VAC"}`), http.StatusOK)
+	expect(serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`), http.StatusOK)
+
+	t.Run("ClosedBeforeTheAuctionCloses", func(t *testing.T) {
+		expect(serve("POST", "/auctions/1/contact/messages", buyerJWT, `{"text": "hi"}`), http.StatusConflict)
+	})
+
+	now = startsAt.Add(2 * time.Hour)
+
+	t.Run("RelaysMaskedMessages", func(t *testing.T) {
+		rr := serve("POST", "/auctions/1/contact/messages", buyerJWT, `{"text": "Reach me on buyer@example.com", "attachments": [{"name": "id.png", "contentType": "image/png", "size": 2048}]}`)
+		expect(rr, http.StatusOK)
+		expect(serve("POST", "/auctions/1/contact/messages", sellerJWT, `{"text": "Friday works"}`), http.StatusOK)
+
+		rr = serve("GET", "/auctions/1/contact", sellerJWT, "")
+		expect(rr, http.StatusOK)
+		if strings.Contains(rr.Body.String(), "a2") || strings.Contains(rr.Body.String(), "buyer@example.com") {
+			t.Errorf("expected the buyer masked, got %s", rr.Body.String())
+		}
+		var thread web.ContactThreadResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &thread); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if thread.Role != domain.ContactSeller || len(thread.Messages) != 2 || thread.Messages[0].From != domain.ContactWinner || len(thread.Messages[0].Attachments) != 1 {
+			t.Errorf("expected the seller's view of both messages, got %+v", thread)
+		}
+	})
+
+	t.Run("RejectsOutsidersAndInvalidMessages", func(t *testing.T) {
+		expect(serve("GET", "/auctions/1/contact", supportJWT, ""), http.StatusForbidden)
+		rr := serve("POST", "/auctions/1/contact/messages", buyerJWT, `{"text": "`+strings.Repeat("a", domain.MaxContactMessageLength+1)+`"}`)
+		expect(rr, http.StatusBadRequest)
+		if !strings.Contains(rr.Body.String(), domain.FieldTooLong) {
+			t.Errorf("expected the text too long, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("ReportsReceivedMessages", func(t *testing.T) {
+		expect(serve("POST", "/auctions/1/contact/messages/2/report", buyerJWT, `{"reason": "Harassment"}`), http.StatusOK)
+		expect(serve("POST", "/auctions/1/contact/messages/1/report", buyerJWT, `{"reason": "Harassment"}`), http.StatusNotFound)
+
+		rr := serve("POST", "/auctions/1/contact/messages/1/report", sellerJWT, `{"reason": "Fraud", "text": "asked to pay outside"}`)
+		expect(rr, http.StatusOK)
+		if strings.Contains(rr.Body.String(), "a2") {
+			t.Errorf("expected the reported user hidden, got %s", rr.Body.String())
+		}
+		var reports []web.ReportResponse
+		rr = serve("GET", "/reports", supportJWT, "")
+		expect(rr, http.StatusOK)
+		if err := json.Unmarshal(rr.Body.Bytes(), &reports); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		last := reports[len(reports)-1]
+		if last.Target.UserId == nil || *last.Target.UserId != "a2" || last.Evidence[0] != "contact/1/1" {
+			t.Errorf("expected a report about the buyer's message, got %+v", last.Report)
+		}
+	})
+
+	t.Run("ClosedAfterRetention", func(t *testing.T) {
+		now = startsAt.Add(time.Hour + domain.ContactRetention + time.Minute)
+		expect(serve("GET", "/auctions/1/contact", buyerJWT, ""), http.StatusConflict)
+	})
+}
@@ -0,0 +1,84 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestCountdown tests the time synchronization endpoints
+func TestCountdown(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(-time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	options := domain.DefaultTimedAscendingOptions()
+	options.TimeFrame = 30 * time.Minute
+	auction := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), domain.NewBuyerOrSeller("a1", "Test"), domain.NewTimedAscendingType(options), domain.VAC)
+	repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: startsAt, Auction: auction}})
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	countdown := func() web.AuctionCountdownResponse {
+		req, _ := http.NewRequest("GET", "/auctions/1/countdown", nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var response web.AuctionCountdownResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return response
+	}
+
+	t.Run("ServerTime", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/time", nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+
+		var response web.ServerTimeResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.ServerTimeMillis != now.UnixNano()/int64(time.Millisecond) {
+			t.Errorf("expected server time %v, got %v", now, response.ServerTime)
+		}
+	})
+
+	t.Run("BeforeStart", func(t *testing.T) {
+		response := countdown()
+		if response.StartsInMillis != time.Minute.Milliseconds() {
+			t.Errorf("expected to start in a minute, got %dms", response.StartsInMillis)
+		}
+		if response.RemainingMillis != (61 * time.Minute).Milliseconds() {
+			t.Errorf("expected 61 minutes remaining, got %dms", response.RemainingMillis)
+		}
+	})
+
+	t.Run("ExtendedByLateBid", func(t *testing.T) {
+		bid := domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: startsAt.Add(50 * time.Minute), Amount: 10}
+		app.State.UpdateRepository(domain.ApplyEvents(app.State.GetRepository(), []domain.Event{domain.BidAcceptedEvent{Time: bid.At, Bid: bid}}))
+		now = startsAt.Add(55 * time.Minute)
+
+		response := countdown()
+		if !response.Expiry.Equal(startsAt.Add(80 * time.Minute)) {
+			t.Errorf("expected the expiry to be extended to %v, got %v", startsAt.Add(80*time.Minute), response.Expiry)
+		}
+		if response.RemainingMillis != (25 * time.Minute).Milliseconds() {
+			t.Errorf("expected 25 minutes remaining, got %dms", response.RemainingMillis)
+		}
+	})
+
+	t.Run("Ended", func(t *testing.T) {
+		now = startsAt.Add(2 * time.Hour)
+		response := countdown()
+		if !response.HasEnded || response.RemainingMillis != 0 {
+			t.Errorf("expected the auction to have ended, got %+v", response)
+		}
+	})
+}
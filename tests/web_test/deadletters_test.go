@@ -0,0 +1,101 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+	"auction-site-go/internal/web"
+)
+
+// TestDeadLetters tests keeping failed commands and retrying them
+func TestDeadLetters(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt.Add(time.Minute) }
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+	deadLetters, _ := persistence.OpenDeadLetters("")
+	app.DeadLetters = deadLetters
+
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	list := func(url string) []domain.DeadLetter {
+		rr := serve("GET", url, supportJWT, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		var letters []domain.DeadLetter
+		if err := json.Unmarshal(rr.Body.Bytes(), &letters); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return letters
+	}
+
+	// The auction doesn't exist yet, so the bid fails
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %v, got %v", http.StatusNotFound, rr.Code)
+	}
+
+	t.Run("SupportOnly", func(t *testing.T) {
+		if rr := serve("GET", "/admin/dead-letters", buyerJWT, ""); rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	letters := list("/admin/dead-letters")
+	if len(letters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(letters))
+	}
+	if _, ok := letters[0].Command.(domain.PlaceBidCommand); !ok {
+		t.Errorf("expected the bid, got %+v", letters[0].Command)
+	}
+
+	t.Run("RetryFailing", func(t *testing.T) {
+		if rr := serve("POST", "/admin/dead-letters/1/retry", supportJWT, ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %v, got %v", http.StatusNotFound, rr.Code)
+		}
+		letters := list("/admin/dead-letters")
+		if len(letters) != 1 || letters[0].Retries != 1 {
+			t.Errorf("expected the retry to be recorded on the same dead letter, got %+v", letters)
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		seller := domain.NewBuyerOrSeller("a1", "Test")
+		auction := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+		app.State.UpdateRepository(domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: startsAt, Auction: auction}}))
+
+		recordedEvents = nil
+		rr := serve("POST", "/admin/dead-letters/1/retry", supportJWT, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if len(recordedEvents) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recordedEvents))
+		}
+		if len(list("/admin/dead-letters")) != 0 || len(list("/admin/dead-letters?all=true")) != 1 {
+			t.Errorf("expected the dead letter to be resolved")
+		}
+		if rr := serve("POST", "/admin/dead-letters/1/retry", supportJWT, ""); rr.Code != http.StatusConflict {
+			t.Errorf("expected status %v, got %v", http.StatusConflict, rr.Code)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package web_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestStoreDegradation tests that reads keep working and writes are rejected
+// cleanly while the store is down
+func TestStoreDegradation(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time {
+		return now
+	}
+
+	var storeErr error
+	onCommand := func(command domain.Command) error { return storeErr }
+	onEvent := func(event domain.Event) error { return storeErr }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", sellerJWT)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	createAuction := func(id string) *httptest.ResponseRecorder {
+		return request("POST", "/auctions", `{"id": `+id+`, "startsAt": "2018-01-01T10:00:00.000Z", "endsAt": "2019-01-01T10:00:00.000Z", "title": "auction"}`)
+	}
+
+	if rr := createAuction("1"); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+	}
+
+	storeErr = errors.New("connection refused")
+	if rr := createAuction("2"); rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the failing write to return %v, got %v", http.StatusInternalServerError, rr.Code)
+	}
+
+	t.Run("RejectsWrites", func(t *testing.T) {
+		rr := createAuction("3")
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %v, got %v", http.StatusServiceUnavailable, rr.Code)
+		}
+		if rr.Header().Get("Retry-After") != "5" {
+			t.Errorf("expected Retry-After 5, got %q", rr.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("ServesStaleReads", func(t *testing.T) {
+		rr := request("GET", "/auctions/1", "")
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		if rr.Header().Get("Warning") == "" || rr.Header().Get("X-Stale-Since") != "2018-08-04T00:00:00Z" {
+			t.Errorf("expected staleness markers, got %v", rr.Header())
+		}
+	})
+
+	t.Run("RecoversAfterRetryInterval", func(t *testing.T) {
+		storeErr = nil
+		now = now.Add(5 * time.Second)
+		if rr := createAuction("4"); rr.Code != http.StatusOK {
+			t.Errorf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		if rr := request("GET", "/auctions/1", ""); rr.Header().Get("Warning") != "" {
+			t.Errorf("expected no staleness markers after recovery")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestDutchAuction tests bidding on a Dutch auction at its current price
+func TestDutchAuction(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(25 * time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func() web.AuctionResponse {
+		rr := serve("GET", "/auctions/1", buyerJWT, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		var response web.AuctionResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response
+	}
+
+	body := `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "vase", "currency": "VAC", "typ": "Dutch|100|15|600|50"}`
+	if rr := serve("POST", "/auctions", sellerJWT, body); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if auction := read(); auction.CurrentPrice == nil || *auction.CurrentPrice != 70 {
+		t.Fatalf("expected a current price of 70, got %+v", auction.CurrentPrice)
+	}
+
+	rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 60}`)
+	if rr.Code != http.StatusBadRequest || !bytes.Contains(rr.Body.Bytes(), []byte(`"price":70`)) {
+		t.Fatalf("expected the bid below the price rejected, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 70}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	auction := read()
+	if auction.Winner == nil || *auction.Winner != "a2" || auction.WinnerPrice == nil || *auction.WinnerPrice != 70 || auction.CurrentPrice != nil {
+		t.Errorf("expected a2 to win at 70, got %+v", auction)
+	}
+}
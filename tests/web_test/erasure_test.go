@@ -0,0 +1,84 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestForgetUser tests erasing the personal data of a user
+func TestForgetUser(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt.Add(time.Minute) }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	repo := domain.ApplyEvents(domain.Repository{}, []domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.Auction{
+			ID:       1,
+			StartsAt: startsAt,
+			Title:    "auction",
+			Expiry:   startsAt.Add(time.Hour),
+			Seller:   domain.NewBuyerOrSeller("a1", "Test"),
+			Type:     domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()),
+			Currency: domain.VAC,
+		}},
+		domain.BidAcceptedEvent{Time: startsAt.Add(time.Second), Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: startsAt.Add(time.Second), Amount: 10}},
+	})
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	serve := func(method, url, jwt string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(""))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("NotAvailable", func(t *testing.T) {
+		if rr := serve("POST", "/admin/users/a2/forget", supportJWT); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %v, got %v", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	var forgotten []domain.UserId
+	app.ForgetUser = func(userId domain.UserId) error {
+		forgotten = append(forgotten, userId)
+		return nil
+	}
+
+	t.Run("SupportOnly", func(t *testing.T) {
+		if rr := serve("POST", "/admin/users/a2/forget", sellerJWT); rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	rr := serve("POST", "/admin/users/a2/forget", supportJWT)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+	}
+	var response web.ForgetUserResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.UserId != "a2" || response.Auctions != 1 || len(forgotten) != 1 {
+		t.Errorf("expected a2 forgotten in 1 auction, got %+v, %v", response, forgotten)
+	}
+
+	// The auction in memory no longer has the bidder's name
+	bids := app.State.GetRepository()[1].State.GetBids()
+	if len(bids) != 1 || bids[0].Bidder.Name != domain.ForgottenName || bids[0].Bidder.ID != "a2" {
+		t.Errorf("expected the bidder forgotten, got %+v", bids)
+	}
+	if seller := app.State.GetRepository()[1].Auction.Seller; seller.Name != "Test" {
+		t.Errorf("expected the seller kept, got %+v", seller)
+	}
+}
@@ -0,0 +1,112 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestEventFeed tests reading events by position
+func TestEventFeed(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return at }
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	// Events with colliding timestamps, only their positions order them
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	var stored []domain.Event
+	for i := 1; i <= 3; i++ {
+		auction := domain.NewAuction(domain.AuctionId(i), at, "Auction", at.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+		stored = append(stored, domain.AuctionAddedEvent{Time: at, Auction: auction})
+	}
+	app.ReadEventsSince = func(position int64) ([]domain.Event, error) {
+		return stored[position:], nil
+	}
+
+	read := func(url, jwt string) (web.EventFeedResponse, int) {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+
+		var response struct {
+			Events []struct {
+				Position int64           `json:"position"`
+				Event    json.RawMessage `json:"event"`
+			} `json:"events"`
+			Position int64 `json:"position"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		feed := web.EventFeedResponse{Position: response.Position}
+		for _, e := range response.Events {
+			event, _ := domain.UnmarshalEvent(e.Event)
+			feed.Events = append(feed.Events, web.PositionedEventResponse{Position: e.Position, Event: event})
+		}
+		return feed, rr.Code
+	}
+
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9" // sub=s1, support
+
+	t.Run("SupportOnly", func(t *testing.T) {
+		if _, code := read("/events", "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"); code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, code)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		feed, code := read("/events?limit=2", supportJWT)
+		if code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, code)
+		}
+		if len(feed.Events) != 2 || feed.Events[0].Position != 1 || feed.Events[1].Position != 2 || feed.Position != 2 {
+			t.Fatalf("expected events at positions 1 and 2, got %+v", feed)
+		}
+
+		feed, _ = read("/events?since=2", supportJWT)
+		if len(feed.Events) != 1 || feed.Events[0].Position != 3 || feed.Position != 3 {
+			t.Fatalf("expected the event at position 3, got %+v", feed)
+		}
+		if id, _ := domain.EventAuctionId(feed.Events[0].Event); id != 3 {
+			t.Errorf("expected auction 3 at position 3, got %d", id)
+		}
+
+		feed, _ = read("/events?since=3", supportJWT)
+		if len(feed.Events) != 0 || feed.Position != 3 {
+			t.Errorf("expected no events after the end, got %+v", feed)
+		}
+	})
+
+	t.Run("EventIds", func(t *testing.T) {
+		app.ReadEventIdsSince = func(position int64) ([]string, error) {
+			return domain.EventIds(stored)[position:], nil
+		}
+		defer func() { app.ReadEventIdsSince = nil }()
+
+		req, _ := http.NewRequest("GET", "/events?since=1", nil)
+		req.Header.Set("x-jwt-payload", supportJWT)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		var response struct {
+			Events []struct {
+				ID string `json:"id"`
+			} `json:"events"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if len(response.Events) != 2 || response.Events[0].ID != domain.NewEventId("auction/2", 1) {
+			t.Errorf("expected the IDs of the events, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("InvalidPosition", func(t *testing.T) {
+		if _, code := read("/events?since=-1", supportJWT); code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, code)
+		}
+	})
+}
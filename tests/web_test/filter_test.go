@@ -0,0 +1,151 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestListFilters tests the filter expressions of the list endpoints
+func TestListFilters(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	auctionType := domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions())
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(1, startsAt, "Old car", startsAt.Add(24*time.Hour), seller, auctionType, domain.VAC)},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(2, startsAt, "Bicycle", startsAt.Add(48*time.Hour), seller, auctionType, domain.SEK)},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(3, startsAt, "It's a car", startsAt.Add(72*time.Hour), seller, auctionType, domain.VAC)},
+		domain.BidAcceptedEvent{Time: startsAt, Bid: domain.Bid{ForAuction: 2, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: startsAt.Add(time.Second), Amount: 12}},
+	})
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	list := func(filter string) ([]domain.AuctionId, int) {
+		req, _ := http.NewRequest("GET", "/auctions?filter="+url.QueryEscape(filter), nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+
+		var items []web.AuctionListItem
+		json.Unmarshal(rr.Body.Bytes(), &items)
+		ids := make([]domain.AuctionId, len(items))
+		for i, item := range items {
+			ids[i] = item.ID
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids, rr.Code
+	}
+
+	tests := []struct {
+		name     string
+		filter   string
+		expected []domain.AuctionId
+	}{
+		{"NoFilter", "", []domain.AuctionId{1, 2, 3}},
+		{"Equality", "currency eq VAC", []domain.AuctionId{1, 3}},
+		{"Contains", "title contains CAR", []domain.AuctionId{1, 3}},
+		{"QuotedString", "title eq 'It''s a car'", []domain.AuctionId{3}},
+		{"Range", "expiry between 2018-08-05T00:00:00Z and 2018-08-06T00:00:00Z", []domain.AuctionId{1, 2}},
+		{"AndBindsTighterThanOr", "id eq 2 or currency eq VAC and id gt 1", []domain.AuctionId{2, 3}},
+		{"Parentheses", "(id eq 2 or currency eq VAC) and id gt 1", []domain.AuctionId{2, 3}},
+		{"NoMatch", "id ge 4", []domain.AuctionId{}},
+		{"BidCount", "bidCount gt 0 and status eq Open", []domain.AuctionId{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, code := list(tt.filter)
+			if code != http.StatusOK {
+				t.Fatalf("expected status %v, got %v", http.StatusOK, code)
+			}
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, ids)
+				}
+			}
+		})
+	}
+
+	invalid := []string{
+		"seller eq a1",
+		"id eq abc",
+		"expiry gt tomorrow",
+		"id contains 1",
+		"(id eq 1",
+		"title eq 'unterminated",
+		"id eq 1 id eq 2",
+		"id between 1 2",
+	}
+	for _, filter := range invalid {
+		t.Run("Invalid "+filter, func(t *testing.T) {
+			if _, code := list(filter); code != http.StatusBadRequest {
+				t.Errorf("expected status %v, got %v", http.StatusBadRequest, code)
+			}
+		})
+	}
+}
+
+// TestAuctionListSummary tests the current price, bid count and status of listed auctions
+func TestAuctionListSummary(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	bid := func(auction domain.AuctionId, amount int64) domain.Event {
+		return domain.BidAcceptedEvent{Time: startsAt, Bid: domain.Bid{ForAuction: auction, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: startsAt.Add(time.Second), Amount: amount}}
+	}
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(2, startsAt, "Painting", startsAt.Add(time.Hour), seller, domain.NewSingleSealedBidType(domain.Blind), domain.VAC)},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: domain.NewAuction(3, startsAt.Add(time.Hour), "Bicycle", startsAt.Add(2*time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)},
+		bid(1, 10), bid(1, 20), bid(2, 30),
+	})
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	list := func() map[domain.AuctionId]web.AuctionListItem {
+		req, _ := http.NewRequest("GET", "/auctions", nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		var items []web.AuctionListItem
+		json.Unmarshal(rr.Body.Bytes(), &items)
+		byId := make(map[domain.AuctionId]web.AuctionListItem)
+		for _, item := range items {
+			byId[item.ID] = item
+		}
+		return byId
+	}
+
+	items := list()
+	if item := items[1]; item.Status != "Open" || item.BidCount != 2 || item.CurrentPrice == nil || *item.CurrentPrice != 20 {
+		t.Errorf("expected an open auction at 20 after 2 bids, got %+v", item)
+	}
+	if item := items[2]; item.BidCount != 1 || item.CurrentPrice != nil {
+		t.Errorf("expected an undisclosed sealed bid, got %+v", item)
+	}
+	if item := items[3]; item.Status != "NotStarted" || item.BidCount != 0 || item.CurrentPrice != nil {
+		t.Errorf("expected a not started auction without bids, got %+v", item)
+	}
+
+	now = startsAt.Add(90 * time.Minute)
+	items = list()
+	if item := items[2]; item.Status != "Ended" || item.CurrentPrice == nil || *item.CurrentPrice != 30 {
+		t.Errorf("expected the sealed bid to be disclosed once ended, got %+v", item)
+	}
+	if item := items[3]; item.Status != "Open" {
+		t.Errorf("expected auction 3 to be open, got %+v", item)
+	}
+}
@@ -0,0 +1,71 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestHealth tests the readiness probe
+func TestHealth(t *testing.T) {
+	getCurrentTime := func() time.Time { return time.Now() }
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	probe := func() int {
+		req, _ := http.NewRequest("GET", "/healthz", nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := probe(); code != http.StatusOK {
+		t.Errorf("expected status %v without health check, got %v", http.StatusOK, code)
+	}
+
+	var storeErr error
+	app.HealthCheck = func(ctx context.Context) error { return storeErr }
+	if code := probe(); code != http.StatusOK {
+		t.Errorf("expected status %v for a healthy store, got %v", http.StatusOK, code)
+	}
+
+	storeErr = errors.New("disk full")
+	if code := probe(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %v for an unhealthy store, got %v", http.StatusServiceUnavailable, code)
+	}
+}
+
+// TestDebugVars tests the metrics are only published to support users
+func TestDebugVars(t *testing.T) {
+	getCurrentTime := func() time.Time { return time.Now() }
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	get := func(jwt string) int {
+		req, _ := http.NewRequest("GET", "/debug/vars", nil)
+		if jwt != "" {
+			req.Header.Set("x-jwt-payload", jwt)
+		}
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	for jwt, expected := range map[string]int{
+		"": http.StatusUnauthorized,
+		"eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K": http.StatusForbidden,
+		"eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9":                         http.StatusOK,
+	} {
+		if code := get(jwt); code != expected {
+			t.Errorf("expected status %v for %q, got %v", expected, jwt, code)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestPriceHistory tests the price trajectory endpoint
+func TestPriceHistory(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(30 * time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	auction := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: auction},
+		domain.BidAcceptedEvent{Time: startsAt, Bid: domain.Bid{ForAuction: 1, Bidder: domain.NewBuyerOrSeller("a2", "Buyer"), At: startsAt.Add(time.Minute), Amount: 10}},
+	})
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := get("/auctions/1/history"); rr.Code != http.StatusConflict {
+		t.Errorf("expected status %v before the close, got %v", http.StatusConflict, rr.Code)
+	}
+	if rr := get("/auctions/2/history"); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %v for an unknown auction, got %v", http.StatusNotFound, rr.Code)
+	}
+
+	now = startsAt.Add(2 * time.Hour)
+	rr := get("/auctions/1/history")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var history domain.PriceHistory
+	json.Unmarshal(rr.Body.Bytes(), &history)
+	if len(history.Points) != 1 || history.Points[0].Price != 10 || history.FinalPrice == nil || *history.FinalPrice != 10 {
+		t.Errorf("expected a single bid at 10, got %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,67 @@
+package web_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/persistence"
+	"auction-site-go/internal/web"
+)
+
+// TestIdempotencyKeys tests that a retried request is rejected with a conflict
+func TestIdempotencyKeys(t *testing.T) {
+	fixedTime, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time {
+		return fixedTime
+	}
+
+	store := persistence.NewIdempotentStore(persistence.NewMemoryStore())
+	onCommand := func(command domain.Command) error {
+		return store.WriteCommands([]domain.Command{command})
+	}
+	onEvent := func(event domain.Event) error {
+		return store.WriteEvents([]domain.Event{event})
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	auctionReq := `{
+		"id": 1,
+		"startsAt": "2018-01-01T10:00:00.000Z",
+		"endsAt": "2019-01-01T10:00:00.000Z",
+		"title": "First auction",
+		"currency": "VAC"
+	}`
+
+	post := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/auctions", bytes.NewBufferString(auctionReq))
+		req.Header.Set("x-jwt-payload", sellerJWT)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "create-1")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := post(); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	rr := post()
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %v, got %v", http.StatusConflict, rr.Code)
+	}
+	expected := `{"idempotencyKey":"create-1","type":"DuplicateCommand"}`
+	if rr.Body.String() != expected {
+		t.Errorf("expected body %s, got %s", expected, rr.Body.String())
+	}
+
+	commands, _ := store.ReadCommands()
+	if len(commands) != 1 {
+		t.Errorf("expected 1 stored command, got %d", len(commands))
+	}
+}
@@ -0,0 +1,75 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestBidIncrements tests that auctions get the default increment table
+// unless their seller overrides it
+func TestBidIncrements(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func(id string) web.AuctionResponse {
+		rr := serve("GET", "/auctions/"+id, buyerJWT, "")
+		var response web.AuctionResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response
+	}
+
+	rr := serve("GET", "/bid-increments", buyerJWT, "")
+	var defaults domain.IncrementTable
+	if err := json.Unmarshal(rr.Body.Bytes(), &defaults); err != nil || defaults.String() != domain.DefaultIncrementTable.String() {
+		t.Errorf("expected the default table, got %s", rr.Body.String())
+	}
+
+	for _, request := range []struct{ url, jwt, body string }{
+		{"/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC"}`},
+		{"/auctions", sellerJWT, `{"id": 2, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "vase", "currency": "VAC", "increments": [{"from": 0, "increment": 25}]}`},
+		{"/auctions/1/bids", buyerJWT, `{"amount": 100}`},
+		{"/auctions/2/bids", buyerJWT, `{"amount": 100}`},
+	} {
+		if rr := serve("POST", request.url, request.jwt, request.body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	if auction := read("1"); auction.MinimumBid == nil || *auction.MinimumBid != 105 || len(auction.Increments) != 3 {
+		t.Errorf("expected the default table with a minimum bid of 105, got %+v", auction)
+	}
+	if auction := read("2"); auction.MinimumBid == nil || *auction.MinimumBid != 125 {
+		t.Errorf("expected the overridden table with a minimum bid of 125, got %+v", auction)
+	}
+	if rr := serve("POST", "/auctions/2/bids", buyerJWT, `{"amount": 120}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a bid not clearing the increment rejected, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = serve("POST", "/auctions", sellerJWT, `{"id": 3, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "lamp", "currency": "VAC", "increments": [{"from": 5, "increment": 1}]}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a table not starting at zero rejected, got %v: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,92 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestLiteRepresentation tests the flat representation of auctions
+func TestLiteRepresentation(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	english := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	sealed := domain.NewAuction(2, startsAt, "Painting", startsAt.Add(time.Hour), seller, domain.NewSingleSealedBidType(domain.Vickrey), domain.SEK)
+	bid := func(auction domain.AuctionId, bidder domain.UserId, amount int64) domain.Event {
+		b := domain.Bid{ForAuction: auction, Bidder: domain.NewBuyerOrSeller(bidder, "Buyer"), At: startsAt.Add(time.Second), Amount: amount}
+		return domain.BidAcceptedEvent{Time: b.At, Bid: b}
+	}
+	repo := domain.EventsToAuctionStates([]domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: english},
+		domain.AuctionAddedEvent{Time: startsAt, Auction: sealed},
+		bid(1, "a2", 10), bid(1, "a3", 15),
+		bid(2, "a2", 30), bid(2, "a3", 20),
+	})
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	list := func() map[int64]web.LiteAuction {
+		req, _ := http.NewRequest("GET", "/lite/v1/auctions", nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		var auctions []web.LiteAuction
+		json.Unmarshal(rr.Body.Bytes(), &auctions)
+		byId := make(map[int64]web.LiteAuction)
+		for _, a := range auctions {
+			byId[a.ID] = a
+		}
+		return byId
+	}
+
+	t.Run("Open", func(t *testing.T) {
+		auctions := list()
+		lite := auctions[1]
+		if lite.Status != "Open" || lite.AuctionType != "English" || lite.StartsAt != "2018-08-04T00:00:00Z" || lite.EndsAt != "2018-08-04T01:00:00Z" {
+			t.Errorf("unexpected lite auction %+v", lite)
+		}
+		if lite.BidCount != 2 || lite.HighestBid == nil || *lite.HighestBid != 15 || lite.WinnerPrice != nil {
+			t.Errorf("expected 2 bids with the highest at 15 and no winner, got %+v", lite)
+		}
+
+		// Sealed bids stay undisclosed
+		if sealed := auctions[2]; sealed.AuctionType != "Vickrey" || sealed.BidCount != 2 || sealed.HighestBid != nil {
+			t.Errorf("expected 2 undisclosed sealed bids, got %+v", sealed)
+		}
+	})
+
+	t.Run("Ended", func(t *testing.T) {
+		now = startsAt.Add(2 * time.Hour)
+		sealed := list()[2]
+		if sealed.Status != "Ended" || sealed.WinnerID != "a2" || sealed.WinnerPrice == nil || *sealed.WinnerPrice != 20 {
+			t.Errorf("expected a2 to win at the second price, got %+v", sealed)
+		}
+		if sealed.HighestBid == nil || *sealed.HighestBid != 30 {
+			t.Errorf("expected the bids to be disclosed, got %+v", sealed)
+		}
+	})
+
+	t.Run("ExplicitNulls", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/lite/v1/auctions/1", nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		var fields map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &fields)
+		for _, field := range []string{"language", "highestBid", "winnerId", "winnerPrice"} {
+			if _, ok := fields[field]; !ok {
+				t.Errorf("expected %s to be present, got %s", field, rr.Body.String())
+			}
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package web_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestEventMetadata tests the stamping of request metadata on events
+func TestEventMetadata(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2018-01-01T12:00:00Z")
+	getCurrentTime := func() time.Time { return now }
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	post := func(url, jwt, correlationId, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Content-Type", "application/json")
+		if correlationId != "" {
+			req.Header.Set("X-Correlation-Id", correlationId)
+		}
+		req.RemoteAddr = "203.0.113.7:51234"
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("StampsRequestMetadata", func(t *testing.T) {
+		rr := post("/auctions", sellerJWT, "dispute-42", `{"id": 1, "startsAt": "2018-01-01T10:00:00.000Z", "endsAt": "2019-01-01T10:00:00.000Z", "title": "Lamp", "currency": "VAC"}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("X-Correlation-Id"); got != "dispute-42" {
+			t.Errorf("expected the correlation ID to be echoed, got %q", got)
+		}
+
+		metadata := domain.GetEventMetadata(recordedEvents[len(recordedEvents)-1])
+		if metadata == nil {
+			t.Fatal("expected the event to carry metadata")
+		}
+		if metadata.CorrelationId != "dispute-42" || metadata.UserId != "a1" || metadata.SourceIP != "203.0.113.7" {
+			t.Errorf("unexpected metadata %+v", *metadata)
+		}
+		if metadata.CausationId == "" {
+			t.Error("expected a causation ID")
+		}
+	})
+
+	t.Run("GeneratesCorrelationId", func(t *testing.T) {
+		rr := post("/auctions/1/bids", buyerJWT, "not valid\n", `{"amount": 10}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		metadata := domain.GetEventMetadata(recordedEvents[len(recordedEvents)-1])
+		if metadata == nil || metadata.CorrelationId == "" || metadata.CorrelationId == "not valid\n" {
+			t.Fatalf("expected a generated correlation ID, got %+v", metadata)
+		}
+		if rr.Header().Get("X-Correlation-Id") != metadata.CorrelationId {
+			t.Errorf("expected the generated correlation ID in the response")
+		}
+		if metadata.UserId != "a2" {
+			t.Errorf("expected user a2, got %s", metadata.UserId)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package web_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestListingModeration tests that listings go through the moderator
+func TestListingModeration(t *testing.T) {
+	fixedTime, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time {
+		return fixedTime
+	}
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+	app.Moderator = domain.NewKeywordModerator([]string{"ivory"}, []string{"replica"})
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	createAuction := func(id int, title string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"id": %d, "startsAt": "2018-01-01T10:00:00.000Z", "endsAt": "2019-01-01T10:00:00.000Z", "title": %q}`, id, title)
+		req, _ := http.NewRequest("POST", "/auctions", bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", sellerJWT)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Rejected", func(t *testing.T) {
+		recordedEvents = nil
+		rr := createAuction(1, "Ivory carving")
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+		if _, ok := app.State.GetRepository()[1]; ok {
+			t.Errorf("expected the rejected listing not to be published")
+		}
+		if len(recordedEvents) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recordedEvents))
+		}
+		if e, ok := recordedEvents[0].(domain.ListingModeratedEvent); !ok || e.Decision.Verdict != domain.ModerationRejected {
+			t.Errorf("expected a rejected ListingModeratedEvent, got %#v", recordedEvents[0])
+		}
+	})
+
+	t.Run("Review", func(t *testing.T) {
+		recordedEvents = nil
+		rr := createAuction(2, "Replica watch")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if len(recordedEvents) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(recordedEvents))
+		}
+		if _, ok := recordedEvents[2].(domain.ReportFiledEvent); !ok {
+			t.Errorf("expected the listing to be queued for review, got %T", recordedEvents[2])
+		}
+		if len(app.State.GetReports()) != 1 {
+			t.Errorf("expected 1 report in the moderation queue, got %d", len(app.State.GetReports()))
+		}
+	})
+
+	t.Run("Approved", func(t *testing.T) {
+		recordedEvents = nil
+		if rr := createAuction(3, "Vintage lamp"); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		if len(recordedEvents) != 2 {
+			t.Errorf("expected 2 events, got %d", len(recordedEvents))
+		}
+	})
+}
+
+// TestProhibitedItemRules tests publishing rule sets and applying them to listings
+func TestProhibitedItemRules(t *testing.T) {
+	fixedTime, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time {
+		return fixedTime
+	}
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	request := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("PublishIsForSupportOnly", func(t *testing.T) {
+		rr := request("POST", "/admin/rules", sellerJWT, `{"rules":[{"keyword":"ivory","verdict":"Rejected"}]}`)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("RejectsInvalidRules", func(t *testing.T) {
+		rr := request("POST", "/admin/rules", supportJWT, `{"rules":[{"keyword":"ivory","verdict":"Approved"}]}`)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("PublishVersions", func(t *testing.T) {
+		request("POST", "/admin/rules", supportJWT, `{"rules":[{"keyword":"ivory","verdict":"Review"}]}`)
+		rr := request("POST", "/admin/rules", supportJWT, `{"rules":[{"keyword":"ivory","verdict":"Rejected"}]}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		rr = request("GET", "/admin/rules/1", supportJWT, "")
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected version 1 to be kept, got %v", rr.Code)
+		}
+		if len(app.State.GetRuleSets()) != 2 {
+			t.Errorf("expected 2 versions, got %d", len(app.State.GetRuleSets()))
+		}
+	})
+
+	t.Run("LatestRulesApplyToListings", func(t *testing.T) {
+		body := `{"id": 1, "startsAt": "2018-01-01T10:00:00.000Z", "endsAt": "2019-01-01T10:00:00.000Z", "title": "Ivory carving"}`
+		rr := request("POST", "/auctions", sellerJWT, body)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
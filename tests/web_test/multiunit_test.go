@@ -0,0 +1,77 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestMultiUnitAuction tests that the top bidders of a multi-unit auction
+// each win a unit, recorded once it closes
+func TestMultiUnitAuction(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	var allocated []domain.UnitsAllocatedEvent
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		if e, ok := event.(domain.UnitsAllocatedEvent); ok {
+			allocated = append(allocated, e)
+		}
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func() web.AuctionResponse {
+		var response web.AuctionResponse
+		if err := json.Unmarshal(serve("GET", "/auctions/1", "", "").Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response
+	}
+
+	for _, request := range []struct{ url, jwt, body string }{
+		{"/auctions", "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo=", `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "tickets", "currency": "VAC", "typ": "MultiUnit|2|uniform"}`},
+		{"/auctions/1/bids", "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K", `{"amount": 30}`},
+		{"/auctions/1/bids", "eyJzdWIiOiJhMyIsICJuYW1lIjoiT3RoZXIiLCAidV90eXAiOiIwIn0K", `{"amount": 20}`},
+	} {
+		if rr := serve("POST", request.url, request.jwt, request.body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	if response := read(); len(response.Allocations) != 2 || response.Winner != nil {
+		t.Errorf("expected two provisional allocations and no winner yet, got %+v", response)
+	}
+
+	now = startsAt.Add(2 * time.Hour)
+	if determined := app.DetermineWinners(context.Background()); determined != 1 {
+		t.Fatalf("expected one auction determined, got %d", determined)
+	}
+	if len(allocated) != 1 || len(allocated[0].Allocations) != 2 || allocated[0].Allocations[1].Price != 20 {
+		t.Fatalf("expected both units allocated at 20, got %+v", allocated)
+	}
+	if determined := app.DetermineWinners(context.Background()); determined != 0 {
+		t.Errorf("expected the allocations recorded once, got %d", determined)
+	}
+
+	response := read()
+	if len(response.Allocations) != 2 || response.Allocations[0].Bidder != "a2" || response.Allocations[0].Price != 20 {
+		t.Errorf("expected a2 and a3 to pay 20 each, got %+v", response.Allocations)
+	}
+}
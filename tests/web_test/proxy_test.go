@@ -0,0 +1,90 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestProxyBidding tests that the engine counters bids up to a maximum bid,
+// which only its bidder sees
+func TestProxyBidding(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	var events []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		events = append(events, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	otherJWT := "eyJzdWIiOiJhMyIsICJuYW1lIjoiT3RoZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func(jwt string) (web.AuctionResponse, string) {
+		rr := serve("GET", "/auctions/1", jwt, "")
+		var response web.AuctionResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response, rr.Body.String()
+	}
+
+	if rr := serve("POST", "/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC", "typ": "English|0|5|0"}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	rr := serve("PUT", "/auctions/1/max-bid", buyerJWT, `{"max": 100}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var response web.MaxBidResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if response.Max != 100 || !response.Leading {
+		t.Errorf("expected a2 leading under a max of 100, got %+v", response)
+	}
+
+	if rr := serve("POST", "/auctions/1/bids", otherJWT, `{"amount": 50}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	auction, _ := read(buyerJWT)
+	if len(auction.Bids) != 3 || auction.Bids[0].Bidder.ID != "a2" || auction.Bids[0].Amount != 55 {
+		t.Errorf("expected a2 to counter at 55, got %+v", auction.Bids)
+	}
+	if auction.MaxBid == nil || *auction.MaxBid != 100 {
+		t.Errorf("expected a2 to see their max bid, got %v", auction.MaxBid)
+	}
+	if auction, body := read(otherJWT); auction.MaxBid != nil || strings.Contains(body, "maxBid") {
+		t.Errorf("expected the max bid hidden from others, got %s", body)
+	}
+
+	var proxyBids int
+	for _, event := range events {
+		if _, ok := event.(domain.ProxyBidPlacedEvent); ok {
+			proxyBids++
+		}
+	}
+	if proxyBids != 2 {
+		t.Errorf("expected the opening and counter bids recorded as proxy bids, got %d", proxyBids)
+	}
+
+	if rr := serve("PUT", "/auctions/1/max-bid", otherJWT, `{"max": 55}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a max under the next bid rejected, got %v: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,95 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestReports tests filing and moderating abuse reports
+func TestReports(t *testing.T) {
+	fixedTime, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time {
+		return fixedTime
+	}
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K" // sub=a2, name=Buyer
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"                       // sub=s1, support
+
+	request := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("FileReport", func(t *testing.T) {
+		rr := request("POST", "/reports", buyerJWT, `{"target":{"userId":"a1"},"reason":"Fraud","text":"never shipped"}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if len(recordedEvents) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recordedEvents))
+		}
+		if _, ok := recordedEvents[0].(domain.ReportFiledEvent); !ok {
+			t.Errorf("expected ReportFiledEvent, got %T", recordedEvents[0])
+		}
+	})
+
+	t.Run("ReportUnknownListing", func(t *testing.T) {
+		rr := request("POST", "/reports", buyerJWT, `{"target":{"auctionId":42},"reason":"Fraud"}`)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %v, got %v", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("QueueIsForSupportOnly", func(t *testing.T) {
+		rr := request("GET", "/reports", buyerJWT, "")
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("ModerateReport", func(t *testing.T) {
+		rr := request("POST", "/reports/1/status", supportJWT, `{"status":"Triaged"}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		rr = request("GET", "/reports?status=Triaged", supportJWT, "")
+		var reports []map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &reports); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if len(reports) != 1 || reports[0]["status"] != "Triaged" || reports[0]["overdue"] != false {
+			t.Errorf("expected 1 triaged report, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("InvalidTransition", func(t *testing.T) {
+		rr := request("POST", "/reports/1/status", supportJWT, `{"status":"Open"}`)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+		expected := `{"from":"Triaged","to":"Open","type":"InvalidReportTransition"}`
+		if rr.Body.String() != expected {
+			t.Errorf("expected body %s, got %s", expected, rr.Body.String())
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestReservePrice tests that the reserve status is reported without the
+// reserve price
+func TestReservePrice(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	var events []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		events = append(events, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func(id string) web.AuctionResponse {
+		rr := serve("GET", "/auctions/"+id, buyerJWT, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		if strings.Contains(rr.Body.String(), "reservePrice") {
+			t.Errorf("expected the reserve price hidden, got %s", rr.Body.String())
+		}
+		var response web.AuctionResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response
+	}
+	countReserveEvents := func() (met, notMet int) {
+		for _, event := range events {
+			switch event.(type) {
+			case domain.ReserveMetEvent:
+				met++
+			case domain.ReserveNotMetEvent:
+				notMet++
+			}
+		}
+		return met, notMet
+	}
+
+	auction := `{"id": %s, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC", "typ": "English|50|0|0"}`
+	for _, request := range []struct{ jwt, url, body string }{
+		{sellerJWT, "/auctions", strings.Replace(auction, "%s", "1", 1)},
+		{sellerJWT, "/auctions", strings.Replace(auction, "%s", "2", 1)},
+		{buyerJWT, "/auctions/1/bids", `{"amount": 30}`},
+		{buyerJWT, "/auctions/2/bids", `{"amount": 30}`},
+	} {
+		if rr := serve("POST", request.url, request.jwt, request.body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+	if status := read("1").Reserve; status != domain.ReserveNotMet {
+		t.Errorf("expected the reserve not met, got %q", status)
+	}
+	if met, notMet := countReserveEvents(); met != 0 || notMet != 0 {
+		t.Errorf("expected nothing reported below the reserve while open, got %d met and %d not met", met, notMet)
+	}
+
+	// A bid at the reserve doesn't meet it, one over it is reported right away
+	if rr := serve("POST", "/auctions/2/bids", buyerJWT, `{"amount": 50}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if met, _ := countReserveEvents(); met != 0 {
+		t.Errorf("expected a bid at the reserve not to meet it, got %d", met)
+	}
+	if rr := serve("POST", "/auctions/2/bids", buyerJWT, `{"amount": 51}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if met, _ := countReserveEvents(); met != 1 {
+		t.Errorf("expected ReserveMet reported, got %d", met)
+	}
+	if status := read("2").Reserve; status != domain.ReserveMet {
+		t.Errorf("expected the reserve met, got %q", status)
+	}
+
+	now = startsAt.Add(time.Hour + time.Second)
+	if n := app.ReportReserves(); n != 1 {
+		t.Errorf("expected ReserveNotMet reported for one auction, got %d", n)
+	}
+	if n := app.ReportReserves(); n != 0 {
+		t.Errorf("expected the reserves reported once, got %d", n)
+	}
+	if met, notMet := countReserveEvents(); met != 1 || notMet != 1 {
+		t.Errorf("expected one of each reserve event, got %d met and %d not met", met, notMet)
+	}
+	if auction := read("1"); auction.Winner != nil || auction.Reserve != domain.ReserveNotMet {
+		t.Errorf("expected no winner below the reserve, got %+v", auction)
+	}
+	if auction := read("2"); auction.Winner == nil || *auction.Winner != "a2" {
+		t.Errorf("expected a2 to win over the reserve, got %+v", auction)
+	}
+}
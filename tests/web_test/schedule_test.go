@@ -0,0 +1,113 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestScheduledStart tests that scheduled auctions refuse bids until their
+// start, which is reported once, and that listings filter by phase
+func TestScheduledStart(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(-time.Hour)
+	getCurrentTime := func() time.Time { return now }
+
+	var events []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		events = append(events, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	list := func(phase string) []domain.AuctionId {
+		rr := serve("GET", "/auctions?phase="+phase, buyerJWT, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var items []web.AuctionListItem
+		if err := json.Unmarshal(rr.Body.Bytes(), &items); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		ids := []domain.AuctionId{}
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+	countStarted := func() int {
+		count := 0
+		for _, event := range events {
+			if _, ok := event.(domain.AuctionStartedEvent); ok {
+				count++
+			}
+		}
+		return count
+	}
+
+	auction := `{"id": %s, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC", "typ": "%t"}`
+	for _, request := range []struct{ id, typ string }{{"1", "Blind"}, {"2", "English|0|0|0"}} {
+		body := strings.Replace(strings.Replace(auction, "%s", request.id, 1), "%t", request.typ, 1)
+		if rr := serve("POST", "/auctions", sellerJWT, body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`); rr.Code == http.StatusOK {
+		t.Errorf("expected a bid before the start refused, got %v", rr.Code)
+	}
+	if ids := list("upcoming"); len(ids) != 2 {
+		t.Errorf("expected both auctions upcoming, got %v", ids)
+	}
+	if n := app.StartAuctions(); n != 0 {
+		t.Errorf("expected nothing started before the start time, got %d", n)
+	}
+
+	now = startsAt.Add(time.Minute)
+	if n := app.StartAuctions(); n != 2 {
+		t.Errorf("expected both auctions started, got %d", n)
+	}
+	if n := app.StartAuctions(); n != 0 {
+		t.Errorf("expected the starts reported once, got %d", n)
+	}
+	if n := countStarted(); n != 2 {
+		t.Errorf("expected two AuctionStarted events, got %d", n)
+	}
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`); rr.Code != http.StatusOK {
+		t.Errorf("expected the bid accepted once started, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if ids := list("live"); len(ids) != 2 {
+		t.Errorf("expected both auctions live, got %v", ids)
+	}
+	if ids := list("upcoming"); len(ids) != 0 {
+		t.Errorf("expected no upcoming auctions, got %v", ids)
+	}
+
+	if rr := serve("DELETE", "/auctions/2", sellerJWT, ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if ids := list("ended"); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("expected the cancelled auction ended, got %v", ids)
+	}
+
+	if rr := serve("GET", "/auctions?phase=soon", buyerJWT, ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %v for an unknown phase, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package web_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestScreening tests that denied parties can't list or place high-value bids
+func TestScreening(t *testing.T) {
+	fixedTime, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time {
+		return fixedTime
+	}
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+	app.Screener = domain.NewDenyListScreener([]string{"a2"})
+	app.ScreeningThreshold = 100
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K" // sub=a2, denied
+	request := func(url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request("/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-01-01T10:00:00.000Z", "endsAt": "2019-01-01T10:00:00.000Z", "title": "auction"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	t.Run("LowValueBidsAreNotScreened", func(t *testing.T) {
+		recordedEvents = nil
+		if rr := request("/auctions/1/bids", buyerJWT, `{"amount": 10}`); rr.Code != http.StatusOK {
+			t.Errorf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		if len(recordedEvents) != 1 {
+			t.Errorf("expected only the bid event, got %d events", len(recordedEvents))
+		}
+	})
+
+	t.Run("HighValueBidsAreBlocked", func(t *testing.T) {
+		recordedEvents = nil
+		rr := request("/auctions/1/bids", buyerJWT, `{"amount": 200}`)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+		if rr.Body.String() != `{"type":"UserBlocked"}` {
+			t.Errorf("unexpected body %s", rr.Body.String())
+		}
+		if len(recordedEvents) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recordedEvents))
+		}
+		if e, ok := recordedEvents[0].(domain.UserScreenedEvent); !ok || !e.Result.Match {
+			t.Errorf("expected a matching UserScreenedEvent, got %#v", recordedEvents[0])
+		}
+	})
+}
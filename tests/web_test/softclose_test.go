@@ -0,0 +1,85 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestSoftClose tests that a late bid extends an auction, which is recorded
+// and shown in its reads
+func TestSoftClose(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	endsAt := startsAt.Add(time.Hour)
+	now := startsAt
+	getCurrentTime := func() time.Time { return now }
+
+	var extensions []domain.AuctionExtendedEvent
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		if e, ok := event.(domain.AuctionExtendedEvent); ok {
+			extensions = append(extensions, e)
+		}
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("POST", "/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC", "typ": "English|0|0|0|0|0||30:60:1"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	now = endsAt.Add(-time.Minute)
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 10}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if len(extensions) != 0 {
+		t.Errorf("expected a bid before the window not to extend, got %+v", extensions)
+	}
+
+	now = endsAt.Add(-10 * time.Second)
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 20}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	extended := endsAt.Add(time.Minute)
+	if len(extensions) != 1 || !extensions[0].EndsAt.Equal(extended) || extensions[0].Extensions != 1 {
+		t.Fatalf("expected an extension to %v, got %+v", extended, extensions)
+	}
+
+	var response web.AuctionResponse
+	if err := json.Unmarshal(serve("GET", "/auctions/1", buyerJWT, "").Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.ExtendedUntil == nil || !response.ExtendedUntil.Equal(extended) || response.Extensions != 1 {
+		t.Errorf("expected the auction extended until %v, got %+v", extended, response)
+	}
+
+	// The extensions are used up
+	now = extended.Add(-10 * time.Second)
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 30}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if len(extensions) != 1 {
+		t.Errorf("expected no more extensions, got %+v", extensions)
+	}
+	now = extended
+	if rr := serve("POST", "/auctions/1/bids", buyerJWT, `{"amount": 40}`); rr.Code == http.StatusOK {
+		t.Errorf("expected the auction closed at %v", extended)
+	}
+}
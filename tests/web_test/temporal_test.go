@@ -0,0 +1,78 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestAuctionAsOf tests reading an auction as it was at a point in time
+func TestAuctionAsOf(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt.Add(2 * time.Hour) }
+
+	seller := domain.NewBuyerOrSeller("a1", "Test")
+	buyer := domain.NewBuyerOrSeller("a2", "Buyer")
+	auction := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), seller, domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	stored := []domain.Event{
+		domain.AuctionAddedEvent{Time: startsAt, Auction: auction},
+		domain.BidAcceptedEvent{Time: startsAt.Add(time.Minute), Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: startsAt.Add(time.Minute), Amount: 10}},
+		domain.BidAcceptedEvent{Time: startsAt.Add(2 * time.Minute), Bid: domain.Bid{ForAuction: 1, Bidder: buyer, At: startsAt.Add(2 * time.Minute), Amount: 20}},
+	}
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.EventsToAuctionStates(stored), onCommand, onEvent, getCurrentTime)
+
+	get := func(url string) (web.AuctionResponse, int) {
+		req, _ := http.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		var response web.AuctionResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return response, rr.Code
+	}
+
+	t.Run("NoEventHistory", func(t *testing.T) {
+		if _, code := get("/auctions/1?asOf=2018-08-04T00:01:30Z"); code != http.StatusNotFound {
+			t.Errorf("expected status %v, got %v", http.StatusNotFound, code)
+		}
+	})
+
+	app.ReadEventsSince = func(position int64) ([]domain.Event, error) {
+		return stored[position:], nil
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		bids   int
+		winner bool
+	}{
+		{"Now", "/auctions/1", http.StatusOK, 2, true},
+		{"AtTime", "/auctions/1?asOf=2018-08-04T00:01:30Z", http.StatusOK, 1, false},
+		{"AtPosition", "/auctions/1?asOf=1", http.StatusOK, 0, false},
+		{"AfterEnd", "/auctions/1?asOf=2018-08-04T01:30:00Z", http.StatusOK, 2, true},
+		{"BeforeListing", "/auctions/1?asOf=2018-08-03T00:00:00Z", http.StatusNotFound, 0, false},
+		{"Invalid", "/auctions/1?asOf=yesterday", http.StatusBadRequest, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, code := get(tt.url)
+			if code != tt.status {
+				t.Fatalf("expected status %v, got %v", tt.status, code)
+			}
+			if code != http.StatusOK {
+				return
+			}
+			if len(response.Bids) != tt.bids || (response.Winner != nil) != tt.winner {
+				t.Errorf("expected %d bids and winner %v, got %+v", tt.bids, tt.winner, response)
+			}
+		})
+	}
+}
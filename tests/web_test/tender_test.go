@@ -0,0 +1,139 @@
+package web_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestTender tests bidding on a tender and revealing it with key shares
+func TestTender(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	publicKey, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	split, err := domain.SplitSecret(x509.MarshalPKCS1PrivateKey(key), 2, 2, rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to split key: %v", err)
+	}
+	ciphertext, _ := rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, []byte("42"), nil)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	strangerJWT := "eyJzdWIiOiJhMyIsICJuYW1lIjoiU3RyYW5nZXIiLCAidV90eXAiOiIwIn0K"
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	post := func(url, jwt string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", url, bytes.NewBuffer(data))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	share := func(i int) map[string]string {
+		return map[string]string{"share": base64.StdEncoding.EncodeToString(split[i])}
+	}
+
+	rr := post("/auctions", sellerJWT, map[string]interface{}{
+		"id":       1,
+		"startsAt": startsAt,
+		"endsAt":   startsAt.Add(time.Hour),
+		"title":    "Road works",
+		"currency": "VAC",
+		"typ":      "Blind",
+		"tender": map[string]interface{}{
+			"publicKey":  base64.StdEncoding.EncodeToString(publicKey),
+			"threshold":  2,
+			"custodians": []string{"a3", "s1"},
+		},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	t.Run("PlainBid", func(t *testing.T) {
+		rr := post("/auctions/1/bids", buyerJWT, map[string]interface{}{"amount": 42})
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("EncryptedBid", func(t *testing.T) {
+		rr := post("/auctions/1/bids", buyerJWT, map[string]interface{}{"sealed": base64.StdEncoding.EncodeToString(ciphertext)})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("ShareBeforeClose", func(t *testing.T) {
+		rr := post("/admin/auctions/1/key-shares", strangerJWT, share(0))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	now = startsAt.Add(2 * time.Hour)
+
+	t.Run("NotACustodian", func(t *testing.T) {
+		rr := post("/admin/auctions/1/key-shares", buyerJWT, share(0))
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("Reveal", func(t *testing.T) {
+		recordedEvents = nil
+		if rr := post("/admin/auctions/1/key-shares", strangerJWT, share(0)); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if rr := post("/admin/auctions/1/key-shares", supportJWT, share(1)); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if len(recordedEvents) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(recordedEvents))
+		}
+		revealed, ok := recordedEvents[2].(domain.TenderRevealedEvent)
+		if !ok || revealed.Amounts["a2"] != 42 {
+			t.Errorf("expected the bid to be revealed, got %+v", recordedEvents[2])
+		}
+
+		req, _ := http.NewRequest("GET", "/auctions/1", nil)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		var auction struct {
+			Winner      string `json:"winner"`
+			WinnerPrice int64  `json:"winnerPrice"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &auction); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if auction.Winner != "a2" || auction.WinnerPrice != 42 {
+			t.Errorf("expected a2 to win at 42, got %+v", auction)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package web_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestServerTiming tests the processing time breakdown of bids
+func TestServerTiming(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	auction := domain.NewAuction(1, startsAt, "Old car", startsAt.Add(time.Hour), domain.NewBuyerOrSeller("a1", "Test"), domain.NewTimedAscendingType(domain.DefaultTimedAscendingOptions()), domain.VAC)
+	repo := domain.EventsToAuctionStates([]domain.Event{domain.AuctionAddedEvent{Time: startsAt, Auction: auction}})
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(repo, onCommand, onEvent, getCurrentTime)
+
+	bid := func(url string, amount string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", url, bytes.NewBufferString(`{"amount":`+amount+`}`))
+		req.Header.Set("x-jwt-payload", "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K")
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("NotRequested", func(t *testing.T) {
+		rr := bid("/auctions/1/bids", "10")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if header := rr.Header().Get("Server-Timing"); header != "" {
+			t.Errorf("expected no Server-Timing header, got %s", header)
+		}
+	})
+
+	t.Run("Requested", func(t *testing.T) {
+		rr := bid("/auctions/1/bids?debug=timing", "20")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		header := rr.Header().Get("Server-Timing")
+		for _, phase := range []string{"screening;dur=", "command;dur=", "validation;dur=", "persistence;dur=", "total;dur="} {
+			if !strings.Contains(header, phase) {
+				t.Errorf("expected %s in Server-Timing header, got %s", phase, header)
+			}
+		}
+	})
+
+	t.Run("RejectedBid", func(t *testing.T) {
+		rr := bid("/auctions/1/bids?debug=timing", "5")
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+		}
+		header := rr.Header().Get("Server-Timing")
+		if !strings.Contains(header, "validation;dur=") || strings.Contains(header, "persistence;dur=") {
+			t.Errorf("expected timings up to validation, got %s", header)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// prefixTranslator is a translation provider prefixing titles with the language
+type prefixTranslator struct{}
+
+func (prefixTranslator) TranslateListing(auction domain.Auction, language string) (domain.Translation, error) {
+	return domain.Translation{Language: language, Title: language + ": " + auction.Title, Source: "prefix"}, nil
+}
+
+// TestListingTranslations tests serving listings in the language of the client
+func TestListingTranslations(t *testing.T) {
+	fixedTime, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time {
+		return fixedTime
+	}
+
+	var recordedEvents []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		recordedEvents = append(recordedEvents, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+	app.Translator = prefixTranslator{}
+	app.TranslationLanguages = []string{"sv", "fr"}
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	request := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	getAuction := func(acceptLanguage string) web.AuctionResponse {
+		req, _ := http.NewRequest("GET", "/auctions/1", nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		var response web.AuctionResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return response
+	}
+
+	t.Run("CreateWithTranslations", func(t *testing.T) {
+		rr := request("POST", "/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-01-01T10:00:00.000Z", "endsAt": "2019-01-01T10:00:00.000Z", "title": "Old car", "translations": {"sv": "Gammal bil"}}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		// Only the language the seller didn't supply is machine translated
+		if len(recordedEvents) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(recordedEvents))
+		}
+		translated, ok := recordedEvents[1].(domain.ListingTranslatedEvent)
+		if !ok || translated.Translation.Language != "fr" || translated.Translation.Source != "prefix" {
+			t.Errorf("expected a provider translation to fr, got %#v", recordedEvents[1])
+		}
+	})
+
+	t.Run("BestMatch", func(t *testing.T) {
+		tests := []struct {
+			acceptLanguage string
+			title          string
+			language       string
+		}{
+			{"", "Old car", ""},
+			{"sv-SE", "Gammal bil", "sv"},
+			{"de, fr;q=0.5, sv;q=0.8", "Gammal bil", "sv"},
+			{"sv;q=0, fr", "fr: Old car", "fr"},
+			{"de", "Old car", ""},
+		}
+		for _, tt := range tests {
+			response := getAuction(tt.acceptLanguage)
+			if response.Title != tt.title || response.Language != tt.language {
+				t.Errorf("expected %q in %q for %q, got %q in %q", tt.title, tt.language, tt.acceptLanguage, response.Title, response.Language)
+			}
+		}
+	})
+
+	t.Run("SellerTranslates", func(t *testing.T) {
+		rr := request("POST", "/auctions/1/translations", sellerJWT, `{"language": "de", "title": "Altes Auto"}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if response := getAuction("de-AT"); response.Title != "Altes Auto" {
+			t.Errorf("expected the German title, got %q", response.Title)
+		}
+	})
+
+	t.Run("OnlySeller", func(t *testing.T) {
+		rr := request("POST", "/auctions/1/translations", buyerJWT, `{"language": "de", "title": "Auto"}`)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %v, got %v", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("InvalidLanguage", func(t *testing.T) {
+		rr := request("POST", "/auctions/1/translations", sellerJWT, `{"language": "not a tag", "title": "Auto"}`)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		repo := domain.EventsToAuctionStates(recordedEvents)
+		title, _ := repo[1].Auction.LocalizedTitle([]string{"de"})
+		if title != "Altes Auto" {
+			t.Errorf("expected translations to be restored from events, got %q", title)
+		}
+	})
+}
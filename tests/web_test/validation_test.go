@@ -0,0 +1,76 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestCommandValidation tests rendering invalid commands as problem+json
+func TestCommandValidation(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt.Add(time.Minute) }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	problem := func(url, jwt, body string) web.ProblemDetails {
+		req, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+		}
+		if contentType := rr.Header().Get("Content-Type"); contentType != "application/problem+json" {
+			t.Errorf("expected a problem+json response, got %s", contentType)
+		}
+		var problem web.ProblemDetails
+		if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return problem
+	}
+
+	t.Run("AddAuction", func(t *testing.T) {
+		p := problem("/auctions", sellerJWT, `{"id": 1, "startsAt": "2018-08-05T00:00:00Z", "endsAt": "2018-08-04T12:00:00Z", "title": "", "currency": "VAC"}`)
+		if p.Type != "InvalidCommand" || p.Status != http.StatusBadRequest || p.Command != "AddAuctionCommand" {
+			t.Errorf("unexpected problem %+v", p)
+		}
+		expected := []web.FieldProblem{
+			{Field: "title", Code: domain.FieldRequired, Message: "title is required"},
+			{Field: "endsAt", Code: domain.FieldMustBeAfter, Message: "endsAt must be after startsAt"},
+		}
+		if len(p.Errors) != len(expected) || p.Errors[0] != expected[0] || p.Errors[1] != expected[1] {
+			t.Errorf("expected %+v, got %+v", expected, p.Errors)
+		}
+	})
+
+	t.Run("MalformedBody", func(t *testing.T) {
+		p := problem("/auctions/1/bids", buyerJWT, `{"amount": "ten"}`)
+		if len(p.Errors) != 1 || p.Errors[0].Field != "amount" || p.Errors[0].Code != domain.FieldMalformed {
+			t.Errorf("expected the amount malformed, got %+v", p.Errors)
+		}
+
+		p = problem("/auctions/1/bids", buyerJWT, `{"amount": `)
+		if len(p.Errors) != 1 || p.Errors[0].Field != "" || p.Errors[0].Code != domain.FieldMalformed {
+			t.Errorf("expected the body malformed, got %+v", p.Errors)
+		}
+	})
+
+	t.Run("PlaceBid", func(t *testing.T) {
+		p := problem("/auctions/1/bids", buyerJWT, `{"amount": 0}`)
+		if len(p.Errors) != 1 || p.Errors[0].Field != "amount" || p.Errors[0].Message != "amount must be positive" {
+			t.Errorf("expected the amount invalid, got %+v", p.Errors)
+		}
+	})
+}
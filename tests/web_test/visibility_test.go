@@ -0,0 +1,104 @@
+package web_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestAuctionVisibility tests that unlisted and private auctions stay out of
+// listings and private ones out of reach of those not invited
+func TestAuctionVisibility(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	getCurrentTime := func() time.Time { return startsAt.Add(time.Minute) }
+
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error { return nil }
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	invitedJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	// a3, not invited
+	strangerJWT := "eyJzdWIiOiJhMyIsICJuYW1lIjoiU3RyYW5nZXIiLCAidV90eXAiOiIwIn0K"
+	supportJWT := "eyJzdWIiOiJzMSIsInVfdHlwIjoiMSJ9"
+	request := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		if jwt != "" {
+			req.Header.Set("x-jwt-payload", jwt)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, body := range []string{
+		`{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-05T00:00:00Z", "title": "Public"}`,
+		`{"id": 2, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-05T00:00:00Z", "title": "Unlisted", "visibility": "unlisted"}`,
+		`{"id": 3, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-05T00:00:00Z", "title": "Private", "visibility": "private", "invitees": ["a2"]}`,
+	} {
+		if rr := request("POST", "/auctions", sellerJWT, body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	t.Run("InvalidVisibility", func(t *testing.T) {
+		rr := request("POST", "/auctions", sellerJWT, `{"id": 4, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-05T00:00:00Z", "title": "Hidden", "visibility": "hidden"}`)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %v, got %v", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("Listings", func(t *testing.T) {
+		for _, url := range []string{"/auctions", "/lite/v1/auctions"} {
+			var items []struct {
+				ID int64 `json:"id"`
+			}
+			json.Unmarshal(request("GET", url, sellerJWT, "").Body.Bytes(), &items)
+			if len(items) != 1 || items[0].ID != 1 {
+				t.Errorf("expected only the public auction in %s, got %v", url, items)
+			}
+		}
+	})
+
+	t.Run("Direct", func(t *testing.T) {
+		tests := []struct {
+			url    string
+			jwt    string
+			status int
+		}{
+			{"/auctions/2", "", http.StatusOK},
+			{"/lite/v1/auctions/2", "", http.StatusOK},
+			{"/auctions/3", "", http.StatusNotFound},
+			{"/auctions/3", strangerJWT, http.StatusNotFound},
+			{"/auctions/3/countdown", strangerJWT, http.StatusNotFound},
+			{"/lite/v1/auctions/3", strangerJWT, http.StatusNotFound},
+			{"/auctions/3", invitedJWT, http.StatusOK},
+			{"/auctions/3", sellerJWT, http.StatusOK},
+			{"/auctions/3", supportJWT, http.StatusOK},
+		}
+		for _, tt := range tests {
+			if rr := request("GET", tt.url, tt.jwt, ""); rr.Code != tt.status {
+				t.Errorf("expected status %v for %s as %q, got %v", tt.status, tt.url, tt.jwt, rr.Code)
+			}
+		}
+	})
+
+	t.Run("Bids", func(t *testing.T) {
+		if rr := request("POST", "/auctions/3/bids", strangerJWT, `{"amount": 10}`); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %v for a stranger, got %v", http.StatusNotFound, rr.Code)
+		}
+		if rr := request("POST", "/auctions/3/bids", invitedJWT, `{"amount": 10}`); rr.Code != http.StatusOK {
+			t.Errorf("expected status %v for an invitee, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if rr := request("POST", "/auctions/2/bids", strangerJWT, `{"amount": 10}`); rr.Code != http.StatusOK {
+			t.Errorf("expected status %v on an unlisted auction, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	})
+}
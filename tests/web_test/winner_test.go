@@ -0,0 +1,88 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auction-site-go/internal/domain"
+	"auction-site-go/internal/web"
+)
+
+// TestSealedBidAuction tests that sealed bids stay hidden until the close,
+// when the winner is determined
+func TestSealedBidAuction(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2018-08-04T00:00:00Z")
+	now := startsAt.Add(time.Minute)
+	getCurrentTime := func() time.Time { return now }
+
+	var events []domain.Event
+	onCommand := func(command domain.Command) error { return nil }
+	onEvent := func(event domain.Event) error {
+		events = append(events, event)
+		return nil
+	}
+	app := web.NewApp(domain.Repository{}, onCommand, onEvent, getCurrentTime)
+
+	sellerJWT := "eyJzdWIiOiJhMSIsICJuYW1lIjoiVGVzdCIsICJ1X3R5cCI6IjAifQo="
+	buyerJWT := "eyJzdWIiOiJhMiIsICJuYW1lIjoiQnV5ZXIiLCAidV90eXAiOiIwIn0K"
+	otherJWT := "eyJzdWIiOiJhMyIsICJuYW1lIjoiT3RoZXIiLCAidV90eXAiOiIwIn0K"
+	serve := func(method, url, jwt, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		req.Header.Set("x-jwt-payload", jwt)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	read := func(jwt string) web.AuctionResponse {
+		rr := serve("GET", "/auctions/1", jwt, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+		var response web.AuctionResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		return response
+	}
+
+	body := `{"id": 1, "startsAt": "2018-08-04T00:00:00Z", "endsAt": "2018-08-04T01:00:00Z", "title": "painting", "currency": "VAC", "typ": "Vickrey"}`
+	for _, request := range []struct{ jwt, url, body string }{
+		{sellerJWT, "/auctions", body},
+		{buyerJWT, "/auctions/1/bids", `{"amount": 30}`},
+		{otherJWT, "/auctions/1/bids", `{"amount": 20}`},
+	} {
+		if rr := serve("POST", request.url, request.jwt, request.body); rr.Code != http.StatusOK {
+			t.Fatalf("expected status %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	if bids := read(buyerJWT).Bids; len(bids) != 1 || bids[0].Amount != 30 {
+		t.Errorf("expected the buyer to only see their bid, got %+v", bids)
+	}
+	if bids := read(sellerJWT).Bids; len(bids) != 0 {
+		t.Errorf("expected the seller to see no bids, got %+v", bids)
+	}
+	if n := app.DetermineWinners(context.Background()); n != 0 {
+		t.Errorf("expected no winners before the close, got %d", n)
+	}
+
+	now = startsAt.Add(time.Hour + time.Second)
+	if n := app.DetermineWinners(context.Background()); n != 1 {
+		t.Fatalf("expected the winner determined, got %d", n)
+	}
+	if n := app.DetermineWinners(context.Background()); n != 0 {
+		t.Errorf("expected the winner determined once, got %d", n)
+	}
+	determined, ok := events[len(events)-1].(domain.WinnerDeterminedEvent)
+	if !ok || determined.Winner != "a2" || determined.Price != 20 {
+		t.Errorf("expected a2 to win at the second price of 20, got %+v", events[len(events)-1])
+	}
+	if auction := read(sellerJWT); len(auction.Bids) != 2 || auction.WinnerPrice == nil || *auction.WinnerPrice != 20 {
+		t.Errorf("expected both bids disclosed after the close, got %+v", auction)
+	}
+}